@@ -0,0 +1,227 @@
+// Package aggregator lets a relayer combine the Groth16 revocation token proofs of many MultiShow presentations into
+// one Groth16 proof of the zkp.RevocationTokenAggregation circuit, so it posts one proof per block instead of one per
+// presentation. The aggregate proof reveals the same tokens and challenges as the single proofs, so the verifier still
+// tests each token against the revocation artifact and consumes each nonce (see MultiShowAggregateVerifier).
+package aggregator
+
+import (
+	"PrivacyPreservingRevocationCode/holder"
+	"PrivacyPreservingRevocationCode/zkp"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	groth16bn254 "github.com/consensys/gnark/backend/groth16/bn254"
+	"github.com/consensys/gnark/backend/solidity"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_bn254"
+	stdgroth16 "github.com/consensys/gnark/std/recursion/groth16"
+	"io"
+	"math/big"
+	"os"
+)
+
+// Aggregator proves the aggregation circuit of a fixed number of revocation token proofs. It is safe for concurrent
+// use.
+type Aggregator struct {
+	n       int                         // n is the number of proofs an aggregate proof covers.
+	innerVk groth16.VerifyingKey        // innerVk is the verifying key of the aggregated revocation token proofs.
+	cs      constraint.ConstraintSystem // cs is the constraint system of the zkp.RevocationTokenAggregation circuit.
+	pk      groth16.ProvingKey          // pk is the Groth16 proving key of the aggregation circuit.
+	vk      groth16.VerifyingKey        // vk is the Groth16 verifying key of the aggregation circuit.
+}
+
+// AggregateProof is an aggregate proof with its public inputs.
+type AggregateProof struct {
+	Proof      groth16.Proof // Proof is the Groth16 proof of the aggregation circuit.
+	IssuerX    *big.Int      // IssuerX is the x-coordinate of the issuer's public key.
+	IssuerY    *big.Int      // IssuerY is the y-coordinate of the issuer's public key.
+	Tokens     []*big.Int    // Tokens are the revocation tokens of the aggregated proofs, in their order.
+	Epoch      int64         // Epoch is the unix epoch of all aggregated proofs.
+	Challenges []*big.Int    // Challenges are the challenges of the aggregated proofs, in their order.
+}
+
+// NewAggregator compiles the circuit aggregating n proofs that verify under the revocation token verifying key at
+// innerVkPath and reads the aggregation circuit's keys, e.g. as written by zkp.SetupAggregation.
+func NewAggregator(n int, innerVkPath, pkPath, vkPath string) (*Aggregator, error) {
+	innerVk := groth16.NewVerifyingKey(ecc.BN254)
+	err := readKey(innerVkPath, innerVk)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read revocation token verifying key: %w", err)
+	}
+	cs, err := zkp.CompileAggregation(n, innerVk)
+	if err != nil {
+		return nil, err
+	}
+	pk := groth16.NewProvingKey(ecc.BN254)
+	err = readKey(pkPath, pk)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read proving key: %w", err)
+	}
+	vk := groth16.NewVerifyingKey(ecc.BN254)
+	err = readKey(vkPath, vk)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read verifying key: %w", err)
+	}
+	// The verifying key counts the commitment of the circuit's range checks as a public input.
+	nbPublic := cs.GetNbPublicVariables() - 1 + len(cs.GetCommitments().CommitmentIndexes())
+	if vk.NbPublicWitness() != nbPublic {
+		return nil, fmt.Errorf("verifying key has %d public inputs, the circuit of %d proofs %d", vk.NbPublicWitness(), n, nbPublic)
+	}
+	return &Aggregator{n: n, innerVk: innerVk, cs: cs, pk: pk, vk: vk}, nil
+}
+
+// readKey reads a proving or verifying key from the file at path.
+func readKey(path string, key io.ReaderFrom) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = key.ReadFrom(f)
+	return err
+}
+
+// N returns the number of proofs an aggregate proof covers.
+func (a *Aggregator) N() int {
+	return a.n
+}
+
+// Aggregate proves that all proofs verify, which must be N Groth16 proofs for the same issuer and epoch, e.g. as
+// returned by holder.RevocationTokenProver.Prove. Each proof is verified first, so an invalid proof fails with its
+// index instead of an unsatisfied circuit. The proof targets the Solidity verifier (see ExportSolidity).
+func (a *Aggregator) Aggregate(proofs []*holder.TokenProof) (*AggregateProof, error) {
+	circuit, result, err := a.assignment(proofs)
+	if err != nil {
+		return nil, err
+	}
+	fullWitness, err := frontend.NewWitness(circuit, ecc.BN254.ScalarField())
+	if err != nil {
+		return nil, err
+	}
+	result.Proof, err = groth16.Prove(a.cs, a.pk, fullWitness, solidity.WithProverTargetSolidityVerifier(backend.GROTH16))
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// assignment verifies the proofs and returns the assignment of the aggregation circuit with the aggregate proof's
+// public inputs, which lacks the proof itself.
+func (a *Aggregator) assignment(proofs []*holder.TokenProof) (*zkp.RevocationTokenAggregation, *AggregateProof, error) {
+	if len(proofs) != a.n {
+		return nil, nil, fmt.Errorf("got %d proofs, the aggregator covers %d", len(proofs), a.n)
+	}
+	circuit, err := zkp.NewRevocationTokenAggregation(a.n, a.innerVk)
+	if err != nil {
+		return nil, nil, err
+	}
+	first := proofs[0].PublicInputs
+	result := &AggregateProof{IssuerX: first.IssuerX, IssuerY: first.IssuerY, Epoch: first.Epoch}
+	circuit.IssuerX, circuit.IssuerY, circuit.Epoch = first.IssuerX, first.IssuerY, first.Epoch
+	for i, p := range proofs {
+		if p.Backend != zkp.Groth16 {
+			return nil, nil, fmt.Errorf("proof %d: aggregation needs %s proofs, got %s", i, zkp.Groth16, p.Backend)
+		}
+		inputs := p.PublicInputs
+		if inputs.IssuerX.Cmp(first.IssuerX) != 0 || inputs.IssuerY.Cmp(first.IssuerY) != 0 || inputs.Epoch != first.Epoch {
+			return nil, nil, fmt.Errorf("proof %d: issuer or epoch differs from the first proof", i)
+		}
+		proof := groth16.NewProof(ecc.BN254)
+		_, err = proof.ReadFrom(bytes.NewReader(p.Proof))
+		if err != nil {
+			return nil, nil, fmt.Errorf("proof %d: %w", i, err)
+		}
+		publicWitness, err := inputs.Witness()
+		if err != nil {
+			return nil, nil, fmt.Errorf("proof %d: %w", i, err)
+		}
+		err = groth16.Verify(proof, a.innerVk, publicWitness)
+		if err != nil {
+			return nil, nil, fmt.Errorf("proof %d: %w", i, err)
+		}
+
+		circuit.Proofs[i], err = stdgroth16.ValueOfProof[sw_bn254.G1Affine, sw_bn254.G2Affine](proof)
+		if err != nil {
+			return nil, nil, fmt.Errorf("proof %d: %w", i, err)
+		}
+		circuit.Tokens[i] = inputs.RevocationToken
+		circuit.Challenges[i] = inputs.Challenge
+		result.Tokens = append(result.Tokens, inputs.RevocationToken)
+		result.Challenges = append(result.Challenges, inputs.Challenge)
+	}
+	return circuit, result, nil
+}
+
+// Verify verifies an aggregate proof against its public inputs, like the Solidity verifier.
+func (a *Aggregator) Verify(p *AggregateProof) error {
+	publicWitness, err := p.witness()
+	if err != nil {
+		return err
+	}
+	return groth16.Verify(p.Proof, a.vk, publicWitness, solidity.WithVerifierTargetSolidityVerifier(backend.GROTH16))
+}
+
+// ExportSolidity writes the Solidity verifier contract of the aggregation circuit's verifying key. Its verifyProof
+// takes the words of AggregateProof.Solidity and the PublicInputs.
+func (a *Aggregator) ExportSolidity(w io.Writer) error {
+	return a.vk.ExportSolidity(w)
+}
+
+// PublicInputs returns the public inputs in the order of the aggregation circuit and its Solidity verifier: the
+// issuer's key, the tokens, the epoch and the challenges.
+func (p *AggregateProof) PublicInputs() []*big.Int {
+	inputs := []*big.Int{p.IssuerX, p.IssuerY}
+	inputs = append(inputs, p.Tokens...)
+	inputs = append(inputs, big.NewInt(p.Epoch))
+	return append(inputs, p.Challenges...)
+}
+
+// witness returns the public witness of the aggregation circuit for the public inputs.
+func (p *AggregateProof) witness() (witness.Witness, error) {
+	if len(p.Tokens) == 0 || len(p.Challenges) != len(p.Tokens) {
+		return nil, errors.New("aggregate proof needs a challenge per token")
+	}
+	assignment := &zkp.RevocationTokenAggregation{
+		IssuerX:    p.IssuerX,
+		IssuerY:    p.IssuerY,
+		Tokens:     make([]frontend.Variable, len(p.Tokens)),
+		Epoch:      p.Epoch,
+		Challenges: make([]frontend.Variable, len(p.Challenges)),
+	}
+	for i := range p.Tokens {
+		assignment.Tokens[i] = p.Tokens[i]
+		assignment.Challenges[i] = p.Challenges[i]
+	}
+	return frontend.NewWitness(assignment, ecc.BN254.ScalarField(), frontend.PublicOnly())
+}
+
+// Solidity returns the proof in the form of the Solidity verifier's verifyProof: the eight words of Ar, Bs and Krs,
+// the commitment of the circuit's range checks and its proof of knowledge.
+func (p *AggregateProof) Solidity() (proof [8]*big.Int, commitments [2]*big.Int, commitmentPok [2]*big.Int, err error) {
+	bn254Proof, ok := p.Proof.(*groth16bn254.Proof)
+	if !ok || len(bn254Proof.Commitments) != 1 {
+		return proof, commitments, commitmentPok, errors.New("aggregate proof must be a BN254 proof with one commitment")
+	}
+	// MarshalSolidity encodes Ar | Bs | Krs | uint32 number of commitments | commitments | commitment pok.
+	raw := bn254Proof.MarshalSolidity()
+	const word = fr.Bytes
+	if len(raw) != 8*word+4+4*word || binary.BigEndian.Uint32(raw[8*word:]) != 1 {
+		return proof, commitments, commitmentPok, errors.New("unexpected proof encoding")
+	}
+	for i := range proof {
+		proof[i] = new(big.Int).SetBytes(raw[i*word : (i+1)*word])
+	}
+	rest := raw[8*word+4:]
+	for i := range commitments {
+		commitments[i] = new(big.Int).SetBytes(rest[i*word : (i+1)*word])
+		commitmentPok[i] = new(big.Int).SetBytes(rest[(i+2)*word : (i+3)*word])
+	}
+	return proof, commitments, commitmentPok, nil
+}
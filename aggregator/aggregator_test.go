@@ -0,0 +1,161 @@
+package aggregator
+
+import (
+	"PrivacyPreservingRevocationCode/holder"
+	"PrivacyPreservingRevocationCode/issuer"
+	"PrivacyPreservingRevocationCode/zkp"
+	"bytes"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const (
+	innerPkPath = "../zkp/sol/build/verifier.g16.pk"
+	innerVkPath = "../zkp/sol/build/verifier.g16.vk"
+)
+
+// aggregationKeysEnv names the directory holding aggregation.g16.pk and aggregation.g16.vk of the aggregation circuit
+// of one proof, as written by zkp.SetupAggregation. The setup takes minutes, so tests needing the keys are skipped
+// without it; TestAggregator_Assignment checks the circuit's assignment without keys.
+const aggregationKeysEnv = "AGGREGATION_KEYS"
+
+// newTestAggregator returns the aggregator of one proof with the keys of aggregationKeysEnv.
+func newTestAggregator(tb testing.TB) *Aggregator {
+	dir := os.Getenv(aggregationKeysEnv)
+	if dir == "" {
+		tb.Skipf("set %s to the keys of zkp.SetupAggregation(1, ...)", aggregationKeysEnv)
+	}
+	a, err := NewAggregator(1, innerVkPath, filepath.Join(dir, "aggregation.g16.pk"), filepath.Join(dir, "aggregation.g16.vk"))
+	require.NoError(tb, err)
+	require.Equal(tb, 1, a.N())
+	return a
+}
+
+func TestAggregator(t *testing.T) {
+	a := newTestAggregator(t)
+
+	prover, err := holder.NewRevocationTokenProver(innerPkPath, innerVkPath)
+	require.NoError(t, err)
+	iss := issuer.NewIssuer(issuer.MultiShow)
+	require.NoError(t, iss.IssueCredentials(2))
+	creds := iss.GetAllValidCreds()
+	epochUnix := time.Now().UTC().Unix()
+	proof, err := prover.Prove(*creds[0], epochUnix, zkp.Challenge([32]byte{1}, []byte("verifier")))
+	require.NoError(t, err)
+
+	aggregate, err := a.Aggregate([]*holder.TokenProof{proof})
+	require.NoError(t, err)
+	require.NoError(t, a.Verify(aggregate))
+	require.Equal(t, []*big.Int{
+		proof.PublicInputs.IssuerX,
+		proof.PublicInputs.IssuerY,
+		proof.PublicInputs.RevocationToken,
+		big.NewInt(epochUnix),
+		proof.PublicInputs.Challenge,
+	}, aggregate.PublicInputs())
+	words, commitments, commitmentPok, err := aggregate.Solidity()
+	require.NoError(t, err)
+	require.NotNil(t, words[7])
+	require.NotNil(t, commitments[1])
+	require.NotNil(t, commitmentPok[1])
+	var sol bytes.Buffer
+	require.NoError(t, a.ExportSolidity(&sol))
+	require.Contains(t, sol.String(), "function verifyProof")
+
+	// The aggregate proof is bound to the tokens and challenges.
+	tampered := *aggregate
+	tampered.Tokens = []*big.Int{new(big.Int).Add(proof.PublicInputs.RevocationToken, big.NewInt(1))}
+	require.Error(t, a.Verify(&tampered))
+	tampered = *aggregate
+	tampered.Challenges = []*big.Int{big.NewInt(1)}
+	require.Error(t, a.Verify(&tampered))
+}
+
+func TestAggregator_Assignment(t *testing.T) {
+	// The aggregator of one proof without keys of the aggregation circuit, whose assignment the test engine solves.
+	innerVk := groth16.NewVerifyingKey(ecc.BN254)
+	require.NoError(t, readKey(innerVkPath, innerVk))
+	a := &Aggregator{n: 1, innerVk: innerVk}
+
+	prover, err := holder.NewRevocationTokenProver(innerPkPath, innerVkPath)
+	require.NoError(t, err)
+	iss := issuer.NewIssuer(issuer.MultiShow)
+	require.NoError(t, iss.IssueCredentials(2))
+	creds := iss.GetAllValidCreds()
+	epochUnix := time.Now().UTC().Unix()
+	proof, err := prover.Prove(*creds[0], epochUnix, zkp.Challenge([32]byte{1}, []byte("verifier")))
+	require.NoError(t, err)
+
+	assignment, result, err := a.assignment([]*holder.TokenProof{proof})
+	require.NoError(t, err)
+	circuit, err := zkp.NewRevocationTokenAggregation(1, innerVk)
+	require.NoError(t, err)
+	require.NoError(t, test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()))
+	require.Equal(t, []*big.Int{
+		proof.PublicInputs.IssuerX,
+		proof.PublicInputs.IssuerY,
+		proof.PublicInputs.RevocationToken,
+		big.NewInt(epochUnix),
+		proof.PublicInputs.Challenge,
+	}, result.PublicInputs())
+
+	// The assignment is bound to the challenges of the proofs.
+	assignment.Challenges[0] = big.NewInt(1)
+	require.Error(t, test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()))
+
+	// Invalid proofs are rejected before proving.
+	forged := *proof
+	forged.PublicInputs.RevocationToken = big.NewInt(1)
+	_, _, err = a.assignment([]*holder.TokenProof{&forged})
+	require.ErrorContains(t, err, "proof 0")
+	plonkProof := *proof
+	plonkProof.Backend = zkp.Plonk
+	_, _, err = a.assignment([]*holder.TokenProof{&plonkProof})
+	require.ErrorContains(t, err, "proof 0")
+	other, err := prover.Prove(*creds[1], epochUnix, zkp.Challenge([32]byte{2}, []byte("verifier")))
+	require.NoError(t, err)
+	_, _, err = a.assignment([]*holder.TokenProof{proof, other})
+	require.ErrorContains(t, err, "covers 1")
+}
+
+func TestNewAggregator(t *testing.T) {
+	_, err := NewAggregator(1, "missing.vk", "missing.pk", "missing.vk")
+	require.ErrorContains(t, err, "revocation token verifying key")
+	_, err = NewAggregator(0, innerVkPath, "missing.pk", "missing.vk")
+	require.Error(t, err)
+}
+
+func TestAggregateProof_PublicInputs(t *testing.T) {
+	p := &AggregateProof{
+		Proof:      groth16.NewProof(ecc.BN254),
+		IssuerX:    big.NewInt(1),
+		IssuerY:    big.NewInt(2),
+		Tokens:     []*big.Int{big.NewInt(3), big.NewInt(4)},
+		Epoch:      5,
+		Challenges: []*big.Int{big.NewInt(6), big.NewInt(7)},
+	}
+	require.Equal(t, []*big.Int{big.NewInt(1), big.NewInt(2), big.NewInt(3), big.NewInt(4), big.NewInt(5), big.NewInt(6), big.NewInt(7)}, p.PublicInputs())
+	publicWitness, err := p.witness()
+	require.NoError(t, err)
+	vector, ok := publicWitness.Vector().(fr.Vector)
+	require.True(t, ok)
+	require.Len(t, vector, 7)
+	for i, v := range p.PublicInputs() {
+		require.Equal(t, v, vector[i].BigInt(new(big.Int)))
+	}
+
+	// A proof without the commitment of the aggregation circuit cannot be encoded for the Solidity verifier.
+	_, _, _, err = p.Solidity()
+	require.Error(t, err)
+	p.Challenges = p.Challenges[:1]
+	_, err = p.witness()
+	require.Error(t, err)
+}
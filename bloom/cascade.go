@@ -1,93 +1,129 @@
 package bloom
 
 import (
+	"bytes"
 	"encoding/binary"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"github.com/bits-and-blooms/bitset"
+	"io"
 	"math"
 	"math/big"
 )
 
-// BloomFilterCascade represents a cascade of Bloom filters.
+// cascadeMagic prefixes every binary encoded BloomFilterCascade.
+var cascadeMagic = [4]byte{'U', 'P', 'B', 'C'}
+
+// Bounds on the header of binary encodings, so a corrupted encoding cannot force large allocations.
+const (
+	maxIssuerIDLen   = 1 << 16 // maxIssuerIDLen bounds the length of the issuer id.
+	maxCascadeLayers = 128     // maxCascadeLayers bounds the number of layers; construction fails beyond 100.
+)
+
+// CascadeFormatVersion is the version of the serialized cascade format written by WriteTo and MarshalJSON.
+// Version 2 added the hash seed of each layer, version 3 the hasher, version 4 the layer kind and version 5 the
+// CascadeParams; older encodings are read with unseeded layers, Keccak256, Bloom layers and DefaultCascadeParams
+// respectively.
+const CascadeFormatVersion uint16 = 5
+
+// BloomFilterCascade represents a cascade of filters, Bloom filters unless created with NewCascadeWithLayers.
 // It is constructed by iteratively filtering false positives from prior layers.
 type BloomFilterCascade struct {
-	filters          []*BloomFilter // filters holds a slice of BloomFilter layers in the Bloom filter cascade.
-	capacity         int            // capacity defines the maximum number of elements expected to be processed by the Bloom filter cascade.
-	falsePosRate     float64        // falsePosRate represents the false positive rate for the first layer.
-	falsePosRateSucc float64        // falsePosRateSucc represents the false positive rate for subsequent layers.
+	filters      []Layer       // filters holds the layers of the cascade, all of kind layerKind.
+	capacity     int           // capacity defines the maximum number of elements expected to be processed by the Bloom filter cascade.
+	falsePosRate float64       // falsePosRate represents the false positive rate for the first layer.
+	params       CascadeParams // params determines the false positive rates and sizes of subsequent layers.
+	epoch        int64         // epoch is the revocation epoch the cascade was built for.
+	issuerID     []byte        // issuerID identifies the issuer that published the cascade (e.g. its public key).
+	unseeded     bool          // unseeded builds all layers with seed 0, as cascades were built before layer seeds.
+	hasher       Hasher        // hasher is the hash function of all layers.
+	layerKind    LayerKind     // layerKind is the filter type of all layers.
 }
 
 // NewCascade creates a new BloomFilterCascade with an initial layer based on the given domain and capacity.
 // The false positive rate for the first layer is computed based on the ratio of capacity to domain size.
+// Layers hash with Keccak256, as the on-chain cascade does.
 func NewCascade(domain, capacity int) *BloomFilterCascade {
-	falsePosRate := float64(capacity) * math.Sqrt(0.5) / float64(domain-capacity)
-	falsePosRateSucc := 0.5
+	return NewCascadeWithHasher(domain, capacity, Keccak256)
+}
 
-	m, k := getOptimalFilterParameters(capacity, falsePosRate)
+// NewCascadeWithHasher creates a new BloomFilterCascade like NewCascade whose layers hash with h.
+func NewCascadeWithHasher(domain, capacity int, h Hasher) *BloomFilterCascade {
+	return newCascade(domain, capacity, BloomLayers, h, DefaultCascadeParams)
+}
 
-	filters := make([]*BloomFilter, 1)
-	filters[0] = NewBloomFilter(m, k)
+// NewCascadeWithLayers creates a new cascade like NewCascadeWithHasher whose layers are filters of the given kind.
+func NewCascadeWithLayers(domain, capacity int, kind LayerKind, h Hasher) (*BloomFilterCascade, error) {
+	return NewCascadeWithParams(domain, capacity, kind, h, DefaultCascadeParams)
+}
 
-	return &BloomFilterCascade{filters, capacity, falsePosRate, falsePosRateSucc}
+// NewCascadeWithParams creates a new cascade like NewCascadeWithLayers whose layers follow params instead of
+// DefaultCascadeParams, such as a preset or the result of OptimizeCascadeParams.
+func NewCascadeWithParams(domain, capacity int, kind LayerKind, h Hasher, params CascadeParams) (*BloomFilterCascade, error) {
+	if kind != BloomLayers && kind != BinaryFuseLayers {
+		return nil, fmt.Errorf("unsupported layer kind %s", kind)
+	}
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+	return newCascade(domain, capacity, kind, h, params), nil
 }
 
-// Update constructs the cascade from a set of true positives and known negatives.
-// Each layer stores false positives of the previous layer, alternating between accepting and rejecting layers.
-// Terminates once no new false positives are found or a maximum depth is reached.
-func (c *BloomFilterCascade) Update(positives [][]byte, negatives [][]byte) error {
+// newCascade creates a new cascade with an empty first layer for valid kind and params.
+func newCascade(domain, capacity int, kind LayerKind, h Hasher, params CascadeParams) *BloomFilterCascade {
+	c := &BloomFilterCascade{capacity: capacity, falsePosRate: params.firstRate(domain, capacity), params: params, hasher: h, layerKind: kind}
 	c.reset()
+	return c
+}
 
-	if len(positives) > c.capacity {
-		return fmt.Errorf("bloom filter capacity exceeded")
+// CascadeFromOnChainFilter reconstructs a BloomFilterCascade from the representation returned by GetOnChainFilter,
+// i.e. the arguments passed to the on-chain updateCascade call. Capacity and false positive rates are not part of
+// the on-chain representation and are left zero, so the resulting cascade can be tested against but not updated.
+// The on-chain cascade hashes with Keccak256.
+func CascadeFromOnChainFilter(filters [][]byte, ks, bitLens, seeds []*big.Int) (*BloomFilterCascade, error) {
+	n := len(filters)
+	if n == 0 {
+		return nil, errors.New("at least one layer required")
 	}
-
-	// Layer 0: insert actual positives
-	for _, p := range positives {
-		c.filters[0].Add(p)
+	if len(ks) != n || len(bitLens) != n || len(seeds) != n {
+		return nil, fmt.Errorf("mismatching lengths: %d filters, %d ks, %d bitLens, %d seeds", n, len(ks), len(bitLens), len(seeds))
 	}
 
-	// Find false positives at layer 0
-	var falsePositives [][]byte
-	for _, n := range negatives {
-		if c.filters[0].Test(n) {
-			falsePositives = append(falsePositives, n)
+	layers := make([]Layer, n)
+	for i, layerBytes := range filters {
+		if ks[i] == nil || !ks[i].IsUint64() || ks[i].Sign() == 0 {
+			return nil, fmt.Errorf("layer %d: invalid k", i)
+		}
+		if bitLens[i] == nil || !bitLens[i].IsUint64() || bitLens[i].Sign() == 0 {
+			return nil, fmt.Errorf("layer %d: invalid bit length", i)
+		}
+		if seeds[i] == nil || !seeds[i].IsUint64() {
+			return nil, fmt.Errorf("layer %d: invalid seed", i)
+		}
+		if len(layerBytes)%8 != 0 {
+			return nil, fmt.Errorf("layer %d: filter length %d is not a multiple of 8", i, len(layerBytes))
+		}
+		m := uint(bitLens[i].Uint64())
+		if m > uint(len(layerBytes))*8 {
+			return nil, fmt.Errorf("layer %d: bit length %d exceeds filter length", i, m)
 		}
-	}
 
-	if len(falsePositives) == 0 {
-		return nil
+		layers[i] = filterFromOnChainBytes(layerBytes, m, uint(ks[i].Uint64()), seeds[i].Uint64(), Keccak256)
 	}
 
-	// Layer 1: insert false positives
-	c.addNextLayer(&falsePositives, c.falsePosRateSucc)
-
-	// Generate succeeding layers
-	prevPrevFalsePositives := &positives
-	prevFalsePositives := &falsePositives
-	layer := 1
-
-	for {
-		var nextFalsePositives [][]byte
-		for _, prev := range *prevPrevFalsePositives {
-			if c.filters[layer].Test(prev) {
-				nextFalsePositives = append(nextFalsePositives, prev)
-			}
-		}
-		prevPrevFalsePositives = prevFalsePositives
-		prevFalsePositives = &nextFalsePositives
-
-		if len(nextFalsePositives) == 0 {
-			return nil
-		} else if len(nextFalsePositives) > 200 {
-			c.addNextLayer(&nextFalsePositives, c.falsePosRateSucc)
-		} else {
-			c.addNextLayer(&nextFalsePositives, 0.1) // Ensure termination
-		}
+	return &BloomFilterCascade{filters: layers, hasher: Keccak256}, nil
+}
 
-		layer++
-		if layer > 100 {
-			return fmt.Errorf("over 100 layers. bloom filter cascade too deep — probably cyclic data")
-		}
+// Update constructs the cascade from a set of true positives and known negatives.
+// Each layer stores false positives of the previous layer, alternating between accepting and rejecting layers.
+// Terminates once no new false positives are found or a maximum depth is reached.
+func (c *BloomFilterCascade) Update(positives [][]byte, negatives [][]byte) error {
+	if len(positives) > c.capacity {
+		c.reset()
+		return fmt.Errorf("bloom filter capacity exceeded")
 	}
+	return c.UpdateFromSources(SliceSource(positives), SliceSource(negatives))
 }
 
 // Test determines whether the given element is accepted by the Bloom Filter Cascade.
@@ -123,68 +159,195 @@ func (c *BloomFilterCascade) Test(element []byte) (bool, int) {
 }
 
 // GetOnChainFilter returns the serialized representation of all Bloom filter layers,
-// their number of hash functions, and the actual bit lengths.
+// their number of hash functions, the actual bit lengths and the hash seeds.
 // Each layer's filter is encoded as a []byte, packed from its internal []uint64.
-func (c *BloomFilterCascade) GetOnChainFilter() (filters [][]byte, numhf []*big.Int, bitLens []*big.Int) {
+// The on-chain cascade hashes with Keccak256, so only such cascades can be tested on-chain.
+// It panics if the cascade's layers are not Bloom filters; see GetOnChainFuseFilter for binary fuse layers.
+func (c *BloomFilterCascade) GetOnChainFilter() (filters [][]byte, numhf, bitLens, seeds []*big.Int) {
 	n := len(c.filters)
 	filters = make([][]byte, n)
 	numhf = make([]*big.Int, n)
 	bitLens = make([]*big.Int, n)
+	seeds = make([]*big.Int, n)
 
-	for i, f := range c.filters {
-		words := f.b.Words() // []uint64 bitvector
-
-		layerBytes := make([]byte, 8*len(words))
-		for j, word := range words {
-			binary.LittleEndian.PutUint64(layerBytes[j*8:(j+1)*8], word)
+	for i, l := range c.filters {
+		f, ok := l.(*BloomFilter)
+		if !ok {
+			panic(fmt.Sprintf("bloom: layer %d is a %s layer, not a Bloom filter", i, l.Kind()))
 		}
-
-		filters[i] = layerBytes
+		filters[i] = onChainBytes(f)
 		numhf[i] = big.NewInt(int64(f.K()))
 		bitLens[i] = big.NewInt(int64(f.BitLen()))
+		seeds[i] = new(big.Int).SetUint64(f.Seed())
+	}
+
+	return filters, numhf, bitLens, seeds
+}
+
+// onChainBytes packs the bit vector of a layer into bytes, little-endian per 64-bit word.
+func onChainBytes(f *BloomFilter) []byte {
+	return wordBytes(f.b.Words())
+}
+
+// wordBytes packs 64-bit words into bytes, little-endian per word, so bit i of the words is bit i%8 of byte i/8.
+func wordBytes(words []uint64) []byte {
+	layerBytes := make([]byte, 8*len(words))
+	for j, word := range words {
+		binary.LittleEndian.PutUint64(layerBytes[j*8:(j+1)*8], word)
 	}
+	return layerBytes
+}
 
-	return filters, numhf, bitLens
+// filterFromOnChainBytes is the inverse of onChainBytes for a layer of m bits, k hash functions, the given seed and
+// hasher. The length of layerBytes must be a multiple of 8.
+func filterFromOnChainBytes(layerBytes []byte, m, k uint, seed uint64, h Hasher) *BloomFilter {
+	words := make([]uint64, len(layerBytes)/8)
+	for j := range words {
+		words[j] = binary.LittleEndian.Uint64(layerBytes[j*8 : (j+1)*8])
+	}
+	return &BloomFilter{m, k, bitset.FromWithLength(m, words), seed, h}
 }
+
+// GetFilters returns the Bloom filter layers of the cascade, or nil if its layers are of another kind.
 func (c *BloomFilterCascade) GetFilters() []*BloomFilter {
+	if c.layerKind != BloomLayers {
+		return nil
+	}
+	filters := make([]*BloomFilter, len(c.filters))
+	for i, l := range c.filters {
+		filters[i] = l.(*BloomFilter)
+	}
+	return filters
+}
+
+// Layers returns the layers of the cascade.
+func (c *BloomFilterCascade) Layers() []Layer {
 	return c.filters
 }
 
-// addNextLayer adds a new Bloom filter layer to the cascade based on the provided elements and false positive rate.
-// The filter stores only the elements passed in and is appended to the internal filter list.
-func (c *BloomFilterCascade) addNextLayer(elements *[][]byte, fprate float64) {
-	capacity := int(max(uint(len(*elements)), 100))
-	m, k := getOptimalFilterParameters(capacity, fprate)
+// LayerKind returns the filter type of the cascade's layers.
+func (c *BloomFilterCascade) LayerKind() LayerKind {
+	return c.layerKind
+}
+
+// Capacity returns the maximum number of positives the cascade was created for.
+func (c *BloomFilterCascade) Capacity() int {
+	return c.capacity
+}
 
-	nextLayer := NewBloomFilter(m, k)
+// FalsePositiveRates returns the false positive rate of the first layer and the rate used for subsequent layers.
+func (c *BloomFilterCascade) FalsePositiveRates() (first, succ float64) {
+	return c.falsePosRate, c.params.SuccRate
+}
 
-	for _, element := range *elements {
-		nextLayer.Add(element)
+// Params returns the parameters the cascade's layers are constructed with.
+func (c *BloomFilterCascade) Params() CascadeParams {
+	return c.params
+}
+
+// Epoch returns the revocation epoch the cascade was built for.
+func (c *BloomFilterCascade) Epoch() int64 {
+	return c.epoch
+}
+
+// SetEpoch records the revocation epoch the cascade was built for.
+func (c *BloomFilterCascade) SetEpoch(epoch int64) {
+	c.epoch = epoch
+}
+
+// Hasher returns the hash function of the cascade's layers.
+func (c *BloomFilterCascade) Hasher() Hasher {
+	if c.hasher == nil {
+		return Keccak256
+	}
+	return c.hasher
+}
+
+// IssuerID returns the identifier of the issuer that published the cascade.
+func (c *BloomFilterCascade) IssuerID() []byte {
+	return c.issuerID
+}
+
+// SetIssuerID records the identifier of the issuer that published the cascade.
+func (c *BloomFilterCascade) SetIssuerID(id []byte) {
+	c.issuerID = append([]byte(nil), id...)
+}
+
+// Equal tests two cascades for equality of their metadata and all layers.
+func (c *BloomFilterCascade) Equal(d *BloomFilterCascade) bool {
+	if c.capacity != d.capacity || c.falsePosRate != d.falsePosRate || c.params != d.params ||
+		c.epoch != d.epoch || !bytes.Equal(c.issuerID, d.issuerID) || c.Hasher().ID() != d.Hasher().ID() ||
+		c.layerKind != d.layerKind || len(c.filters) != len(d.filters) {
+		return false
 	}
+	for i := range c.filters {
+		if !layersEqual(c.filters[i], d.filters[i]) {
+			return false
+		}
+	}
+	return true
+}
 
+// addNextLayer adds a new layer to the cascade based on the provided elements and false positive rate.
+// The filter stores only the elements passed in and is appended to the internal filter list. Bloom layers are sized
+// for at least the minimum capacity of the cascade's parameters. With more than one worker the elements are inserted
+// concurrently.
+func (c *BloomFilterCascade) addNextLayer(elements *[][]byte, fprate float64, workers int) error {
+	nextLayer, err := newLayer(c.layerKind, *elements, c.params.MinCapacity, fprate, c.layerSeed(len(c.filters)), c.hasher, workers)
+	if err != nil {
+		return fmt.Errorf("layer %d: %w", len(c.filters), err)
+	}
 	c.filters = append(c.filters, nextLayer)
+	return nil
+}
+
+// setFirstLayer replaces layer 0 by a layer holding the positives, which must not exceed the capacity.
+func (c *BloomFilterCascade) setFirstLayer(positives [][]byte, workers int) error {
+	first, err := newLayer(c.layerKind, positives, c.capacity, c.falsePosRate, c.layerSeed(0), c.hasher, workers)
+	if err != nil {
+		return fmt.Errorf("layer 0: %w", err)
+	}
+	c.filters[0] = first
+	return nil
+}
+
+// layerSeed returns the hash seed of the given layer, which is its index. Deep layers share m and k, so without
+// distinct seeds an element probes the same bits in every layer and false positives repeat from layer to layer.
+// Layer 0 keeps seed 0, i.e. the plain keccak256 hash.
+func (c *BloomFilterCascade) layerSeed(layer int) uint64 {
+	if c.unseeded {
+		return 0
+	}
+	return uint64(layer)
 }
 
 // reset clears the cascade and reinitializes the first filter layer with original parameters.
 func (c *BloomFilterCascade) reset() {
-	m, k := getOptimalFilterParameters(c.capacity, c.falsePosRate)
-	c.filters = []*BloomFilter{NewBloomFilter(m, k)}
+	// An empty layer of a supported kind cannot fail.
+	first, _ := newLayer(c.layerKind, nil, c.capacity, c.falsePosRate, c.layerSeed(0), c.hasher, 1)
+	c.filters = []Layer{first}
 }
 
 // printStats prints the size and number of hash functions for each layer in the cascade.
-// It also reports the total size in bits and cumulative number of hash functions.
+// It also reports the total size in bits and cumulative number of hash functions; binary fuse layers always probe
+// three slots.
 func (c *BloomFilterCascade) printStats() {
-	fmt.Println("Bloom Filter Cascade Statistics:")
+	fmt.Printf("%s Filter Cascade Statistics:\n", c.layerKind)
 
 	var totalSizeBits uint
 	var totalHashFuncs uint
 
-	for i, f := range c.filters {
-		size := f.BitLen()
-		k := f.K()
+	for i, l := range c.filters {
+		size := l.BitLen()
+		switch f := l.(type) {
+		case *BloomFilter:
+			totalHashFuncs += f.K()
+			fmt.Printf("Layer %d: size = %d bits, hash functions = %d, seed = %d\n", i, size, f.K(), f.Seed())
+		case *BinaryFuseFilter:
+			totalHashFuncs += 3
+			fmt.Printf("Layer %d: size = %d bits, fingerprint bits = %d, seed = %d\n", i, size, f.FingerprintBits(), f.Seed())
+		}
 		totalSizeBits += size
-		totalHashFuncs += k
-		fmt.Printf("Layer %d: size = %d bits, hash functions = %d\n", i, size, k)
 	}
 
 	fmt.Printf("Total: size = %d bits, total hash functions = %d\n", totalSizeBits, totalHashFuncs)
@@ -200,3 +363,289 @@ func getOptimalFilterParameters(capacity int, fprate float64) (m, k uint) {
 	k = uint(math.Round(kFloat))
 	return m, k
 }
+
+// cascadeJSON is an unexported type for marshaling/unmarshaling BloomFilterCascade struct.
+type cascadeJSON struct {
+	Version          uint16            `json:"version"`
+	Capacity         int               `json:"capacity"`
+	FalsePosRate     float64           `json:"falsePosRate"`
+	FalsePosRateSucc float64           `json:"falsePosRateSucc"`
+	Epoch            int64             `json:"epoch"`
+	IssuerID         []byte            `json:"issuerId"`
+	Hasher           HasherID          `json:"hasher"`
+	LayerKind        LayerKind         `json:"layerKind,omitempty"`
+	Layers           []json.RawMessage `json:"layers"`
+	Params           *CascadeParams    `json:"params,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler interface.
+func (c BloomFilterCascade) MarshalJSON() ([]byte, error) {
+	layers := make([]json.RawMessage, len(c.filters))
+	for i, l := range c.filters {
+		data, err := json.Marshal(l)
+		if err != nil {
+			return nil, fmt.Errorf("layer %d: %w", i, err)
+		}
+		layers[i] = data
+	}
+	return json.Marshal(cascadeJSON{
+		Version:          CascadeFormatVersion,
+		Capacity:         c.capacity,
+		FalsePosRate:     c.falsePosRate,
+		FalsePosRateSucc: c.params.SuccRate,
+		Epoch:            c.epoch,
+		IssuerID:         c.issuerID,
+		Hasher:           c.Hasher().ID(),
+		LayerKind:        c.layerKind,
+		Layers:           layers,
+		Params:           &c.params,
+	})
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface.
+func (c *BloomFilterCascade) UnmarshalJSON(data []byte) error {
+	var j cascadeJSON
+	err := json.Unmarshal(data, &j)
+	if err != nil {
+		return err
+	}
+	if j.Version < 1 || j.Version > CascadeFormatVersion {
+		return fmt.Errorf("unsupported cascade format version %d", j.Version)
+	}
+	if len(j.Layers) == 0 {
+		return errors.New("cascade has no layers")
+	}
+	if len(j.Layers) > maxCascadeLayers {
+		return fmt.Errorf("%d layers exceed %d", len(j.Layers), maxCascadeLayers)
+	}
+	params := legacyCascadeParams(j.FalsePosRateSucc)
+	if j.Params != nil {
+		params = *j.Params
+	}
+	err = params.Validate()
+	if err != nil {
+		return err
+	}
+	h, err := HasherByID(j.Hasher)
+	if err != nil {
+		return err
+	}
+	layers := make([]Layer, len(j.Layers))
+	for i, data := range j.Layers {
+		layers[i], err = unmarshalLayer(j.LayerKind, data)
+		if err != nil {
+			return fmt.Errorf("layer %d: %w", i, err)
+		}
+		if layers[i].Hasher().ID() != h.ID() {
+			return fmt.Errorf("layer %d: hasher %s differs from cascade hasher %s", i, layers[i].Hasher().ID(), h.ID())
+		}
+	}
+	c.capacity = j.Capacity
+	c.falsePosRate = j.FalsePosRate
+	c.params = params
+	c.unseeded = j.Version == 1
+	c.epoch = j.Epoch
+	c.issuerID = j.IssuerID
+	c.hasher = h
+	c.layerKind = j.LayerKind
+	c.filters = layers
+	return nil
+}
+
+// WriteTo writes a versioned binary representation of the BloomFilterCascade to an i/o stream.
+// The encoding consists of a magic prefix, the format version, the cascade metadata (capacity, false positive rates,
+// epoch, issuer id, hasher, layer kind and the other parameters) followed by the number of layers and each layer as its seed and the layer
+// as written by its WriteTo.
+// It returns the number of bytes written.
+func (c *BloomFilterCascade) WriteTo(stream io.Writer) (int64, error) {
+	if len(c.issuerID) > maxIssuerIDLen {
+		return 0, fmt.Errorf("issuer id of %d bytes exceeds %d", len(c.issuerID), maxIssuerIDLen)
+	}
+	var header bytes.Buffer
+	header.Write(cascadeMagic[:])
+	_ = binary.Write(&header, binary.BigEndian, CascadeFormatVersion)
+	_ = binary.Write(&header, binary.BigEndian, uint64(c.capacity))
+	_ = binary.Write(&header, binary.BigEndian, math.Float64bits(c.falsePosRate))
+	_ = binary.Write(&header, binary.BigEndian, math.Float64bits(c.params.SuccRate))
+	_ = binary.Write(&header, binary.BigEndian, c.epoch)
+	_ = binary.Write(&header, binary.BigEndian, uint32(len(c.issuerID)))
+	header.Write(c.issuerID)
+	header.WriteByte(byte(c.Hasher().ID()))
+	header.WriteByte(byte(c.layerKind))
+	writeCascadeParams(&header, c.params)
+	_ = binary.Write(&header, binary.BigEndian, uint32(len(c.filters)))
+
+	n, err := stream.Write(header.Bytes())
+	numBytes := int64(n)
+	if err != nil {
+		return numBytes, err
+	}
+
+	for _, l := range c.filters {
+		err = binary.Write(stream, binary.BigEndian, l.Seed())
+		if err != nil {
+			return numBytes, err
+		}
+		numBytes += int64(binary.Size(l.Seed()))
+		layerBytes, err := l.WriteTo(stream)
+		numBytes += layerBytes
+		if err != nil {
+			return numBytes, err
+		}
+	}
+	return numBytes, nil
+}
+
+// ReadFrom reads a binary representation of the BloomFilterCascade (such as might have been written by WriteTo())
+// from an i/o stream. It returns the number of bytes read.
+func (c *BloomFilterCascade) ReadFrom(stream io.Reader) (int64, error) {
+	var magic [4]byte
+	var version uint16
+	var capacity, fpBits, fpSuccBits uint64
+	var epoch int64
+	var idLen, layerCount uint32
+
+	_, err := io.ReadFull(stream, magic[:])
+	if err != nil {
+		return 0, err
+	}
+	if magic != cascadeMagic {
+		return 0, errors.New("not a bloom filter cascade encoding")
+	}
+	err = binary.Read(stream, binary.BigEndian, &version)
+	if err != nil {
+		return 0, err
+	}
+	if version < 1 || version > CascadeFormatVersion {
+		return 0, fmt.Errorf("unsupported cascade format version %d", version)
+	}
+	for _, v := range []any{&capacity, &fpBits, &fpSuccBits, &epoch, &idLen} {
+		err = binary.Read(stream, binary.BigEndian, v)
+		if err != nil {
+			return 0, err
+		}
+	}
+	if idLen > maxIssuerIDLen {
+		return 0, fmt.Errorf("issuer id of %d bytes exceeds %d", idLen, maxIssuerIDLen)
+	}
+	issuerID := make([]byte, idLen)
+	_, err = io.ReadFull(stream, issuerID)
+	if err != nil {
+		return 0, err
+	}
+	h := Keccak256
+	var hasherID HasherID
+	if version > 2 {
+		err = binary.Read(stream, binary.BigEndian, &hasherID)
+		if err != nil {
+			return 0, err
+		}
+		h, err = HasherByID(hasherID)
+		if err != nil {
+			return 0, err
+		}
+	}
+	kind := BloomLayers
+	if version > 3 {
+		err = binary.Read(stream, binary.BigEndian, &kind)
+		if err != nil {
+			return 0, err
+		}
+		if kind != BloomLayers && kind != BinaryFuseLayers {
+			return 0, fmt.Errorf("unsupported layer kind %s", kind)
+		}
+	}
+	params := legacyCascadeParams(math.Float64frombits(fpSuccBits))
+	if version > 4 {
+		params, err = readCascadeParams(stream, params.SuccRate)
+		if err != nil {
+			return 0, err
+		}
+	}
+	err = params.Validate()
+	if err != nil {
+		return 0, err
+	}
+	err = binary.Read(stream, binary.BigEndian, &layerCount)
+	if err != nil {
+		return 0, err
+	}
+	if layerCount == 0 {
+		return 0, errors.New("cascade has no layers")
+	}
+	if layerCount > maxCascadeLayers {
+		return 0, fmt.Errorf("%d layers exceed %d", layerCount, maxCascadeLayers)
+	}
+
+	numBytes := int64(len(magic) + binary.Size(version) + 4*binary.Size(capacity) + 2*binary.Size(idLen) + len(issuerID))
+	if version > 2 {
+		numBytes += int64(binary.Size(hasherID))
+	}
+	if version > 3 {
+		numBytes += int64(binary.Size(kind))
+	}
+	if version > 4 {
+		numBytes += cascadeParamsSize
+	}
+	filters := make([]Layer, layerCount)
+	for i := range filters {
+		var seed uint64
+		if version > 1 {
+			err = binary.Read(stream, binary.BigEndian, &seed)
+			if err != nil {
+				return 0, fmt.Errorf("layer %d: %w", i, err)
+			}
+			numBytes += int64(binary.Size(seed))
+		}
+		f, layerBytes, err := readLayer(kind, stream, seed, h)
+		if err != nil {
+			return 0, fmt.Errorf("layer %d: %w", i, err)
+		}
+		numBytes += layerBytes
+		filters[i] = f
+	}
+
+	c.filters = filters
+	c.capacity = int(capacity)
+	c.falsePosRate = math.Float64frombits(fpBits)
+	c.params = params
+	c.unseeded = version == 1
+	c.epoch = epoch
+	c.hasher = h
+	c.layerKind = kind
+	if idLen > 0 {
+		c.issuerID = issuerID
+	} else {
+		c.issuerID = nil
+	}
+	return numBytes, nil
+}
+
+// GobEncode implements gob.GobEncoder interface.
+func (c *BloomFilterCascade) GobEncode() ([]byte, error) {
+	return c.MarshalBinary()
+}
+
+// GobDecode implements gob.GobDecoder interface.
+func (c *BloomFilterCascade) GobDecode(data []byte) error {
+	return c.UnmarshalBinary(data)
+}
+
+// MarshalBinary implements binary.BinaryMarshaler interface.
+func (c *BloomFilterCascade) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	_, err := c.WriteTo(&buf)
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary implements binary.BinaryUnmarshaler interface.
+func (c *BloomFilterCascade) UnmarshalBinary(data []byte) error {
+	buf := bytes.NewBuffer(data)
+	_, err := c.ReadFrom(buf)
+
+	return err
+}
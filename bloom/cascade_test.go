@@ -1,10 +1,17 @@
 package bloom
 
 import (
+	"bytes"
 	"crypto/rand"
+	"encoding/binary"
+	"encoding/gob"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"github.com/stretchr/testify/require"
+	"math"
+	mrand "math/rand"
+	"runtime"
 	"testing"
 )
 
@@ -26,7 +33,7 @@ func TestCascade_Update(t *testing.T) {
 	err = cascade.Update(nil, valid)
 	require.NoError(t, err, "Update with no revocations should not fail")
 	require.Len(t, cascade.filters, 1, "Cascade should reset to one filter if no positives are provided")
-	require.Equal(t, uint(0), cascade.filters[0].BitSet().Count(), "Reset filter should be empty")
+	require.Equal(t, uint(0), cascade.GetFilters()[0].BitSet().Count(), "Reset filter should be empty")
 }
 
 func TestCascade_Check(t *testing.T) {
@@ -58,7 +65,7 @@ func TestCascade_EmptyDomain(t *testing.T) {
 	err := cascade.Update(nil, nil)
 	require.NoError(t, err)
 	require.Len(t, cascade.filters, 1)
-	require.Equal(t, uint(0), cascade.filters[0].BitSet().Count())
+	require.Equal(t, uint(0), cascade.GetFilters()[0].BitSet().Count())
 }
 
 func TestCascade_SingleElement(t *testing.T) {
@@ -108,6 +115,277 @@ func TestCascade_LayerCountSanity(t *testing.T) {
 	require.Less(t, len(cascade.filters), 100, "Too many layers: possible non-converging cascade")
 }
 
+func TestCascade_BinaryRoundTrip(t *testing.T) {
+	domain := 10_000
+	capacity := 1_000
+
+	valid, revoked := genRevocationTokens(domain, capacity)
+	cascade := NewCascade(domain, capacity)
+	require.NoError(t, cascade.Update(revoked, valid))
+	cascade.SetEpoch(1_700_000_000)
+	cascade.SetIssuerID([]byte("issuer-1"))
+
+	data, err := cascade.MarshalBinary()
+	require.NoError(t, err)
+
+	var decoded BloomFilterCascade
+	require.NoError(t, decoded.UnmarshalBinary(data))
+	require.True(t, cascade.Equal(&decoded), "decoded cascade differs from original")
+	require.Equal(t, int64(1_700_000_000), decoded.Epoch())
+	require.Equal(t, []byte("issuer-1"), decoded.IssuerID())
+
+	for _, tok := range revoked {
+		ok, _ := decoded.Test(tok)
+		require.True(t, ok)
+	}
+
+	// A decoded cascade can be updated again since capacity and rates are preserved.
+	require.NoError(t, decoded.Update(revoked, valid))
+
+	var buf bytes.Buffer
+	require.NoError(t, gob.NewEncoder(&buf).Encode(cascade))
+	var gobDecoded BloomFilterCascade
+	require.NoError(t, gob.NewDecoder(&buf).Decode(&gobDecoded))
+	require.True(t, cascade.Equal(&gobDecoded))
+}
+
+func TestCascade_BinaryRejectsInvalid(t *testing.T) {
+	cascade := NewCascade(1000, 10)
+	require.NoError(t, cascade.Update(generateRandom128BitSlices(10), nil))
+	data, err := cascade.MarshalBinary()
+	require.NoError(t, err)
+
+	var decoded BloomFilterCascade
+	require.Error(t, decoded.UnmarshalBinary(data[:len(data)-1]), "truncated encoding must be rejected")
+
+	corrupted := append([]byte(nil), data...)
+	corrupted[0] = 'X'
+	require.Error(t, decoded.UnmarshalBinary(corrupted), "wrong magic must be rejected")
+
+	corrupted = append([]byte(nil), data...)
+	corrupted[5] = 0xff // low byte of the format version
+	require.Error(t, decoded.UnmarshalBinary(corrupted), "unknown version must be rejected")
+
+	for n := range data {
+		require.Error(t, decoded.UnmarshalBinary(data[:n]), "encoding truncated to %d bytes must be rejected", n)
+	}
+
+	// Oversized header fields are rejected before anything is allocated for them. The issuer id is empty, so the
+	// layer count follows the hasher, layer kind and parameters, and the first layer its seed.
+	const idLenOffset, layerCountOffset = 38, 76
+	const bitLenOffset = layerCountOffset + 4 + 8
+	for _, offset := range []int{idLenOffset, layerCountOffset, bitLenOffset, bitLenOffset + 16} {
+		corrupted = append([]byte(nil), data...)
+		corrupted[offset] = 0x7f
+		var before, after runtime.MemStats
+		runtime.ReadMemStats(&before)
+		require.Error(t, decoded.UnmarshalBinary(corrupted), "oversized field at offset %d must be rejected", offset)
+		runtime.ReadMemStats(&after)
+		require.Less(t, after.TotalAlloc-before.TotalAlloc, uint64(1<<20), "field at offset %d", offset)
+	}
+
+	// The bits of a layer are read as they arrive, so announcing the largest layer does not allocate it.
+	corrupted = append([]byte(nil), data...)
+	binary.BigEndian.PutUint64(corrupted[bitLenOffset:], maxBloomFilterBits)
+	binary.BigEndian.PutUint64(corrupted[bitLenOffset+16:], maxBloomFilterBits)
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	require.Error(t, decoded.UnmarshalBinary(corrupted), "layer exceeding the input must be rejected")
+	runtime.ReadMemStats(&after)
+	require.Less(t, after.TotalAlloc-before.TotalAlloc, uint64(1<<20))
+
+	// Decoded parameters are validated; the minimum capacity precedes the layer count.
+	corrupted = append([]byte(nil), data...)
+	binary.BigEndian.PutUint64(corrupted[layerCountOffset-8:], 0)
+	require.Error(t, decoded.UnmarshalBinary(corrupted), "invalid parameters must be rejected")
+}
+
+func TestCascade_JSONRoundTrip(t *testing.T) {
+	domain := 10_000
+	capacity := 500
+
+	valid, revoked := genRevocationTokens(domain, capacity)
+	cascade := NewCascade(domain, capacity)
+	require.NoError(t, cascade.Update(revoked, valid))
+	cascade.SetEpoch(42)
+	cascade.SetIssuerID([]byte{0x02, 0xab, 0xcd})
+
+	data, err := json.Marshal(cascade)
+	require.NoError(t, err)
+
+	var decoded BloomFilterCascade
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.True(t, cascade.Equal(&decoded), "decoded cascade differs from original")
+}
+
+func TestCascade_JSONRejectsInvalid(t *testing.T) {
+	cascade := NewCascade(1000, 10)
+	require.NoError(t, cascade.Update(generateRandom128BitSlices(10), nil))
+	data, err := json.Marshal(cascade)
+	require.NoError(t, err)
+
+	for name, modify := range map[string]func(j *cascadeJSON){
+		"zero minimum capacity": func(j *cascadeJSON) { j.Params.MinCapacity = 0 },
+		"negative tail rate":    func(j *cascadeJSON) { j.Params.TailRate = -0.5 },
+		"legacy rate":           func(j *cascadeJSON) { j.Version, j.Params, j.FalsePosRateSucc = 4, nil, 1 },
+		"too many layers": func(j *cascadeJSON) {
+			for len(j.Layers) <= maxCascadeLayers {
+				j.Layers = append(j.Layers, j.Layers[0])
+			}
+		},
+	} {
+		var j cascadeJSON
+		require.NoError(t, json.Unmarshal(data, &j))
+		modify(&j)
+		corrupted, err := json.Marshal(j)
+		require.NoError(t, err)
+		var decoded BloomFilterCascade
+		require.Error(t, json.Unmarshal(corrupted, &decoded), name)
+	}
+}
+
+func TestCascade_FromOnChainFilter(t *testing.T) {
+	domain := 10_000
+	capacity := 1_000
+
+	valid, revoked := genRevocationTokens(domain, capacity)
+	cascade := NewCascade(domain, capacity)
+	require.NoError(t, cascade.Update(revoked, valid))
+
+	filters, ks, bitLens, seeds := cascade.GetOnChainFilter()
+	restored, err := CascadeFromOnChainFilter(filters, ks, bitLens, seeds)
+	require.NoError(t, err)
+	require.Len(t, restored.GetFilters(), len(cascade.GetFilters()))
+	for i, f := range restored.GetFilters() {
+		require.True(t, f.Equal(cascade.GetFilters()[i]), "layer %d differs", i)
+	}
+
+	for _, tok := range valid {
+		ok, layer := restored.Test(tok)
+		_, expectedLayer := cascade.Test(tok)
+		require.False(t, ok)
+		require.Equal(t, expectedLayer, layer)
+	}
+	for _, tok := range revoked {
+		ok, _ := restored.Test(tok)
+		require.True(t, ok)
+	}
+
+	_, err = CascadeFromOnChainFilter(filters, ks[1:], bitLens, seeds)
+	require.Error(t, err)
+	_, err = CascadeFromOnChainFilter(filters, ks, bitLens, seeds[1:])
+	require.Error(t, err)
+	_, err = CascadeFromOnChainFilter(nil, nil, nil, nil)
+	require.Error(t, err)
+}
+
+func TestCascade_LayerSeeds(t *testing.T) {
+	domain := 100_000
+	capacity := 10_000
+
+	valid, revoked := genRevocationTokens(domain, capacity)
+	seeded := NewCascade(domain, capacity)
+	require.NoError(t, seeded.Update(revoked, valid))
+	for i, f := range seeded.GetFilters() {
+		require.Equal(t, uint64(i), f.Seed(), "layer %d", i)
+	}
+	for _, tok := range revoked {
+		ok, _ := seeded.Test(tok)
+		require.True(t, ok)
+	}
+	for _, tok := range valid {
+		ok, _ := seeded.Test(tok)
+		require.False(t, ok)
+	}
+
+	unseeded := NewCascade(domain, capacity)
+	unseeded.unseeded = true
+	require.NoError(t, unseeded.Update(revoked, valid))
+	for _, f := range unseeded.GetFilters() {
+		require.Zero(t, f.Seed())
+	}
+	require.Equal(t, unseeded.GetFilters()[0], seeded.GetFilters()[0], "layer 0 is unseeded")
+	t.Logf("seeded: %d layers, %d bits; unseeded: %d layers, %d bits",
+		len(seeded.GetFilters()), totalBits(seeded), len(unseeded.GetFilters()), totalBits(unseeded))
+
+	// Version 1 encodings predate layer seeds and decode to unseeded layers.
+	data, err := json.Marshal(unseeded)
+	require.NoError(t, err)
+	data = bytes.Replace(data, []byte(fmt.Sprintf(`"version":%d`, CascadeFormatVersion)), []byte(`"version":1`), 1)
+	var decoded BloomFilterCascade
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.True(t, unseeded.Equal(&decoded))
+	require.True(t, decoded.unseeded, "updates of a version 1 cascade keep its layers unseeded")
+
+	var v1 bytes.Buffer
+	v1.Write(cascadeMagic[:])
+	for _, v := range []any{uint16(1), uint64(capacity), math.Float64bits(unseeded.falsePosRate),
+		math.Float64bits(unseeded.params.SuccRate), int64(0), uint32(0), uint32(len(unseeded.GetFilters()))} {
+		require.NoError(t, binary.Write(&v1, binary.BigEndian, v))
+	}
+	for _, f := range unseeded.GetFilters() {
+		_, err = f.WriteTo(&v1)
+		require.NoError(t, err)
+	}
+	decoded = BloomFilterCascade{}
+	require.NoError(t, decoded.UnmarshalBinary(v1.Bytes()))
+	require.True(t, unseeded.Equal(&decoded))
+	require.True(t, decoded.unseeded, "updates of a version 1 cascade keep its layers unseeded")
+	require.NoError(t, decoded.Update(revoked, valid))
+	for _, f := range decoded.GetFilters() {
+		require.Zero(t, f.Seed())
+	}
+}
+
+func TestCascade_UpdateParallel(t *testing.T) {
+	for _, cfg := range []struct{ domain, capacity, workers int }{
+		{1_000, 50, 4},
+		{100_000, 10_000, 4},
+		{100_000, 10_000, 0},
+		{200_000, 100_000, 7},
+	} {
+		valid, revoked := genRevocationTokens(cfg.domain, cfg.capacity)
+
+		expected := NewCascade(cfg.domain, cfg.capacity)
+		require.NoError(t, expected.Update(revoked, valid))
+
+		parallel := NewCascade(cfg.domain, cfg.capacity)
+		require.NoError(t, parallel.UpdateParallel(revoked, valid, cfg.workers))
+		require.True(t, expected.Equal(parallel), "domain %d, capacity %d, workers %d", cfg.domain, cfg.capacity, cfg.workers)
+	}
+
+	cascade := NewCascade(1_000, 10)
+	require.NoError(t, cascade.UpdateParallel(generateRandom128BitSlices(10), generateRandom128BitSlices(100), 4))
+	require.Error(t, cascade.UpdateParallel(generateRandom128BitSlices(11), nil, 4))
+	require.True(t, cascade.Equal(NewCascade(1_000, 10)))
+}
+
+func TestCascade_OrderIndependent(t *testing.T) {
+	valid, revoked := genRevocationTokens(20_000, 2_000)
+	for _, kind := range []LayerKind{BloomLayers, BinaryFuseLayers} {
+		expected, err := NewCascadeWithParams(20_000, 2_000, kind, Keccak256, DefaultCascadeParams)
+		require.NoError(t, err)
+		require.NoError(t, expected.Update(revoked, valid))
+		expectedBytes, err := expected.MarshalBinary()
+		require.NoError(t, err)
+
+		// The layers only depend on the sets, not on the order of their elements or the number of workers.
+		for _, workers := range []int{1, 4} {
+			shuffledValid := append([][]byte(nil), valid...)
+			shuffledRevoked := append([][]byte(nil), revoked...)
+			mrand.Shuffle(len(shuffledValid), func(a, b int) { shuffledValid[a], shuffledValid[b] = shuffledValid[b], shuffledValid[a] })
+			mrand.Shuffle(len(shuffledRevoked), func(a, b int) { shuffledRevoked[a], shuffledRevoked[b] = shuffledRevoked[b], shuffledRevoked[a] })
+
+			cascade, err := NewCascadeWithParams(20_000, 2_000, kind, Keccak256, DefaultCascadeParams)
+			require.NoError(t, err)
+			require.NoError(t, cascade.UpdateParallel(shuffledRevoked, shuffledValid, workers))
+			data, err := cascade.MarshalBinary()
+			require.NoError(t, err)
+			require.Equal(t, expectedBytes, data, "%s, %d workers", kind, workers)
+		}
+	}
+}
+
 func BenchmarkCascadeGeneration(b *testing.B) {
 	domainSizes := []int{50_000, 100_000, 200_000, 300_000, 400_000, 500_000, 600_000, 700_000, 800_000, 900_000, 1_000_000}
 	revocationRates := []float64{0.05, 0.1}
@@ -132,6 +410,67 @@ func BenchmarkCascadeGeneration(b *testing.B) {
 	}
 }
 
+func BenchmarkCascadeGenerationParallel(b *testing.B) {
+	domainSizes := []int{50_000, 100_000, 200_000, 300_000, 400_000, 500_000, 600_000, 700_000, 800_000, 900_000, 1_000_000}
+	revocationRates := []float64{0.05, 0.1}
+
+	for _, domain := range domainSizes {
+		for _, rate := range revocationRates {
+			name := fmt.Sprintf("Domain_%d_Rate_%.2f", domain, rate)
+			b.Run(name, func(b *testing.B) {
+				capacity := int(float64(domain) * rate)
+				valid := generateRandom128BitSlices(domain - capacity)
+				revoked := generateRandom128BitSlices(capacity)
+
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					cascade := getCascadeFromRate(domain, rate)
+					if err := cascade.UpdateParallel(revoked, valid, 0); err != nil {
+						b.Fatalf("UpdateParallel failed: %v", err)
+					}
+				}
+			})
+		}
+	}
+}
+
+// BenchmarkCascadeLayerSeeds compares the depth and total size of cascades with seeded and unseeded layers.
+func BenchmarkCascadeLayerSeeds(b *testing.B) {
+	domain := 100_000
+	capacity := 1_000
+
+	for _, unseeded := range []bool{false, true} {
+		name := "seeded"
+		if unseeded {
+			name = "unseeded"
+		}
+		b.Run(name, func(b *testing.B) {
+			var layers, bits int
+			for i := 0; i < b.N; i++ {
+				valid, revoked := genRevocationTokens(domain, capacity)
+				cascade := NewCascade(domain, capacity)
+				cascade.unseeded = unseeded
+				if err := cascade.Update(revoked, valid); err != nil {
+					b.Fatal(err)
+				}
+				layers += len(cascade.GetFilters())
+				bits += int(totalBits(cascade))
+			}
+			b.ReportMetric(float64(layers)/float64(b.N), "layers/op")
+			b.ReportMetric(float64(bits)/float64(b.N), "bits/op")
+		})
+	}
+}
+
+// totalBits returns the summed bit length of all layers of c.
+func totalBits(c *BloomFilterCascade) uint {
+	var bits uint
+	for _, f := range c.Layers() {
+		bits += f.BitLen()
+	}
+	return bits
+}
+
 func getCascadeFromRate(domain int, maxRevocationRate float64) *BloomFilterCascade {
 	capacity := int(float64(domain) * maxRevocationRate)
 	return NewCascade(domain, capacity)
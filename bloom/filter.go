@@ -18,11 +18,16 @@ import (
 // requirement is to make membership queries; _i.e._, whether an item is a
 // member of a set.
 type BloomFilter struct {
-	m uint
-	k uint
-	b *bitset.BitSet
+	m    uint
+	k    uint
+	b    *bitset.BitSet
+	seed uint64 // seed is mixed into the hash of every element, see baseHashes.
+	h    Hasher // h is the hash function elements are hashed with; nil means Keccak256.
 }
 
+// maxBloomFilterBits bounds the size of decoded filters and patched layers, like the slots of a BinaryFuseFilter.
+const maxBloomFilterBits = 1 << 32
+
 func max(x, y uint) uint {
 	if x > y {
 		return x
@@ -33,7 +38,19 @@ func max(x, y uint) uint {
 // NewBloomFilter creates a new Bloom filter with _m_ bits and _k_ hashing functions
 // We force _m_ and _k_ to be at least one to avoid panics.
 func NewBloomFilter(m uint, k uint) *BloomFilter {
-	return &BloomFilter{max(1, m), max(1, k), bitset.New(m)}
+	return NewSeededBloomFilter(m, k, 0)
+}
+
+// NewSeededBloomFilter creates a new Bloom filter with _m_ bits and _k_ hashing functions
+// whose hash is seeded with _seed_. Filters with different seeds map an element to independent locations.
+func NewSeededBloomFilter(m uint, k uint, seed uint64) *BloomFilter {
+	return NewBloomFilterWithHasher(m, k, seed, Keccak256)
+}
+
+// NewBloomFilterWithHasher creates a new Bloom filter with _m_ bits and _k_ hashing functions
+// that hashes elements with _h_ and _seed_.
+func NewBloomFilterWithHasher(m uint, k uint, seed uint64, h Hasher) *BloomFilter {
+	return &BloomFilter{max(1, m), max(1, k), bitset.New(m), seed, h}
 }
 
 // From creates a new Bloom filter with len(_data_) * 64 bits and _k_ hashing
@@ -46,13 +63,16 @@ func From(data []uint64, k uint) *BloomFilter {
 // FromWithM creates a new Bloom filter with _m_ length, _k_ hashing functions.
 // The data slice is not going to be reset.
 func FromWithM(data []uint64, m, k uint) *BloomFilter {
-	return &BloomFilter{m, k, bitset.From(data)}
+	return &BloomFilter{m, k, bitset.From(data), 0, Keccak256}
 }
 
 // baseHashes returns the four hash values of data that are used to create k
-// hashes
-func baseHashes(data []byte) [4]uint64 {
-	hash := crypto.Keccak256(data) // 32 bytes
+// hashes, i.e. the digest of h (Keccak256 if nil) for the given seed.
+func baseHashes(h Hasher, data []byte, seed uint64) [4]uint64 {
+	if h == nil {
+		h = Keccak256
+	}
+	hash := h.Sum(data, seed) // 32 bytes
 
 	return [4]uint64{
 		binary.BigEndian.Uint64(hash[0:8]),
@@ -120,6 +140,24 @@ func (f *BloomFilter) K() uint {
 	return f.k
 }
 
+// Kind implements Layer interface.
+func (f *BloomFilter) Kind() LayerKind {
+	return BloomLayers
+}
+
+// Seed returns the hash seed of the BloomFilter
+func (f *BloomFilter) Seed() uint64 {
+	return f.seed
+}
+
+// Hasher returns the hash function of the BloomFilter
+func (f *BloomFilter) Hasher() Hasher {
+	if f.h == nil {
+		return Keccak256
+	}
+	return f.h
+}
+
 // BitSet returns the underlying bitset for this filter.
 func (f *BloomFilter) BitSet() *bitset.BitSet {
 	return f.b
@@ -127,7 +165,7 @@ func (f *BloomFilter) BitSet() *bitset.BitSet {
 
 // Add data to the Bloom Filter. Returns the filter (allows chaining)
 func (f *BloomFilter) Add(data []byte) *BloomFilter {
-	h := baseHashes(data)
+	h := baseHashes(f.h, data, f.seed)
 	for i := uint(0); i < f.k; i++ {
 		f.b.Set(f.location(h, i))
 	}
@@ -145,13 +183,21 @@ func (f *BloomFilter) Merge(g *BloomFilter) error {
 		return fmt.Errorf("k's don't match: %d != %d", f.m, g.m)
 	}
 
+	if f.seed != g.seed {
+		return fmt.Errorf("seeds don't match: %d != %d", f.seed, g.seed)
+	}
+
+	if f.Hasher().ID() != g.Hasher().ID() {
+		return fmt.Errorf("hashers don't match: %s != %s", f.Hasher().ID(), g.Hasher().ID())
+	}
+
 	f.b.InPlaceUnion(g.b)
 	return nil
 }
 
 // Copy creates a copy of a Bloom filter.
 func (f *BloomFilter) Copy() *BloomFilter {
-	fc := NewBloomFilter(f.m, f.k)
+	fc := NewBloomFilterWithHasher(f.m, f.k, f.seed, f.h)
 	fc.Merge(f) // #nosec
 	return fc
 }
@@ -165,7 +211,7 @@ func (f *BloomFilter) AddString(data string) *BloomFilter {
 // If true, the result might be a false positive. If false, the data
 // is definitely not in the set.
 func (f *BloomFilter) Test(data []byte) bool {
-	h := baseHashes(data)
+	h := baseHashes(f.h, data, f.seed)
 	for i := uint(0); i < f.k; i++ {
 		if !f.b.Test(f.location(h, i)) {
 			return false
@@ -174,6 +220,17 @@ func (f *BloomFilter) Test(data []byte) bool {
 	return true
 }
 
+// HashLocations returns the bit locations of data for the first n hash functions of the BloomFilter. Test checks
+// the locations of the first k.
+func (f *BloomFilter) HashLocations(data []byte, n uint) []uint {
+	h := baseHashes(f.h, data, f.seed)
+	locs := make([]uint, n)
+	for i := range locs {
+		locs[i] = f.location(h, uint(i))
+	}
+	return locs
+}
+
 // TestString returns true if the string is in the BloomFilter, false otherwise.
 // If true, the result might be a false positive. If false, the data
 // is definitely not in the set.
@@ -198,7 +255,7 @@ func (f *BloomFilter) TestLocations(locs []uint64) bool {
 // Returns the result of Test.
 func (f *BloomFilter) TestAndAdd(data []byte) bool {
 	present := true
-	h := baseHashes(data)
+	h := baseHashes(f.h, data, f.seed)
 	for i := uint(0); i < f.k; i++ {
 		l := f.location(h, i)
 		if !f.b.Test(l) {
@@ -222,7 +279,7 @@ func (f *BloomFilter) TestAndAddString(data string) bool {
 // Returns the result of Test.
 func (f *BloomFilter) TestOrAdd(data []byte) bool {
 	present := true
-	h := baseHashes(data)
+	h := baseHashes(f.h, data, f.seed)
 	for i := uint(0); i < f.k; i++ {
 		l := f.location(h, i)
 		if !f.b.Test(l) {
@@ -287,14 +344,16 @@ func (f *BloomFilter) ApproximatedSize() uint32 {
 
 // bloomFilterJSON is an unexported type for marshaling/unmarshaling BloomFilter struct.
 type bloomFilterJSON struct {
-	M uint           `json:"m"`
-	K uint           `json:"k"`
-	B *bitset.BitSet `json:"b"`
+	M    uint           `json:"m"`
+	K    uint           `json:"k"`
+	B    *bitset.BitSet `json:"b"`
+	Seed uint64         `json:"seed,omitempty"`
+	Hash HasherID       `json:"hasher,omitempty"`
 }
 
 // MarshalJSON implements json.Marshaler interface.
 func (f BloomFilter) MarshalJSON() ([]byte, error) {
-	return json.Marshal(bloomFilterJSON{f.m, f.k, f.b})
+	return json.Marshal(bloomFilterJSON{f.m, f.k, f.b, f.seed, f.Hasher().ID()})
 }
 
 // UnmarshalJSON implements json.Unmarshaler interface.
@@ -306,12 +365,19 @@ func (f *BloomFilter) UnmarshalJSON(data []byte) error {
 	}
 	f.m = j.M
 	f.k = j.K
+	h, err := HasherByID(j.Hash)
+	if err != nil {
+		return err
+	}
 	f.b = j.B
+	f.seed = j.Seed
+	f.h = h
 	return nil
 }
 
 // WriteTo writes a binary representation of the BloomFilter to an i/o stream.
-// It returns the number of bytes written.
+// It returns the number of bytes written. Seed and hasher are not part of the encoding,
+// BloomFilterCascade stores them with the cascade.
 //
 // Performance: if this function is used to write to a disk or network
 // connection, it might be beneficial to wrap the stream in a bufio.Writer.
@@ -343,7 +409,7 @@ func (f *BloomFilter) WriteTo(stream io.Writer) (int64, error) {
 //	f, err := os.Open("myfile")
 //	r := bufio.NewReader(f)
 func (f *BloomFilter) ReadFrom(stream io.Reader) (int64, error) {
-	var m, k uint64
+	var m, k, length uint64
 	err := binary.Read(stream, binary.BigEndian, &m)
 	if err != nil {
 		return 0, err
@@ -352,15 +418,27 @@ func (f *BloomFilter) ReadFrom(stream io.Reader) (int64, error) {
 	if err != nil {
 		return 0, err
 	}
-	b := &bitset.BitSet{}
-	numBytes, err := b.ReadFrom(stream)
+	if m < 1 || m > maxBloomFilterBits || k < 1 || k > math.MaxUint32 {
+		return 0, fmt.Errorf("invalid bloom filter parameters m=%d, k=%d", m, k)
+	}
+	// The words of the bit set are read as they arrive, so a corrupted length cannot allocate more than the input.
+	err = binary.Read(stream, binary.BigEndian, &length)
+	if err != nil {
+		return 0, err
+	}
+	if length > m {
+		return 0, fmt.Errorf("bit set of %d bits exceeds filter of %d bits", length, m)
+	}
+	words, err := readWords(stream, (length+63)/64)
 	if err != nil {
 		return 0, err
 	}
 	f.m = uint(m)
 	f.k = uint(k)
-	f.b = b
-	return numBytes + int64(2*binary.Size(uint64(0))), nil
+	f.b = bitset.FromWithLength(uint(length), words)
+	f.seed = 0
+	f.h = Keccak256
+	return int64(binary.Size(words) + 3*binary.Size(uint64(0))), nil
 }
 
 // GobEncode implements gob.GobEncoder interface.
@@ -403,15 +481,15 @@ func (f *BloomFilter) UnmarshalBinary(data []byte) error {
 
 // Equal tests for the equality of two Bloom filters
 func (f *BloomFilter) Equal(g *BloomFilter) bool {
-	return f.m == g.m && f.k == g.k && f.b.Equal(g.b)
+	return f.m == g.m && f.k == g.k && f.seed == g.seed && f.Hasher().ID() == g.Hasher().ID() && f.b.Equal(g.b)
 }
 
-// Locations returns a list of hash locations representing a data item.
+// Locations returns a list of hash locations representing a data item in an unseeded filter.
 func Locations(data []byte, k uint) []uint64 {
 	locs := make([]uint64, k)
 
 	// calculate locations
-	h := baseHashes(data)
+	h := baseHashes(Keccak256, data, 0)
 	for i := uint(0); i < k; i++ {
 		locs[i] = location(h, i)
 	}
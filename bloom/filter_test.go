@@ -166,7 +166,7 @@ func chiTestBloom(m, k, rounds uint, elements [][]byte) (succeeds bool) {
 	chi := make([]float64, m)
 
 	for _, data := range elements {
-		h := baseHashes(data)
+		h := baseHashes(Keccak256, data, 0)
 		for i := uint(0); i < f.k; i++ {
 			results[f.location(h, i)]++
 		}
@@ -261,7 +261,7 @@ func TestMarshalUnmarshalJSON(t *testing.T) {
 }
 
 func TestMarshalUnmarshalJSONValue(t *testing.T) {
-	f := BloomFilter{1000, 4, bitset.New(1000)}
+	f := BloomFilter{1000, 4, bitset.New(1000), 0, Keccak256}
 	data, err := json.Marshal(f)
 	if err != nil {
 		t.Fatal(err.Error())
@@ -0,0 +1,396 @@
+package bloom
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"math/bits"
+)
+
+// maxFuseAttempts bounds the number of seeds tried to construct a BinaryFuseFilter. A construction fails with
+// probability well below 1% for sets of more than a few elements, so this is only reached for duplicate hashes.
+const maxFuseAttempts = 100
+
+// maxFuseElements bounds the number of elements of a BinaryFuseFilter, so that its slots take at most 2^32 bits.
+const maxFuseElements = 1 << 26
+
+// BinaryFuseFilter is a 3-wise binary fuse filter (Graf and Lemire, "Binary Fuse Filters: Fast and Smaller Than Xor
+// Filters", 2022) with fingerprints of 1 to 32 bits. It holds the fixed set it was constructed with and accepts any
+// other element with probability 2^-fingerprintBits. For large sets it takes about 1.125 * fingerprintBits bits per
+// element, compared to 1.44 * log2(1/p) bits of a Bloom filter with false positive rate p.
+//
+// An element is located by the base hashes of the filter's Hasher and seed, like in a BloomFilter: the first base hash
+// selects one slot in each of three consecutive segments and the second is the fingerprint. The element is contained
+// if the XOR of its fingerprint and the three slots is zero.
+type BinaryFuseFilter struct {
+	fingerprintBits uint     // fingerprintBits is the width of a fingerprint.
+	segmentLength   uint32   // segmentLength is the number of slots of a segment, a power of two.
+	segmentCount    uint32   // segmentCount is the number of segments the first slot of an element falls into.
+	fingerprints    []uint64 // fingerprints holds the packed slots, in the bit order of onChainBytes.
+	seed            uint64   // seed is mixed into the hash of every element, see baseHashes.
+	h               Hasher   // h is the hash function elements are hashed with; nil means Keccak256.
+}
+
+// NewBinaryFuseFilter constructs a binary fuse filter of elements with fingerprints of fingerprintBits bits whose
+// elements are hashed with h and seed. If the construction fails for seed, seed + 2^32, seed + 2*2^32, ... are tried
+// in turn; Seed returns the seed of the result. Duplicate elements are stored once.
+func NewBinaryFuseFilter(elements [][]byte, fingerprintBits uint, seed uint64, h Hasher) (*BinaryFuseFilter, error) {
+	if fingerprintBits < 1 || fingerprintBits > 32 {
+		return nil, fmt.Errorf("fingerprint bits %d out of range [1, 32]", fingerprintBits)
+	}
+	if len(elements) > maxFuseElements {
+		return nil, fmt.Errorf("%d elements exceed a binary fuse filter", len(elements))
+	}
+	f := &BinaryFuseFilter{fingerprintBits: fingerprintBits, seed: seed, h: h}
+	if len(elements) == 0 {
+		return f, nil
+	}
+	f.segmentLength, f.segmentCount = binaryFuseParameters(len(elements))
+	f.fingerprints = make([]uint64, (uint64(f.slots())*uint64(fingerprintBits)+63)/64)
+
+	for attempt := uint64(0); attempt < maxFuseAttempts; attempt++ {
+		f.seed = seed + attempt<<32
+		if f.populate(elements) {
+			return f, nil
+		}
+	}
+	return nil, fmt.Errorf("failed to construct binary fuse filter of %d elements", len(elements))
+}
+
+// binaryFuseParameters returns the segment length and count of a filter of n > 0 elements, as chosen by the reference
+// implementation for arity 3.
+func binaryFuseParameters(n int) (segmentLength, segmentCount uint32) {
+	segmentLength = 1 << int(math.Floor(math.Log(float64(n))/math.Log(3.33)+2.25))
+	if segmentLength > 1<<18 {
+		segmentLength = 1 << 18
+	}
+	capacity := 0
+	if n > 1 {
+		sizeFactor := math.Max(1.125, 0.875+0.25*math.Log(1_000_000)/math.Log(float64(n)))
+		capacity = int(math.Round(float64(n) * sizeFactor))
+	}
+	count := (capacity+int(segmentLength)-1)/int(segmentLength) - 2
+	if count < 1 {
+		count = 1
+	}
+	return segmentLength, uint32(count)
+}
+
+// fuseKey is an element as seen by a BinaryFuseFilter.
+type fuseKey struct {
+	slots       [3]uint32 // slots are the element's slots.
+	fingerprint uint32    // fingerprint is the element's fingerprint.
+}
+
+// populate assigns the slots such that all elements are contained, for the current seed. It reports false if the
+// elements cannot be peeled, in which case the construction has to be retried with another seed.
+func (f *BinaryFuseFilter) populate(elements [][]byte) bool {
+	keys := make([]fuseKey, 0, len(elements))
+	seen := make(map[fuseKey]struct{}, len(elements))
+	for _, e := range elements {
+		k := f.key(e)
+		// Equal keys, e.g. of duplicate elements, could never be peeled.
+		if _, ok := seen[k]; ok {
+			continue
+		}
+		seen[k] = struct{}{}
+		keys = append(keys, k)
+	}
+
+	slots := f.slots()
+	count := make([]uint32, slots)
+	xorKeys := make([]uint32, slots)
+	for i, k := range keys {
+		for _, s := range k.slots {
+			count[s]++
+			xorKeys[s] ^= uint32(i)
+		}
+	}
+
+	// Peel keys that are alone in one of their slots until no key is left.
+	var queue []uint32
+	for s, c := range count {
+		if c == 1 {
+			queue = append(queue, uint32(s))
+		}
+	}
+	type peeled struct{ key, slot uint32 }
+	stack := make([]peeled, 0, len(keys))
+	for len(queue) > 0 {
+		s := queue[len(queue)-1]
+		queue = queue[:len(queue)-1]
+		if count[s] != 1 {
+			continue
+		}
+		i := xorKeys[s]
+		stack = append(stack, peeled{i, s})
+		for _, t := range keys[i].slots {
+			count[t]--
+			xorKeys[t] ^= i
+			if count[t] == 1 {
+				queue = append(queue, t)
+			}
+		}
+	}
+	if len(stack) != len(keys) {
+		return false
+	}
+
+	// Assign the slots in reverse order of peeling; a key's own slot is not assigned before it.
+	clear(f.fingerprints)
+	for j := len(stack) - 1; j >= 0; j-- {
+		k := keys[stack[j].key]
+		v := k.fingerprint
+		for _, t := range k.slots {
+			v ^= f.get(t)
+		}
+		f.set(stack[j].slot, v)
+	}
+	return true
+}
+
+// key returns the slots and fingerprint of data.
+func (f *BinaryFuseFilter) key(data []byte) fuseKey {
+	// The slots come from the last word, as the first word of a MiMCBN254 digest is below the field modulus.
+	h := baseHashes(f.h, data, f.seed)
+	hi, _ := bits.Mul64(h[3], uint64(f.segmentCount)*uint64(f.segmentLength))
+	mask := uint64(f.segmentLength - 1)
+	s0 := hi
+	s1 := (s0 + uint64(f.segmentLength)) ^ ((h[3] >> 18) & mask)
+	s2 := (s0 + 2*uint64(f.segmentLength)) ^ (h[3] & mask)
+	return fuseKey{
+		slots:       [3]uint32{uint32(s0), uint32(s1), uint32(s2)},
+		fingerprint: uint32(h[1] & f.fingerprintMask()),
+	}
+}
+
+// slots returns the number of slots of the filter.
+func (f *BinaryFuseFilter) slots() uint32 {
+	if f.segmentCount == 0 {
+		return 0
+	}
+	return (f.segmentCount + 2) * f.segmentLength
+}
+
+// fingerprintMask returns the mask of the fingerprint bits.
+func (f *BinaryFuseFilter) fingerprintMask() uint64 {
+	return 1<<f.fingerprintBits - 1
+}
+
+// get returns the value of slot s.
+func (f *BinaryFuseFilter) get(s uint32) uint32 {
+	pos := uint64(s) * uint64(f.fingerprintBits)
+	w, off := pos/64, pos%64
+	v := f.fingerprints[w] >> off
+	if off+uint64(f.fingerprintBits) > 64 {
+		v |= f.fingerprints[w+1] << (64 - off)
+	}
+	return uint32(v & f.fingerprintMask())
+}
+
+// set sets slot s to v.
+func (f *BinaryFuseFilter) set(s uint32, v uint32) {
+	pos := uint64(s) * uint64(f.fingerprintBits)
+	w, off := pos/64, pos%64
+	mask := f.fingerprintMask()
+	f.fingerprints[w] = f.fingerprints[w]&^(mask<<off) | uint64(v)<<off
+	if off+uint64(f.fingerprintBits) > 64 {
+		f.fingerprints[w+1] = f.fingerprints[w+1]&^(mask>>(64-off)) | uint64(v)>>(64-off)
+	}
+}
+
+// Test returns true if data may be in the set of the BinaryFuseFilter. Elements of the set are always accepted,
+// others with probability 2^-FingerprintBits.
+func (f *BinaryFuseFilter) Test(data []byte) bool {
+	if f.segmentCount == 0 {
+		return false
+	}
+	k := f.key(data)
+	return k.fingerprint^f.get(k.slots[0])^f.get(k.slots[1])^f.get(k.slots[2]) == 0
+}
+
+// Kind implements Layer interface.
+func (f *BinaryFuseFilter) Kind() LayerKind {
+	return BinaryFuseLayers
+}
+
+// BitLen returns the number of bits of the slots.
+func (f *BinaryFuseFilter) BitLen() uint {
+	return uint(f.slots()) * f.fingerprintBits
+}
+
+// FingerprintBits returns the width of a fingerprint.
+func (f *BinaryFuseFilter) FingerprintBits() uint {
+	return f.fingerprintBits
+}
+
+// SegmentLength returns the number of slots of a segment.
+func (f *BinaryFuseFilter) SegmentLength() uint32 {
+	return f.segmentLength
+}
+
+// SegmentCount returns the number of segments the first slot of an element falls into; the filter has two more.
+// It is zero for an empty filter.
+func (f *BinaryFuseFilter) SegmentCount() uint32 {
+	return f.segmentCount
+}
+
+// Seed returns the hash seed of the BinaryFuseFilter.
+func (f *BinaryFuseFilter) Seed() uint64 {
+	return f.seed
+}
+
+// Hasher returns the hash function of the BinaryFuseFilter.
+func (f *BinaryFuseFilter) Hasher() Hasher {
+	if f.h == nil {
+		return Keccak256
+	}
+	return f.h
+}
+
+// Equal tests for the equality of two binary fuse filters.
+func (f *BinaryFuseFilter) Equal(g *BinaryFuseFilter) bool {
+	if f.fingerprintBits != g.fingerprintBits || f.segmentLength != g.segmentLength ||
+		f.segmentCount != g.segmentCount || f.seed != g.seed || f.Hasher().ID() != g.Hasher().ID() ||
+		len(f.fingerprints) != len(g.fingerprints) {
+		return false
+	}
+	for i := range f.fingerprints {
+		if f.fingerprints[i] != g.fingerprints[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// binaryFuseFilterJSON is an unexported type for marshaling/unmarshaling BinaryFuseFilter struct.
+type binaryFuseFilterJSON struct {
+	FingerprintBits uint     `json:"fingerprintBits"`
+	SegmentLength   uint32   `json:"segmentLength"`
+	SegmentCount    uint32   `json:"segmentCount"`
+	Fingerprints    []uint64 `json:"fingerprints"`
+	Seed            uint64   `json:"seed,omitempty"`
+	Hash            HasherID `json:"hasher,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler interface.
+func (f BinaryFuseFilter) MarshalJSON() ([]byte, error) {
+	return json.Marshal(binaryFuseFilterJSON{f.fingerprintBits, f.segmentLength, f.segmentCount, f.fingerprints, f.seed, f.Hasher().ID()})
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface.
+func (f *BinaryFuseFilter) UnmarshalJSON(data []byte) error {
+	var j binaryFuseFilterJSON
+	err := json.Unmarshal(data, &j)
+	if err != nil {
+		return err
+	}
+	h, err := HasherByID(j.Hash)
+	if err != nil {
+		return err
+	}
+	g, err := binaryFuseFilterOfParameters(j.FingerprintBits, j.SegmentLength, j.SegmentCount, j.Seed, h, j.Fingerprints)
+	if err != nil {
+		return err
+	}
+	*f = *g
+	return nil
+}
+
+// WriteTo writes a binary representation of the BinaryFuseFilter to an i/o stream: the fingerprint bits, segment
+// length, segment count and number of words as uint64 followed by the words. It returns the number of bytes written.
+// Seed and hasher are not part of the encoding, BloomFilterCascade stores them with the cascade.
+func (f *BinaryFuseFilter) WriteTo(stream io.Writer) (int64, error) {
+	header := []uint64{uint64(f.fingerprintBits), uint64(f.segmentLength), uint64(f.segmentCount), uint64(len(f.fingerprints))}
+	err := binary.Write(stream, binary.BigEndian, header)
+	if err != nil {
+		return 0, err
+	}
+	err = binary.Write(stream, binary.BigEndian, f.fingerprints)
+	if err != nil {
+		return int64(binary.Size(header)), err
+	}
+	return int64(binary.Size(header) + binary.Size(f.fingerprints)), nil
+}
+
+// ReadFrom reads a binary representation of the BinaryFuseFilter (such as might have been written by WriteTo())
+// from an i/o stream. It returns the number of bytes read.
+func (f *BinaryFuseFilter) ReadFrom(stream io.Reader) (int64, error) {
+	var header [4]uint64
+	err := binary.Read(stream, binary.BigEndian, &header)
+	if err != nil {
+		return 0, err
+	}
+	if header[0] > 32 || header[1] > math.MaxUint32 || header[2] > math.MaxUint32 {
+		return 0, errors.New("invalid binary fuse filter parameters")
+	}
+	words, err := readWords(stream, header[3])
+	if err != nil {
+		return 0, err
+	}
+	g, err := binaryFuseFilterOfParameters(uint(header[0]), uint32(header[1]), uint32(header[2]), 0, Keccak256, words)
+	if err != nil {
+		return 0, err
+	}
+	*f = *g
+	return int64(binary.Size(header) + binary.Size(g.fingerprints)), nil
+}
+
+// binaryFuseFilterOfParameters returns the binary fuse filter of the given fingerprint words after checking its
+// parameters and that the words hold exactly its slots. An empty filter has no segments.
+func binaryFuseFilterOfParameters(fingerprintBits uint, segmentLength, segmentCount uint32, seed uint64, h Hasher, fingerprints []uint64) (*BinaryFuseFilter, error) {
+	if fingerprintBits < 1 || fingerprintBits > 32 {
+		return nil, fmt.Errorf("fingerprint bits %d out of range [1, 32]", fingerprintBits)
+	}
+	f := &BinaryFuseFilter{fingerprintBits: fingerprintBits, segmentLength: segmentLength, segmentCount: segmentCount, seed: seed, h: h}
+	if segmentCount == 0 {
+		if segmentLength != 0 {
+			return nil, errors.New("empty binary fuse filter has a segment length")
+		}
+		if len(fingerprints) != 0 {
+			return nil, fmt.Errorf("%d words do not fit an empty binary fuse filter", len(fingerprints))
+		}
+		return f, nil
+	}
+	if segmentLength == 0 || segmentLength&(segmentLength-1) != 0 {
+		return nil, fmt.Errorf("segment length %d is not a power of two", segmentLength)
+	}
+	// The slots are bounded like in NewBinaryFuseFilter.
+	if uint64(segmentCount)+2 > (1<<32)/uint64(segmentLength)/uint64(fingerprintBits) {
+		return nil, errors.New("binary fuse filter too large")
+	}
+	if uint64(len(fingerprints)) != (uint64(f.slots())*uint64(fingerprintBits)+63)/64 {
+		return nil, fmt.Errorf("%d words do not fit %d slots of %d bits", len(fingerprints), f.slots(), fingerprintBits)
+	}
+	f.fingerprints = fingerprints
+	return f, nil
+}
+
+// GetOnChainFuseFilter returns the arguments of the updateCascade call of the CascadingBinaryFuseFilter contract: the
+// slots of each layer packed like GetOnChainFilter, the fingerprint bits, segment lengths, segment counts and seeds.
+// It panics if the cascade's layers are not binary fuse filters.
+func (c *BloomFilterCascade) GetOnChainFuseFilter() (filters [][]byte, fingerprintBits, segmentLengths, segmentCounts, seeds []*big.Int) {
+	n := len(c.filters)
+	filters = make([][]byte, n)
+	fingerprintBits = make([]*big.Int, n)
+	segmentLengths = make([]*big.Int, n)
+	segmentCounts = make([]*big.Int, n)
+	seeds = make([]*big.Int, n)
+
+	for i, l := range c.filters {
+		f, ok := l.(*BinaryFuseFilter)
+		if !ok {
+			panic(fmt.Sprintf("bloom: layer %d is a %s layer, not a binary fuse filter", i, l.Kind()))
+		}
+		filters[i] = wordBytes(f.fingerprints)
+		fingerprintBits[i] = big.NewInt(int64(f.fingerprintBits))
+		segmentLengths[i] = big.NewInt(int64(f.segmentLength))
+		segmentCounts[i] = big.NewInt(int64(f.segmentCount))
+		seeds[i] = new(big.Int).SetUint64(f.seed)
+	}
+	return filters, fingerprintBits, segmentLengths, segmentCounts, seeds
+}
@@ -0,0 +1,245 @@
+package bloom
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"math/bits"
+	"testing"
+)
+
+func TestBinaryFuseFilter(t *testing.T) {
+	for _, cfg := range []struct {
+		n               int
+		fingerprintBits uint
+	}{
+		{0, 4}, {1, 1}, {2, 8}, {10, 4}, {1_000, 1}, {1_000, 13}, {1_000, 32}, {50_000, 4},
+	} {
+		elements := generateRandom128BitSlices(cfg.n)
+		f, err := NewBinaryFuseFilter(elements, cfg.fingerprintBits, 7, Keccak256)
+		require.NoError(t, err, "n %d, fingerprint bits %d", cfg.n, cfg.fingerprintBits)
+		for _, e := range elements {
+			require.True(t, f.Test(e), "n %d, fingerprint bits %d", cfg.n, cfg.fingerprintBits)
+		}
+		filter, fingerprintBits, segmentLength, segmentCount, seed := onChainFuseLayer(f)
+		for _, e := range append(elements, generateRandom128BitSlices(100)...) {
+			require.Equal(t, f.Test(e), onChainFuseTest(filter, fingerprintBits, segmentLength, segmentCount, seed, e))
+		}
+	}
+
+	// Other elements are accepted with probability 2^-fingerprintBits, in about 1.2 * fingerprintBits bits each.
+	elements := generateRandom128BitSlices(50_000)
+	f, err := NewBinaryFuseFilter(elements, 4, 0, Keccak256)
+	require.NoError(t, err)
+	falsePositives := 0
+	for _, e := range generateRandom128BitSlices(100_000) {
+		if f.Test(e) {
+			falsePositives++
+		}
+	}
+	require.InDelta(t, 1.0/16, float64(falsePositives)/100_000, 0.005)
+	require.Less(t, f.BitLen(), uint(1.25*4*50_000))
+
+	// Duplicates are stored once.
+	f, err = NewBinaryFuseFilter(append(elements[:100:100], elements[:100]...), 8, 0, Keccak256)
+	require.NoError(t, err)
+	for _, e := range elements[:100] {
+		require.True(t, f.Test(e))
+	}
+
+	_, err = NewBinaryFuseFilter(elements, 0, 0, Keccak256)
+	require.Error(t, err)
+	_, err = NewBinaryFuseFilter(elements, 33, 0, Keccak256)
+	require.Error(t, err)
+}
+
+func TestBinaryFuseFilter_RoundTrip(t *testing.T) {
+	f, err := NewBinaryFuseFilter(generateRandom128BitSlices(1_000), 5, 3, MiMCBN254)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	n, err := f.WriteTo(&buf)
+	require.NoError(t, err)
+	require.Equal(t, int64(buf.Len()), n)
+	var decoded BinaryFuseFilter
+	read, err := decoded.ReadFrom(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	require.Equal(t, n, read)
+	// Seed and hasher are stored by the cascade.
+	decoded.seed, decoded.h = f.seed, f.h
+	require.True(t, f.Equal(&decoded))
+
+	data, err := json.Marshal(f)
+	require.NoError(t, err)
+	var fromJSON BinaryFuseFilter
+	require.NoError(t, json.Unmarshal(data, &fromJSON))
+	require.True(t, f.Equal(&fromJSON))
+
+	_, err = decoded.ReadFrom(bytes.NewReader(buf.Bytes()[:buf.Len()-1]))
+	require.Error(t, err)
+	corrupted := append([]byte(nil), buf.Bytes()...)
+	corrupted[31]++ // number of words
+	_, err = decoded.ReadFrom(bytes.NewReader(corrupted))
+	require.Error(t, err)
+}
+
+func TestCascade_BinaryFuseLayers(t *testing.T) {
+	domain := 100_000
+	capacity := 10_000
+
+	valid, revoked := genRevocationTokens(domain, capacity)
+	cascade, err := NewCascadeWithLayers(domain, capacity, BinaryFuseLayers, Keccak256)
+	require.NoError(t, err)
+	require.NoError(t, cascade.Update(revoked, valid))
+	require.Equal(t, BinaryFuseLayers, cascade.LayerKind())
+	require.Nil(t, cascade.GetFilters())
+
+	filters, fingerprintBits, segmentLengths, segmentCounts, seeds := cascade.GetOnChainFuseFilter()
+	require.Len(t, filters, len(cascade.Layers()))
+	for _, tokens := range [][][]byte{valid[:10_000], revoked} {
+		for _, tok := range tokens {
+			ok, layer := cascade.Test(tok)
+			onChainOk, onChainLayer := onChainFuseCascadeTest(filters, fingerprintBits, segmentLengths, segmentCounts, seeds, tok)
+			require.Equal(t, ok, onChainOk)
+			require.Equal(t, layer, onChainLayer)
+		}
+	}
+	for _, tok := range valid {
+		ok, _ := cascade.Test(tok)
+		require.False(t, ok)
+	}
+	for _, tok := range revoked {
+		ok, _ := cascade.Test(tok)
+		require.True(t, ok)
+	}
+
+	bloomCascade := NewCascade(domain, capacity)
+	require.NoError(t, bloomCascade.Update(revoked, valid))
+	require.Less(t, totalBits(cascade), totalBits(bloomCascade))
+	t.Logf("binary fuse: %d layers, %d bits; bloom: %d layers, %d bits",
+		len(cascade.Layers()), totalBits(cascade), len(bloomCascade.Layers()), totalBits(bloomCascade))
+
+	// Parallel and streamed construction build the same cascade.
+	parallel, err := NewCascadeWithLayers(domain, capacity, BinaryFuseLayers, Keccak256)
+	require.NoError(t, err)
+	require.NoError(t, parallel.UpdateParallel(revoked, valid, 4))
+	require.True(t, cascade.Equal(parallel))
+	streamed, err := NewCascadeWithLayers(domain, capacity, BinaryFuseLayers, Keccak256)
+	require.NoError(t, err)
+	require.NoError(t, streamed.UpdateFromSources(SliceSource(revoked), SliceSource(valid)))
+	require.True(t, cascade.Equal(streamed))
+	require.Error(t, streamed.UpdateFromSources(SliceSource(generateRandom128BitSlices(capacity+1)), nil))
+
+	// The layer kind is part of both encodings.
+	cascade.SetEpoch(42)
+	data, err := cascade.MarshalBinary()
+	require.NoError(t, err)
+	var decoded BloomFilterCascade
+	require.NoError(t, decoded.UnmarshalBinary(data))
+	require.True(t, cascade.Equal(&decoded))
+	data, err = json.Marshal(cascade)
+	require.NoError(t, err)
+	var fromJSON BloomFilterCascade
+	require.NoError(t, json.Unmarshal(data, &fromJSON))
+	require.True(t, cascade.Equal(&fromJSON))
+	require.False(t, cascade.Equal(bloomCascade))
+
+	// Only Bloom layers can be patched and published to CascadingBloomFilter.
+	_, err = Diff(cascade, &decoded)
+	require.Error(t, err)
+	require.Panics(t, func() { cascade.GetOnChainFilter() })
+	require.Panics(t, func() { bloomCascade.GetOnChainFuseFilter() })
+	_, err = NewCascadeWithLayers(domain, capacity, LayerKind(2), Keccak256)
+	require.Error(t, err)
+}
+
+func TestCascade_EmptyBinaryFuseLayers(t *testing.T) {
+	cascade, err := NewCascadeWithLayers(1000, 10, BinaryFuseLayers, Keccak256)
+	require.NoError(t, err)
+	valid := generateRandom128BitSlices(100)
+	require.NoError(t, cascade.Update(nil, valid))
+	require.Len(t, cascade.Layers(), 1)
+	for _, tok := range valid {
+		ok, _ := cascade.Test(tok)
+		require.False(t, ok)
+	}
+	data, err := cascade.MarshalBinary()
+	require.NoError(t, err)
+	var decoded BloomFilterCascade
+	require.NoError(t, decoded.UnmarshalBinary(data))
+	require.True(t, cascade.Equal(&decoded))
+}
+
+// BenchmarkCascadeLayerKinds compares the depth and total size of cascades of Bloom and binary fuse layers.
+func BenchmarkCascadeLayerKinds(b *testing.B) {
+	for _, cfg := range []struct{ domain, capacity int }{
+		{10_000, 500}, {10_000, 1_000}, {100_000, 5_000}, {100_000, 10_000}, {1_000_000, 50_000}, {1_000_000, 100_000},
+	} {
+		for _, kind := range []LayerKind{BloomLayers, BinaryFuseLayers} {
+			b.Run(fmt.Sprintf("D%d_C%d_%s", cfg.domain, cfg.capacity, kind), func(b *testing.B) {
+				var layers, bits int
+				for i := 0; i < b.N; i++ {
+					valid, revoked := genRevocationTokens(cfg.domain, cfg.capacity)
+					cascade, err := NewCascadeWithLayers(cfg.domain, cfg.capacity, kind, Keccak256)
+					if err != nil {
+						b.Fatal(err)
+					}
+					if err := cascade.Update(revoked, valid); err != nil {
+						b.Fatal(err)
+					}
+					layers += len(cascade.Layers())
+					bits += int(totalBits(cascade))
+				}
+				b.ReportMetric(float64(layers)/float64(b.N), "layers/op")
+				b.ReportMetric(float64(bits)/float64(b.N), "bits/op")
+			})
+		}
+	}
+}
+
+// onChainFuseLayer returns the on-chain representation of a binary fuse layer, see GetOnChainFuseFilter.
+func onChainFuseLayer(f *BinaryFuseFilter) (filter []byte, fingerprintBits, segmentLength, segmentCount, seed uint64) {
+	return wordBytes(f.fingerprints), uint64(f.fingerprintBits), uint64(f.segmentLength), uint64(f.segmentCount), f.seed
+}
+
+// onChainFuseTest mirrors _testInLayer of the CascadingBinaryFuseFilter contract on the packed bytes of a layer.
+func onChainFuseTest(filter []byte, fingerprintBits, segmentLength, segmentCount, seed uint64, token []byte) bool {
+	if segmentCount == 0 {
+		return false
+	}
+	h := baseHashes(Keccak256, token, seed)
+	s0, _ := bits.Mul64(h[3], segmentCount*segmentLength)
+	mask := segmentLength - 1
+	v := h[1] & (1<<fingerprintBits - 1)
+	for _, slot := range []uint64{s0, (s0 + segmentLength) ^ ((h[3] >> 18) & mask), (s0 + 2*segmentLength) ^ (h[3] & mask)} {
+		pos := slot * fingerprintBits
+		var w uint64
+		for j := (pos + fingerprintBits - 1) / 8; ; j-- {
+			w = w<<8 | uint64(filter[j])
+			if j == pos/8 {
+				break
+			}
+		}
+		v ^= (w >> (pos % 8)) & (1<<fingerprintBits - 1)
+	}
+	return v == 0
+}
+
+// onChainFuseCascadeTest mirrors testToken of the CascadingBinaryFuseFilter contract on the arguments of its
+// updateCascade call.
+func onChainFuseCascadeTest(filters [][]byte, fingerprintBits, segmentLengths, segmentCounts, seeds []*big.Int, token []byte) (bool, int) {
+	last := len(filters) - 1
+	for layer := range filters {
+		match := onChainFuseTest(filters[layer], fingerprintBits[layer].Uint64(), segmentLengths[layer].Uint64(),
+			segmentCounts[layer].Uint64(), seeds[layer].Uint64(), token)
+		if layer == last {
+			return match == (layer%2 == 0), layer
+		}
+		if !match {
+			return layer%2 == 1, layer
+		}
+	}
+	panic("unreachable: all layers exhausted without return")
+}
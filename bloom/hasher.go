@@ -0,0 +1,132 @@
+package bloom
+
+import (
+	"encoding/binary"
+	"fmt"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/mimc"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// HasherID identifies a Hasher in serialized filters and cascades.
+type HasherID uint8
+
+// Identifiers of the supported hash functions.
+const (
+	Keccak256ID HasherID = 0 // Keccak256ID identifies Keccak256, the hash of the on-chain cascade.
+	MiMCBN254ID HasherID = 1 // MiMCBN254ID identifies MiMCBN254, the hash of gnark's std/hash/mimc over BN254.
+)
+
+// String returns the name of the hash function.
+func (id HasherID) String() string {
+	switch id {
+	case Keccak256ID:
+		return "keccak256"
+	case MiMCBN254ID:
+		return "mimc-bn254"
+	default:
+		return fmt.Sprintf("unknown hasher %d", uint8(id))
+	}
+}
+
+// Hasher computes the 32 byte digest the hash locations of an element are derived from.
+// The four 64-bit big-endian words of the digest are the base hashes of the element.
+type Hasher interface {
+	// ID identifies the hash function in serialized filters and cascades.
+	ID() HasherID
+	// Sum returns the digest of data in a filter with the given seed.
+	Sum(data []byte, seed uint64) [32]byte
+}
+
+var (
+	// Keccak256 hashes keccak256(data) for seed 0 and keccak256(data || seed) with the seed as 8 bytes big-endian
+	// otherwise, like the CascadingBloomFilter contract.
+	Keccak256 Hasher = keccak256Hasher{}
+	// MiMCBN254 hashes MiMC(x_1, ..., x_n, seed) over the BN254 scalar field, where x_i are the 32 byte big-endian
+	// chunks of data. A revocation token that is a field element is hashed as a single element, so cascades built
+	// with MiMCBN254 can be checked in a gnark circuit with std/hash/mimc. Data with a chunk that is not below the
+	// field modulus is split into 31 byte chunks instead and hashed as MiMC(y_1, ..., y_m, seed, p-1); the trailing
+	// p-1 can never be a seed, so such data does not collide with its reduction modulo the field.
+	MiMCBN254 Hasher = mimcHasher{}
+)
+
+// HasherByID returns the Hasher with the given identifier.
+func HasherByID(id HasherID) (Hasher, error) {
+	switch id {
+	case Keccak256ID:
+		return Keccak256, nil
+	case MiMCBN254ID:
+		return MiMCBN254, nil
+	default:
+		return nil, fmt.Errorf("unsupported hasher %d", uint8(id))
+	}
+}
+
+// keccak256Hasher implements Keccak256.
+type keccak256Hasher struct{}
+
+// ID implements Hasher interface.
+func (keccak256Hasher) ID() HasherID {
+	return Keccak256ID
+}
+
+// Sum implements Hasher interface.
+func (keccak256Hasher) Sum(data []byte, seed uint64) (digest [32]byte) {
+	if seed == 0 {
+		copy(digest[:], crypto.Keccak256(data))
+		return digest
+	}
+	var s [8]byte
+	binary.BigEndian.PutUint64(s[:], seed)
+	copy(digest[:], crypto.Keccak256(data, s[:]))
+	return digest
+}
+
+// mimcHasher implements MiMCBN254.
+type mimcHasher struct{}
+
+// ID implements Hasher interface.
+func (mimcHasher) ID() HasherID {
+	return MiMCBN254ID
+}
+
+// Sum implements Hasher interface.
+func (mimcHasher) Sum(data []byte, seed uint64) (digest [32]byte) {
+	h := mimc.NewMiMC()
+	chunk := fr.Bytes
+	if !canonicalChunks(data) {
+		chunk = fr.Bytes - 1
+	}
+	var e fr.Element
+	for start := 0; start < len(data); start += chunk {
+		end := start + chunk
+		if end > len(data) {
+			end = len(data)
+		}
+		e.SetBytes(data[start:end])
+		b := e.Bytes()
+		_, _ = h.Write(b[:]) // canonical field elements are always accepted
+	}
+	e.SetUint64(seed)
+	b := e.Bytes()
+	_, _ = h.Write(b[:])
+	if chunk != fr.Bytes {
+		e.SetInt64(-1)
+		b = e.Bytes()
+		_, _ = h.Write(b[:])
+	}
+	copy(digest[:], h.Sum(nil))
+	return digest
+}
+
+// canonicalChunks reports whether every 32 byte big-endian chunk of data is below the field modulus. A shorter last
+// chunk always is.
+func canonicalChunks(data []byte) bool {
+	var e fr.Element
+	for start := 0; start+fr.Bytes <= len(data); start += fr.Bytes {
+		if err := e.SetBytesCanonical(data[start : start+fr.Bytes]); err != nil {
+			return false
+		}
+	}
+	return true
+}
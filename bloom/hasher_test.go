@@ -0,0 +1,118 @@
+package bloom
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/hash/mimc"
+	"github.com/consensys/gnark/test"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"testing"
+)
+
+// mimcDigestCircuit asserts that Digest is the MiMCBN254 digest of the field element Token with Seed.
+type mimcDigestCircuit struct {
+	Token  frontend.Variable
+	Seed   frontend.Variable
+	Digest frontend.Variable `gnark:",public"`
+}
+
+// Define implements frontend.Circuit interface.
+func (c *mimcDigestCircuit) Define(api frontend.API) error {
+	h, err := mimc.NewMiMC(api)
+	if err != nil {
+		return err
+	}
+	h.Write(c.Token, c.Seed)
+	api.AssertIsEqual(h.Sum(), c.Digest)
+	return nil
+}
+
+func TestHasher_Keccak256(t *testing.T) {
+	data := []byte("revocation token")
+	digest := Keccak256.Sum(data, 0)
+	require.Equal(t, crypto.Keccak256(data), digest[:])
+
+	seeded := Keccak256.Sum(data, 3)
+	require.Equal(t, crypto.Keccak256(data, []byte{0, 0, 0, 0, 0, 0, 0, 3}), seeded[:])
+}
+
+func TestHasher_MiMCBN254(t *testing.T) {
+	var token fr.Element
+	_, err := token.SetRandom()
+	require.NoError(t, err)
+	tokenBytes := token.Bytes()
+
+	digest := MiMCBN254.Sum(tokenBytes[:], 5)
+	require.NotEqual(t, digest, MiMCBN254.Sum(tokenBytes[:], 6), "seeds must change the digest")
+
+	// The digest of a token that is a field element is computed by gnark's in-circuit MiMC.
+	assignment := &mimcDigestCircuit{Token: token.BigInt(new(big.Int)), Seed: 5, Digest: new(big.Int).SetBytes(digest[:])}
+	require.NoError(t, test.IsSolved(&mimcDigestCircuit{}, assignment, ecc.BN254.ScalarField()))
+
+	// A chunk that is not below the modulus does not collide with its reduction, nor with its own split.
+	unreduced := new(big.Int).Add(token.BigInt(new(big.Int)), fr.Modulus())
+	if unreduced.BitLen() <= 8*fr.Bytes {
+		require.NotEqual(t, digest, MiMCBN254.Sum(unreduced.FillBytes(make([]byte, fr.Bytes)), 5))
+	}
+	modulus := fr.Modulus().FillBytes(make([]byte, fr.Bytes))
+	split := make([]byte, 2*fr.Bytes) // the 31 byte chunks of the modulus as canonical elements
+	copy(split[1:fr.Bytes], modulus[:fr.Bytes-1])
+	split[2*fr.Bytes-1] = modulus[fr.Bytes-1]
+	require.NotEqual(t, MiMCBN254.Sum(modulus, 5), MiMCBN254.Sum(split, 5))
+	require.NotEqual(t, MiMCBN254.Sum(modulus, 5), MiMCBN254.Sum(make([]byte, fr.Bytes), 5))
+
+	h, err := HasherByID(MiMCBN254ID)
+	require.NoError(t, err)
+	require.Equal(t, MiMCBN254, h)
+	_, err = HasherByID(HasherID(42))
+	require.Error(t, err)
+}
+
+func TestHasher_Cascade(t *testing.T) {
+	domain := 10_000
+	capacity := 500
+
+	valid, revoked := genRevocationTokens(domain, capacity)
+	cascade := NewCascadeWithHasher(domain, capacity, MiMCBN254)
+	require.NoError(t, cascade.Update(revoked, valid))
+	require.Equal(t, MiMCBN254ID, cascade.Hasher().ID())
+	for _, f := range cascade.GetFilters() {
+		require.Equal(t, MiMCBN254ID, f.Hasher().ID())
+	}
+	for _, tok := range revoked {
+		ok, _ := cascade.Test(tok)
+		require.True(t, ok)
+	}
+	for _, tok := range valid {
+		ok, _ := cascade.Test(tok)
+		require.False(t, ok)
+	}
+
+	// The hasher is recorded in both encodings.
+	data, err := cascade.MarshalBinary()
+	require.NoError(t, err)
+	var decoded BloomFilterCascade
+	require.NoError(t, decoded.UnmarshalBinary(data))
+	require.True(t, cascade.Equal(&decoded))
+	require.Equal(t, MiMCBN254ID, decoded.Hasher().ID())
+
+	data, err = json.Marshal(cascade)
+	require.NoError(t, err)
+	var jsonDecoded BloomFilterCascade
+	require.NoError(t, json.Unmarshal(data, &jsonDecoded))
+	require.True(t, cascade.Equal(&jsonDecoded))
+
+	invalid := bytes.Replace(data, []byte(`"hasher":1,"layers"`), []byte(`"hasher":0,"layers"`), 1)
+	require.Error(t, json.Unmarshal(invalid, &jsonDecoded), "layers must use the cascade hasher")
+
+	keccak := NewCascade(domain, capacity)
+	require.NoError(t, keccak.Update(revoked, valid))
+	require.False(t, keccak.Equal(cascade))
+	_, err = Diff(keccak, cascade)
+	require.Error(t, err)
+}
@@ -0,0 +1,146 @@
+package bloom
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+)
+
+// LayerKind identifies the filter type of the layers of a cascade.
+type LayerKind uint8
+
+const (
+	// BloomLayers are Bloom filters (BloomFilter), tested on-chain by the CascadingBloomFilter contract. Only cascades
+	// of Bloom layers can be patched (Diff) and committed to for NonRevocationProof circuits.
+	BloomLayers LayerKind = iota
+	// BinaryFuseLayers are binary fuse filters (BinaryFuseFilter), tested on-chain by the CascadingBinaryFuseFilter
+	// contract. Cascades of 10^5 revocation tokens or more take 6-16% less space than with Bloom layers, see
+	// BenchmarkCascadeLayerKinds, at the cost of up to two more layers.
+	BinaryFuseLayers
+)
+
+// String returns the name of the layer kind.
+func (k LayerKind) String() string {
+	switch k {
+	case BloomLayers:
+		return "bloom"
+	case BinaryFuseLayers:
+		return "binary-fuse"
+	default:
+		return fmt.Sprintf("LayerKind(%d)", uint8(k))
+	}
+}
+
+// Layer is the filter of a cascade layer, implemented by *BloomFilter and *BinaryFuseFilter.
+type Layer interface {
+	// Kind returns the filter type of the layer.
+	Kind() LayerKind
+	// Test reports whether data matches the layer.
+	Test(data []byte) bool
+	// BitLen returns the number of bits of the layer's filter.
+	BitLen() uint
+	// Seed returns the hash seed of the layer.
+	Seed() uint64
+	// Hasher returns the hash function of the layer.
+	Hasher() Hasher
+	// WriteTo writes the filter without seed and hasher, which the cascade stores.
+	WriteTo(stream io.Writer) (int64, error)
+}
+
+// newLayer returns a layer of the given kind holding elements with a false positive rate of at most fprate, or the
+// rate of Bloom layers holding minCapacity elements if there are fewer.
+func newLayer(kind LayerKind, elements [][]byte, minCapacity int, fprate float64, seed uint64, h Hasher, workers int) (Layer, error) {
+	switch kind {
+	case BloomLayers:
+		m, k := getOptimalFilterParameters(int(max(uint(len(elements)), uint(minCapacity))), fprate)
+		f := NewBloomFilterWithHasher(m, k, seed, h)
+		addAll(f, elements, workers)
+		return f, nil
+	case BinaryFuseLayers:
+		return NewBinaryFuseFilter(elements, fuseFingerprintBits(fprate), seed, h)
+	default:
+		return nil, fmt.Errorf("unsupported layer kind %s", kind)
+	}
+}
+
+// fuseFingerprintBits returns the width of the fingerprints of a binary fuse filter with a false positive rate of at
+// most fprate.
+func fuseFingerprintBits(fprate float64) uint {
+	bits := math.Ceil(-math.Log2(fprate))
+	if bits < 1 || math.IsNaN(bits) {
+		return 1
+	}
+	if bits > 32 {
+		return 32
+	}
+	return uint(bits)
+}
+
+// readLayer reads a layer of the given kind as written by its WriteTo and sets its seed and hasher.
+func readLayer(kind LayerKind, stream io.Reader, seed uint64, h Hasher) (Layer, int64, error) {
+	switch kind {
+	case BloomLayers:
+		f := &BloomFilter{}
+		n, err := f.ReadFrom(stream)
+		f.seed, f.h = seed, h
+		return f, n, err
+	case BinaryFuseLayers:
+		f := &BinaryFuseFilter{}
+		n, err := f.ReadFrom(stream)
+		f.seed, f.h = seed, h
+		return f, n, err
+	default:
+		return nil, 0, fmt.Errorf("unsupported layer kind %s", kind)
+	}
+}
+
+// readWordsChunk is the number of words readWords allocates at a time.
+const readWordsChunk = 1 << 15
+
+// readWords reads n big-endian words. Memory is allocated as the words arrive, so a header announcing a large filter
+// cannot allocate more than the stream holds.
+func readWords(stream io.Reader, n uint64) ([]uint64, error) {
+	var words []uint64
+	for uint64(len(words)) < n {
+		chunk := make([]uint64, readWordsChunk)
+		if left := n - uint64(len(words)); left < readWordsChunk {
+			chunk = chunk[:left]
+		}
+		err := binary.Read(stream, binary.BigEndian, chunk)
+		if err != nil {
+			return nil, err
+		}
+		words = append(words, chunk...)
+	}
+	return words, nil
+}
+
+// unmarshalLayer decodes a layer of the given kind from its JSON encoding.
+func unmarshalLayer(kind LayerKind, data []byte) (Layer, error) {
+	var l Layer
+	switch kind {
+	case BloomLayers:
+		l = &BloomFilter{}
+	case BinaryFuseLayers:
+		l = &BinaryFuseFilter{}
+	default:
+		return nil, fmt.Errorf("unsupported layer kind %s", kind)
+	}
+	return l, json.Unmarshal(data, l)
+}
+
+// layersEqual tests two layers for equality of their kind and filter.
+func layersEqual(a, b Layer) bool {
+	switch a := a.(type) {
+	case *BloomFilter:
+		b, ok := b.(*BloomFilter)
+		return ok && a.Equal(b)
+	case *BinaryFuseFilter:
+		b, ok := b.(*BinaryFuseFilter)
+		return ok && a.Equal(b)
+	default:
+		return false
+	}
+}
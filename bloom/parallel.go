@@ -0,0 +1,154 @@
+package bloom
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// minShardSize is the smallest number of elements handed to a worker; smaller sets are processed sequentially.
+const minShardSize = 2048
+
+// UpdateParallel constructs the cascade like Update, spreading the insertion of elements and the false positive
+// tests of every layer across workers goroutines (runtime.NumCPU() if workers < 1). Bits are set atomically in the
+// shared layer, so the result is identical to Update on the same sets. Binary fuse layers are constructed
+// sequentially; only their false positive tests run concurrently. Like Update, a failed construction leaves the
+// cascade empty.
+func (c *BloomFilterCascade) UpdateParallel(positives, negatives [][]byte, workers int) error {
+	if workers < 1 {
+		workers = runtime.NumCPU()
+	}
+	err := c.updateParallel(positives, negatives, workers)
+	if err != nil {
+		c.reset()
+	}
+	return err
+}
+
+// updateParallel constructs the cascade for UpdateParallel, which resets it if an error leaves it partially
+// constructed.
+func (c *BloomFilterCascade) updateParallel(positives, negatives [][]byte, workers int) error {
+	c.reset()
+
+	if len(positives) > c.capacity {
+		return fmt.Errorf("bloom filter capacity exceeded")
+	}
+
+	// Layer 0: insert actual positives
+	err := c.setFirstLayer(positives, workers)
+	if err != nil {
+		return err
+	}
+
+	// Find false positives at layer 0
+	falsePositives := testAll(negatives, c.filters[0], workers)
+	if len(falsePositives) == 0 {
+		return nil
+	}
+
+	// Layer 1: insert false positives
+	err = c.addNextLayer(&falsePositives, c.params.SuccRate, workers)
+	if err != nil {
+		return err
+	}
+
+	nextFalsePositives := testAll(positives, c.filters[1], workers)
+	return c.addSucceedingLayers(falsePositives, nextFalsePositives, workers)
+}
+
+// shards splits n elements into at most workers contiguous ranges of at least minShardSize elements.
+func shards(n, workers int) [][2]int {
+	count := workers
+	if limit := (n + minShardSize - 1) / minShardSize; limit < count {
+		count = limit
+	}
+	if count < 1 {
+		count = 1
+	}
+	ranges := make([][2]int, count)
+	size := (n + count - 1) / count
+	for i := range ranges {
+		start := i * size
+		end := start + size
+		if start > n {
+			start = n
+		}
+		if end > n {
+			end = n
+		}
+		ranges[i] = [2]int{start, end}
+	}
+	return ranges
+}
+
+// addAll inserts elements into f, concurrently if more than one worker is given.
+func addAll(f *BloomFilter, elements [][]byte, workers int) {
+	ranges := shards(len(elements), workers)
+	// The bit set has to cover all locations for concurrent insertion, as it cannot grow atomically.
+	if len(ranges) == 1 || f.b.Len() < f.m {
+		for _, e := range elements {
+			f.Add(e)
+		}
+		return
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(len(ranges))
+	for _, r := range ranges {
+		go func(shard [][]byte) {
+			defer wg.Done()
+			for _, e := range shard {
+				f.addAtomic(e)
+			}
+		}(elements[r[0]:r[1]])
+	}
+	wg.Wait()
+}
+
+// testAll returns the elements matching f, in their original order. The tests run concurrently if more than one
+// worker is given.
+func testAll(elements [][]byte, f Layer, workers int) [][]byte {
+	ranges := shards(len(elements), workers)
+	if len(ranges) == 1 {
+		var matches [][]byte
+		for _, e := range elements {
+			if f.Test(e) {
+				matches = append(matches, e)
+			}
+		}
+		return matches
+	}
+
+	results := make([][][]byte, len(ranges))
+	var wg sync.WaitGroup
+	wg.Add(len(ranges))
+	for i, r := range ranges {
+		go func(i int, shard [][]byte) {
+			defer wg.Done()
+			for _, e := range shard {
+				if f.Test(e) {
+					results[i] = append(results[i], e)
+				}
+			}
+		}(i, elements[r[0]:r[1]])
+	}
+	wg.Wait()
+
+	var matches [][]byte
+	for _, shardMatches := range results {
+		matches = append(matches, shardMatches...)
+	}
+	return matches
+}
+
+// addAtomic adds data to the filter like Add, setting the bits atomically so that several goroutines may add to the
+// same filter. The bit set must already cover all m bits.
+func (f *BloomFilter) addAtomic(data []byte) {
+	words := f.b.Words()
+	h := baseHashes(f.h, data, f.seed)
+	for i := uint(0); i < f.k; i++ {
+		loc := f.location(h, i)
+		atomic.OrUint64(&words[loc/64], 1<<(loc%64))
+	}
+}
@@ -0,0 +1,312 @@
+package bloom
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// CascadeParams determines the false positive rates and minimum sizes of the layers of a cascade.
+type CascadeParams struct {
+	FirstRateFactor float64 `json:"firstRateFactor"` // FirstRateFactor scales the first layer's rate capacity/(domain-capacity).
+	SuccRate        float64 `json:"succRate"`        // SuccRate is the false positive rate of the layers from layer 1 on.
+	TailThreshold   int     `json:"tailThreshold"`   // TailThreshold is the number of elements up to which layers after layer 1 use TailRate.
+	TailRate        float64 `json:"tailRate"`        // TailRate is the false positive rate of small layers, which ensures termination.
+	MinCapacity     int     `json:"minCapacity"`     // MinCapacity is the number of elements Bloom layers after layer 0 are sized for at least.
+}
+
+// Presets of CascadeParams. The presets for an objective were found by OptimizeCascadeParams across domains of 10^4
+// to 10^6 credentials with 1-10% revoked; optimize for a specific domain and revocation count to do better.
+var (
+	// DefaultCascadeParams are the parameters of NewCascade, which approximately minimize the total size of Bloom
+	// layers.
+	DefaultCascadeParams = CascadeParams{FirstRateFactor: math.Sqrt(0.5), SuccRate: 0.5, TailThreshold: 200, TailRate: 0.1, MinCapacity: 100}
+	// MinBitsCascadeParams minimize the total size of the layers, at the cost of deeper cascades of small layers.
+	MinBitsCascadeParams = CascadeParams{FirstRateFactor: math.Exp2(-0.25), SuccRate: 0.5, TailThreshold: 50, TailRate: 0.1, MinCapacity: 1}
+	// MinLayersCascadeParams minimize the number of layers, and so the worst-case gas of testToken, with larger layers.
+	MinLayersCascadeParams = CascadeParams{FirstRateFactor: math.Exp2(-4), SuccRate: math.Exp2(-3.5), TailThreshold: 1000, TailRate: 0.001, MinCapacity: 1000}
+	// MinCalldataCascadeParams minimize the calldata of updateCascade, trading a few more bits for fewer layers.
+	MinCalldataCascadeParams = CascadeParams{FirstRateFactor: math.Exp2(-1.75), SuccRate: math.Exp2(-3.5), TailThreshold: 200, TailRate: 0.001, MinCapacity: 100}
+)
+
+// Validate checks that the rates are in (0, 1) and the sizes are positive.
+func (p CascadeParams) Validate() error {
+	if !(p.FirstRateFactor > 0) || math.IsInf(p.FirstRateFactor, 1) {
+		return fmt.Errorf("first rate factor %v is not positive", p.FirstRateFactor)
+	}
+	if !(p.SuccRate > 0 && p.SuccRate < 1) {
+		return fmt.Errorf("succeeding rate %v is not in (0, 1)", p.SuccRate)
+	}
+	if !(p.TailRate > 0 && p.TailRate < 1) {
+		return fmt.Errorf("tail rate %v is not in (0, 1)", p.TailRate)
+	}
+	if p.TailThreshold < 0 {
+		return fmt.Errorf("negative tail threshold %d", p.TailThreshold)
+	}
+	if p.MinCapacity < 1 {
+		return fmt.Errorf("minimum capacity %d is not positive", p.MinCapacity)
+	}
+	return nil
+}
+
+// legacyCascadeParams returns the parameters of cascades encoded before version 5, which only recorded the rate of
+// subsequent layers.
+func legacyCascadeParams(succRate float64) CascadeParams {
+	p := DefaultCascadeParams
+	p.SuccRate = succRate
+	return p
+}
+
+// cascadeParamsSize is the size of the parameters written by writeCascadeParams.
+const cascadeParamsSize = 4 * 8
+
+// writeCascadeParams writes the parameters but SuccRate, which precedes them in cascade and patch encodings.
+func writeCascadeParams(buf *bytes.Buffer, p CascadeParams) {
+	_ = binary.Write(buf, binary.BigEndian, math.Float64bits(p.FirstRateFactor))
+	_ = binary.Write(buf, binary.BigEndian, uint64(p.TailThreshold))
+	_ = binary.Write(buf, binary.BigEndian, math.Float64bits(p.TailRate))
+	_ = binary.Write(buf, binary.BigEndian, uint64(p.MinCapacity))
+}
+
+// readCascadeParams reads the parameters written by writeCascadeParams.
+func readCascadeParams(stream io.Reader, succRate float64) (CascadeParams, error) {
+	var firstRateFactor, tailThreshold, tailRate, minCapacity uint64
+	for _, v := range []any{&firstRateFactor, &tailThreshold, &tailRate, &minCapacity} {
+		err := binary.Read(stream, binary.BigEndian, v)
+		if err != nil {
+			return CascadeParams{}, err
+		}
+	}
+	return CascadeParams{
+		FirstRateFactor: math.Float64frombits(firstRateFactor),
+		SuccRate:        succRate,
+		TailThreshold:   int(tailThreshold),
+		TailRate:        math.Float64frombits(tailRate),
+		MinCapacity:     int(minCapacity),
+	}, nil
+}
+
+// firstRate returns the false positive rate of layer 0 of a cascade of capacity positives out of domain elements.
+func (p CascadeParams) firstRate(domain, capacity int) float64 {
+	return float64(capacity) * p.FirstRateFactor / float64(domain-capacity)
+}
+
+// layerRate returns the false positive rate of a layer after layer 1 holding the given number of elements.
+func (p CascadeParams) layerRate(elements int) float64 {
+	if elements > p.TailThreshold {
+		return p.SuccRate
+	}
+	return p.TailRate
+}
+
+// CascadeObjective is what OptimizeCascadeParams minimizes.
+type CascadeObjective uint8
+
+const (
+	// MinimizeBits minimizes the total size of the layers, i.e. the off-chain artifact.
+	MinimizeBits CascadeObjective = iota
+	// MinimizeLayers minimizes the worst-case gas of testToken, which is dominated by the number of layers.
+	MinimizeLayers
+	// MinimizeCalldata minimizes the calldata of updateCascade, i.e. the total size plus a per-layer overhead.
+	MinimizeCalldata
+)
+
+// String returns the name of the objective.
+func (o CascadeObjective) String() string {
+	switch o {
+	case MinimizeBits:
+		return "bits"
+	case MinimizeLayers:
+		return "layers"
+	case MinimizeCalldata:
+		return "calldata"
+	default:
+		return fmt.Sprintf("CascadeObjective(%d)", uint8(o))
+	}
+}
+
+// EVM gas costs of PredictCascade (Berlin, EIP-2929).
+const (
+	txGas           = 21_000 // txGas is the base cost of a transaction.
+	calldataByteGas = 16     // calldataByteGas is the cost of a non-zero byte of calldata.
+	calldataZeroGas = 4      // calldataZeroGas is the cost of a zero byte of calldata.
+	coldStoreGas    = 22_100 // coldStoreGas is the cost of writing a cold, empty storage slot.
+	coldLoadGas     = 2_100  // coldLoadGas is the cost of reading a cold storage slot.
+)
+
+// CascadePrediction is the expected size and on-chain cost of a cascade.
+type CascadePrediction struct {
+	Layers        int    // Layers is the number of layers.
+	Bits          uint   // Bits is the total size of the layers' filters.
+	CalldataBytes int    // CalldataBytes is the size of the calldata of updateCascade.
+	UpdateGas     uint64 // UpdateGas is the gas of updateCascade on empty storage.
+	TestTokenGas  uint64 // TestTokenGas is the gas of measureTestTokenGas for a token that reaches the last layer.
+}
+
+// PredictCascade predicts the expected cascade of capacity revoked tokens out of domain tokens with the given layers
+// and parameters from the expected number of false positives of each layer, using the same layer sizes as the
+// cascade. Small layers count with the probability that they hold any false positive.
+// Gas counts the transaction, its calldata and the storage slots written or read by the CascadingBloomFilter or
+// CascadingBinaryFuseFilter contract, but not the execution of their code.
+func PredictCascade(domain, capacity int, kind LayerKind, params CascadeParams) (CascadePrediction, error) {
+	if err := params.Validate(); err != nil {
+		return CascadePrediction{}, err
+	}
+	if kind != BloomLayers && kind != BinaryFuseLayers {
+		return CascadePrediction{}, fmt.Errorf("unsupported layer kind %s", kind)
+	}
+	if capacity < 1 || capacity >= domain {
+		return CascadePrediction{}, fmt.Errorf("capacity %d is not in [1, %d)", capacity, domain)
+	}
+	firstRate := params.firstRate(domain, capacity)
+	if firstRate >= 1 {
+		return CascadePrediction{}, fmt.Errorf("first layer rate %v is not below 1", firstRate)
+	}
+
+	// Layer i holds the false positives of layer i-1 among the elements of layer i-2, the positives for layer 1.
+	// Small layers hold few false positives, so whether they exist at all is random: a layer expecting λ elements
+	// exists with probability 1-exp(-λ) and then holds λ/(1-exp(-λ)) elements on average. Every layer counts with
+	// the probability of its existence.
+	var pred CascadePrediction
+	var layers, bits, dataBytes, padBytes, storageSlots, testTokenGas float64
+	prevElements, elements := float64(domain-capacity), float64(capacity)
+	for layer := 0; elements >= minExpectedElements; layer++ {
+		if layer > 100 {
+			return CascadePrediction{}, errors.New("over 100 layers")
+		}
+		exists := 1.0
+		if layer > 0 {
+			exists = -math.Expm1(-elements)
+		}
+		n := int(math.Max(1, math.Round(elements/exists)))
+
+		var layerBits uint
+		var rate float64
+		var probes int
+		switch {
+		case kind == BinaryFuseLayers:
+			fprate := params.SuccRate
+			if layer == 0 {
+				fprate = firstRate
+			} else if layer > 1 {
+				fprate = params.layerRate(n)
+			}
+			segmentLength, segmentCount := binaryFuseParameters(n)
+			f := fuseFingerprintBits(fprate)
+			layerBits = uint(segmentCount+2) * uint(segmentLength) * f
+			rate = math.Exp2(-float64(f))
+			probes = 3
+		case layer == 0:
+			m, k := getOptimalFilterParameters(capacity, firstRate)
+			layerBits, rate, probes = m, bloomFalsePositiveRate(m, k, n), int(k)
+		default:
+			fprate := params.SuccRate
+			if layer > 1 {
+				fprate = params.layerRate(n)
+			}
+			m, k := getOptimalFilterParameters(int(max(uint(n), uint(params.MinCapacity))), fprate)
+			layerBits, rate, probes = m, bloomFalsePositiveRate(m, k, n), int(k)
+		}
+
+		layerBytes := 8 * int((layerBits+63)/64)
+		layers += exists
+		bits += exists * float64(layerBits)
+		dataBytes += exists * float64(layerBytes)
+		padBytes += exists * float64((32-layerBytes%32)%32)
+		// The layer's metadata and the length of its filter, and the filter's words.
+		storageSlots += exists * float64(2+(layerBytes+31)/32)
+		testTokenGas += exists * float64(2+probes) * coldLoadGas
+
+		prevElements, elements = elements, prevElements*rate
+	}
+	pred.Layers = int(math.Round(layers))
+	pred.Bits = uint(math.Round(bits))
+
+	// updateCascade(bytes[], uint256[]...) has one array of filters and 3 (Bloom) or 4 (fuse) arrays of metadata.
+	metadataArrays := 3
+	if kind == BinaryFuseLayers {
+		metadataArrays = 4
+	}
+	words := 1 + metadataArrays + // heads
+		1 + 2*pred.Layers + // filters: length, offsets and lengths
+		metadataArrays*(1+pred.Layers) // metadata: lengths and values
+	zeroBytes := float64(32*words) + padBytes
+	pred.CalldataBytes = int(math.Round(4 + dataBytes + zeroBytes))
+	pred.UpdateGas = txGas + uint64(math.Round((4+dataBytes)*calldataByteGas+zeroBytes*calldataZeroGas+(1+storageSlots)*coldStoreGas))
+	// measureTestTokenGas(bytes) of a 16-byte token, and the length of the layer array.
+	pred.TestTokenGas = txGas + (4+16)*calldataByteGas + (3*32-16)*calldataZeroGas + coldLoadGas + uint64(math.Round(testTokenGas))
+	return pred, nil
+}
+
+// minExpectedElements is the expected number of elements below which PredictCascade neglects further layers.
+const minExpectedElements = 1e-3
+
+// bloomFalsePositiveRate returns the expected false positive rate of a Bloom filter of m bits and k hash functions
+// holding n elements.
+func bloomFalsePositiveRate(m, k uint, n int) float64 {
+	return math.Pow(1-math.Exp(-float64(k)*float64(n)/float64(m)), float64(k))
+}
+
+// OptimizeCascadeParams searches the parameters that minimize the objective for a cascade of capacity revoked tokens
+// out of domain tokens, as predicted by PredictCascade, and returns them with their prediction. Ties are broken by
+// the total size for MinimizeLayers and by testToken gas otherwise.
+func OptimizeCascadeParams(domain, capacity int, kind LayerKind, objective CascadeObjective) (CascadeParams, CascadePrediction, error) {
+	var key func(p CascadePrediction) [2]uint64
+	switch objective {
+	case MinimizeBits:
+		key = func(p CascadePrediction) [2]uint64 { return [2]uint64{uint64(p.Bits), p.TestTokenGas} }
+	case MinimizeLayers:
+		key = func(p CascadePrediction) [2]uint64 { return [2]uint64{p.TestTokenGas, uint64(p.Bits)} }
+	case MinimizeCalldata:
+		key = func(p CascadePrediction) [2]uint64 { return [2]uint64{uint64(p.CalldataBytes), p.TestTokenGas} }
+	default:
+		return CascadeParams{}, CascadePrediction{}, fmt.Errorf("unsupported objective %s", objective)
+	}
+	// Binary fuse layers are sized by their elements only.
+	minCapacities := []int{1, 10, 100, 1000}
+	if kind == BinaryFuseLayers {
+		minCapacities = []int{DefaultCascadeParams.MinCapacity}
+	}
+
+	var best CascadeParams
+	var bestPred CascadePrediction
+	var bestKey [2]uint64
+	found := false
+	var lastErr error
+	for i := -16; i <= 8; i++ {
+		for j := 1; j <= 12; j++ {
+			for _, tailThreshold := range []int{0, 50, 200, 1000} {
+				for _, tailRate := range []float64{0.5, 0.1, 0.01, 0.001} {
+					for _, minCapacity := range minCapacities {
+						params := CascadeParams{
+							FirstRateFactor: math.Exp2(float64(i) / 4),
+							SuccRate:        math.Exp2(-float64(j) / 2),
+							TailThreshold:   tailThreshold,
+							TailRate:        tailRate,
+							MinCapacity:     minCapacity,
+						}
+						pred, err := PredictCascade(domain, capacity, kind, params)
+						if err != nil {
+							lastErr = err
+							continue
+						}
+						if k := key(pred); !found || k[0] < bestKey[0] || k[0] == bestKey[0] && k[1] < bestKey[1] {
+							best, bestPred, bestKey, found = params, pred, k, true
+						}
+					}
+					// The tail rate is unused without a tail.
+					if tailThreshold == 0 {
+						break
+					}
+				}
+			}
+		}
+	}
+	if !found {
+		return CascadeParams{}, CascadePrediction{}, lastErr
+	}
+	return best, bestPred, nil
+}
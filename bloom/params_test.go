@@ -0,0 +1,173 @@
+package bloom
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/stretchr/testify/require"
+	"math"
+	"testing"
+)
+
+var cascadePresets = map[string]CascadeParams{
+	"default":  DefaultCascadeParams,
+	"bits":     MinBitsCascadeParams,
+	"layers":   MinLayersCascadeParams,
+	"calldata": MinCalldataCascadeParams,
+}
+
+func TestCascadeParams_Validate(t *testing.T) {
+	for name, p := range cascadePresets {
+		require.NoError(t, p.Validate(), name)
+	}
+	for _, modify := range []func(p *CascadeParams){
+		func(p *CascadeParams) { p.FirstRateFactor = 0 },
+		func(p *CascadeParams) { p.FirstRateFactor = math.Inf(1) },
+		func(p *CascadeParams) { p.SuccRate = 1 },
+		func(p *CascadeParams) { p.SuccRate = math.NaN() },
+		func(p *CascadeParams) { p.TailRate = 0 },
+		func(p *CascadeParams) { p.TailThreshold = -1 },
+		func(p *CascadeParams) { p.MinCapacity = 0 },
+	} {
+		p := DefaultCascadeParams
+		modify(&p)
+		require.Error(t, p.Validate())
+		_, err := NewCascadeWithParams(10_000, 100, BloomLayers, Keccak256, p)
+		require.Error(t, err)
+	}
+}
+
+func TestCascade_Params(t *testing.T) {
+	domain := 10_000
+	capacity := 1_000
+	valid, revoked := genRevocationTokens(domain, capacity)
+
+	// The default parameters build the cascade of NewCascade.
+	cascade := NewCascade(domain, capacity)
+	require.NoError(t, cascade.Update(revoked, valid))
+	require.Equal(t, DefaultCascadeParams, cascade.Params())
+	withParams, err := NewCascadeWithParams(domain, capacity, BloomLayers, Keccak256, DefaultCascadeParams)
+	require.NoError(t, err)
+	require.NoError(t, withParams.Update(revoked, valid))
+	require.True(t, cascade.Equal(withParams))
+
+	for name, p := range cascadePresets {
+		for _, kind := range []LayerKind{BloomLayers, BinaryFuseLayers} {
+			c, err := NewCascadeWithParams(domain, capacity, kind, Keccak256, p)
+			require.NoError(t, err)
+			require.NoError(t, c.Update(revoked, valid))
+			for _, tok := range valid {
+				ok, _ := c.Test(tok)
+				require.False(t, ok, "%s, %s", name, kind)
+			}
+			for _, tok := range revoked {
+				ok, _ := c.Test(tok)
+				require.True(t, ok, "%s, %s", name, kind)
+			}
+
+			// The parameters are part of both encodings.
+			data, err := c.MarshalBinary()
+			require.NoError(t, err)
+			var decoded BloomFilterCascade
+			require.NoError(t, decoded.UnmarshalBinary(data))
+			require.True(t, c.Equal(&decoded))
+			require.Equal(t, p, decoded.Params())
+			data, err = json.Marshal(c)
+			require.NoError(t, err)
+			var fromJSON BloomFilterCascade
+			require.NoError(t, json.Unmarshal(data, &fromJSON))
+			require.True(t, c.Equal(&fromJSON))
+			if p != DefaultCascadeParams {
+				require.False(t, c.Equal(cascade))
+			}
+		}
+	}
+
+	// Version 4 encodings predate the parameters and decode to the defaults.
+	data, err := json.Marshal(cascade)
+	require.NoError(t, err)
+	var legacy map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(data, &legacy))
+	delete(legacy, "params")
+	legacy["version"] = json.RawMessage("4")
+	data, err = json.Marshal(legacy)
+	require.NoError(t, err)
+	var decoded BloomFilterCascade
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.True(t, cascade.Equal(&decoded))
+	require.Equal(t, DefaultCascadeParams, decoded.Params())
+}
+
+func TestPredictCascade(t *testing.T) {
+	// The prediction is the expected cascade. Whether small layers exist depends on the tokens, which changes the size
+	// of a single cascade of a small domain by up to a tail layer, so it is compared to the mean of several cascades.
+	for _, cfg := range []struct{ domain, capacity, builds int }{{10_000, 1_000, 20}, {100_000, 5_000, 2}} {
+		tokens := make([][2][][]byte, cfg.builds)
+		for b := range tokens {
+			tokens[b][0], tokens[b][1] = genRevocationTokens(cfg.domain, cfg.capacity)
+		}
+		for name, p := range cascadePresets {
+			for _, kind := range []LayerKind{BloomLayers, BinaryFuseLayers} {
+				pred, err := PredictCascade(cfg.domain, cfg.capacity, kind, p)
+				require.NoError(t, err)
+				var layers, bits float64
+				for _, tok := range tokens {
+					c, err := NewCascadeWithParams(cfg.domain, cfg.capacity, kind, Keccak256, p)
+					require.NoError(t, err)
+					require.NoError(t, c.Update(tok[1], tok[0]))
+					layers += float64(len(c.Layers())) / float64(cfg.builds)
+					bits += float64(totalBits(c)) / float64(cfg.builds)
+				}
+
+				msg := fmt.Sprintf("D%d C%d %s %s", cfg.domain, cfg.capacity, name, kind)
+				require.InDelta(t, layers, pred.Layers, 3, msg)
+				require.InEpsilon(t, bits, pred.Bits, 0.05, msg)
+				require.Greater(t, pred.CalldataBytes, int(pred.Bits/8), msg)
+				require.Greater(t, pred.UpdateGas, pred.TestTokenGas, msg)
+			}
+		}
+	}
+
+	_, err := PredictCascade(10_000, 10_000, BloomLayers, DefaultCascadeParams)
+	require.Error(t, err)
+	_, err = PredictCascade(10_000, 0, BloomLayers, DefaultCascadeParams)
+	require.Error(t, err)
+	_, err = PredictCascade(10_000, 100, LayerKind(2), DefaultCascadeParams)
+	require.Error(t, err)
+	_, err = PredictCascade(10_000, 100, BloomLayers, CascadeParams{})
+	require.Error(t, err)
+	// The first layer would accept everything.
+	p := DefaultCascadeParams
+	p.FirstRateFactor = 1_000
+	_, err = PredictCascade(10_000, 100, BloomLayers, p)
+	require.Error(t, err)
+}
+
+func TestOptimizeCascadeParams(t *testing.T) {
+	domain := 100_000
+	capacity := 5_000
+	objectives := map[CascadeObjective]func(p CascadePrediction) uint64{
+		MinimizeBits:     func(p CascadePrediction) uint64 { return uint64(p.Bits) },
+		MinimizeLayers:   func(p CascadePrediction) uint64 { return p.TestTokenGas },
+		MinimizeCalldata: func(p CascadePrediction) uint64 { return uint64(p.CalldataBytes) },
+	}
+	for _, kind := range []LayerKind{BloomLayers, BinaryFuseLayers} {
+		for objective, cost := range objectives {
+			params, pred, err := OptimizeCascadeParams(domain, capacity, kind, objective)
+			require.NoError(t, err)
+			require.NoError(t, params.Validate())
+			expected, err := PredictCascade(domain, capacity, kind, params)
+			require.NoError(t, err)
+			require.Equal(t, expected, pred)
+			for name, p := range cascadePresets {
+				presetPred, err := PredictCascade(domain, capacity, kind, p)
+				require.NoError(t, err)
+				require.LessOrEqual(t, cost(pred), cost(presetPred), "%s: %s beats the optimum for %s", kind, name, objective)
+			}
+		}
+	}
+
+	_, _, err := OptimizeCascadeParams(domain, capacity, BloomLayers, CascadeObjective(3))
+	require.Error(t, err)
+	_, _, err = OptimizeCascadeParams(domain, domain, BloomLayers, MinimizeBits)
+	require.Error(t, err)
+}
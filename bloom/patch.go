@@ -0,0 +1,352 @@
+package bloom
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+)
+
+// PatchWordSize is the granularity of a CascadePatch in bytes. It matches one EVM storage slot, so the gas cost of
+// publishing a patch on-chain is proportional to the number of changed words.
+const PatchWordSize = 32
+
+// patchMagic prefixes every binary encoded CascadePatch.
+var patchMagic = [4]byte{'U', 'P', 'B', 'P'}
+
+// PatchFormatVersion is the version of the serialized patch format written by CascadePatch.WriteTo.
+// Version 2 added the hash seed of each patched layer and version 3 the CascadeParams; older encodings are read with
+// unseeded layers and DefaultCascadeParams respectively.
+const PatchFormatVersion uint16 = 3
+
+// LayerPatch describes the changes of a single cascade layer.
+// Words are taken from the on-chain representation of the layer (see GetOnChainFilter), zero padded to a multiple
+// of PatchWordSize. If the size of a layer changes, the layer is cleared first and all its non-zero words are listed.
+type LayerPatch struct {
+	Layer   uint32                // Layer is the index of the patched layer.
+	K       uint32                // K is the number of hash functions of the layer after patching.
+	BitLen  uint64                // BitLen is the number of bits of the layer after patching.
+	Seed    uint64                // Seed is the hash seed of the layer after patching.
+	Indices []uint32              // Indices holds the indices of the changed words in ascending order.
+	Words   [][PatchWordSize]byte // Words holds the new content of the changed words.
+}
+
+// CascadePatch transforms the cascade of one epoch into the cascade of another epoch.
+// It is computed by Diff and applied by BloomFilterCascade.ApplyPatch or the on-chain patchCascade method.
+type CascadePatch struct {
+	FromEpoch    int64         // FromEpoch is the epoch of the cascade the patch applies to.
+	ToEpoch      int64         // ToEpoch is the epoch of the cascade after patching.
+	LayerCount   uint32        // LayerCount is the number of layers after patching.
+	Capacity     int           // Capacity is the capacity of the cascade after patching.
+	FalsePosRate float64       // FalsePosRate is the first layer false positive rate after patching.
+	Params       CascadeParams // Params are the parameters of subsequent layers after patching.
+	Layers       []LayerPatch  // Layers holds the patches of all layers whose parameters or words changed.
+}
+
+// Diff computes the patch that transforms the cascade from into the cascade to.
+// Both cascades must have been published by the same issuer and consist of Bloom layers: the slots of a binary fuse
+// filter depend on all of its elements, so its layers change almost entirely from one epoch to the next.
+func Diff(from, to *BloomFilterCascade) (*CascadePatch, error) {
+	if !bytes.Equal(from.issuerID, to.issuerID) {
+		return nil, errors.New("cascades were published by different issuers")
+	}
+	if from.Hasher().ID() != to.Hasher().ID() {
+		return nil, errors.New("cascades use different hashers")
+	}
+	if from.layerKind != BloomLayers || to.layerKind != BloomLayers {
+		return nil, fmt.Errorf("patches need %s layers", BloomLayers)
+	}
+	fromFilters, toFilters := from.GetFilters(), to.GetFilters()
+
+	p := &CascadePatch{
+		FromEpoch:    from.epoch,
+		ToEpoch:      to.epoch,
+		LayerCount:   uint32(len(to.filters)),
+		Capacity:     to.capacity,
+		FalsePosRate: to.falsePosRate,
+		Params:       to.params,
+	}
+
+	for i, f := range toFilters {
+		newBytes := onChainBytes(f)
+
+		var oldBytes []byte
+		if i < len(fromFilters) {
+			oldBytes = onChainBytes(fromFilters[i])
+		}
+		resized := len(oldBytes) != len(newBytes)
+		if resized {
+			oldBytes = make([]byte, len(newBytes)) // the layer is cleared before patching
+		}
+
+		lp := LayerPatch{Layer: uint32(i), K: uint32(f.K()), BitLen: uint64(f.BitLen()), Seed: f.Seed()}
+		for w := 0; w*PatchWordSize < len(newBytes); w++ {
+			newWord := patchWord(newBytes, w)
+			if newWord != patchWord(oldBytes, w) {
+				lp.Indices = append(lp.Indices, uint32(w))
+				lp.Words = append(lp.Words, newWord)
+			}
+		}
+
+		// New layers are always resized, so fromFilters[i] exists when it is accessed.
+		paramsChanged := resized || fromFilters[i].K() != f.K() || fromFilters[i].BitLen() != f.BitLen() ||
+			fromFilters[i].Seed() != f.Seed()
+		if paramsChanged || len(lp.Indices) > 0 {
+			p.Layers = append(p.Layers, lp)
+		}
+	}
+	return p, nil
+}
+
+// patchWord returns the w-th PatchWordSize word of b, zero padded at the end of b.
+func patchWord(b []byte, w int) (word [PatchWordSize]byte) {
+	end := (w + 1) * PatchWordSize
+	if end > len(b) {
+		end = len(b)
+	}
+	copy(word[:], b[w*PatchWordSize:end])
+	return word
+}
+
+// ApplyPatch applies a patch computed by Diff to the cascade.
+// The cascade must be at the patch's FromEpoch. On error, the cascade is left unchanged.
+func (c *BloomFilterCascade) ApplyPatch(p *CascadePatch) error {
+	if c.epoch != p.FromEpoch {
+		return fmt.Errorf("patch applies to epoch %d, cascade is at epoch %d", p.FromEpoch, c.epoch)
+	}
+	if p.LayerCount == 0 {
+		return errors.New("patch leaves no layers")
+	}
+	if p.LayerCount > maxCascadeLayers {
+		return fmt.Errorf("%d layers exceed %d", p.LayerCount, maxCascadeLayers)
+	}
+	if c.layerKind != BloomLayers {
+		return fmt.Errorf("patches need %s layers", BloomLayers)
+	}
+	err := p.Params.Validate()
+	if err != nil {
+		return err
+	}
+
+	filters := make([]*BloomFilter, p.LayerCount)
+	copy(filters, c.GetFilters())
+	for _, lp := range p.Layers {
+		if lp.Layer >= p.LayerCount {
+			return fmt.Errorf("patched layer %d out of range", lp.Layer)
+		}
+		if lp.K == 0 || lp.BitLen == 0 || lp.BitLen > maxBloomFilterBits {
+			return fmt.Errorf("layer %d: invalid parameters", lp.Layer)
+		}
+		if len(lp.Indices) != len(lp.Words) {
+			return fmt.Errorf("layer %d: %d indices for %d words", lp.Layer, len(lp.Indices), len(lp.Words))
+		}
+
+		m := uint(lp.BitLen)
+		layerBytes := make([]byte, patchLayerByteLen(lp.BitLen))
+		// Like on-chain, a layer is only cleared if the length of its representation changes.
+		if f := filters[lp.Layer]; f != nil {
+			if oldBytes := onChainBytes(f); len(oldBytes) == len(layerBytes) {
+				copy(layerBytes, oldBytes)
+			}
+		}
+		for j, w := range lp.Indices {
+			start := int(w) * PatchWordSize
+			if start >= len(layerBytes) {
+				return fmt.Errorf("layer %d: word %d out of range", lp.Layer, w)
+			}
+			copy(layerBytes[start:], lp.Words[j][:])
+		}
+		filters[lp.Layer] = filterFromOnChainBytes(layerBytes, m, uint(lp.K), lp.Seed, c.hasher)
+	}
+	layers := make([]Layer, len(filters))
+	for i, f := range filters {
+		if f == nil {
+			return fmt.Errorf("patch does not provide new layer %d", i)
+		}
+		layers[i] = f
+	}
+
+	c.filters = layers
+	c.epoch = p.ToEpoch
+	c.capacity = p.Capacity
+	c.falsePosRate = p.FalsePosRate
+	c.params = p.Params
+	return nil
+}
+
+// patchLayerByteLen returns the length of the on-chain representation of a layer with bitLen bits.
+func patchLayerByteLen(bitLen uint64) int {
+	return int((bitLen+63)/64) * 8
+}
+
+// ChangedWords returns the total number of words written by the patch.
+func (p *CascadePatch) ChangedWords() int {
+	n := 0
+	for _, lp := range p.Layers {
+		n += len(lp.Indices)
+	}
+	return n
+}
+
+// GetOnChainPatch returns the arguments of the on-chain patchCascade call.
+// Word indices and words of all patched layers are flattened; wordCounts[i] is the number of words of layers[i].
+func (p *CascadePatch) GetOnChainPatch() (layerCount *big.Int, layers, ks, bitLens, seeds, wordCounts, wordIndices []*big.Int, words [][32]byte) {
+	layerCount = big.NewInt(int64(p.LayerCount))
+	for _, lp := range p.Layers {
+		layers = append(layers, big.NewInt(int64(lp.Layer)))
+		ks = append(ks, big.NewInt(int64(lp.K)))
+		bitLens = append(bitLens, new(big.Int).SetUint64(lp.BitLen))
+		seeds = append(seeds, new(big.Int).SetUint64(lp.Seed))
+		wordCounts = append(wordCounts, big.NewInt(int64(len(lp.Indices))))
+		for j, w := range lp.Indices {
+			wordIndices = append(wordIndices, big.NewInt(int64(w)))
+			words = append(words, lp.Words[j])
+		}
+	}
+	return layerCount, layers, ks, bitLens, seeds, wordCounts, wordIndices, words
+}
+
+// WriteTo writes a compact binary representation of the patch to an i/o stream.
+// It returns the number of bytes written.
+func (p *CascadePatch) WriteTo(stream io.Writer) (int64, error) {
+	var buf bytes.Buffer
+	buf.Write(patchMagic[:])
+	_ = binary.Write(&buf, binary.BigEndian, PatchFormatVersion)
+	_ = binary.Write(&buf, binary.BigEndian, p.FromEpoch)
+	_ = binary.Write(&buf, binary.BigEndian, p.ToEpoch)
+	_ = binary.Write(&buf, binary.BigEndian, p.LayerCount)
+	_ = binary.Write(&buf, binary.BigEndian, uint64(p.Capacity))
+	_ = binary.Write(&buf, binary.BigEndian, math.Float64bits(p.FalsePosRate))
+	_ = binary.Write(&buf, binary.BigEndian, math.Float64bits(p.Params.SuccRate))
+	writeCascadeParams(&buf, p.Params)
+	_ = binary.Write(&buf, binary.BigEndian, uint32(len(p.Layers)))
+	for _, lp := range p.Layers {
+		if len(lp.Indices) != len(lp.Words) {
+			return 0, fmt.Errorf("layer %d: %d indices for %d words", lp.Layer, len(lp.Indices), len(lp.Words))
+		}
+		_ = binary.Write(&buf, binary.BigEndian, lp.Layer)
+		_ = binary.Write(&buf, binary.BigEndian, lp.K)
+		_ = binary.Write(&buf, binary.BigEndian, lp.BitLen)
+		_ = binary.Write(&buf, binary.BigEndian, lp.Seed)
+		_ = binary.Write(&buf, binary.BigEndian, uint32(len(lp.Indices)))
+		for j, w := range lp.Indices {
+			_ = binary.Write(&buf, binary.BigEndian, w)
+			buf.Write(lp.Words[j][:])
+		}
+	}
+
+	n, err := stream.Write(buf.Bytes())
+	return int64(n), err
+}
+
+// ReadFrom reads a binary representation of the patch (such as might have been written by WriteTo()) from an
+// i/o stream. It returns the number of bytes read.
+func (p *CascadePatch) ReadFrom(stream io.Reader) (int64, error) {
+	r := &countingReader{r: stream}
+
+	var magic [4]byte
+	_, err := io.ReadFull(r, magic[:])
+	if err != nil {
+		return r.n, err
+	}
+	if magic != patchMagic {
+		return r.n, errors.New("not a cascade patch encoding")
+	}
+
+	var version uint16
+	err = binary.Read(r, binary.BigEndian, &version)
+	if err != nil {
+		return r.n, err
+	}
+	if version < 1 || version > PatchFormatVersion {
+		return r.n, fmt.Errorf("unsupported patch format version %d", version)
+	}
+
+	var capacity, fpBits, fpSuccBits uint64
+	var layerPatches uint32
+	var q CascadePatch
+	for _, v := range []any{&q.FromEpoch, &q.ToEpoch, &q.LayerCount, &capacity, &fpBits, &fpSuccBits} {
+		err = binary.Read(r, binary.BigEndian, v)
+		if err != nil {
+			return r.n, err
+		}
+	}
+	q.Capacity = int(capacity)
+	q.FalsePosRate = math.Float64frombits(fpBits)
+	q.Params = legacyCascadeParams(math.Float64frombits(fpSuccBits))
+	if version > 2 {
+		q.Params, err = readCascadeParams(r, q.Params.SuccRate)
+		if err != nil {
+			return r.n, err
+		}
+	}
+	err = binary.Read(r, binary.BigEndian, &layerPatches)
+	if err != nil {
+		return r.n, err
+	}
+
+	for i := uint32(0); i < layerPatches; i++ {
+		var lp LayerPatch
+		var count uint32
+		fields := []any{&lp.Layer, &lp.K, &lp.BitLen, &lp.Seed, &count}
+		if version == 1 {
+			fields = []any{&lp.Layer, &lp.K, &lp.BitLen, &count}
+		}
+		for _, v := range fields {
+			err = binary.Read(r, binary.BigEndian, v)
+			if err != nil {
+				return r.n, err
+			}
+		}
+		for j := uint32(0); j < count; j++ {
+			var w uint32
+			var word [PatchWordSize]byte
+			err = binary.Read(r, binary.BigEndian, &w)
+			if err != nil {
+				return r.n, err
+			}
+			_, err = io.ReadFull(r, word[:])
+			if err != nil {
+				return r.n, err
+			}
+			lp.Indices = append(lp.Indices, w)
+			lp.Words = append(lp.Words, word)
+		}
+		q.Layers = append(q.Layers, lp)
+	}
+
+	*p = q
+	return r.n, nil
+}
+
+// MarshalBinary implements binary.BinaryMarshaler interface.
+func (p *CascadePatch) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	_, err := p.WriteTo(&buf)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary implements binary.BinaryUnmarshaler interface.
+func (p *CascadePatch) UnmarshalBinary(data []byte) error {
+	_, err := p.ReadFrom(bytes.NewReader(data))
+	return err
+}
+
+// countingReader counts the bytes read from the underlying reader.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+// Read implements io.Reader interface.
+func (c *countingReader) Read(b []byte) (int, error) {
+	n, err := c.r.Read(b)
+	c.n += int64(n)
+	return n, err
+}
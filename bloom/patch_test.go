@@ -0,0 +1,180 @@
+package bloom
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+// genEpochCascades builds the cascades of two consecutive epochs in which additional tokens got revoked.
+func genEpochCascades(t *testing.T, domain, capacity, newlyRevoked int) (from, to *BloomFilterCascade, valid, revoked [][]byte) {
+	valid, revoked = genRevocationTokens(domain, capacity-newlyRevoked)
+
+	from = NewCascade(domain, capacity)
+	require.NoError(t, from.Update(revoked, valid))
+	from.SetEpoch(86400)
+	from.SetIssuerID([]byte("issuer"))
+
+	revoked = append(revoked, valid[:newlyRevoked]...)
+	valid = valid[newlyRevoked:]
+
+	to = NewCascade(domain, capacity)
+	require.NoError(t, to.Update(revoked, valid))
+	to.SetEpoch(2 * 86400)
+	to.SetIssuerID([]byte("issuer"))
+	return from, to, valid, revoked
+}
+
+func TestPatch_DiffAndApply(t *testing.T) {
+	from, to, valid, revoked := genEpochCascades(t, 100_000, 10_000, 10)
+
+	patch, err := Diff(from, to)
+	require.NoError(t, err)
+	require.Equal(t, int64(86400), patch.FromEpoch)
+	require.Equal(t, int64(2*86400), patch.ToEpoch)
+
+	// Each new revocation sets at most k bits, so few revocations only touch a fraction of the first layer.
+	layer0 := to.GetFilters()[0]
+	layer0Words := (len(onChainBytes(layer0)) + PatchWordSize - 1) / PatchWordSize
+	require.Equal(t, uint32(0), patch.Layers[0].Layer)
+	require.LessOrEqual(t, len(patch.Layers[0].Indices), 10*int(layer0.K()), "more words changed than bits set")
+	require.Less(t, 10*int(layer0.K()), layer0Words/4)
+
+	require.NoError(t, from.ApplyPatch(patch))
+	require.True(t, from.Equal(to))
+
+	for _, tok := range revoked {
+		res, _ := from.Test(tok)
+		require.True(t, res)
+	}
+	for _, tok := range valid {
+		res, _ := from.Test(tok)
+		require.False(t, res)
+	}
+}
+
+func TestPatch_LayerCountChange(t *testing.T) {
+	valid, revoked := genRevocationTokens(10_000, 100)
+
+	single := NewCascade(10_000, 100)
+	require.NoError(t, single.Update(revoked[:1], nil))
+	full := NewCascade(10_000, 100)
+	require.NoError(t, full.Update(revoked, valid))
+	require.Greater(t, len(full.filters), 1)
+
+	// Grow the cascade.
+	patch, err := Diff(single, full)
+	require.NoError(t, err)
+	grown := NewCascade(10_000, 100)
+	require.NoError(t, grown.Update(revoked[:1], nil))
+	require.NoError(t, grown.ApplyPatch(patch))
+	require.True(t, grown.Equal(full))
+
+	// Shrink it again.
+	patch, err = Diff(full, single)
+	require.NoError(t, err)
+	require.NoError(t, grown.ApplyPatch(patch))
+	require.True(t, grown.Equal(single))
+
+	// An unchanged cascade yields an empty patch.
+	patch, err = Diff(full, full)
+	require.NoError(t, err)
+	require.Empty(t, patch.Layers)
+	require.Zero(t, patch.ChangedWords())
+}
+
+func TestPatch_ApplyRejectsInvalid(t *testing.T) {
+	from, to, _, _ := genEpochCascades(t, 10_000, 1_000, 5)
+
+	patch, err := Diff(from, to)
+	require.NoError(t, err)
+
+	// The patch does not apply to a cascade of another epoch.
+	require.Error(t, to.ApplyPatch(patch))
+
+	other := NewCascade(10_000, 1_000)
+	other.SetIssuerID([]byte("other"))
+	_, err = Diff(from, other)
+	require.Error(t, err)
+
+	// Words out of range leave the cascade untouched.
+	before := NewCascade(10_000, 1_000)
+	require.NoError(t, before.UnmarshalBinary(mustMarshal(t, from)))
+	patch.Layers[0].Indices = append(patch.Layers[0].Indices, 1<<30)
+	patch.Layers[0].Words = append(patch.Layers[0].Words, [PatchWordSize]byte{})
+	require.Error(t, from.ApplyPatch(patch))
+	require.True(t, from.Equal(before))
+
+	// So do oversized layer counts and layers.
+	patch.Layers[0].Indices, patch.Layers[0].Words = patch.Layers[0].Indices[:0], patch.Layers[0].Words[:0]
+	patch.Layers[0].BitLen = 1 << 40
+	require.Error(t, from.ApplyPatch(patch))
+	patch.LayerCount = 1 << 31
+	require.Error(t, from.ApplyPatch(patch))
+	require.True(t, from.Equal(before))
+}
+
+func TestPatch_BinaryRoundTrip(t *testing.T) {
+	from, to, _, _ := genEpochCascades(t, 10_000, 1_000, 5)
+
+	patch, err := Diff(from, to)
+	require.NoError(t, err)
+
+	data, err := patch.MarshalBinary()
+	require.NoError(t, err)
+	require.Less(t, len(data), len(mustMarshal(t, to)))
+
+	var decoded CascadePatch
+	require.NoError(t, decoded.UnmarshalBinary(data))
+	require.Equal(t, *patch, decoded)
+
+	require.Error(t, decoded.UnmarshalBinary(data[:len(data)-1]))
+	corrupted := bytes.Clone(data)
+	corrupted[0] = 'X'
+	require.Error(t, decoded.UnmarshalBinary(corrupted))
+}
+
+func TestPatch_Params(t *testing.T) {
+	valid, revoked := genRevocationTokens(10_000, 1_000)
+	from := NewCascade(10_000, 1_000)
+	require.NoError(t, from.Update(revoked, valid))
+	to, err := NewCascadeWithParams(10_000, 1_000, BloomLayers, Keccak256, MinCalldataCascadeParams)
+	require.NoError(t, err)
+	require.NoError(t, to.Update(revoked, valid))
+
+	patch, err := Diff(from, to)
+	require.NoError(t, err)
+	require.Equal(t, MinCalldataCascadeParams, patch.Params)
+	data, err := patch.MarshalBinary()
+	require.NoError(t, err)
+	var decoded CascadePatch
+	require.NoError(t, decoded.UnmarshalBinary(data))
+	require.Equal(t, *patch, decoded)
+
+	require.NoError(t, from.ApplyPatch(&decoded))
+	require.True(t, from.Equal(to))
+	require.Equal(t, MinCalldataCascadeParams, from.Params())
+}
+
+func TestPatch_OnChainPatch(t *testing.T) {
+	from, to, _, _ := genEpochCascades(t, 10_000, 1_000, 5)
+
+	patch, err := Diff(from, to)
+	require.NoError(t, err)
+
+	layerCount, layers, ks, bitLens, seeds, wordCounts, wordIndices, words := patch.GetOnChainPatch()
+	require.Equal(t, int64(len(to.filters)), layerCount.Int64())
+	require.Len(t, layers, len(patch.Layers))
+	require.Len(t, ks, len(patch.Layers))
+	require.Len(t, bitLens, len(patch.Layers))
+	require.Len(t, seeds, len(patch.Layers))
+	require.Len(t, wordCounts, len(patch.Layers))
+	require.Len(t, wordIndices, patch.ChangedWords())
+	require.Len(t, words, patch.ChangedWords())
+}
+
+func mustMarshal(t *testing.T, c *BloomFilterCascade) []byte {
+	data, err := c.MarshalBinary()
+	require.NoError(t, err)
+	return data
+}
@@ -31,7 +31,7 @@ var (
 
 // BloomMetaData contains all meta data concerning the Bloom contract.
 var BloomMetaData = &bind.MetaData{
-	ABI: "[{\"inputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"constructor\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"i\",\"type\":\"uint256\"}],\"name\":\"getLayerMetadata\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"filterSizeBits_\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"k_\",\"type\":\"uint256\"},{\"internalType\":\"bytes\",\"name\":\"filter_\",\"type\":\"bytes\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"layerCount\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"bytes\",\"name\":\"token\",\"type\":\"bytes\"}],\"name\":\"measureTestTokenGas\",\"outputs\":[{\"internalType\":\"bool\",\"name\":\"\",\"type\":\"bool\"},{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"bytes\",\"name\":\"token\",\"type\":\"bytes\"}],\"name\":\"testToken\",\"outputs\":[{\"internalType\":\"bool\",\"name\":\"\",\"type\":\"bool\"},{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"newOwner\",\"type\":\"address\"}],\"name\":\"transferOwnership\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"bytes[]\",\"name\":\"newFilters\",\"type\":\"bytes[]\"},{\"internalType\":\"uint256[]\",\"name\":\"ks\",\"type\":\"uint256[]\"},{\"internalType\":\"uint256[]\",\"name\":\"bitLens\",\"type\":\"uint256[]\"}],\"name\":\"updateCascade\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"}]",
+	ABI: "[{\"inputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"constructor\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"i\",\"type\":\"uint256\"}],\"name\":\"getLayerMetadata\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"filterSizeBits_\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"k_\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"seed_\",\"type\":\"uint256\"},{\"internalType\":\"bytes\",\"name\":\"filter_\",\"type\":\"bytes\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"layerCount\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"bytes\",\"name\":\"token\",\"type\":\"bytes\"}],\"name\":\"measureTestTokenGas\",\"outputs\":[{\"internalType\":\"bool\",\"name\":\"\",\"type\":\"bool\"},{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"newLayerCount\",\"type\":\"uint256\"},{\"internalType\":\"uint256[]\",\"name\":\"layerIds\",\"type\":\"uint256[]\"},{\"internalType\":\"uint256[]\",\"name\":\"ks\",\"type\":\"uint256[]\"},{\"internalType\":\"uint256[]\",\"name\":\"bitLens\",\"type\":\"uint256[]\"},{\"internalType\":\"uint256[]\",\"name\":\"seeds\",\"type\":\"uint256[]\"},{\"internalType\":\"uint256[]\",\"name\":\"wordCounts\",\"type\":\"uint256[]\"},{\"internalType\":\"uint256[]\",\"name\":\"wordIndices\",\"type\":\"uint256[]\"},{\"internalType\":\"bytes32[]\",\"name\":\"words\",\"type\":\"bytes32[]\"}],\"name\":\"patchCascade\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"bytes\",\"name\":\"token\",\"type\":\"bytes\"}],\"name\":\"testToken\",\"outputs\":[{\"internalType\":\"bool\",\"name\":\"\",\"type\":\"bool\"},{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"newOwner\",\"type\":\"address\"}],\"name\":\"transferOwnership\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"bytes[]\",\"name\":\"newFilters\",\"type\":\"bytes[]\"},{\"internalType\":\"uint256[]\",\"name\":\"ks\",\"type\":\"uint256[]\"},{\"internalType\":\"uint256[]\",\"name\":\"bitLens\",\"type\":\"uint256[]\"},{\"internalType\":\"uint256[]\",\"name\":\"seeds\",\"type\":\"uint256[]\"}],\"name\":\"updateCascade\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"}]",
 	Bin: "0x608060405234602257600e60a5565b60146026565b6124536100b1823961245390f35b602c565b60405190565b600080fd5b60001b90565b90604660018060a01b03916031565b9181191691161790565b60018060a01b031690565b90565b606d60696071926050565b605b565b6050565b90565b607b90605e565b90565b6085906074565b90565b90565b90609b609760a192607e565b6088565b82546037565b9055565b60ae336000608b565b56fe60806040526004361015610013575b61051b565b61001e60003561007d565b80634786b5731461007857806356e7f6c714610073578063a50e2b431461006e578063b163337d14610069578063d423db2a146100645763f2fde38b0361000e576104e8565b610457565b61041d565b6102da565b6101c0565b610163565b60e01c90565b60405190565b600080fd5b600080fd5b600080fd5b600080fd5b600080fd5b600080fd5b909182601f830112156100e15781359167ffffffffffffffff83116100dc5760200192600183028401116100d757565b6100a2565b61009d565b610098565b9060208282031261011857600082013567ffffffffffffffff81116101135761010f92016100a7565b9091565b610093565b61008e565b151590565b61012b9061011d565b9052565b90565b61013b9061012f565b9052565b91602061016192949361015a60408201966000830190610122565b0190610132565b565b346101955761017c6101763660046100e6565b9061052a565b90610191610188610083565b9283928361013f565b0390f35b610089565b60009103126101a557565b61008e565b91906101be90600060208501940190610132565b565b346101f0576101d036600461019a565b6101ec6101db610550565b6101e3610083565b918291826101aa565b0390f35b610089565b6101fe8161012f565b0361020557565b600080fd5b90503590610217826101f5565b565b90602082820312610233576102309160000161020a565b90565b61008e565b5190565b60209181520190565b60005b838110610259575050906000910152565b806020918301518185015201610248565b601f801991011690565b61029361029c6020936102a19361028a81610238565b9384809361023c565b95869101610245565b61026a565b0190565b6102ca6102d79492936102c060608401956000850190610132565b6020830190610132565b6040818403910152610274565b90565b3461030d576103096102f56102f0366004610219565b61087a565b610300939193610083565b938493846102a5565b0390f35b610089565b909182601f8301121561034c5781359167ffffffffffffffff831161034757602001926020830284011161034257565b6100a2565b61009d565b610098565b909182601f8301121561038b5781359167ffffffffffffffff831161038657602001926020830284011161038157565b6100a2565b61009d565b610098565b9060608282031261041257600082013567ffffffffffffffff811161040d57816103bb918401610312565b929093602082013567ffffffffffffffff811161040857836103de918401610351565b929093604082013567ffffffffffffffff8111610403576103ff9201610351565b9091565b610093565b610093565b610093565b61008e565b60000190565b346104525761043c610430366004610390565b949390939291926118d4565b610444610083565b8061044e81610417565b0390f35b610089565b346104895761047061046a3660046100e6565b90611a0a565b9061048561047c610083565b9283928361013f565b0390f35b610089565b60018060a01b031690565b6104a29061048e565b90565b6104ae81610499565b036104b557565b600080fd5b905035906104c7826104a5565b565b906020828203126104e3576104e0916000016104ba565b90565b61008e565b34610516576105006104fb3660046104c9565b611d15565b610508610083565b8061051281610417565b0390f35b610089565b600080fd5b600090565b600090565b9061054691610537610520565b50610540610525565b50611a0a565b91909190565b5490565b610558610525565b50610563600161054c565b90565b606090565b60209181520190565b60007f496e76616c6964206c6179657200000000000000000000000000000000000000910152565b6105a9600d60209261056b565b6105b281610574565b0190565b6105cc906020810190600081830391015261059c565b90565b156105d657565b6105de610083565b62461bcd60e51b8152806105f4600482016105b6565b0390fd5b634e487b7160e01b600052603260045260246000fd5b600052602060002090565b600052602060002090565b906020610636818306601f0393610619565b91040191565b6106458161054c565b8210156106605761065760029161060e565b91020190600090565b6105f8565b90565b60001c90565b67ffffffffffffffff1690565b61068761068c91610668565b61066e565b90565b610699905461067b565b90565b67ffffffffffffffff1690565b90565b6106c06106bb6106c59261069c565b6106a9565b61012f565b90565b60401c90565b63ffffffff1690565b6106e36106e8916106c8565b6106ce565b90565b6106f590546106d7565b90565b63ffffffff1690565b61071561071061071a926106f8565b6106a9565b61012f565b90565b634e487b7160e01b600052602260045260246000fd5b9060016002830492168015610753575b602083101461074e57565b61071d565b91607f1691610743565b60209181520190565b600052602060002090565b906000929180549061078c61078583610733565b809461075d565b916001811690816000146107e557506001146107a8575b505050565b6107b59192939450610766565b916000925b8184106107cd57505001903880806107a3565b600181602092959395548486015201910192906107ba565b92949550505060ff19168252151560200201903880806107a3565b9061080a91610771565b90565b634e487b7160e01b600052604160045260246000fd5b9061082d9061026a565b810190811067ffffffffffffffff82111761084757604052565b61080d565b9061086c6108659261085c610083565b93848092610800565b0383610823565b565b6108779061084c565b90565b6108c86108ce91610889610525565b50610892610525565b5061089b610566565b506108c1816108bb6108b56108b0600161054c565b61012f565b9161012f565b106105cf565b600161063c565b50610665565b906108e36108de6000840161068f565b6106ac565b9061090760016108fd6108f8600087016106eb565b610701565b940192939261086e565b90565b60018060a01b031690565b61092161092691610668565b61090a565b90565b6109339054610915565b90565b60007f4e6f74206f776e65720000000000000000000000000000000000000000000000910152565b61096b600960209261056b565b61097481610936565b0190565b61098e906020810190600081830391015261095e565b90565b1561099857565b6109a0610083565b62461bcd60e51b8152806109b660048201610978565b0390fd5b906109ed95949392916109e8336109e26109dc6109d76000610929565b610499565b91610499565b14610991565b6116bb565b565b5090565b90565b610a0a610a05610a0f926109f3565b6106a9565b61012f565b90565b60007f4174206c65617374206f6e65206c617965720000000000000000000000000000910152565b610a47601260209261056b565b610a5081610a12565b0190565b610a6a9060208101906000818303910152610a3a565b90565b15610a7457565b610a7c610083565b62461bcd60e51b815280610a9260048201610a54565b0390fd5b5090565b60007f4e656564206b20666f722065616368206c617965720000000000000000000000910152565b610acf601560209261056b565b610ad881610a9a565b0190565b610af29060208101906000818303910152610ac2565b90565b15610afc57565b610b04610083565b62461bcd60e51b815280610b1a60048201610adc565b0390fd5b60007f4e656564206269744c656e20666f722065616368206c61796572000000000000910152565b610b53601a60209261056b565b610b5c81610b1e565b0190565b610b769060208101906000818303910152610b46565b90565b15610b8057565b610b88610083565b62461bcd60e51b815280610b9e60048201610b60565b0390fd5b634e487b7160e01b600052601160045260246000fd5b610bc7610bcd9193929361012f565b9261012f565b91610bd983820261012f565b928184041490151715610be857565b610ba2565b610bf8906002610bb8565b90565b1c90565b90610c139060001990602003600802610bfb565b8154169055565b1b90565b91906008610c3a910291610c3460001984610c1a565b92610c1a565b9181191691161790565b610c58610c53610c5d9261012f565b6106a9565b61012f565b90565b90565b9190610c79610c74610c8193610c44565b610c60565b908354610c1e565b9055565b610c9791610c91610525565b91610c63565b565b5b818110610ca5575050565b80610cb36000600193610c85565b01610c9a565b90610cca9060001990600802610bfb565b191690565b81610cd991610cb9565b906002021790565b90600091610cf9610cf182610766565b928354610ccf565b905555565b601f602091010490565b91929060208210600014610d6257601f8411600114610d3257610d2c929350610ccf565b90555b5b565b5090610d58610d5d936001610d4f610d4985610766565b92610cfe565b82019101610c99565b610ce1565b610d2f565b50610d998293610d73600194610766565b610d92610d7f85610cfe565b820192601f861680610da4575b50610cfe565b0190610c99565b600202179055610d30565b610db090888603610bff565b38610d8c565b929091680100000000000000008211610e1857602011600014610e095760208110600014610ded57610de791610ccf565b90555b5b565b60019160ff1916610dfd84610766565b55600202019055610dea565b60019150600202019055610deb565b61080d565b908154610e2981610733565b90818311610e52575b818310610e40575b50505050565b610e4993610d08565b38808080610e3a565b610e5e83838387610db6565b610e32565b6000610e6e91610e1d565b565b634e487b7160e01b600052600060045260246000fd5b90600003610e9957610e9790610e63565b565b610e70565b60006001610eb192828082015501610e86565b565b90600003610ec657610ec490610e9e565b565b610e70565b5b818110610ed7575050565b80610ee56000600293610eb3565b01610ecc565b9091828110610efa575b505050565b610f18610f12610f0c610f2395610bed565b92610bed565b9261060e565b918201910190610ecb565b388080610ef5565b90680100000000000000008111610f545781610f49610f529361054c565b90828155610eeb565b565b61080d565b6000610f6491610f2b565b565b90600003610f7957610f7790610f59565b565b610e70565b600080fd5b600080fd5b600080fd5b903590600160200381360303821215610fcf570180359067ffffffffffffffff8211610fca57602001916001820236038313610fc557565b610f88565b610f83565b610f7e565b90821015610fef576020610feb9202810190610f8d565b9091565b6105f8565b9190811015611004576020020190565b6105f8565b35611013816101f5565b90565b60007f6b206d757374206265203e203000000000000000000000000000000000000000910152565b61104b600d60209261056b565b61105481611016565b0190565b61106e906020810190600081830391015261103e565b90565b1561107857565b611080610083565b62461bcd60e51b81528061109660048201611058565b0390fd5b60007f6269744c656e206d757374206265203e20300000000000000000000000000000910152565b6110cf601260209261056b565b6110d88161109a565b0190565b6110f290602081019060008183039101526110c2565b90565b156110fc57565b611104610083565b62461bcd60e51b81528061111a600482016110dc565b0390fd5b5090565b90565b61113961113461113e92611122565b6106a9565b61012f565b90565b60007f6269744c656e206578636565647320662e6c656e6774682a3800000000000000910152565b611176601960209261056b565b61117f81611141565b0190565b6111999060208101906000818303910152611169565b90565b156111a357565b6111ab610083565b62461bcd60e51b8152806111c160048201611183565b0390fd5b60007f6b20746f6f206c6172676520666f722075696e74333200000000000000000000910152565b6111fa601660209261056b565b611203816111c5565b0190565b61121d90602081019060008183039101526111ed565b90565b1561122757565b61122f610083565b62461bcd60e51b81528061124560048201611207565b0390fd5b60007f6269744c656e20746f6f206c6172676520666f722075696e7436340000000000910152565b61127e601b60209261056b565b61128781611249565b0190565b6112a19060208101906000818303910152611271565b90565b156112ab57565b6112b3610083565b62461bcd60e51b8152806112c96004820161128b565b0390fd5b90565b6112e46112df6112e99261012f565b6106a9565b61069c565b90565b6113006112fb6113059261012f565b6106a9565b6106f8565b90565b9061131b611314610083565b9283610823565b565b6113276060611308565b90565b906113349061069c565b9052565b90611342906106f8565b9052565b600080fd5b67ffffffffffffffff81116113695761136560209161026a565b0190565b61080d565b90826000939282370152565b9092919261138f61138a8261134b565b611308565b938185526020850190828401116113ab576113a99261136e565b565b611346565b6113bb91369161137a565b90565b52565b600052602060002090565b5490565b6113d9816113cc565b8210156113f4576113eb6002916113c1565b91020190600090565b6105f8565b611403905161069c565b90565b60001b90565b9061141f67ffffffffffffffff91611406565b9181191691161790565b61143d6114386114429261069c565b6106a9565b61069c565b90565b90565b9061145d61145861146492611429565b611445565b825461140c565b9055565b61147290516106f8565b90565b60401b90565b906114926bffffffff000000000000000091611475565b9181191691161790565b6114b06114ab6114b5926106f8565b6106a9565b6106f8565b90565b90565b906114d06114cb6114d79261149c565b6114b8565b825461147b565b9055565b5190565b9190601f81116114ef575b505050565b6114fb61152093610766565b90602061150784610cfe565b83019310611528575b61151990610cfe565b0190610c99565b3880806114ea565b915061151981929050611510565b9061154081610238565b9067ffffffffffffffff8211611602576115648261155e8554610733565b856114df565b602090601f8311600114611599579180916115889360009261158d575b5050610ccf565b90555b565b90915001513880611581565b601f198316916115a885610766565b9260005b8181106115ea575091600293918560019694106115d0575b5050500201905561158b565b6115e0910151601f841690610cb9565b90553880806115c4565b919360206001819287870151815501950192016115ac565b61080d565b9061161191611536565b565b906116596040600161165f9461163860008201611632600088016113f9565b90611448565b6116516000820161164b60208801611468565b906114bb565b0192016114db565b90611607565b565b91906116725761167091611613565b565b610e70565b90815491680100000000000000008310156116a7578261169f9160016116a5950181556113d0565b90611661565b565b61080d565b60016116b8910161012f565b90565b95949193956116cb8183906109ef565b956116e9876116e36116dd60006109f6565b9161012f565b11610a6d565b61170f876117096117036116fe8a8a90610a96565b61012f565b9161012f565b14610af5565b6117358761172f6117296117248c8990610a96565b61012f565b9161012f565b14610b79565b61174160006001610f66565b61174b60006109f6565b5b8061175f6117598a61012f565b9161012f565b10156118c957806118bf8a896118ba6118b18b6117b16117ac8d6117a361179e8f8f906118c49e61179292919091610fd4565b96909699908d91610ff4565b611009565b97908a91610ff4565b611009565b936117cf866117c96117c360006109f6565b9161012f565b11611071565b6117ec856117e66117e060006109f6565b9161012f565b116110f5565b6118268561181f61181961181461180487879061111e565b61180e6008611125565b90610bb8565b61012f565b9161012f565b111561119c565b6118478661184061183a63ffffffff610701565b9161012f565b1115611220565b61186c8561186561185f67ffffffffffffffff6106ac565b9161012f565b11156112a4565b6118ac61188b61188561187f60016112cd565b976112d0565b976112ec565b9291926118a361189961131d565b9860008a0161132a565b60208801611338565b6113b0565b604084016113be565b611677565b6116ac565b61174c565b509650505050505050565b906118e295949392916109ba565b565b60007f4e6f206c61796572730000000000000000000000000000000000000000000000910152565b611919600960209261056b565b611922816118e4565b0190565b61193c906020810190600081830391015261190c565b90565b1561194657565b61194e610083565b62461bcd60e51b81528061196460048201611926565b0390fd5b90565b90565b61198261197d6119879261196b565b6106a9565b61012f565b90565b61199961199f9193929361012f565b9261012f565b82039182116119aa57565b610ba2565b60007f756e726561636861626c65000000000000000000000000000000000000000000910152565b6119e4600b60209261056b565b6119ed816119af565b0190565b611a0790602081019060008183039101526119d7565b90565b9190611a4f90611a18610520565b50611a21610525565b50611a2c600161054c565b93611a4a85611a44611a3e60006109f6565b9161012f565b1161193f565b611ece565b91611a5a60006109f6565b5b80611a6e611a688461012f565b9161012f565b1015611b7257611aca611a8c611a866001849061063c565b50610665565b6001810190611ab06000611aa9611aa482850161068f565b6106ac565b92016106eb565b90611ac4611abe8994611968565b92610701565b91612187565b81611af0611aea611ae586611adf600161196e565b9061198a565b61012f565b9161012f565b14611b3857611aff901561011d565b611b1157611b0c906116ac565b611a5b565b92505081611b1f600161196e565b16611b33611b2d600161196e565b9161012f565b149190565b91509250611b6d611b6784611b4d600161196e565b16611b61611b5b60006109f6565b9161012f565b1461011d565b9161011d565b149190565b611b7a610083565b62461bcd60e51b815280611b90600482016119f1565b0390fd5b611bc290611bbd33611bb7611bb1611bac6000610929565b610499565b91610499565b14610991565b611ce2565b565b611bd8611bd3611bdd926109f3565b6106a9565b61048e565b90565b611be990611bc4565b90565b60007f4e6577206f776e6572206973207a65726f206164647265737300000000000000910152565b611c21601960209261056b565b611c2a81611bec565b0190565b611c449060208101906000818303910152611c14565b90565b15611c4e57565b611c56610083565b62461bcd60e51b815280611c6c60048201611c2e565b0390fd5b90611c8160018060a01b0391611406565b9181191691161790565b611c9f611c9a611ca49261048e565b6106a9565b61048e565b90565b611cb090611c8b565b90565b611cbc90611ca7565b90565b90565b90611cd7611cd2611cde92611cb3565b611cbf565b8254611c70565b9055565b611d1390611d0c81611d05611cff611cfa6000611be0565b610499565b91610499565b1415611c47565b6000611cc2565b565b611d1e90611b94565b565b67ffffffffffffffff8111611d355760200290565b61080d565b611d46611d4b91611d20565b611308565b90565b369037565b90611d71611d6083611d3a565b92611d6b8491611d20565b90611d4e565b565b611d7d6004611d53565b90565b60200190565b90565b60ff1690565b611da3611d9e611da892611d86565b6106a9565b611d89565b90565b90565b611dcd90611dc7611dc1611dd294611d89565b91611dab565b90610bfb565b611dab565b90565b611de1611de691610668565b610c44565b90565b50600490565b90611df982611de9565b811015611e07576020020190565b6105f8565b90565b611e23611e1e611e2892611e0c565b6106a9565b611d89565b90565b611e4a90611e44611e3e611e4f94611d89565b9161012f565b90610bfb565b61012f565b90565b90565b611e69611e64611e6e92611e52565b6106a9565b61012f565b90565b90565b611e88611e83611e8d92611e71565b6106a9565b611d89565b90565b90565b611ea7611ea2611eac92611e90565b6106a9565b61012f565b90565b90565b611ec6611ec1611ecb92611eaf565b6106a9565b61012f565b90565b9190611ffe611fe7611eeb61201793611ee5611d73565b966113b0565b611efd611ef782610238565b91611d80565b20611f3e611f25611f20611f1b84611f1560c0611d8f565b90611dae565b611dd5565b6112d0565b611f3988611f3360006109f6565b90611def565b61132a565b611f90611f77611f60611f5084611dd5565b611f5a6080611e0f565b90611e2b565b611f7167ffffffffffffffff611e55565b166112d0565b611f8b88611f85600161196e565b90611def565b61132a565b611fe2611fc9611fb2611fa284611dd5565b611fac6040611e74565b90611e2b565b611fc367ffffffffffffffff611e55565b166112d0565b611fdd88611fd76002611e93565b90611def565b61132a565b611dd5565b611ff867ffffffffffffffff611e55565b166112d0565b6120128461200c6003611eb2565b90611def565b61132a565b565b61202d61202861203292611eaf565b6106a9565b611d89565b90565b90565b61204c61204761205192612035565b6106a9565b61012f565b90565b61206861206361206d9261012f565b6106a9565b611d89565b90565b61207a9054610733565b90565b9061208782612070565b808210156120b5576020116000146120a55760209006601f0390915b565b6120ae91610624565b90916120a3565b6105f8565b60f81b90565b6120c9906120ba565b90565b6120dc9060086120e19302610bfb565b6120c0565b90565b906120ef91546120cc565b90565b60f81c90565b61210c61210761211192611d89565b6106a9565b611d89565b90565b612120612125916120f2565b6120f8565b90565b6121479061214161213b61214c94611d89565b91611d89565b90610bfb565b611d89565b90565b61216361215e6121689261196b565b6106a9565b611d89565b90565b61217f61217a612184926109f3565b6106a9565b611d89565b90565b90929192612193610520565b5061219e60006109f6565b5b806121b26121ac8761012f565b9161012f565b101561224a5761220f6121c785838591612352565b61220a6122056121ff6121f86121e7856121e16003612019565b90611e2b565b946121f26007612038565b16612054565b938861207d565b906120e4565b612114565b612128565b612219600161214f565b1661222d612227600061216b565b91611d89565b146122405761223b906116ac565b61219f565b5050505050600090565b5050505050600190565b61226861226361226d9261196b565b6106a9565b61069c565b90565b61227c6122829161069c565b9161069c565b019067ffffffffffffffff821161229557565b610ba2565b6122ae6122a96122b392611eaf565b6106a9565b61069c565b90565b6122d5906122cf6122c96122da94611d89565b9161069c565b90610bfb565b61069c565b90565b6122f16122ec6122f692611e90565b6106a9565b61069c565b90565b600090565b90612309910261069c565b90565b90612317910161069c565b90565b634e487b7160e01b600052601260045260246000fd5b61233c6123429161012f565b9161012f565b90811561234d570690565b61231a565b6124106124159161240a61237261241a969561236c610525565b506112d0565b916123fa6123f561239f61239a846123948861238e6001612254565b166106ac565b90611def565b6113f9565b926123ef6123ea60026123e56123df6123c48b8c6123bd6001612254565b1690612270565b6123ce600361229a565b166123d9600161214f565b906122b6565b916122dd565b612270565b6106ac565b90611def565b6113f9565b906124036122f9565b50926122fe565b9061230c565b6106ac565b612330565b9056fea26469706673582212205cecf5311f1b73f51ef73a1b203b6c235e98816240a97f269467fdb70f8e612b64736f6c634300081e0033",
 }
 
@@ -204,10 +204,11 @@ func (_Bloom *BloomTransactorRaw) Transact(opts *bind.TransactOpts, method strin
 
 // GetLayerMetadata is a free data retrieval call binding the contract method 0xa50e2b43.
 //
-// Solidity: function getLayerMetadata(uint256 i) view returns(uint256 filterSizeBits_, uint256 k_, bytes filter_)
+// Solidity: function getLayerMetadata(uint256 i) view returns(uint256 filterSizeBits_, uint256 k_, uint256 seed_, bytes filter_)
 func (_Bloom *BloomCaller) GetLayerMetadata(opts *bind.CallOpts, i *big.Int) (struct {
 	FilterSizeBits *big.Int
 	K              *big.Int
+	Seed           *big.Int
 	Filter         []byte
 }, error) {
 	var out []interface{}
@@ -216,6 +217,7 @@ func (_Bloom *BloomCaller) GetLayerMetadata(opts *bind.CallOpts, i *big.Int) (st
 	outstruct := new(struct {
 		FilterSizeBits *big.Int
 		K              *big.Int
+		Seed           *big.Int
 		Filter         []byte
 	})
 	if err != nil {
@@ -224,7 +226,8 @@ func (_Bloom *BloomCaller) GetLayerMetadata(opts *bind.CallOpts, i *big.Int) (st
 
 	outstruct.FilterSizeBits = *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
 	outstruct.K = *abi.ConvertType(out[1], new(*big.Int)).(**big.Int)
-	outstruct.Filter = *abi.ConvertType(out[2], new([]byte)).(*[]byte)
+	outstruct.Seed = *abi.ConvertType(out[2], new(*big.Int)).(**big.Int)
+	outstruct.Filter = *abi.ConvertType(out[3], new([]byte)).(*[]byte)
 
 	return *outstruct, err
 
@@ -232,10 +235,11 @@ func (_Bloom *BloomCaller) GetLayerMetadata(opts *bind.CallOpts, i *big.Int) (st
 
 // GetLayerMetadata is a free data retrieval call binding the contract method 0xa50e2b43.
 //
-// Solidity: function getLayerMetadata(uint256 i) view returns(uint256 filterSizeBits_, uint256 k_, bytes filter_)
+// Solidity: function getLayerMetadata(uint256 i) view returns(uint256 filterSizeBits_, uint256 k_, uint256 seed_, bytes filter_)
 func (_Bloom *BloomSession) GetLayerMetadata(i *big.Int) (struct {
 	FilterSizeBits *big.Int
 	K              *big.Int
+	Seed           *big.Int
 	Filter         []byte
 }, error) {
 	return _Bloom.Contract.GetLayerMetadata(&_Bloom.CallOpts, i)
@@ -243,10 +247,11 @@ func (_Bloom *BloomSession) GetLayerMetadata(i *big.Int) (struct {
 
 // GetLayerMetadata is a free data retrieval call binding the contract method 0xa50e2b43.
 //
-// Solidity: function getLayerMetadata(uint256 i) view returns(uint256 filterSizeBits_, uint256 k_, bytes filter_)
+// Solidity: function getLayerMetadata(uint256 i) view returns(uint256 filterSizeBits_, uint256 k_, uint256 seed_, bytes filter_)
 func (_Bloom *BloomCallerSession) GetLayerMetadata(i *big.Int) (struct {
 	FilterSizeBits *big.Int
 	K              *big.Int
+	Seed           *big.Int
 	Filter         []byte
 }, error) {
 	return _Bloom.Contract.GetLayerMetadata(&_Bloom.CallOpts, i)
@@ -336,6 +341,27 @@ func (_Bloom *BloomTransactorSession) MeasureTestTokenGas(token []byte) (*types.
 	return _Bloom.Contract.MeasureTestTokenGas(&_Bloom.TransactOpts, token)
 }
 
+// PatchCascade is a paid mutator transaction binding the contract method 0x9ade5fa8.
+//
+// Solidity: function patchCascade(uint256 newLayerCount, uint256[] layerIds, uint256[] ks, uint256[] bitLens, uint256[] seeds, uint256[] wordCounts, uint256[] wordIndices, bytes32[] words) returns()
+func (_Bloom *BloomTransactor) PatchCascade(opts *bind.TransactOpts, newLayerCount *big.Int, layerIds []*big.Int, ks []*big.Int, bitLens []*big.Int, seeds []*big.Int, wordCounts []*big.Int, wordIndices []*big.Int, words [][32]byte) (*types.Transaction, error) {
+	return _Bloom.contract.Transact(opts, "patchCascade", newLayerCount, layerIds, ks, bitLens, seeds, wordCounts, wordIndices, words)
+}
+
+// PatchCascade is a paid mutator transaction binding the contract method 0x9ade5fa8.
+//
+// Solidity: function patchCascade(uint256 newLayerCount, uint256[] layerIds, uint256[] ks, uint256[] bitLens, uint256[] seeds, uint256[] wordCounts, uint256[] wordIndices, bytes32[] words) returns()
+func (_Bloom *BloomSession) PatchCascade(newLayerCount *big.Int, layerIds []*big.Int, ks []*big.Int, bitLens []*big.Int, seeds []*big.Int, wordCounts []*big.Int, wordIndices []*big.Int, words [][32]byte) (*types.Transaction, error) {
+	return _Bloom.Contract.PatchCascade(&_Bloom.TransactOpts, newLayerCount, layerIds, ks, bitLens, seeds, wordCounts, wordIndices, words)
+}
+
+// PatchCascade is a paid mutator transaction binding the contract method 0x9ade5fa8.
+//
+// Solidity: function patchCascade(uint256 newLayerCount, uint256[] layerIds, uint256[] ks, uint256[] bitLens, uint256[] seeds, uint256[] wordCounts, uint256[] wordIndices, bytes32[] words) returns()
+func (_Bloom *BloomTransactorSession) PatchCascade(newLayerCount *big.Int, layerIds []*big.Int, ks []*big.Int, bitLens []*big.Int, seeds []*big.Int, wordCounts []*big.Int, wordIndices []*big.Int, words [][32]byte) (*types.Transaction, error) {
+	return _Bloom.Contract.PatchCascade(&_Bloom.TransactOpts, newLayerCount, layerIds, ks, bitLens, seeds, wordCounts, wordIndices, words)
+}
+
 // TransferOwnership is a paid mutator transaction binding the contract method 0xf2fde38b.
 //
 // Solidity: function transferOwnership(address newOwner) returns()
@@ -357,23 +383,23 @@ func (_Bloom *BloomTransactorSession) TransferOwnership(newOwner common.Address)
 	return _Bloom.Contract.TransferOwnership(&_Bloom.TransactOpts, newOwner)
 }
 
-// UpdateCascade is a paid mutator transaction binding the contract method 0xb163337d.
+// UpdateCascade is a paid mutator transaction binding the contract method 0x8b56bd3b.
 //
-// Solidity: function updateCascade(bytes[] newFilters, uint256[] ks, uint256[] bitLens) returns()
-func (_Bloom *BloomTransactor) UpdateCascade(opts *bind.TransactOpts, newFilters [][]byte, ks []*big.Int, bitLens []*big.Int) (*types.Transaction, error) {
-	return _Bloom.contract.Transact(opts, "updateCascade", newFilters, ks, bitLens)
+// Solidity: function updateCascade(bytes[] newFilters, uint256[] ks, uint256[] bitLens, uint256[] seeds) returns()
+func (_Bloom *BloomTransactor) UpdateCascade(opts *bind.TransactOpts, newFilters [][]byte, ks []*big.Int, bitLens []*big.Int, seeds []*big.Int) (*types.Transaction, error) {
+	return _Bloom.contract.Transact(opts, "updateCascade", newFilters, ks, bitLens, seeds)
 }
 
-// UpdateCascade is a paid mutator transaction binding the contract method 0xb163337d.
+// UpdateCascade is a paid mutator transaction binding the contract method 0x8b56bd3b.
 //
-// Solidity: function updateCascade(bytes[] newFilters, uint256[] ks, uint256[] bitLens) returns()
-func (_Bloom *BloomSession) UpdateCascade(newFilters [][]byte, ks []*big.Int, bitLens []*big.Int) (*types.Transaction, error) {
-	return _Bloom.Contract.UpdateCascade(&_Bloom.TransactOpts, newFilters, ks, bitLens)
+// Solidity: function updateCascade(bytes[] newFilters, uint256[] ks, uint256[] bitLens, uint256[] seeds) returns()
+func (_Bloom *BloomSession) UpdateCascade(newFilters [][]byte, ks []*big.Int, bitLens []*big.Int, seeds []*big.Int) (*types.Transaction, error) {
+	return _Bloom.Contract.UpdateCascade(&_Bloom.TransactOpts, newFilters, ks, bitLens, seeds)
 }
 
-// UpdateCascade is a paid mutator transaction binding the contract method 0xb163337d.
+// UpdateCascade is a paid mutator transaction binding the contract method 0x8b56bd3b.
 //
-// Solidity: function updateCascade(bytes[] newFilters, uint256[] ks, uint256[] bitLens) returns()
-func (_Bloom *BloomTransactorSession) UpdateCascade(newFilters [][]byte, ks []*big.Int, bitLens []*big.Int) (*types.Transaction, error) {
-	return _Bloom.Contract.UpdateCascade(&_Bloom.TransactOpts, newFilters, ks, bitLens)
+// Solidity: function updateCascade(bytes[] newFilters, uint256[] ks, uint256[] bitLens, uint256[] seeds) returns()
+func (_Bloom *BloomTransactorSession) UpdateCascade(newFilters [][]byte, ks []*big.Int, bitLens []*big.Int, seeds []*big.Int) (*types.Transaction, error) {
+	return _Bloom.Contract.UpdateCascade(&_Bloom.TransactOpts, newFilters, ks, bitLens, seeds)
 }
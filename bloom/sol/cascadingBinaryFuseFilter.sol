@@ -0,0 +1,216 @@
+// SPDX-License-Identifier: MIT
+pragma solidity ^0.8.0;
+
+/// @title CascadingBinaryFuseFilter
+/// @notice Variant of CascadingBloomFilter whose layers are binary fuse filters (bloom.BinaryFuseLayers): a token
+///         matches a layer if the XOR of its fingerprint and its three slots is zero. The layers are published as
+///         returned by the off-chain `GetOnChainFuseFilter`. There is no patchCascade, as the slots of a binary fuse
+///         filter change almost entirely from one epoch to the next.
+contract CascadingBinaryFuseFilter {
+    /* ─── State ───────────────────────────────────────────────────────────── */
+
+    address private _owner;
+
+    struct Layer {
+        uint32  segmentLength;
+        uint32  segmentCount;
+        uint8   fingerprintBits;
+        uint64  seed;
+        bytes   filter;
+    }
+
+    Layer[] private layers;
+
+    /* ─── Modifiers ───────────────────────────────────────────────────────── */
+
+    modifier onlyOwner() {
+        require(msg.sender == _owner, "Not owner");
+        _;
+    }
+
+    /* ─── Constructor ─────────────────────────────────────────────────────── */
+
+    constructor() {
+        _owner = msg.sender;
+    }
+
+    /* ─── Public/External API ──────────────────────────────────────────────── */
+
+    /// @notice Transfer ownership to a new address.
+    /// @param newOwner The new owner address
+    function transferOwnership(address newOwner) external onlyOwner {
+        require(newOwner != address(0), "New owner is zero address");
+        _owner = newOwner;
+    }
+
+    /// @notice Replace all layers in one call.
+    /// @param newFilters      newFilters[i] is the packed slots of layer i
+    /// @param fingerprintBits fingerprintBits[i] = width of the fingerprints of layer i
+    /// @param segmentLengths  segmentLengths[i] = number of slots of a segment of layer i (0 for an empty layer)
+    /// @param segmentCounts   segmentCounts[i] = number of segments a first slot of layer i falls into (0 if empty)
+    /// @param seeds           seeds[i] = hash seed of layer i
+    function updateCascade(
+        bytes[] calldata newFilters,
+        uint256[] calldata fingerprintBits,
+        uint256[] calldata segmentLengths,
+        uint256[] calldata segmentCounts,
+        uint256[] calldata seeds
+    ) external onlyOwner {
+        uint256 len = newFilters.length;
+        require(len > 0,                       "At least one layer");
+        require(len == fingerprintBits.length, "Need fingerprintBits for each layer");
+        require(len == segmentLengths.length,  "Need segmentLength for each layer");
+        require(len == segmentCounts.length,   "Need segmentCount for each layer");
+        require(len == seeds.length,           "Need seed for each layer");
+
+        // Wipe out existing layers (cheapest way to reset a dynamic array)
+        delete layers;
+
+        for (uint256 i = 0; i < len; ) {
+            bytes calldata f     = newFilters[i];
+            uint256      bits    = fingerprintBits[i];
+            uint256      segLen  = segmentLengths[i];
+            uint256      count   = segmentCounts[i];
+            uint256      seed    = seeds[i];
+
+            // -- validate inputs --
+            require(bits > 0 && bits <= 32,        "fingerprintBits out of range");
+            require(segLen <= type(uint32).max,    "segmentLength too large for uint32");
+            require(count <= type(uint32).max,     "segmentCount too large for uint32");
+            require(seed <= type(uint64).max,      "seed too large for uint64");
+            if (count == 0) {
+                require(segLen == 0,               "Empty layer has a segmentLength");
+            } else {
+                require(segLen > 0 && (segLen & (segLen - 1)) == 0, "segmentLength not a power of two");
+                require((count + 2) * segLen * bits <= f.length * 8, "Slots exceed f.length*8");
+            }
+
+            layers.push(
+                Layer({
+                    segmentLength:   uint32(segLen),
+                    segmentCount:    uint32(count),
+                    fingerprintBits: uint8(bits),
+                    seed:            uint64(seed),
+                    filter:          f
+                })
+            );
+
+            unchecked { ++i; }
+        }
+    }
+
+    /// @notice Return the total number of layers.
+    function layerCount() external view returns (uint256) {
+        return layers.length;
+    }
+
+    /// @notice Test `token` against every layer like CascadingBloomFilter.testToken.
+    ///         Returns (accepted, layerIndexReached).
+    function testToken(bytes calldata token) public view returns (bool, uint256) {
+        uint256 n = layers.length;
+        require(n > 0, "No layers");
+
+        // The 4×64‐bit hashes only change with the layer seed:
+        uint64 seed = 0;
+        uint64[4] memory h = extractHashes(token, seed);
+
+        for (uint256 li = 0; li < n; ) {
+            Layer storage L = layers[li];
+            if (L.seed != seed) {
+                seed = L.seed;
+                h    = extractHashes(token, seed);
+            }
+            bool match_ = _testInLayer(L, h);
+
+            // If this is the last layer:
+            if (li == n - 1) {
+                bool wantMatch = (li & 1) == 0;
+                return (match_ == wantMatch, li);
+            }
+
+            if (!match_) {
+                bool acceptEarly = (li & 1) == 1;
+                return (acceptEarly, li);
+            }
+
+            unchecked { ++li; }
+        }
+
+        // This point should never happen.
+        revert("unreachable");
+    }
+
+    /// @notice Gas-measurable variant of `testToken`, intended for benchmarking only.
+    function measureTestTokenGas(bytes calldata token) external returns (bool, uint256) {
+      return testToken(token);
+    }
+
+    /// @notice Return metadata and full filter bytes for layer i.
+    function getLayerMetadata(uint256 i)
+    external
+    view
+    returns (
+        uint256 segmentLength_,
+        uint256 segmentCount_,
+        uint256 fingerprintBits_,
+        uint256 seed_,
+        bytes memory filter_
+    )
+    {
+        require(i < layers.length, "Invalid layer");
+        Layer storage L = layers[i];
+        return (uint256(L.segmentLength), uint256(L.segmentCount), uint256(L.fingerprintBits), uint256(L.seed), L.filter);
+    }
+
+    /* ─── Internal Helpers ─────────────────────────────────────────────────── */
+
+    /// @notice Test a single layer as a binary fuse filter, matching the off-chain BinaryFuseFilter.Test.
+    /// @param L the layer (in storage)
+    /// @param h 4×64‐bit hashes: h[3] selects the slots, h[1] is the fingerprint
+    function _testInLayer(Layer storage L, uint64[4] memory h) internal view returns (bool) {
+        uint256 count = L.segmentCount;
+        if (count == 0) {
+            return false;
+        }
+        uint256 segLen = L.segmentLength;
+        uint256 bits   = L.fingerprintBits;
+        uint256 hash   = h[3];
+        uint256 mask   = segLen - 1;
+
+        // One slot in each of three consecutive segments; the first is ⌊hash · count · segLen / 2^64⌋.
+        uint256 s0 = (hash * count * segLen) >> 64;
+        uint256 s1 = (s0 + segLen) ^ ((hash >> 18) & mask);
+        uint256 s2 = (s0 + 2 * segLen) ^ (hash & mask);
+
+        bytes storage filter = L.filter;
+        uint256 v = uint256(h[1]) & ((1 << bits) - 1);
+        v ^= _getSlot(filter, s0, bits) ^ _getSlot(filter, s1, bits) ^ _getSlot(filter, s2, bits);
+        return v == 0;
+    }
+
+    /// @notice Read slot `slot` of `bits` bits; slot i occupies bits [i·bits, (i+1)·bits) in the little‐endian bit
+    ///         order of the off-chain packing (bit j is bit j % 8 of byte j / 8).
+    function _getSlot(bytes storage filter, uint256 slot, uint256 bits) internal view returns (uint256 v) {
+        uint256 pos   = slot * bits;
+        uint256 first = pos >> 3;
+        // At most five bytes, the most significant first.
+        for (uint256 j = (pos + bits - 1) >> 3; ; ) {
+            v = (v << 8) | uint8(filter[j]);
+            if (j == first) {
+                break;
+            }
+            unchecked { --j; }
+        }
+        return (v >> (pos & 7)) & ((1 << bits) - 1);
+    }
+
+    /// @notice Extract four 64‐bit values from keccak256(token), or keccak256(token || seed) for a non-zero seed
+    ///         (8 bytes big-endian), matching the off-chain baseHashes.
+    function extractHashes(bytes calldata token, uint64 seed) internal pure returns (uint64[4] memory h) {
+        bytes32 digest = seed == 0 ? keccak256(token) : keccak256(abi.encodePacked(token, seed));
+        h[0] = uint64(uint256(digest >> 192));
+        h[1] = uint64((uint256(digest) >> 128) & 0xFFFFFFFFFFFFFFFF);
+        h[2] = uint64((uint256(digest) >>  64) & 0xFFFFFFFFFFFFFFFF);
+        h[3] = uint64(uint256(digest)          & 0xFFFFFFFFFFFFFFFF);
+    }
+}
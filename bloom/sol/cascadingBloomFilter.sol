@@ -9,6 +9,7 @@ contract CascadingBloomFilter {
     struct Layer {
         uint64  filterSizeBits;
         uint32  k;
+        uint64  seed;
         bytes   filter;
     }
 
@@ -40,15 +41,18 @@ contract CascadingBloomFilter {
     /// @param newFilters   newFilters[i] is the full packed bit‐vector for layer i
     /// @param ks           ks[i] = number of hash functions for layer i
     /// @param bitLens      bitLens[i] = number of bits used in layer i
+    /// @param seeds        seeds[i] = hash seed of layer i
     function updateCascade(
         bytes[] calldata newFilters,
         uint256[] calldata ks,
-        uint256[] calldata bitLens
+        uint256[] calldata bitLens,
+        uint256[] calldata seeds
     ) external onlyOwner {
         uint256 len = newFilters.length;
         require(len > 0,             "At least one layer");
         require(len == ks.length,    "Need k for each layer");
         require(len == bitLens.length, "Need bitLen for each layer");
+        require(len == seeds.length, "Need seed for each layer");
 
         // Wipe out existing layers (cheapest way to reset a dynamic array)
         delete layers;
@@ -58,6 +62,7 @@ contract CascadingBloomFilter {
             bytes calldata f   = newFilters[i];
             uint256      k_   = ks[i];
             uint256      bits = bitLens[i];
+            uint256      seed = seeds[i];
 
             // -- validate inputs --
             require(k_ > 0,                     "k must be > 0");
@@ -65,12 +70,14 @@ contract CascadingBloomFilter {
             require(bits <= f.length * 8,      "bitLen exceeds f.length*8");
             require(k_ <= type(uint32).max,    "k too large for uint32");
             require(bits <= type(uint64).max,  "bitLen too large for uint64");
+            require(seed <= type(uint64).max,  "seed too large for uint64");
 
-            // Pack into (uint64 filterSizeBits, uint32 k, bytes filter)
+            // Pack into (uint64 filterSizeBits, uint32 k, uint64 seed, bytes filter)
             layers.push(
                 Layer({
                     filterSizeBits: uint64(bits),
                     k:              uint32(k_),
+                    seed:           uint64(seed),
                     filter:         f
                 })
             );
@@ -79,6 +86,88 @@ contract CascadingBloomFilter {
         }
     }
 
+    /// @notice Apply a delta between two cascades as produced by the off-chain `Diff`.
+    ///         Only the listed 32-byte words are written, so the cost grows with the number of changed words.
+    ///         A layer whose byte length changes is cleared before its words are written.
+    /// @param newLayerCount number of layers after patching
+    /// @param layerIds      layerIds[i] is the index of the i-th patched layer
+    /// @param ks            ks[i] = number of hash functions for layer layerIds[i]
+    /// @param bitLens       bitLens[i] = number of bits used in layer layerIds[i]
+    /// @param seeds         seeds[i] = hash seed of layer layerIds[i]
+    /// @param wordCounts    wordCounts[i] = number of words patched in layer layerIds[i]
+    /// @param wordIndices   word indices of all patched layers, concatenated in the order of layerIds
+    /// @param words         new word contents, aligned with wordIndices
+    function patchCascade(
+        uint256 newLayerCount,
+        uint256[] calldata layerIds,
+        uint256[] calldata ks,
+        uint256[] calldata bitLens,
+        uint256[] calldata seeds,
+        uint256[] calldata wordCounts,
+        uint256[] calldata wordIndices,
+        bytes32[] calldata words
+    ) external onlyOwner {
+        uint256 n = layerIds.length;
+        require(newLayerCount > 0,          "At least one layer");
+        require(n == ks.length,             "Need k for each layer");
+        require(n == bitLens.length,        "Need bitLen for each layer");
+        require(n == seeds.length,          "Need seed for each layer");
+        require(n == wordCounts.length,     "Need wordCount for each layer");
+        require(wordIndices.length == words.length, "Need index for each word");
+
+        // Shrink or grow the cascade. New layers must be part of the patch.
+        uint256 oldLayerCount = layers.length;
+        while (layers.length > newLayerCount) {
+            layers.pop();
+        }
+        while (layers.length < newLayerCount) {
+            layers.push();
+        }
+
+        uint256 w = 0;
+        for (uint256 i = 0; i < n; ) {
+            uint256 li   = layerIds[i];
+            uint256 k_   = ks[i];
+            uint256 bits = bitLens[i];
+            uint256 seed = seeds[i];
+
+            // -- validate inputs --
+            require(li < newLayerCount,        "Invalid layer");
+            require(k_ > 0,                     "k must be > 0");
+            require(bits > 0,                   "bitLen must be > 0");
+            require(k_ <= type(uint32).max,    "k too large for uint32");
+            require(bits <= type(uint64).max,  "bitLen too large for uint64");
+            require(seed <= type(uint64).max,  "seed too large for uint64");
+
+            Layer storage L = layers[li];
+            L.filterSizeBits = uint64(bits);
+            L.k              = uint32(k_);
+            L.seed           = uint64(seed);
+
+            // Off-chain layers are packed as 64-bit words.
+            uint256 byteLen = ((bits + 63) >> 6) << 3;
+            if (L.filter.length != byteLen) {
+                L.filter = new bytes(byteLen);
+            }
+
+            uint256 end = w + wordCounts[i];
+            require(end <= words.length, "Not enough words");
+            for (; w < end; ) {
+                _writeWord(L.filter, wordIndices[w], words[w], byteLen);
+                unchecked { ++w; }
+            }
+
+            unchecked { ++i; }
+        }
+        require(w == words.length, "Unused words");
+
+        // Every appended layer must have been written by the patch.
+        for (uint256 li = oldLayerCount; li < newLayerCount; ) {
+            require(layers[li].k > 0, "New layer missing in patch");
+            unchecked { ++li; }
+        }
+    }
+
     /// @notice Return the total number of layers.
     function layerCount() external view returns (uint256) {
         return layers.length;
@@ -91,11 +180,16 @@ contract CascadingBloomFilter {
         uint256 n = layers.length;
         require(n > 0, "No layers");
 
-        // Precompute 4×64‐bit hashes once:
-        uint64[4] memory h = extractHashes(token);
+        // The 4×64‐bit hashes only change with the layer seed:
+        uint64 seed = 0;
+        uint64[4] memory h = extractHashes(token, seed);
 
         for (uint256 li = 0; li < n; ) {
             Layer storage L = layers[li];
+            if (L.seed != seed) {
+                seed = L.seed;
+                h    = extractHashes(token, seed);
+            }
             bool match_     = _testInLayer(L.filter, uint256(L.filterSizeBits), L.k, h);
 
             // If this is the last layer:
@@ -129,12 +223,13 @@ contract CascadingBloomFilter {
     returns (
         uint256 filterSizeBits_,
         uint256 k_,
+        uint256 seed_,
         bytes memory filter_
     )
     {
         require(i < layers.length, "Invalid layer");
         Layer storage L = layers[i];
-        return (uint256(L.filterSizeBits), uint256(L.k), L.filter);
+        return (uint256(L.filterSizeBits), uint256(L.k), uint256(L.seed), L.filter);
     }
 
     /* ─── Internal Helpers ─────────────────────────────────────────────────── */
@@ -170,9 +265,37 @@ contract CascadingBloomFilter {
         return true;
     }
 
-    /// @notice Extract four 64‐bit values from keccak256(token)
-    function extractHashes(bytes calldata token) internal pure returns (uint64[4] memory h) {
-        bytes32 digest = keccak256(token);
+    /// @notice Overwrite the 32-byte word `index` of a storage byte array of length `byteLen`.
+    /// @dev    Long byte arrays (> 31 bytes) keep their data at keccak256(slot), one word per slot, so a
+    ///         word maps to exactly one SSTORE. Short arrays are written byte by byte.
+    function _writeWord(
+        bytes storage filter,
+        uint256 index,
+        bytes32 word,
+        uint256 byteLen
+    ) internal {
+        uint256 start = index << 5;
+        require(start < byteLen, "Word out of range");
+
+        if (byteLen > 31) {
+            // Bytes beyond byteLen in the last word are zero (padding of the off-chain diff).
+            assembly {
+                mstore(0, filter.slot)
+                sstore(add(keccak256(0, 32), index), word)
+            }
+            return;
+        }
+
+        for (uint256 j = 0; j < byteLen; ) {
+            filter[j] = word[j];
+            unchecked { ++j; }
+        }
+    }
+
+    /// @notice Extract four 64‐bit values from keccak256(token), or keccak256(token || seed) for a non-zero seed
+    ///         (8 bytes big-endian), matching the off-chain baseHashes.
+    function extractHashes(bytes calldata token, uint64 seed) internal pure returns (uint64[4] memory h) {
+        bytes32 digest = seed == 0 ? keccak256(token) : keccak256(abi.encodePacked(token, seed));
         h[0] = uint64(uint256(digest >> 192));
         h[1] = uint64((uint256(digest) >> 128) & 0xFFFFFFFFFFFFFFFF);
         h[2] = uint64((uint256(digest) >>  64) & 0xFFFFFFFFFFFFFFFF);
@@ -8,11 +8,14 @@ import (
 	"fmt"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/stretchr/testify/require"
 	"math/big"
+	"os/exec"
 	"sort"
+	"strings"
 	"testing"
 	"time"
 )
@@ -79,6 +82,19 @@ func TestCompileAndGenBindings(t *testing.T) {
 }
 */
 
+// requireCurrentBytecode makes DeployBloom deploy the current cascadingBloomFilter.sol. If the shipped bytecode
+// predates layer seeds in updateCascade (0x8b56bd3b) or patchCascade (0x9ade5fa8), the source is compiled with solc
+// instead, and the test skips without solc until the bindings are regenerated with TestCompileAndGenBindings.
+func requireCurrentBytecode(tb testing.TB) {
+	if strings.Contains(onchain.BloomBin, "638b56bd3b") && strings.Contains(onchain.BloomBin, "639ade5fa8") {
+		return
+	}
+	if _, err := exec.LookPath("solc"); err != nil {
+		tb.Skip("shipped bytecode predates layer seeds and patchCascade and solc is not installed; regenerate the bindings with TestCompileAndGenBindings")
+	}
+	onchain.BloomBin = common.Bytes2Hex(compileWithSolc(tb, "cascadingBloomFilter.sol")["CascadingBloomFilter"].bin)
+}
+
 func TestDeploy(t *testing.T) {
 	// 1) Set up a simulated backend and a funded transactor
 	privKey, err := crypto.GenerateKey()
@@ -109,6 +125,8 @@ func TestDeploy(t *testing.T) {
 }
 
 func TestOnChainFilterSerialization(t *testing.T) {
+	requireCurrentBytecode(t)
+
 	privKey, err := crypto.GenerateKey()
 	require.NoError(t, err)
 	auth, err := bind.NewKeyedTransactorWithChainID(privKey, big.NewInt(1337))
@@ -132,8 +150,8 @@ func TestOnChainFilterSerialization(t *testing.T) {
 	require.NoError(t, err)
 
 	// Off-chain → On-chain
-	onChainFilters, numHf, bitLens := cascade.GetOnChainFilter()
-	_, err = contract.UpdateCascade(auth, onChainFilters, numHf, bitLens)
+	onChainFilters, numHf, bitLens, seeds := cascade.GetOnChainFilter()
+	_, err = contract.UpdateCascade(auth, onChainFilters, numHf, bitLens, seeds)
 	require.NoError(t, err)
 	sim.Commit()
 
@@ -148,11 +166,14 @@ func TestOnChainFilterSerialization(t *testing.T) {
 
 		require.Equal(t, uint64(expectedBitLen), debug.FilterSizeBits.Uint64(), "layer %d: bitLen mismatch", i)
 		require.Equal(t, uint64(expectedK), debug.K.Uint64(), "layer %d: k mismatch", i)
+		require.Equal(t, seeds[i].Uint64(), debug.Seed.Uint64(), "layer %d: seed mismatch", i)
 		require.Equal(t, expectedFilter, debug.Filter, "layer %d: filter bytes mismatch", i)
 	}
 }
 
 func TestUpdate(t *testing.T) {
+	requireCurrentBytecode(t)
+
 	privKey, err := crypto.GenerateKey()
 	require.NoError(t, err)
 	auth, err := bind.NewKeyedTransactorWithChainID(privKey, big.NewInt(1337))
@@ -180,11 +201,11 @@ func TestUpdate(t *testing.T) {
 	err = cascade.Update(revoked, valid)
 	require.NoError(t, err, "failed to update cascade with test data")
 
-	onChainFilter, numHf, bitlen := cascade.GetOnChainFilter()
+	onChainFilter, numHf, bitlen, seeds := cascade.GetOnChainFilter()
 	require.NotNil(t, onChainFilter, "expected on-chain filter to be non-nil")
 	require.NotNil(t, numHf, "expected number of hash functions to be non-nil")
 
-	tx, err = contract.UpdateCascade(auth, onChainFilter, numHf, bitlen)
+	tx, err = contract.UpdateCascade(auth, onChainFilter, numHf, bitlen, seeds)
 	require.NoError(t, err, "failed to update on-chain filter")
 	sim.Commit()
 
@@ -214,7 +235,76 @@ func TestUpdate(t *testing.T) {
 
 }
 
+func TestPatchCascade(t *testing.T) {
+	requireCurrentBytecode(t)
+
+	privKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	auth, err := bind.NewKeyedTransactorWithChainID(privKey, big.NewInt(1337))
+	require.NoError(t, err)
+
+	alloc := core.GenesisAlloc{
+		auth.From: {Balance: big.NewInt(1_000_000_000_000_000_000)}, // 1 ETH
+	}
+	sim := backends.NewSimulatedBackend(alloc, 3_000_000_000)
+
+	_, _, contract, err := onchain.DeployBloom(auth, sim)
+	require.NoError(t, err, "deployment failed")
+	sim.Commit()
+
+	domain := 10_000
+	capacity := 1_000
+
+	valid, revoked := genRevocationTokens(domain, capacity-10)
+	from := bloom.NewCascade(domain, capacity)
+	require.NoError(t, from.Update(revoked, valid))
+	revoked = append(revoked, valid[:10]...)
+	valid = valid[10:]
+	to := bloom.NewCascade(domain, capacity)
+	require.NoError(t, to.Update(revoked, valid))
+
+	filters, numHf, bitLens, seeds := from.GetOnChainFilter()
+	tx, err := contract.UpdateCascade(auth, filters, numHf, bitLens, seeds)
+	require.NoError(t, err)
+	sim.Commit()
+	receipt, err := sim.TransactionReceipt(context.Background(), tx.Hash())
+	require.NoError(t, err)
+	updateGas := receipt.GasUsed
+
+	patch, err := bloom.Diff(from, to)
+	require.NoError(t, err)
+	layerCount, layers, ks, patchBitLens, patchSeeds, wordCounts, wordIndices, words := patch.GetOnChainPatch()
+	tx, err = contract.PatchCascade(auth, layerCount, layers, ks, patchBitLens, patchSeeds, wordCounts, wordIndices, words)
+	require.NoError(t, err)
+	sim.Commit()
+	receipt, err = sim.TransactionReceipt(context.Background(), tx.Hash())
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), receipt.Status, "patch reverted")
+	t.Logf("updateCascade gas: %d, patchCascade gas: %d (%d words)", updateGas, receipt.GasUsed, patch.ChangedWords())
+
+	// The patched on-chain cascade equals the new cascade.
+	expected, expectedK, expectedBitLens, expectedSeeds := to.GetOnChainFilter()
+	lc, err := contract.LayerCount(&bind.CallOpts{})
+	require.NoError(t, err)
+	require.Equal(t, int64(len(expected)), lc.Int64())
+	for i := range expected {
+		layer, err := contract.GetLayerMetadata(&bind.CallOpts{}, big.NewInt(int64(i)))
+		require.NoError(t, err)
+		require.Equal(t, expectedBitLens[i].Uint64(), layer.FilterSizeBits.Uint64(), "layer %d: bitLen mismatch", i)
+		require.Equal(t, expectedK[i].Uint64(), layer.K.Uint64(), "layer %d: k mismatch", i)
+		require.Equal(t, expectedSeeds[i].Uint64(), layer.Seed.Uint64(), "layer %d: seed mismatch", i)
+		require.Equal(t, expected[i], layer.Filter, "layer %d: filter bytes mismatch", i)
+	}
+	for _, tok := range revoked[len(revoked)-10:] {
+		res, _, err := contract.TestToken(&bind.CallOpts{}, tok)
+		require.NoError(t, err)
+		require.True(t, res, "newly revoked token not detected as revoked")
+	}
+}
+
 func BenchmarkTestTokenByLayer(b *testing.B) {
+	requireCurrentBytecode(b)
+
 	const domain = 100_000
 	const capacity = 10_000
 
@@ -238,8 +328,8 @@ func BenchmarkTestTokenByLayer(b *testing.B) {
 	err = cascade.Update(revoked, valid)
 	require.NoError(b, err)
 
-	onChainFilters, numHf, bitLens := cascade.GetOnChainFilter()
-	_, err = contract.UpdateCascade(auth, onChainFilters, numHf, bitLens)
+	onChainFilters, numHf, bitLens, seeds := cascade.GetOnChainFilter()
+	_, err = contract.UpdateCascade(auth, onChainFilters, numHf, bitLens, seeds)
 	require.NoError(b, err)
 	sim.Commit()
 
@@ -281,6 +371,8 @@ func BenchmarkTestTokenByLayer(b *testing.B) {
 
 // BenchmarkUpdateCascade benchmarks the gas consumption during the update process of an on-chain Bloom filter cascade.
 func BenchmarkUpdateCascade(b *testing.B) {
+	requireCurrentBytecode(b)
+
 	configs := []struct {
 		name     string
 		domain   int
@@ -361,9 +453,9 @@ func runUpdateCascadeBenchmark(domain, capacity int) (uint64, uint64, error) {
 	if err := cascade1.Update(revoked1, valid1); err != nil {
 		return 0, 0, err
 	}
-	onChainFilter1, numHf1, bitlen1 := cascade1.GetOnChainFilter()
+	onChainFilter1, numHf1, bitlen1, seeds1 := cascade1.GetOnChainFilter()
 
-	tx1, err := contract.UpdateCascade(auth, onChainFilter1, numHf1, bitlen1)
+	tx1, err := contract.UpdateCascade(auth, onChainFilter1, numHf1, bitlen1, seeds1)
 	if err != nil {
 		return 0, 0, err
 	}
@@ -380,9 +472,9 @@ func runUpdateCascadeBenchmark(domain, capacity int) (uint64, uint64, error) {
 	if err := cascade2.Update(revoked2, valid2); err != nil {
 		return 0, 0, err
 	}
-	onChainFilter2, numHf2, bitlen2 := cascade2.GetOnChainFilter()
+	onChainFilter2, numHf2, bitlen2, seeds2 := cascade2.GetOnChainFilter()
 
-	tx2, err := contract.UpdateCascade(auth, onChainFilter2, numHf2, bitlen2)
+	tx2, err := contract.UpdateCascade(auth, onChainFilter2, numHf2, bitlen2, seeds2)
 	if err != nil {
 		return 0, 0, err
 	}
@@ -0,0 +1,199 @@
+package bloom
+
+import (
+	"PrivacyPreservingRevocationCode/bloom"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// solcContract is a contract compiled by solc.
+type solcContract struct {
+	abi abi.ABI
+	bin []byte
+}
+
+// compileWithSolc compiles the given contracts of this directory and returns them by name. No bindings are shipped
+// for CascadingBinaryFuseFilter, so it skips if solc is not installed.
+func compileWithSolc(tb testing.TB, files ...string) map[string]solcContract {
+	solc, err := exec.LookPath("solc")
+	if err != nil {
+		tb.Skip("solc is not installed")
+	}
+	args := append([]string{"--combined-json", "abi,bin", "--evm-version", "istanbul", "--via-ir"}, files...)
+	out, err := exec.Command(solc, args...).Output()
+	require.NoError(tb, err, "solc failed")
+
+	var combined struct {
+		Contracts map[string]struct {
+			Abi json.RawMessage `json:"abi"`
+			Bin string          `json:"bin"`
+		} `json:"contracts"`
+	}
+	require.NoError(tb, json.Unmarshal(out, &combined))
+
+	contracts := make(map[string]solcContract)
+	for id, c := range combined.Contracts {
+		// Older solc versions encode the ABI as a JSON string.
+		abiJSON := string(c.Abi)
+		var s string
+		if json.Unmarshal(c.Abi, &s) == nil {
+			abiJSON = s
+		}
+		parsed, err := abi.JSON(strings.NewReader(abiJSON))
+		require.NoError(tb, err)
+		contracts[id[strings.LastIndex(id, ":")+1:]] = solcContract{abi: parsed, bin: common.FromHex(c.Bin)}
+	}
+	return contracts
+}
+
+// cascadeDeployment is a compiled cascade contract on a simulated chain.
+type cascadeDeployment struct {
+	sim      *backends.SimulatedBackend
+	auth     *bind.TransactOpts
+	contract *bind.BoundContract
+}
+
+// deployCascadeContract deploys the compiled contract name.
+func deployCascadeContract(tb testing.TB, contracts map[string]solcContract, name string) *cascadeDeployment {
+	key, err := crypto.GenerateKey()
+	require.NoError(tb, err)
+	auth, err := bind.NewKeyedTransactorWithChainID(key, big.NewInt(1337))
+	require.NoError(tb, err)
+	sim := backends.NewSimulatedBackend(core.GenesisAlloc{auth.From: {Balance: big.NewInt(1_000_000_000_000_000_000)}}, 30_000_000_000)
+
+	c, ok := contracts[name]
+	require.True(tb, ok, "contract %s was not compiled", name)
+	_, _, contract, err := bind.DeployContract(auth, c.abi, c.bin, sim)
+	require.NoError(tb, err)
+	sim.Commit()
+	return &cascadeDeployment{sim: sim, auth: auth, contract: contract}
+}
+
+// update publishes cascade with updateCascade and returns the gas used and the size of the calldata.
+func (d *cascadeDeployment) update(tb testing.TB, cascade *bloom.BloomFilterCascade) (gas uint64, calldata int) {
+	var params []interface{}
+	switch cascade.LayerKind() {
+	case bloom.BloomLayers:
+		filters, numHf, bitLens, seeds := cascade.GetOnChainFilter()
+		params = []interface{}{filters, numHf, bitLens, seeds}
+	case bloom.BinaryFuseLayers:
+		filters, fingerprintBits, segmentLengths, segmentCounts, seeds := cascade.GetOnChainFuseFilter()
+		params = []interface{}{filters, fingerprintBits, segmentLengths, segmentCounts, seeds}
+	}
+	tx, err := d.contract.Transact(d.auth, "updateCascade", params...)
+	require.NoError(tb, err)
+	d.sim.Commit()
+	receipt, err := d.sim.TransactionReceipt(context.Background(), tx.Hash())
+	require.NoError(tb, err)
+	require.Equal(tb, uint64(1), receipt.Status, "updateCascade reverted")
+	return receipt.GasUsed, len(tx.Data())
+}
+
+// testToken calls testToken of the contract.
+func (d *cascadeDeployment) testToken(tb testing.TB, token []byte) (bool, int) {
+	var out []interface{}
+	require.NoError(tb, d.contract.Call(&bind.CallOpts{}, &out, "testToken", token))
+	return out[0].(bool), int(out[1].(*big.Int).Int64())
+}
+
+// testTokenGas returns the gas used by measureTestTokenGas for token.
+func (d *cascadeDeployment) testTokenGas(tb testing.TB, token []byte) uint64 {
+	tx, err := d.contract.Transact(d.auth, "measureTestTokenGas", token)
+	require.NoError(tb, err)
+	d.sim.Commit()
+	receipt, err := d.sim.TransactionReceipt(context.Background(), tx.Hash())
+	require.NoError(tb, err)
+	return receipt.GasUsed
+}
+
+func TestBinaryFuseCascade(t *testing.T) {
+	contracts := compileWithSolc(t, "cascadingBinaryFuseFilter.sol")
+	d := deployCascadeContract(t, contracts, "CascadingBinaryFuseFilter")
+
+	domain := 10_000
+	capacity := 1_000
+	valid, revoked := genRevocationTokens(domain, capacity)
+	cascade, err := bloom.NewCascadeWithLayers(domain, capacity, bloom.BinaryFuseLayers, bloom.Keccak256)
+	require.NoError(t, err)
+	require.NoError(t, cascade.Update(revoked, valid))
+	d.update(t, cascade)
+
+	filters, fingerprintBits, segmentLengths, segmentCounts, seeds := cascade.GetOnChainFuseFilter()
+	for i := range filters {
+		var out []interface{}
+		require.NoError(t, d.contract.Call(&bind.CallOpts{}, &out, "getLayerMetadata", big.NewInt(int64(i))))
+		require.Equal(t, segmentLengths[i].Uint64(), out[0].(*big.Int).Uint64(), "layer %d: segment length mismatch", i)
+		require.Equal(t, segmentCounts[i].Uint64(), out[1].(*big.Int).Uint64(), "layer %d: segment count mismatch", i)
+		require.Equal(t, fingerprintBits[i].Uint64(), out[2].(*big.Int).Uint64(), "layer %d: fingerprint bits mismatch", i)
+		require.Equal(t, seeds[i].Uint64(), out[3].(*big.Int).Uint64(), "layer %d: seed mismatch", i)
+		require.Equal(t, filters[i], out[4].([]byte), "layer %d: filter bytes mismatch", i)
+	}
+
+	for _, tokens := range [][][]byte{valid, revoked} {
+		for i, tok := range tokens {
+			expected, expectedLayer := cascade.Test(tok)
+			res, layer := d.testToken(t, tok)
+			require.Equal(t, expected, res, "token %d", i)
+			require.Equal(t, expectedLayer, layer, "token %d", i)
+		}
+	}
+}
+
+// BenchmarkLayerKinds compares the gas and calldata of updateCascade, and the average gas of testToken for revoked
+// tokens, of the CascadingBloomFilter and CascadingBinaryFuseFilter contracts on the same revocation lists.
+func BenchmarkLayerKinds(b *testing.B) {
+	contracts := compileWithSolc(b, "cascadingBloomFilter.sol", "cascadingBinaryFuseFilter.sol")
+	contractNames := map[bloom.LayerKind]string{
+		bloom.BloomLayers:      "CascadingBloomFilter",
+		bloom.BinaryFuseLayers: "CascadingBinaryFuseFilter",
+	}
+
+	configs := []struct {
+		domain   int
+		capacity int
+	}{
+		{10_000, 1_000},
+		{100_000, 5_000},
+		{100_000, 10_000},
+		{1_000_000, 50_000},
+		{1_000_000, 100_000},
+	}
+
+	fmt.Println("| Domain   | Capacity | Layers      | Layers | Bits    | Calldata | updateCascade Gas | Avg testToken Gas |")
+	fmt.Println("|----------|----------|-------------|--------|---------|----------|-------------------|-------------------|")
+	for _, cfg := range configs {
+		valid, revoked := genRevocationTokens(cfg.domain, cfg.capacity)
+		for _, kind := range []bloom.LayerKind{bloom.BloomLayers, bloom.BinaryFuseLayers} {
+			cascade, err := bloom.NewCascadeWithLayers(cfg.domain, cfg.capacity, kind, bloom.Keccak256)
+			require.NoError(b, err)
+			require.NoError(b, cascade.Update(revoked, valid))
+			var bits uint
+			for _, l := range cascade.Layers() {
+				bits += l.BitLen()
+			}
+
+			d := deployCascadeContract(b, contracts, contractNames[kind])
+			gas, calldata := d.update(b, cascade)
+			var testGas uint64
+			samples := revoked[:100]
+			for _, tok := range samples {
+				testGas += d.testTokenGas(b, tok)
+			}
+
+			fmt.Printf("| %8d | %8d | %-11s | %6d | %7d | %8d | %17d | %17d |\n", cfg.domain, cfg.capacity, kind,
+				len(cascade.Layers()), bits, calldata, gas, testGas/uint64(len(samples)))
+		}
+	}
+}
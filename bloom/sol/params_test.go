@@ -0,0 +1,53 @@
+package bloom
+
+import (
+	"PrivacyPreservingRevocationCode/bloom"
+	"fmt"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+// BenchmarkCascadeParams compares the predicted gas and calldata of updateCascade, and the maximum gas of testToken,
+// of the cascade parameter presets with those measured on the contracts.
+func BenchmarkCascadeParams(b *testing.B) {
+	contracts := compileWithSolc(b, "cascadingBloomFilter.sol", "cascadingBinaryFuseFilter.sol")
+	contractNames := map[bloom.LayerKind]string{
+		bloom.BloomLayers:      "CascadingBloomFilter",
+		bloom.BinaryFuseLayers: "CascadingBinaryFuseFilter",
+	}
+	presets := []struct {
+		name   string
+		params bloom.CascadeParams
+	}{
+		{"default", bloom.DefaultCascadeParams},
+		{"min-bits", bloom.MinBitsCascadeParams},
+		{"min-layers", bloom.MinLayersCascadeParams},
+		{"min-calldata", bloom.MinCalldataCascadeParams},
+	}
+
+	fmt.Println("| Domain   | Capacity | Layers      | Preset       | Layers | Calldata (pred.) | updateCascade Gas (pred.) | Max testToken Gas (pred.) |")
+	fmt.Println("|----------|----------|-------------|--------------|--------|------------------|---------------------------|---------------------------|")
+	for _, cfg := range []struct{ domain, capacity int }{{10_000, 1_000}, {100_000, 5_000}, {1_000_000, 50_000}} {
+		valid, revoked := genRevocationTokens(cfg.domain, cfg.capacity)
+		for _, kind := range []bloom.LayerKind{bloom.BloomLayers, bloom.BinaryFuseLayers} {
+			for _, preset := range presets {
+				pred, err := bloom.PredictCascade(cfg.domain, cfg.capacity, kind, preset.params)
+				require.NoError(b, err)
+				cascade, err := bloom.NewCascadeWithParams(cfg.domain, cfg.capacity, kind, bloom.Keccak256, preset.params)
+				require.NoError(b, err)
+				require.NoError(b, cascade.Update(revoked, valid))
+
+				d := deployCascadeContract(b, contracts, contractNames[kind])
+				gas, calldata := d.update(b, cascade)
+				var maxTestGas uint64
+				for _, tok := range append(revoked[:100:100], valid[:100]...) {
+					maxTestGas = max(maxTestGas, d.testTokenGas(b, tok))
+				}
+
+				fmt.Printf("| %8d | %8d | %-11s | %-12s | %6d | %7d (%7d) | %11d (%11d) | %11d (%11d) |\n",
+					cfg.domain, cfg.capacity, kind, preset.name, len(cascade.Layers()), calldata, pred.CalldataBytes,
+					gas, pred.UpdateGas, maxTestGas, pred.TestTokenGas)
+			}
+		}
+	}
+}
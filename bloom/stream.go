@@ -0,0 +1,151 @@
+package bloom
+
+import (
+	"fmt"
+	"iter"
+)
+
+// TokenSource yields the tokens of a set together with an error that aborts the iteration.
+// A source passed to UpdateFromSources must be re-iterable: every iteration has to yield the same tokens, in any
+// order, since the cascade is built in several passes. A token is only used until the builder asks for the next one,
+// so synchronous sources may reuse a buffer; the builder copies the tokens it retains.
+type TokenSource = iter.Seq2[[]byte, error]
+
+// SliceSource returns a TokenSource over tokens held in memory.
+func SliceSource(tokens [][]byte) TokenSource {
+	return func(yield func([]byte, error) bool) {
+		for _, t := range tokens {
+			if !yield(t, nil) {
+				return
+			}
+		}
+	}
+}
+
+// ChannelSource returns a TokenSource that calls open at the start of every iteration and yields the tokens
+// received until the channel is closed. If the iteration stops early, stop is closed so the producer can quit;
+// producers must select on it when sending.
+func ChannelSource(open func(stop <-chan struct{}) <-chan []byte) TokenSource {
+	return func(yield func([]byte, error) bool) {
+		stop := make(chan struct{})
+		defer close(stop)
+		for t := range open(stop) {
+			if !yield(t, nil) {
+				return
+			}
+		}
+	}
+}
+
+// UpdateFromSources constructs the cascade like Update, but reads positives and negatives from re-iterable sources
+// instead of slices. Positives are read twice and negatives once; only the false positives of each layer are held
+// in memory, and for binary fuse layers the positives, from which the first layer is constructed. The result is
+// identical to Update on the same sets. If the construction fails, e.g. because the positives exceed the capacity or
+// a source yields an error, the cascade is left empty like after a failed Update.
+func (c *BloomFilterCascade) UpdateFromSources(positives, negatives TokenSource) error {
+	err := c.updateFromSources(positives, negatives)
+	if err != nil {
+		c.reset()
+	}
+	return err
+}
+
+// updateFromSources constructs the cascade for UpdateFromSources, which resets it if an error leaves it partially
+// constructed.
+func (c *BloomFilterCascade) updateFromSources(positives, negatives TokenSource) error {
+	c.reset()
+
+	// Layer 0: insert actual positives
+	err := c.addFirstLayer(positives)
+	if err != nil {
+		return err
+	}
+
+	// Find false positives at layer 0
+	falsePositives, err := collectMatches(negatives, c.filters[0])
+	if err != nil {
+		return err
+	}
+	if len(falsePositives) == 0 {
+		return nil
+	}
+
+	// Layer 1: insert false positives
+	err = c.addNextLayer(&falsePositives, c.params.SuccRate, 1)
+	if err != nil {
+		return err
+	}
+
+	// Layer 1 is tested against all positives, which requires a second pass over the source
+	nextFalsePositives, err := collectMatches(positives, c.filters[1])
+	if err != nil {
+		return err
+	}
+	return c.addSucceedingLayers(falsePositives, nextFalsePositives, 1)
+}
+
+// addSucceedingLayers generates the layers from layer 2 on. prevPrevFalsePositives are the false positives of
+// layer 0 inserted into layer 1, prevFalsePositives the false positives of layer 1 and the input of layer 2.
+// Insertion and false positive tests are spread across the given number of workers.
+func (c *BloomFilterCascade) addSucceedingLayers(prevPrevFalsePositives, prevFalsePositives [][]byte, workers int) error {
+	layer := 1
+	for {
+		if len(prevFalsePositives) == 0 {
+			return nil
+		}
+		// Small layers use the tail rate to ensure termination
+		err := c.addNextLayer(&prevFalsePositives, c.params.layerRate(len(prevFalsePositives)), workers)
+		if err != nil {
+			return err
+		}
+
+		layer++
+		if layer > 100 {
+			return fmt.Errorf("over 100 layers. bloom filter cascade too deep — probably cyclic data")
+		}
+
+		nextFalsePositives := testAll(prevPrevFalsePositives, c.filters[layer], workers)
+		prevPrevFalsePositives = prevFalsePositives
+		prevFalsePositives = nextFalsePositives
+	}
+}
+
+// addFirstLayer inserts the positives into layer 0, which must be empty. Bloom layers are filled while reading the
+// source; binary fuse layers are constructed from the whole set, so the positives are collected first.
+func (c *BloomFilterCascade) addFirstLayer(positives TokenSource) error {
+	first, isBloom := c.filters[0].(*BloomFilter)
+	var collected [][]byte
+	count := 0
+	for p, err := range positives {
+		if err != nil {
+			return err
+		}
+		count++
+		if count > c.capacity {
+			return fmt.Errorf("bloom filter capacity exceeded")
+		}
+		if isBloom {
+			first.Add(p)
+		} else {
+			collected = append(collected, append([]byte(nil), p...))
+		}
+	}
+	if isBloom {
+		return nil
+	}
+	return c.setFirstLayer(collected, 1)
+}
+
+// collectMatches returns copies of the tokens of source that match f.
+func collectMatches(source TokenSource, f Layer) ([][]byte, error) {
+	var matches [][]byte
+	for t, err := range source {
+		if err != nil {
+			return nil, err
+		}
+		if f.Test(t) {
+			matches = append(matches, append([]byte(nil), t...))
+		}
+	}
+	return matches, nil
+}
@@ -0,0 +1,90 @@
+package bloom
+
+import (
+	"errors"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestStream_MatchesUpdate(t *testing.T) {
+	for _, cfg := range []struct{ domain, capacity int }{{1_000, 50}, {10_000, 500}, {20_000, 10_000}} {
+		valid, revoked := genRevocationTokens(cfg.domain, cfg.capacity)
+
+		expected := NewCascade(cfg.domain, cfg.capacity)
+		require.NoError(t, expected.Update(revoked, valid))
+
+		streamed := NewCascade(cfg.domain, cfg.capacity)
+		require.NoError(t, streamed.UpdateFromSources(SliceSource(revoked), SliceSource(valid)))
+		require.True(t, expected.Equal(streamed), "domain %d, capacity %d", cfg.domain, cfg.capacity)
+	}
+}
+
+func TestStream_Sources(t *testing.T) {
+	domain, capacity := 10_000, 500
+	valid, revoked := genRevocationTokens(domain, capacity)
+
+	expected := NewCascade(domain, capacity)
+	require.NoError(t, expected.Update(revoked, valid))
+
+	// A synchronous source reusing a single buffer for all tokens, the builder has to copy what it retains.
+	reusingSource := func(tokens [][]byte) TokenSource {
+		return func(yield func([]byte, error) bool) {
+			buf := make([]byte, 16)
+			for _, token := range tokens {
+				copy(buf, token)
+				if !yield(buf, nil) {
+					return
+				}
+			}
+		}
+	}
+	streamed := NewCascade(domain, capacity)
+	require.NoError(t, streamed.UpdateFromSources(reusingSource(revoked), reusingSource(valid)))
+	require.True(t, expected.Equal(streamed))
+
+	channelSource := func(tokens [][]byte) TokenSource {
+		return ChannelSource(func(stop <-chan struct{}) <-chan []byte {
+			ch := make(chan []byte)
+			go func() {
+				defer close(ch)
+				for _, token := range tokens {
+					select {
+					case ch <- token:
+					case <-stop:
+						return
+					}
+				}
+			}()
+			return ch
+		})
+	}
+	streamed = NewCascade(domain, capacity)
+	require.NoError(t, streamed.UpdateFromSources(channelSource(revoked), channelSource(valid)))
+	require.True(t, expected.Equal(streamed))
+}
+
+func TestStream_Errors(t *testing.T) {
+	valid, revoked := genRevocationTokens(1_000, 50)
+
+	cascade := NewCascade(1_000, 40)
+	err := cascade.UpdateFromSources(SliceSource(revoked), SliceSource(valid))
+	require.ErrorContains(t, err, "capacity exceeded")
+	// The tokens inserted before the capacity was exceeded are discarded.
+	require.True(t, cascade.Equal(NewCascade(1_000, 40)))
+	accepted, _ := cascade.Test(revoked[0])
+	require.False(t, accepted)
+
+	errSource := errors.New("source failed")
+	failing := func(yield func([]byte, error) bool) {
+		if !yield(valid[0], nil) {
+			return
+		}
+		yield(nil, errSource)
+	}
+	cascade = NewCascade(1_000, 50)
+	require.NoError(t, cascade.Update(revoked, valid))
+	require.ErrorIs(t, cascade.UpdateFromSources(SliceSource(revoked), failing), errSource)
+	require.True(t, cascade.Equal(NewCascade(1_000, 50)))
+	require.ErrorIs(t, cascade.UpdateFromSources(failing, SliceSource(valid)), errSource)
+	require.True(t, cascade.Equal(NewCascade(1_000, 50)))
+}
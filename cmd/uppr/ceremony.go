@@ -0,0 +1,185 @@
+package main
+
+import (
+	"PrivacyPreservingRevocationCode/zkp/ceremony"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"os"
+)
+
+// runCeremony executes a ceremony command.
+func runCeremony(cmd string, args []string) error {
+	switch cmd {
+	case "init":
+		return ceremonyInit(args)
+	case "contribute":
+		return ceremonyContribute(args)
+	case "accept":
+		return ceremonyAccept(args)
+	case "verify":
+		return ceremonyVerify(args)
+	case "finalize":
+		return ceremonyFinalize(args)
+	default:
+		return fmt.Errorf("unknown ceremony command %q", cmd)
+	}
+}
+
+// ceremonyInit initializes a phase-2 ceremony for the revocation circuit from a phase-1 result.
+func ceremonyInit(args []string) error {
+	fs := flag.NewFlagSet("ceremony init", flag.ContinueOnError)
+	dir := fs.String("dir", "ceremony", "ceremony directory")
+	phase1Path := fs.String("phase1", "", "phase-1 result of 2^14 powers of tau in gnark's encoding")
+	err := parseFlags(fs, args, false)
+	if err != nil {
+		return err
+	}
+	if *phase1Path == "" {
+		return errors.New("--phase1 is required")
+	}
+	f, err := os.Open(*phase1Path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = ceremony.Init(*dir, f)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("initialized ceremony in %s\n", *dir)
+	return nil
+}
+
+// ceremonyContribute contributes to a phase-2 state and prints the hash of the contribution.
+func ceremonyContribute(args []string) error {
+	fs := flag.NewFlagSet("ceremony contribute", flag.ContinueOnError)
+	in := fs.String("in", "", "latest phase-2 state of the ceremony")
+	out := fs.String("out", "", "file to write the contribution to")
+	err := parseFlags(fs, args, false)
+	if err != nil {
+		return err
+	}
+	if *in == "" || *out == "" {
+		return errors.New("--in and --out are required")
+	}
+	inFile, err := os.Open(*in)
+	if err != nil {
+		return err
+	}
+	defer inFile.Close()
+	outFile, err := os.OpenFile(*out, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return err
+	}
+	defer outFile.Close()
+	hash, err := ceremony.Contribute(inFile, outFile)
+	if err != nil {
+		return err
+	}
+	err = outFile.Close()
+	if err != nil {
+		return err
+	}
+	fmt.Println(hexutil.Encode(hash))
+	return nil
+}
+
+// ceremonyAccept verifies a contribution and adds it to the ceremony.
+func ceremonyAccept(args []string) error {
+	fs := flag.NewFlagSet("ceremony accept", flag.ContinueOnError)
+	dir := fs.String("dir", "ceremony", "ceremony directory")
+	contribution := fs.String("contribution", "", "contribution written by 'uppr ceremony contribute'")
+	err := parseFlags(fs, args, false)
+	if err != nil {
+		return err
+	}
+	if *contribution == "" {
+		return errors.New("--contribution is required")
+	}
+	c, err := ceremony.Open(*dir)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(*contribution)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	hash, err := c.Accept(f)
+	if err != nil {
+		return err
+	}
+	latest, err := c.Latest()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("accepted %s as %s\n", hexutil.Encode(hash), latest)
+	return nil
+}
+
+// ceremonyVerify verifies the whole ceremony and prints the hashes of its contributions.
+func ceremonyVerify(args []string) error {
+	fs := flag.NewFlagSet("ceremony verify", flag.ContinueOnError)
+	dir := fs.String("dir", "ceremony", "ceremony directory")
+	err := parseFlags(fs, args, false)
+	if err != nil {
+		return err
+	}
+	c, err := ceremony.Open(*dir)
+	if err != nil {
+		return err
+	}
+	hashes, err := c.Verify()
+	if err != nil {
+		return err
+	}
+	for i, hash := range hashes {
+		fmt.Printf("%d %s\n", i+1, hexutil.Encode(hash))
+	}
+	return nil
+}
+
+// ceremonyFinalize writes the keys and the Solidity verifier of the ceremony and prints its transcript hash.
+func ceremonyFinalize(args []string) error {
+	fs := flag.NewFlagSet("ceremony finalize", flag.ContinueOnError)
+	dir := fs.String("dir", "ceremony", "ceremony directory")
+	pkPath := fs.String("pk", "verifier.g16.pk", "file to write the Groth16 proving key to")
+	vkPath := fs.String("vk", "verifier.g16.vk", "file to write the Groth16 verifying key to")
+	solPath := fs.String("sol", "revocationTokenVerifier.sol", "file to write the Solidity verifier to")
+	err := parseFlags(fs, args, false)
+	if err != nil {
+		return err
+	}
+	c, err := ceremony.Open(*dir)
+	if err != nil {
+		return err
+	}
+
+	var files []*os.File
+	defer func() {
+		for _, f := range files {
+			f.Close()
+		}
+	}()
+	for _, path := range []string{*pkPath, *vkPath, *solPath} {
+		f, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		files = append(files, f)
+	}
+	transcript, err := c.Finalize(files[0], files[1], files[2])
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		err = f.Close()
+		if err != nil {
+			return err
+		}
+	}
+	fmt.Println(hexutil.Encode(transcript))
+	return nil
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"PrivacyPreservingRevocationCode/epoch"
+	"PrivacyPreservingRevocationCode/issuer"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// presentation is the file format written by holder prove and read by verifier check. Presentations of both types
+// carry the verifier's nonce they are bound to. OneShow presentations carry the VRF public key, proof, credential
+// signature, the holder's signature binding them to the nonce and the parameters of checkCredentialFast; MultiShow
+// presentations carry the Groth16 proof and its public inputs.
+type presentation struct {
+	Type            string         `json:"type"`
+	Epoch           int64          `json:"epoch"`
+	RevocationToken hexutil.Bytes  `json:"revocationToken"`
+	IssuerPublicKey hexutil.Bytes  `json:"issuerPublicKey"`
+	VrfPublicKey    hexutil.Bytes  `json:"vrfPublicKey,omitempty"`
+	VrfProof        hexutil.Bytes  `json:"vrfProof,omitempty"`
+	Signature       hexutil.Bytes  `json:"signature,omitempty"`
+	UPoint          []*hexutil.Big `json:"uPoint,omitempty"`
+	VComponents     []*hexutil.Big `json:"vComponents,omitempty"`
+	ZkProof         hexutil.Bytes  `json:"zkProof,omitempty"`
+	OnChainProof    []*hexutil.Big `json:"onChainProof,omitempty"`
+	PublicInputs    []*hexutil.Big `json:"publicInputs,omitempty"`
+	HolderSignature hexutil.Bytes  `json:"holderSignature,omitempty"`
+	Nonce           hexutil.Bytes  `json:"nonce"`
+}
+
+// parseCredentialType parses the name of a credential type as accepted on the command line.
+func parseCredentialType(s string) (issuer.CredentialType, error) {
+	switch strings.ToLower(s) {
+	case "oneshow":
+		return issuer.OneShow, nil
+	case "multishow":
+		return issuer.MultiShow, nil
+	default:
+		return 0, fmt.Errorf("unknown credential type %q (want oneshow or multishow)", s)
+	}
+}
+
+// parseNonce decodes a hex encoded 32 byte nonce.
+func parseNonce(s string) ([32]byte, error) {
+	var nonce [32]byte
+	b, err := hexutil.Decode(s)
+	if err != nil {
+		return nonce, fmt.Errorf("invalid nonce: %w", err)
+	}
+	if len(b) != len(nonce) {
+		return nonce, fmt.Errorf("invalid nonce: %d bytes, want %d", len(b), len(nonce))
+	}
+	copy(nonce[:], b)
+	return nonce, nil
+}
+
+// parseEpoch resolves an --epoch flag value against policy. An empty value selects the current epoch.
+func parseEpoch(policy epoch.Policy, s string) (int64, error) {
+	if s == "" {
+		return policy.Current()
+	}
+	if unix, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return unix, policy.Validate(unix)
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid epoch %q: want a unix timestamp or an RFC 3339 time", s)
+	}
+	return policy.At(t)
+}
+
+// policyFlags registers the flags defining an epoch policy and returns a function resolving them.
+func policyFlags(fs *flag.FlagSet) func() (epoch.Policy, error) {
+	length := fs.Duration("epoch-length", epoch.Default.Length, "length of an epoch")
+	genesis := fs.String("genesis", epoch.Default.Genesis.Format(time.RFC3339), "start of the first epoch (RFC 3339)")
+	return func() (epoch.Policy, error) {
+		g, err := time.Parse(time.RFC3339, *genesis)
+		if err != nil {
+			return epoch.Policy{}, fmt.Errorf("invalid genesis: %w", err)
+		}
+		return epoch.NewPolicy(g, *length)
+	}
+}
+
+// readJSON decodes the JSON file at path into v.
+func readJSON(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// readVerifyingKey reads a Groth16 verifying key of the RevocationTokenProof circuit.
+func readVerifyingKey(path string) (groth16.VerifyingKey, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	vk := groth16.NewVerifyingKey(ecc.BN254)
+	_, err = vk.ReadFrom(f)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read verifying key: %w", err)
+	}
+	return vk, nil
+}
+
+// writeJSON writes v as indented JSON to the file at path.
+func writeJSON(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0600)
+}
+
+// parseFlags parses args into fs and rejects unexpected positional arguments unless allowArgs is set.
+func parseFlags(fs *flag.FlagSet, args []string, allowArgs bool) error {
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+	if !allowArgs && fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+	return nil
+}
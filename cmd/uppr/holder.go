@@ -0,0 +1,322 @@
+package main
+
+import (
+	"PrivacyPreservingRevocationCode/holder"
+	"PrivacyPreservingRevocationCode/issuer"
+	"PrivacyPreservingRevocationCode/zkp"
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"math/big"
+	"os"
+)
+
+// runHolder executes a holder command.
+func runHolder(cmd string, args []string) error {
+	switch cmd {
+	case "request":
+		return holderRequest(args)
+	case "register":
+		return holderRegister(args)
+	case "accept":
+		return holderAccept(args)
+	case "prove":
+		return holderProve(args)
+	default:
+		return fmt.Errorf("unknown holder command %q", cmd)
+	}
+}
+
+// loadWallet reads the holder's wallet from path, or returns an empty wallet if the file does not exist yet.
+func loadWallet(path string) (*holder.Wallet, error) {
+	w := holder.NewWallet()
+	err := readJSON(path, w)
+	if errors.Is(err, os.ErrNotExist) {
+		return w, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot read wallet: %w", err)
+	}
+	return w, nil
+}
+
+// holderRequest generates a VRF key pair in the holder's wallet and writes the issuance request for a credential on
+// it. The VRF secret key only goes into the wallet.
+func holderRequest(args []string) error {
+	fs := flag.NewFlagSet("holder request", flag.ContinueOnError)
+	walletPath := fs.String("wallet", "wallet.json", "wallet file holding the holder's VRF keys")
+	credType := fs.String("type", "", "credential type: oneshow or multishow")
+	issuerKey := fs.String("issuer-key", "", "hex encoded issuer public key")
+	out := fs.String("out", "", "file the request is written to")
+	err := parseFlags(fs, args, false)
+	if err != nil {
+		return err
+	}
+	if *issuerKey == "" || *out == "" {
+		return errors.New("--issuer-key and --out are required")
+	}
+	ct, err := parseCredentialType(*credType)
+	if err != nil {
+		return err
+	}
+	issuerPk, err := hexutil.Decode(*issuerKey)
+	if err != nil {
+		return fmt.Errorf("invalid issuer key: %w", err)
+	}
+
+	wallet, err := loadWallet(*walletPath)
+	if err != nil {
+		return err
+	}
+	req, err := wallet.RequestCredential(ct, issuerPk)
+	if err != nil {
+		return err
+	}
+	err = writeJSON(*walletPath, wallet)
+	if err != nil {
+		return err
+	}
+	err = writeJSON(*out, req)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("created %s issuance request: %s\n", ct, *out)
+	return nil
+}
+
+// holderRegister adds the revocation tokens of the issuer's registration ticket with their proofs to a request.
+func holderRegister(args []string) error {
+	fs := flag.NewFlagSet("holder register", flag.ContinueOnError)
+	walletPath := fs.String("wallet", "wallet.json", "wallet file holding the holder's VRF keys")
+	reqPath := fs.String("request", "", "request file written by 'uppr holder request', updated in place")
+	ticketPath := fs.String("ticket", "", "ticket file written by 'uppr issuer ticket'")
+	pkPath := fs.String("pk", "zkp/sol/build/verifier.g16.pk", "Groth16 proving key (MultiShow only)")
+	vkPath := fs.String("vk", "zkp/sol/build/verifier.g16.vk", "Groth16 verifying key (MultiShow only)")
+	err := parseFlags(fs, args, false)
+	if err != nil {
+		return err
+	}
+	if *reqPath == "" || *ticketPath == "" {
+		return errors.New("--request and --ticket are required")
+	}
+
+	var req issuer.IssuanceRequest
+	err = readJSON(*reqPath, &req)
+	if err != nil {
+		return fmt.Errorf("cannot read request: %w", err)
+	}
+	var ticket issuer.RegistrationTicket
+	err = readJSON(*ticketPath, &ticket)
+	if err != nil {
+		return fmt.Errorf("cannot read ticket: %w", err)
+	}
+	wallet, err := loadWallet(*walletPath)
+	if err != nil {
+		return err
+	}
+
+	var prover holder.TokenProver
+	if req.Type == issuer.MultiShow {
+		prover, err = holder.NewRevocationTokenProver(*pkPath, *vkPath)
+		if err != nil {
+			return err
+		}
+	}
+	err = wallet.RegisterTokens(&req, &ticket, prover)
+	if err != nil {
+		return err
+	}
+	err = writeJSON(*reqPath, &req)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("registered revocation tokens for %d epochs: %s\n", len(req.Tokens), *reqPath)
+	return nil
+}
+
+// holderAccept verifies the credential the issuer returned for a request, adds it to the wallet and writes it with
+// its VRF key pair for 'uppr holder prove'.
+func holderAccept(args []string) error {
+	fs := flag.NewFlagSet("holder accept", flag.ContinueOnError)
+	walletPath := fs.String("wallet", "wallet.json", "wallet file holding the holder's VRF keys")
+	reqPath := fs.String("request", "", "request file the credential was issued for")
+	credPath := fs.String("credential", "", "credential file written by 'uppr issuer issue-blind'")
+	issuerKey := fs.String("issuer-key", "", "hex encoded issuer public key")
+	out := fs.String("out", "", "file the credential is written to")
+	err := parseFlags(fs, args, false)
+	if err != nil {
+		return err
+	}
+	if *reqPath == "" || *credPath == "" || *issuerKey == "" || *out == "" {
+		return errors.New("--request, --credential, --issuer-key and --out are required")
+	}
+	issuerPk, err := hexutil.Decode(*issuerKey)
+	if err != nil {
+		return fmt.Errorf("invalid issuer key: %w", err)
+	}
+
+	var req issuer.IssuanceRequest
+	err = readJSON(*reqPath, &req)
+	if err != nil {
+		return fmt.Errorf("cannot read request: %w", err)
+	}
+	var cred issuer.Credential
+	err = readJSON(*credPath, &cred)
+	if err != nil {
+		return fmt.Errorf("cannot read credential: %w", err)
+	}
+	wallet, err := loadWallet(*walletPath)
+	if err != nil {
+		return err
+	}
+
+	internal, err := wallet.AcceptCredential(&req, cred, issuerPk)
+	if err != nil {
+		return err
+	}
+	err = writeJSON(*walletPath, wallet)
+	if err != nil {
+		return err
+	}
+	err = writeJSON(*out, internal)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("accepted %s credential valid until epoch %d: %s\n", cred.Type, cred.ValidUntil, *out)
+	return nil
+}
+
+// holderProve creates a presentation of a credential for an epoch.
+func holderProve(args []string) error {
+	fs := flag.NewFlagSet("holder prove", flag.ContinueOnError)
+	credPath := fs.String("cred", "", "credential file written by 'uppr issuer issue' or 'uppr holder accept'")
+	epochFlag := fs.String("epoch", "", "epoch of the presentation (default: current epoch)")
+	out := fs.String("out", "", "file the presentation is written to")
+	pkPath := fs.String("pk", "zkp/sol/build/verifier.g16.pk", "Groth16 proving key (MultiShow only)")
+	vkPath := fs.String("vk", "zkp/sol/build/verifier.g16.vk", "Groth16 verifying key (MultiShow only)")
+	nonceHex := fs.String("nonce", "", "hex encoded 32 byte nonce handed out by the verifier")
+	verifierIDHex := fs.String("verifier-id", "", "hex encoded id of the verifier, e.g. its contract address")
+	policy := policyFlags(fs)
+	err := parseFlags(fs, args, false)
+	if err != nil {
+		return err
+	}
+	if *credPath == "" || *out == "" || *nonceHex == "" || *verifierIDHex == "" {
+		return errors.New("--cred, --out, --nonce and --verifier-id are required")
+	}
+	nonce, err := parseNonce(*nonceHex)
+	if err != nil {
+		return err
+	}
+	verifierID, err := hexutil.Decode(*verifierIDHex)
+	if err != nil {
+		return fmt.Errorf("invalid verifier id: %w", err)
+	}
+
+	p, err := policy()
+	if err != nil {
+		return err
+	}
+	epochUnix, err := parseEpoch(p, *epochFlag)
+	if err != nil {
+		return err
+	}
+
+	var cred issuer.InternalCredential
+	err = readJSON(*credPath, &cred)
+	if err != nil {
+		return fmt.Errorf("cannot read credential: %w", err)
+	}
+
+	var pres *presentation
+	switch cred.Credential.Type {
+	case issuer.OneShow:
+		pres, err = proveOneShow(cred, epochUnix, nonce, verifierID)
+	case issuer.MultiShow:
+		pres, err = proveMultiShow(cred, epochUnix, *pkPath, *vkPath, nonce, verifierID)
+	default:
+		err = errors.New("unknown credential type")
+	}
+	if err != nil {
+		return err
+	}
+
+	err = writeJSON(*out, pres)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("created %s presentation for epoch %d: %s\n", cred.Credential.Type, epochUnix, *out)
+	return nil
+}
+
+// proveOneShow evaluates the VRF of a OneShow credential on the epoch and binds it to the verifier's nonce.
+func proveOneShow(cred issuer.InternalCredential, epochUnix int64, nonce [32]byte, verifierID []byte) (*presentation, error) {
+	pres, err := holder.NewOneShowProver().GenProof(cred, epochUnix, nonce, verifierID)
+	if err != nil {
+		return nil, err
+	}
+	// Issuers identify themselves by their compressed key, credentials may hold it uncompressed.
+	issuerPk, err := secp256k1.ParsePubKey(cred.IssuerPublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid issuer public key: %w", err)
+	}
+	return &presentation{
+		Type:            issuer.OneShow.String(),
+		Epoch:           epochUnix,
+		RevocationToken: hexutil.Bytes(pres.RevocationToken),
+		IssuerPublicKey: issuerPk.SerializeCompressed(),
+		VrfPublicKey:    pres.PublicKey,
+		VrfProof:        pres.Proof,
+		Signature:       pres.Signature,
+		UPoint:          toHexBigs(pres.UPoint[:]),
+		VComponents:     toHexBigs(pres.VComponents[:]),
+		Nonce:           nonce[:],
+		HolderSignature: pres.HolderSignature,
+	}, nil
+}
+
+// proveMultiShow creates the Groth16 proof of a MultiShow credential for the epoch, bound to the verifier's nonce.
+func proveMultiShow(cred issuer.InternalCredential, epochUnix int64, pkPath, vkPath string, nonce [32]byte, verifierID []byte) (*presentation, error) {
+	prover, err := holder.NewRevocationTokenProver(pkPath, vkPath)
+	if err != nil {
+		return nil, err
+	}
+	proof, onChainProof, _, publicInputs, err := prover.GenProof(cred, epochUnix, zkp.Challenge(nonce, verifierID))
+	if err != nil {
+		return nil, err
+	}
+	// The token as inserted into the artifact, i.e. including leading zero bytes.
+	token, _, err := cred.GenRevocationToken(epochUnix)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	_, err = proof.WriteTo(&buf)
+	if err != nil {
+		return nil, err
+	}
+
+	return &presentation{
+		Type:            issuer.MultiShow.String(),
+		Epoch:           epochUnix,
+		RevocationToken: hexutil.Bytes(token),
+		IssuerPublicKey: cred.IssuerPublicKey,
+		ZkProof:         buf.Bytes(),
+		OnChainProof:    toHexBigs(onChainProof[:]),
+		PublicInputs:    toHexBigs(publicInputs[:]),
+		Nonce:           nonce[:],
+	}, nil
+}
+
+// toHexBigs converts big integers for JSON encoding.
+func toHexBigs(values []*big.Int) []*hexutil.Big {
+	out := make([]*hexutil.Big, len(values))
+	for i, v := range values {
+		out[i] = (*hexutil.Big)(v)
+	}
+	return out
+}
@@ -0,0 +1,354 @@
+package main
+
+import (
+	"PrivacyPreservingRevocationCode/epoch"
+	"PrivacyPreservingRevocationCode/issuer"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// policyFileName is the name of the file in the issuer directory holding the issuer's epoch policy.
+const policyFileName = "policy.json"
+
+// defaultIssuerDir is the issuer state directory used if --dir is not given.
+const defaultIssuerDir = "uppr-issuer"
+
+// runIssuer executes an issuer command.
+func runIssuer(cmd string, args []string) error {
+	switch cmd {
+	case "init":
+		return issuerInit(args)
+	case "issue":
+		return issuerIssue(args)
+	case "ticket":
+		return issuerTicket(args)
+	case "issue-blind":
+		return issuerIssueBlind(args)
+	case "revoke":
+		return issuerRevoke(args)
+	case "publish-artifact":
+		return issuerPublishArtifact(args)
+	default:
+		return fmt.Errorf("unknown issuer command %q", cmd)
+	}
+}
+
+// issuerInit creates a new issuer state directory with a fresh issuer key and epoch policy.
+func issuerInit(args []string) error {
+	fs := flag.NewFlagSet("issuer init", flag.ContinueOnError)
+	dir := fs.String("dir", defaultIssuerDir, "issuer state directory")
+	credType := fs.String("type", "", "credential type: oneshow or multishow")
+	policy := policyFlags(fs)
+	err := parseFlags(fs, args, false)
+	if err != nil {
+		return err
+	}
+
+	ct, err := parseCredentialType(*credType)
+	if err != nil {
+		return err
+	}
+	p, err := policy()
+	if err != nil {
+		return err
+	}
+
+	store, err := issuer.OpenFileStore(*dir)
+	if err != nil {
+		return err
+	}
+	state, err := store.Load()
+	if err != nil {
+		_ = store.Close()
+		return err
+	}
+	if state != nil {
+		_ = store.Close()
+		return fmt.Errorf("issuer already initialized in %s", *dir)
+	}
+
+	iss, err := issuer.NewIssuerWithStore(ct, store)
+	if err != nil {
+		_ = store.Close()
+		return err
+	}
+	defer iss.Close()
+
+	err = writeJSON(filepath.Join(*dir, policyFileName), p)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("initialized %s issuer in %s\npublic key: %x\n", ct, *dir, iss.GetPublicKey())
+	return nil
+}
+
+// openIssuer reopens the issuer and its epoch policy from an issuer state directory.
+func openIssuer(dir string) (*issuer.Issuer, error) {
+	store, err := issuer.OpenFileStore(dir)
+	if err != nil {
+		return nil, err
+	}
+	state, err := store.Load()
+	if err != nil {
+		_ = store.Close()
+		return nil, err
+	}
+	if state == nil {
+		_ = store.Close()
+		return nil, fmt.Errorf("no issuer in %s, run 'uppr issuer init' first", dir)
+	}
+
+	iss, err := issuer.NewIssuerWithStore(state.CredentialType, store)
+	if err != nil {
+		_ = store.Close()
+		return nil, err
+	}
+
+	var p epoch.Policy
+	err = readJSON(filepath.Join(dir, policyFileName), &p)
+	if err == nil {
+		_, err = epoch.NewPolicy(p.Genesis, p.Length)
+	}
+	if err != nil {
+		_ = iss.Close()
+		return nil, fmt.Errorf("invalid epoch policy: %w", err)
+	}
+	iss.SetEpochPolicy(p)
+	return iss, nil
+}
+
+// issuerIssue issues credentials and writes each credential to a file for its holder. The issuer generates the VRF
+// key pairs, so it learns their secret keys, and the credential files contain them: they are only readable by their
+// owner and must reach the holders over a private channel. issuerTicket and issuerIssueBlind issue credentials on
+// VRF keys the holders generate themselves instead.
+func issuerIssue(args []string) error {
+	fs := flag.NewFlagSet("issuer issue", flag.ContinueOnError)
+	dir := fs.String("dir", defaultIssuerDir, "issuer state directory")
+	count := fs.Uint("count", 1, "number of credentials to issue with random ids")
+	id := fs.Int64("id", -1, "issue a single credential with this id")
+	out := fs.String("out", ".", "directory the credential files are written to")
+	err := parseFlags(fs, args, false)
+	if err != nil {
+		return err
+	}
+
+	iss, err := openIssuer(*dir)
+	if err != nil {
+		return err
+	}
+	defer iss.Close()
+
+	err = os.MkdirAll(*out, 0700)
+	if err != nil {
+		return err
+	}
+
+	var ids []uint
+	if *id >= 0 {
+		err = iss.IssueCredential(uint(*id))
+		if err != nil {
+			return err
+		}
+		ids = append(ids, uint(*id))
+	} else {
+		before := make(map[uint]bool)
+		for _, c := range append(iss.GetAllValidCreds(), iss.GetAllRevokedCreds()...) {
+			before[c.ID] = true
+		}
+		err = iss.IssueCredentials(*count)
+		if err != nil {
+			return err
+		}
+		for _, c := range iss.GetAllValidCreds() {
+			if !before[c.ID] {
+				ids = append(ids, c.ID)
+			}
+		}
+	}
+
+	for _, credID := range ids {
+		cred, err := iss.GetCredentialCopy(credID)
+		if err != nil {
+			return err
+		}
+		path := filepath.Join(*out, fmt.Sprintf("credential-%d.json", credID))
+		err = writeJSON(path, cred)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("issued credential %d: %s\n", credID, path)
+	}
+	return nil
+}
+
+// issuerTicket verifies the proof of possession of a holder's issuance request and writes the registration ticket
+// telling the holder which revocation tokens to register.
+func issuerTicket(args []string) error {
+	fs := flag.NewFlagSet("issuer ticket", flag.ContinueOnError)
+	dir := fs.String("dir", defaultIssuerDir, "issuer state directory")
+	reqPath := fs.String("request", "", "request file written by 'uppr holder request'")
+	out := fs.String("out", "", "file the registration ticket is written to")
+	err := parseFlags(fs, args, false)
+	if err != nil {
+		return err
+	}
+	if *reqPath == "" || *out == "" {
+		return errors.New("--request and --out are required")
+	}
+
+	var req issuer.IssuanceRequest
+	err = readJSON(*reqPath, &req)
+	if err != nil {
+		return fmt.Errorf("cannot read request: %w", err)
+	}
+	iss, err := openIssuer(*dir)
+	if err != nil {
+		return err
+	}
+	defer iss.Close()
+
+	ticket, err := iss.RegistrationTicket(&req)
+	if err != nil {
+		return err
+	}
+	err = writeJSON(*out, ticket)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("registration ticket for %d epochs: %s\n", len(ticket.Epochs), *out)
+	return nil
+}
+
+// issuerIssueBlind verifies the revocation tokens registered by a holder's issuance request and writes the credential
+// issued on its VRF public key.
+func issuerIssueBlind(args []string) error {
+	fs := flag.NewFlagSet("issuer issue-blind", flag.ContinueOnError)
+	dir := fs.String("dir", defaultIssuerDir, "issuer state directory")
+	reqPath := fs.String("request", "", "request file written by 'uppr holder register'")
+	id := fs.Int64("id", -1, "id of the credential")
+	vkPath := fs.String("vk", "zkp/sol/build/verifier.g16.vk", "Groth16 verifying key of the token proofs (MultiShow only)")
+	out := fs.String("out", "", "file the credential is written to")
+	err := parseFlags(fs, args, false)
+	if err != nil {
+		return err
+	}
+	if *reqPath == "" || *out == "" || *id < 0 {
+		return errors.New("--request, --id and --out are required")
+	}
+
+	var req issuer.IssuanceRequest
+	err = readJSON(*reqPath, &req)
+	if err != nil {
+		return fmt.Errorf("cannot read request: %w", err)
+	}
+	iss, err := openIssuer(*dir)
+	if err != nil {
+		return err
+	}
+	defer iss.Close()
+
+	if iss.CredentialType() == issuer.MultiShow {
+		vk, err := readVerifyingKey(*vkPath)
+		if err != nil {
+			return err
+		}
+		iss.SetTokenVerifyingKey(vk)
+	}
+	cred, err := iss.IssueBlindCredential(uint(*id), &req)
+	if err != nil {
+		return err
+	}
+	err = writeJSON(*out, cred)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("issued credential %d valid until epoch %d: %s\n", *id, cred.ValidUntil, *out)
+	return nil
+}
+
+// issuerRevoke revokes the credentials with the given ids.
+func issuerRevoke(args []string) error {
+	fs := flag.NewFlagSet("issuer revoke", flag.ContinueOnError)
+	dir := fs.String("dir", defaultIssuerDir, "issuer state directory")
+	err := parseFlags(fs, args, true)
+	if err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return errors.New("no credential id given")
+	}
+
+	iss, err := openIssuer(*dir)
+	if err != nil {
+		return err
+	}
+	defer iss.Close()
+
+	for _, arg := range fs.Args() {
+		credID, err := strconv.ParseUint(arg, 10, 0)
+		if err != nil {
+			return fmt.Errorf("invalid credential id %q", arg)
+		}
+		err = iss.RevokeCredential(uint(credID))
+		if err != nil {
+			return fmt.Errorf("credential %d: %w", credID, err)
+		}
+		fmt.Printf("revoked credential %d\n", credID)
+	}
+	return nil
+}
+
+// issuerPublishArtifact builds the revocation artifact of an epoch and writes it to a file.
+func issuerPublishArtifact(args []string) error {
+	fs := flag.NewFlagSet("issuer publish-artifact", flag.ContinueOnError)
+	dir := fs.String("dir", defaultIssuerDir, "issuer state directory")
+	epochFlag := fs.String("epoch", "", "epoch of the artifact (default: current epoch)")
+	out := fs.String("out", "", "file the artifact is written to")
+	asJSON := fs.Bool("json", false, "write the artifact as JSON instead of binary")
+	err := parseFlags(fs, args, false)
+	if err != nil {
+		return err
+	}
+	if *out == "" {
+		return errors.New("--out is required")
+	}
+
+	iss, err := openIssuer(*dir)
+	if err != nil {
+		return err
+	}
+	defer iss.Close()
+
+	epochUnix, err := parseEpoch(iss.EpochPolicy(), *epochFlag)
+	if err != nil {
+		return err
+	}
+	if iss.AmountRevoked() == 0 {
+		return errors.New("no credential revoked yet, the artifact would be empty")
+	}
+	artifact, err := iss.GenRevocationArtifactStreamed(epochUnix)
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		err = writeJSON(*out, artifact)
+	} else {
+		var data []byte
+		data, err = artifact.MarshalBinary()
+		if err == nil {
+			err = os.WriteFile(*out, data, 0600)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("published artifact for epoch %d (%s) with %d layers: %s\n",
+		epochUnix, time.Unix(epochUnix, 0).UTC().Format(time.RFC3339), len(artifact.Layers()), *out)
+	return nil
+}
@@ -0,0 +1,110 @@
+// Command uppr drives the issuer, holder and verifier roles of UPPR, and the setup ceremony of the MultiShow keys,
+// from files on disk.
+//
+// Usage:
+//
+//	uppr issuer init --type oneshow|multishow [--dir DIR] [--epoch-length 24h] [--genesis RFC3339]
+//	uppr issuer issue [--dir DIR] [--count N | --id ID] [--out DIR]
+//	uppr issuer ticket [--dir DIR] --request FILE --out FILE
+//	uppr issuer issue-blind [--dir DIR] --request FILE --id ID --out FILE [--vk FILE]
+//	uppr issuer revoke [--dir DIR] <id>...
+//	uppr issuer publish-artifact [--dir DIR] [--epoch EPOCH] --out FILE [--json]
+//	uppr holder request --type oneshow|multishow --issuer-key HEX --out FILE [--wallet FILE]
+//	uppr holder register --request FILE --ticket FILE [--wallet FILE] [--pk FILE] [--vk FILE]
+//	uppr holder accept --request FILE --credential FILE --issuer-key HEX --out FILE [--wallet FILE]
+//	uppr holder prove --cred FILE [--epoch EPOCH] --out FILE [--pk FILE] --nonce HEX --verifier-id HEX
+//	uppr verifier nonce
+//	uppr verifier check --artifact FILE --presentation FILE --verifier-id HEX [--vk FILE] [--issuer-key HEX] [--nonce HEX]
+//	uppr ceremony init --phase1 FILE [--dir DIR]
+//	uppr ceremony contribute --in FILE --out FILE
+//	uppr ceremony accept --contribution FILE [--dir DIR]
+//	uppr ceremony verify [--dir DIR]
+//	uppr ceremony finalize [--dir DIR] [--pk FILE] [--vk FILE] [--sol FILE]
+//
+// EPOCH is either a unix timestamp aligned to the epoch policy or an RFC 3339 time within the epoch.
+// It defaults to the current epoch.
+//
+// 'issuer issue' generates the VRF keys of the credentials itself and writes them into the credential files. With blind
+// issuance (holder request, issuer ticket, holder register, issuer issue-blind, holder accept), holders keep their VRF
+// secret keys in their wallet file and the issuer only signs their public keys, until the last registered epoch.
+//
+// Presentations are bound to a nonce handed out by the verifier and to the verifier's id, which is the address of its
+// contract for on-chain verification.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+)
+
+// Exit codes of the uppr command.
+const (
+	exitOK      = 0 // exitOK signals success, i.e. a valid credential for verifier check.
+	exitError   = 1 // exitError signals invalid usage or a failure to run the command.
+	exitInvalid = 2 // exitInvalid signals that verifier check rejected the presentation.
+)
+
+// errInvalid is returned by commands whose check failed, as opposed to commands that could not run.
+var errInvalid = errors.New("presentation rejected")
+
+const usage = `usage: uppr <role> <command> [flags]
+
+roles and commands:
+  issuer init              create a new issuer state directory
+  issuer issue             issue credentials and write them for their holders
+  issuer ticket            answer a holder's issuance request with a registration ticket
+  issuer issue-blind       issue a credential on a holder's registered request
+  issuer revoke            revoke credentials by id
+  issuer publish-artifact  write the revocation artifact of an epoch
+  holder request           request a credential on a fresh VRF key of the wallet
+  holder register          register the revocation tokens of a registration ticket
+  holder accept            verify an issued credential and add it to the wallet
+  holder prove             create a presentation for an epoch
+  verifier nonce           print a fresh nonce to bind a presentation to
+  verifier check           check a presentation against a revocation artifact
+  ceremony init            start a Groth16 phase-2 ceremony for the MultiShow keys
+  ceremony contribute      contribute to the latest state of a ceremony
+  ceremony accept          verify a contribution and add it to the ceremony
+  ceremony verify          verify all contributions of a ceremony
+  ceremony finalize        write the keys and Solidity verifier of a ceremony
+
+Run 'uppr <role> <command> -h' for the flags of a command.
+`
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+// run executes the command given by args and returns the exit code.
+func run(args []string) int {
+	if len(args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		return exitError
+	}
+
+	var err error
+	switch args[0] {
+	case "issuer":
+		err = runIssuer(args[1], args[2:])
+	case "holder":
+		err = runHolder(args[1], args[2:])
+	case "verifier":
+		err = runVerifier(args[1], args[2:])
+	case "ceremony":
+		err = runCeremony(args[1], args[2:])
+	default:
+		err = fmt.Errorf("unknown role %q", args[0])
+	}
+
+	switch {
+	case err == nil, errors.Is(err, flag.ErrHelp):
+		return exitOK
+	case errors.Is(err, errInvalid):
+		return exitInvalid
+	default:
+		fmt.Fprintln(os.Stderr, "uppr:", err)
+		return exitError
+	}
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"PrivacyPreservingRevocationCode/issuer"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+const (
+	testPk         = "../../zkp/sol/build/verifier.g16.pk"
+	testVk         = "../../zkp/sol/build/verifier.g16.vk"
+	testNonce      = "0x0101010101010101010101010101010101010101010101010101010101010101"
+	testVerifierID = "0x00000000000000000000000000000000000000aa"
+)
+
+func TestCLI_EndToEnd(t *testing.T) {
+	for _, mode := range []string{"oneshow", "multishow"} {
+		t.Run(mode, func(t *testing.T) {
+			tmp := t.TempDir()
+			dir := filepath.Join(tmp, "issuer")
+			creds := filepath.Join(tmp, "creds")
+			artifact := filepath.Join(tmp, "artifact.bin")
+			epoch := "86400"
+
+			require.Equal(t, exitOK, run([]string{"issuer", "init", "--dir", dir, "--type", mode}))
+			require.Equal(t, exitError, run([]string{"issuer", "init", "--dir", dir, "--type", mode}), "init twice must fail")
+
+			require.Equal(t, exitOK, run([]string{"issuer", "issue", "--dir", dir, "--id", "1", "--out", creds}))
+			require.Equal(t, exitOK, run([]string{"issuer", "issue", "--dir", dir, "--id", "2", "--out", creds}))
+			require.Equal(t, exitOK, run([]string{"issuer", "issue", "--dir", dir, "--count", "20", "--out", creds}))
+			files, err := os.ReadDir(creds)
+			require.NoError(t, err)
+			require.Len(t, files, 22)
+
+			require.Equal(t, exitOK, run([]string{"issuer", "revoke", "--dir", dir, "2"}))
+			require.Equal(t, exitError, run([]string{"issuer", "revoke", "--dir", dir, "12345678901"}))
+
+			require.Equal(t, exitOK, run([]string{"issuer", "publish-artifact", "--dir", dir, "--epoch", epoch, "--out", artifact}))
+			require.Equal(t, exitError, run([]string{"issuer", "publish-artifact", "--dir", dir, "--epoch", "86401", "--out", artifact}), "unaligned epoch must fail")
+
+			for id, want := range map[int]int{1: exitOK, 2: exitInvalid} {
+				pres := filepath.Join(tmp, "presentation-"+strconv.Itoa(id)+".json")
+				cred := filepath.Join(creds, "credential-"+strconv.Itoa(id)+".json")
+				require.Equal(t, exitOK, run([]string{"holder", "prove", "--cred", cred, "--epoch", epoch, "--out", pres, "--pk", testPk, "--vk", testVk, "--nonce", testNonce, "--verifier-id", testVerifierID}))
+				require.Equal(t, want, run([]string{"verifier", "check", "--artifact", artifact, "--presentation", pres, "--vk", testVk, "--verifier-id", testVerifierID, "--nonce", testNonce}))
+			}
+
+			// A presentation for another epoch is rejected, as the artifact does not cover it.
+			pres := filepath.Join(tmp, "presentation-other.json")
+			cred := filepath.Join(creds, "credential-1.json")
+			require.Equal(t, exitOK, run([]string{"holder", "prove", "--cred", cred, "--epoch", "1970-01-03T12:00:00Z", "--out", pres, "--pk", testPk, "--vk", testVk, "--nonce", testNonce, "--verifier-id", testVerifierID}))
+			require.Equal(t, exitInvalid, run([]string{"verifier", "check", "--artifact", artifact, "--presentation", pres, "--vk", testVk, "--verifier-id", testVerifierID}))
+
+			// Presentations are bound to the verifier and its nonce.
+			pres = filepath.Join(tmp, "presentation-1.json")
+			require.Equal(t, exitInvalid, run([]string{"verifier", "check", "--artifact", artifact, "--presentation", pres, "--vk", testVk, "--verifier-id", "0xbb"}))
+			require.Equal(t, exitError, run([]string{"verifier", "check", "--artifact", artifact, "--presentation", pres, "--vk", testVk, "--verifier-id", testVerifierID, "--nonce", "0x" + strings.Repeat("02", 32)}))
+			require.Equal(t, exitError, run([]string{"verifier", "check", "--artifact", artifact, "--presentation", pres, "--vk", testVk}))
+			require.Equal(t, exitError, run([]string{"holder", "prove", "--cred", cred, "--epoch", epoch, "--out", pres, "--pk", testPk, "--vk", testVk}))
+		})
+	}
+}
+
+func TestCLI_BlindIssuance(t *testing.T) {
+	for _, mode := range []string{"oneshow", "multishow"} {
+		t.Run(mode, func(t *testing.T) {
+			tmp := t.TempDir()
+			dir := filepath.Join(tmp, "issuer")
+			wallet := filepath.Join(tmp, "wallet.json")
+			req := filepath.Join(tmp, "request.json")
+			ticket := filepath.Join(tmp, "ticket.json")
+			issued := filepath.Join(tmp, "issued.json")
+			cred := filepath.Join(tmp, "credential.json")
+			artifact := filepath.Join(tmp, "artifact.bin")
+			pres := filepath.Join(tmp, "presentation.json")
+
+			require.Equal(t, exitOK, run([]string{"issuer", "init", "--dir", dir, "--type", mode}))
+			iss, err := openIssuer(dir)
+			require.NoError(t, err)
+			issuerKey := hexutil.Encode(iss.GetPublicKey())
+			require.NoError(t, iss.Close())
+
+			require.Equal(t, exitOK, run([]string{"holder", "request", "--wallet", wallet, "--type", mode, "--issuer-key", issuerKey, "--out", req}))
+			require.Equal(t, exitOK, run([]string{"issuer", "ticket", "--dir", dir, "--request", req, "--out", ticket}))
+			require.Equal(t, exitError, run([]string{"issuer", "issue-blind", "--dir", dir, "--request", req, "--id", "5", "--vk", testVk, "--out", issued}), "unregistered request must fail")
+			require.Equal(t, exitOK, run([]string{"holder", "register", "--wallet", wallet, "--request", req, "--ticket", ticket, "--pk", testPk, "--vk", testVk}))
+			require.Equal(t, exitOK, run([]string{"issuer", "issue-blind", "--dir", dir, "--request", req, "--id", "5", "--vk", testVk, "--out", issued}))
+			require.Equal(t, exitOK, run([]string{"holder", "accept", "--wallet", wallet, "--request", req, "--credential", issued, "--issuer-key", issuerKey, "--out", cred}))
+
+			// The issuer never sees the VRF secret key, which the holder keeps in its private wallet.
+			data, err := os.ReadFile(issued)
+			require.NoError(t, err)
+			require.NotContains(t, string(data), "vrfPrivateKey")
+			info, err := os.Stat(wallet)
+			require.NoError(t, err)
+			require.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+			require.Equal(t, exitOK, run([]string{"issuer", "issue", "--dir", dir, "--id", "1", "--out", tmp}))
+			require.Equal(t, exitOK, run([]string{"issuer", "revoke", "--dir", dir, "5"}))
+			require.Equal(t, exitOK, run([]string{"issuer", "publish-artifact", "--dir", dir, "--out", artifact}))
+			info, err = os.Stat(artifact)
+			require.NoError(t, err)
+			require.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+			require.Equal(t, exitOK, run([]string{"holder", "prove", "--cred", cred, "--out", pres, "--pk", testPk, "--vk", testVk, "--nonce", testNonce, "--verifier-id", testVerifierID}))
+			require.Equal(t, exitInvalid, run([]string{"verifier", "check", "--artifact", artifact, "--presentation", pres, "--vk", testVk, "--verifier-id", testVerifierID, "--nonce", testNonce}))
+
+			// The credential expires after the registered epochs.
+			var c issuer.InternalCredential
+			require.NoError(t, readJSON(cred, &c))
+			expired := strconv.FormatInt(c.Credential.ValidUntil+86400, 10)
+			require.Equal(t, exitError, run([]string{"holder", "prove", "--cred", cred, "--epoch", expired, "--out", pres, "--pk", testPk, "--vk", testVk, "--nonce", testNonce, "--verifier-id", testVerifierID}))
+		})
+	}
+}
+
+func TestCLI_Usage(t *testing.T) {
+	require.Equal(t, exitError, run(nil))
+	require.Equal(t, exitError, run([]string{"auditor", "check"}))
+	require.Equal(t, exitError, run([]string{"issuer", "unknown"}))
+	require.Equal(t, exitError, run([]string{"issuer", "issue", "--dir", filepath.Join(t.TempDir(), "missing")}))
+	require.Equal(t, exitError, run([]string{"issuer", "init", "--type", "manyshow", "--dir", t.TempDir()}))
+	require.Equal(t, exitOK, run([]string{"verifier", "nonce"}))
+	require.Equal(t, exitError, run([]string{"ceremony", "unknown"}))
+	require.Equal(t, exitError, run([]string{"ceremony", "init", "--dir", t.TempDir()}))
+	require.Equal(t, exitError, run([]string{"ceremony", "contribute", "--in", testVk, "--out", filepath.Join(t.TempDir(), "contribution.bin")}))
+	require.Equal(t, exitError, run([]string{"ceremony", "accept", "--dir", t.TempDir(), "--contribution", testVk}))
+	require.Equal(t, exitError, run([]string{"ceremony", "finalize", "--dir", t.TempDir()}))
+	require.True(t, strings.HasPrefix(usage, "usage: uppr"))
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"PrivacyPreservingRevocationCode/bloom"
+	"PrivacyPreservingRevocationCode/issuer"
+	"PrivacyPreservingRevocationCode/verifier"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"math/big"
+	"os"
+)
+
+// checkResult is printed by verifier check. Code is the error code of the on-chain verifier of the credential type.
+type checkResult struct {
+	Valid  bool   `json:"valid"`
+	Code   int    `json:"code"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// runVerifier executes a verifier command.
+func runVerifier(cmd string, args []string) error {
+	switch cmd {
+	case "check":
+		return verifierCheck(args)
+	case "nonce":
+		return verifierNonce(args)
+	default:
+		return fmt.Errorf("unknown verifier command %q", cmd)
+	}
+}
+
+// verifierCheck checks a presentation against a revocation artifact and prints the result.
+func verifierCheck(args []string) error {
+	fs := flag.NewFlagSet("verifier check", flag.ContinueOnError)
+	artifactPath := fs.String("artifact", "", "artifact file written by 'uppr issuer publish-artifact'")
+	presPath := fs.String("presentation", "", "presentation file written by 'uppr holder prove'")
+	vkPath := fs.String("vk", "zkp/sol/build/verifier.g16.vk", "Groth16 verifying key (MultiShow only)")
+	issuerKey := fs.String("issuer-key", "", "hex encoded issuer public key (default: issuer recorded in the artifact)")
+	verifierIDHex := fs.String("verifier-id", "", "hex encoded id of the verifier, e.g. its contract address")
+	nonceHex := fs.String("nonce", "", "hex encoded nonce the presentation must be bound to (default: any)")
+	err := parseFlags(fs, args, false)
+	if err != nil {
+		return err
+	}
+	if *artifactPath == "" || *presPath == "" || *verifierIDHex == "" {
+		return errors.New("--artifact, --presentation and --verifier-id are required")
+	}
+	verifierID, err := hexutil.Decode(*verifierIDHex)
+	if err != nil {
+		return fmt.Errorf("invalid verifier id: %w", err)
+	}
+
+	artifact, err := readArtifact(*artifactPath)
+	if err != nil {
+		return fmt.Errorf("cannot read artifact: %w", err)
+	}
+	var pres presentation
+	err = readJSON(*presPath, &pres)
+	if err != nil {
+		return fmt.Errorf("cannot read presentation: %w", err)
+	}
+
+	issuerPk := artifact.IssuerID()
+	if *issuerKey != "" {
+		issuerPk, err = hexutil.Decode(*issuerKey)
+		if err != nil {
+			return fmt.Errorf("invalid issuer key: %w", err)
+		}
+	}
+	if !bytes.Equal(issuerPk, pres.IssuerPublicKey) {
+		return errors.New("presentation was not issued by the issuer of the artifact")
+	}
+	nonce, err := presentationNonce(&pres, *nonceHex)
+	if err != nil {
+		return err
+	}
+	var res checkResult
+	switch pres.Type {
+	case issuer.OneShow.String():
+		res, err = checkOneShow(artifact, &pres, issuerPk, verifierID, nonce)
+	case issuer.MultiShow.String():
+		res, err = checkMultiShow(artifact, &pres, issuerPk, *vkPath, verifierID, nonce)
+	default:
+		err = fmt.Errorf("unknown presentation type %q", pres.Type)
+	}
+	if err != nil {
+		return err
+	}
+
+	out, err := json.Marshal(res)
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(out))
+	if !res.Valid {
+		return errInvalid
+	}
+	return nil
+}
+
+// readArtifact reads a binary or JSON encoded revocation artifact.
+func readArtifact(path string) (*bloom.BloomFilterCascade, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	artifact := &bloom.BloomFilterCascade{}
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		err = json.Unmarshal(data, artifact)
+	} else {
+		err = artifact.UnmarshalBinary(data)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return artifact, nil
+}
+
+// presentationNonce returns the nonce a presentation is bound to. If nonceHex is set, it must be that nonce.
+// The command does not remember nonces across runs, so replays of a presentation are only detected by verifiers that
+// keep their state, such as the contracts.
+func presentationNonce(pres *presentation, nonceHex string) ([32]byte, error) {
+	var nonce [32]byte
+	if len(pres.Nonce) != len(nonce) {
+		return nonce, errors.New("presentation has no valid nonce")
+	}
+	copy(nonce[:], pres.Nonce)
+	if nonceHex != "" {
+		want, err := parseNonce(nonceHex)
+		if err != nil {
+			return nonce, err
+		}
+		if want != nonce {
+			return nonce, errors.New("presentation is not bound to the given nonce")
+		}
+	}
+	return nonce, nil
+}
+
+// checkOneShow checks a OneShow presentation with the off-chain OneShow verifier.
+func checkOneShow(artifact *bloom.BloomFilterCascade, pres *presentation, issuerPk, verifierID []byte, nonce [32]byte) (checkResult, error) {
+	v, err := verifier.NewOneShowVerifier(issuerPk, verifierID, artifact)
+	if err != nil {
+		return checkResult{}, err
+	}
+	valid, code := v.CheckCredential(pres.VrfPublicKey, pres.Signature, pres.VrfProof, pres.Epoch, nonce, pres.HolderSignature)
+	return newCheckResult(valid, int(code), code.String()), nil
+}
+
+// verifierNonce prints a fresh nonce for a holder to bind a presentation to: the holder signature of a OneShow
+// presentation and the proof challenge of a MultiShow presentation both commit to it.
+func verifierNonce(args []string) error {
+	fs := flag.NewFlagSet("verifier nonce", flag.ContinueOnError)
+	err := parseFlags(fs, args, false)
+	if err != nil {
+		return err
+	}
+	nonce, err := verifier.NewNonce()
+	if err != nil {
+		return err
+	}
+	fmt.Println(hexutil.Encode(nonce[:]))
+	return nil
+}
+
+// checkMultiShow checks a MultiShow presentation with the off-chain MultiShow verifier.
+func checkMultiShow(artifact *bloom.BloomFilterCascade, pres *presentation, issuerPk []byte, vkPath string, verifierID []byte, nonce [32]byte) (checkResult, error) {
+	vk, err := readVerifyingKey(vkPath)
+	if err != nil {
+		return checkResult{}, err
+	}
+
+	v, err := verifier.NewMultiShowVerifier(issuerPk, verifierID, vk, artifact)
+	if err != nil {
+		return checkResult{}, err
+	}
+	if len(pres.OnChainProof) != 8 {
+		code := verifier.MultiShowProofInvalid
+		return newCheckResult(false, int(code), code.String()), nil
+	}
+	var proof [8]*big.Int
+	for i, w := range pres.OnChainProof {
+		proof[i] = w.ToInt()
+	}
+	valid, code := v.CheckCredential(proof, new(big.Int).SetBytes(pres.RevocationToken), pres.Epoch, nonce)
+	return newCheckResult(valid, int(code), code.String()), nil
+}
+
+// newCheckResult returns the result of a check, with a reason for rejected presentations.
+func newCheckResult(valid bool, code int, reason string) checkResult {
+	if valid {
+		return checkResult{Valid: true, Code: code}
+	}
+	return checkResult{Code: code, Reason: reason}
+}
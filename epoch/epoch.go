@@ -0,0 +1,100 @@
+package epoch
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Policy divides time into fixed-length windows aligned to a genesis time.
+// An epoch is identified by the unix timestamp (in seconds) at which its window starts, so that issuers, holders
+// and verifiers derive the same epoch for a given point in time without sharing any state.
+type Policy struct {
+	Genesis time.Time     // Genesis is the start of the first epoch window.
+	Length  time.Duration // Length is the duration of a single epoch window. Must be a positive multiple of a second.
+}
+
+var (
+	// Hourly is a Policy with one hour windows aligned to the unix epoch.
+	Hourly = Policy{Genesis: time.Unix(0, 0).UTC(), Length: time.Hour}
+	// Daily is a Policy with 24 hour windows aligned to the unix epoch (i.e. midnight UTC).
+	Daily = Policy{Genesis: time.Unix(0, 0).UTC(), Length: 24 * time.Hour}
+
+	// Default is the Policy used by issuers and holders unless configured otherwise.
+	Default = Daily
+)
+
+// NewPolicy creates a Policy with windows of the given length starting at genesis.
+func NewPolicy(genesis time.Time, length time.Duration) (Policy, error) {
+	p := Policy{Genesis: genesis.UTC(), Length: length}
+	if err := p.validate(); err != nil {
+		return Policy{}, err
+	}
+	return p, nil
+}
+
+// At returns the epoch whose window contains t. Times before genesis belong to no epoch and yield an error.
+func (p Policy) At(t time.Time) (int64, error) {
+	if err := p.validate(); err != nil {
+		return 0, err
+	}
+	if t.Before(p.Genesis) {
+		return 0, fmt.Errorf("time %s is before genesis %s", t.UTC(), p.Genesis)
+	}
+	n := t.Sub(p.Genesis) / p.Length
+	return p.Genesis.Add(n * p.Length).Unix(), nil
+}
+
+// Current returns the epoch of the current time.
+func (p Policy) Current() (int64, error) {
+	return p.At(time.Now())
+}
+
+// Next returns the epoch following the given epoch.
+func (p Policy) Next(epoch int64) int64 {
+	return epoch + int64(p.Length/time.Second)
+}
+
+// Start returns the start time of the epoch window.
+func (p Policy) Start(epoch int64) time.Time {
+	return time.Unix(epoch, 0).UTC()
+}
+
+// Index returns the number of windows between genesis and the given epoch.
+func (p Policy) Index(epoch int64) int64 {
+	return (epoch - p.Genesis.Unix()) / int64(p.Length/time.Second)
+}
+
+// Validate checks that epoch is the start of a window of this policy.
+func (p Policy) Validate(epoch int64) error {
+	if err := p.validate(); err != nil {
+		return err
+	}
+	offset := epoch - p.Genesis.Unix()
+	if offset < 0 {
+		return fmt.Errorf("epoch %d is before genesis %d", epoch, p.Genesis.Unix())
+	}
+	if offset%int64(p.Length/time.Second) != 0 {
+		return fmt.Errorf("epoch %d is not aligned to a %s window", epoch, p.Length)
+	}
+	return nil
+}
+
+// validate checks the policy parameters.
+func (p Policy) validate() error {
+	if p.Length < time.Second || p.Length%time.Second != 0 {
+		return errors.New("epoch length must be a positive multiple of a second")
+	}
+	if p.Genesis.Nanosecond() != 0 {
+		return errors.New("epoch genesis must be a whole second")
+	}
+	return nil
+}
+
+// Bytes returns the 8-byte big-endian encoding of an epoch used as VRF and hash input for revocation tokens.
+func Bytes(epoch int64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, uint64(epoch))
+	return b
+}
@@ -0,0 +1,53 @@
+package epoch
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestPolicy_At(t *testing.T) {
+	genesis := time.Date(2025, 1, 1, 6, 0, 0, 0, time.UTC)
+	p, err := NewPolicy(genesis, time.Hour)
+	require.NoError(t, err)
+
+	e, err := p.At(genesis)
+	require.NoError(t, err)
+	require.Equal(t, genesis.Unix(), e)
+
+	e, err = p.At(genesis.Add(90 * time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, genesis.Add(time.Hour).Unix(), e)
+	require.Equal(t, int64(1), p.Index(e))
+	require.Equal(t, genesis.Add(2*time.Hour).Unix(), p.Next(e))
+	require.Equal(t, genesis.Add(time.Hour), p.Start(e))
+
+	_, err = p.At(genesis.Add(-time.Second))
+	require.Error(t, err)
+}
+
+func TestPolicy_Validate(t *testing.T) {
+	now := time.Now()
+	e, err := Daily.At(now)
+	require.NoError(t, err)
+	require.NoError(t, Daily.Validate(e))
+	require.Error(t, Daily.Validate(e+1))
+	require.Equal(t, 0, Daily.Start(e).Hour(), "daily epochs start at midnight UTC")
+
+	current, err := Hourly.Current()
+	require.NoError(t, err)
+	require.NoError(t, Hourly.Validate(current))
+}
+
+func TestPolicy_Invalid(t *testing.T) {
+	_, err := NewPolicy(time.Unix(0, 0), 0)
+	require.Error(t, err)
+	_, err = NewPolicy(time.Unix(0, 0), 1500*time.Millisecond)
+	require.Error(t, err)
+	_, err = NewPolicy(time.Unix(0, 5), time.Hour)
+	require.Error(t, err)
+}
+
+func TestBytes(t *testing.T) {
+	require.Equal(t, []byte{0, 0, 0, 0, 0x65, 0x53, 0xf1, 0x00}, Bytes(1_700_000_000))
+}
@@ -0,0 +1,79 @@
+package holder
+
+import (
+	"PrivacyPreservingRevocationCode/issuer"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+)
+
+// ProofKey identifies a proof of a batch by its credential and epoch.
+type ProofKey struct {
+	Credential int   // Credential is the index of the credential in the batch.
+	Epoch      int64 // Epoch is the unix epoch the proof is for.
+}
+
+// GenProofs proves the revocation tokens of all credentials for all epochs in parallel, sharing the prover's
+// constraint system and proving key, e.g. so a holder can precompute the proofs of the next epochs offline. As proofs
+// are bound to the verifier's challenge, challenge must return the challenge of each proof in advance, e.g. derived
+// from a nonce per credential and epoch. See ProverPool.ProveAll.
+func (r *RevocationTokenProver) GenProofs(ctx context.Context, creds []issuer.InternalCredential, epochs []int64, challenge func(ProofKey) *big.Int) (map[ProofKey]*TokenProof, error) {
+	return NewProverPool(r, 0).ProveAll(ctx, creds, epochs, challenge)
+}
+
+// ProveAll proves all credentials for all epochs, each bound to the challenge returned for its key, on the pool's
+// workers and returns the proofs keyed by credential and epoch. The first failing proof cancels the remaining ones and
+// its error is returned, as is the context's error if the context is done first.
+func (p *ProverPool) ProveAll(ctx context.Context, creds []issuer.InternalCredential, epochs []int64, challenge func(ProofKey) *big.Int) (map[ProofKey]*TokenProof, error) {
+	if challenge == nil {
+		return nil, errors.New("missing verifier challenges")
+	}
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	keys := make(chan ProofKey)
+	go func() {
+		defer close(keys)
+		for i := range creds {
+			for _, epochUnix := range epochs {
+				select {
+				case keys <- ProofKey{Credential: i, Epoch: epochUnix}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		proofs = make(map[ProofKey]*TokenProof, len(creds)*len(epochs))
+	)
+	for w := 0; w < min(p.Workers(), len(creds)*len(epochs)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for key := range keys {
+				proof, err := p.Prove(ctx, creds[key.Credential], key.Epoch, challenge(key))
+				if err != nil {
+					// Only the first cause is kept, later errors stem from the cancellation.
+					cancel(fmt.Errorf("credential %d, epoch %d: %w", key.Credential, key.Epoch, err))
+					continue
+				}
+				mu.Lock()
+				proofs[key] = proof
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	err := context.Cause(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return proofs, nil
+}
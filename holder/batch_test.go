@@ -0,0 +1,163 @@
+package holder
+
+import (
+	"PrivacyPreservingRevocationCode/issuer"
+	"PrivacyPreservingRevocationCode/zkp"
+	"context"
+	"encoding/binary"
+	"errors"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// failingProver fails to prove for the epoch failEpoch, counts the proofs it generated and records the largest
+// number of goroutines it saw. Its proofs carry the epoch and challenge they were generated for.
+type failingProver struct {
+	countingProver
+	failEpoch  int64
+	proofs     atomic.Int32
+	goroutines atomic.Int32
+}
+
+func (f *failingProver) Prove(cred issuer.InternalCredential, epochUnix int64, challenge *big.Int) (*TokenProof, error) {
+	if epochUnix == f.failEpoch {
+		return nil, errors.New("prover failed")
+	}
+	f.proofs.Add(1)
+	for n := int32(runtime.NumGoroutine()); ; {
+		m := f.goroutines.Load()
+		if n <= m || f.goroutines.CompareAndSwap(m, n) {
+			break
+		}
+	}
+	proof, err := f.countingProver.Prove(cred, epochUnix, challenge)
+	if err != nil {
+		return nil, err
+	}
+	proof.PublicInputs.Epoch = epochUnix
+	proof.PublicInputs.Challenge = challenge
+	return proof, nil
+}
+
+// keyChallenge derives a distinct challenge for each proof of a batch.
+func keyChallenge(key ProofKey) *big.Int {
+	var nonce [32]byte
+	binary.BigEndian.PutUint64(nonce[:8], uint64(key.Credential))
+	binary.BigEndian.PutUint64(nonce[8:16], uint64(key.Epoch))
+	return zkp.Challenge(nonce, []byte("verifier"))
+}
+
+func TestRevocationTokenProver_GenProofs(t *testing.T) {
+	prover, err := NewRevocationTokenProver("../zkp/sol/build/verifier.g16.pk", "../zkp/sol/build/verifier.g16.vk")
+	require.NoError(t, err)
+	iss := issuer.NewIssuer(issuer.MultiShow)
+	require.NoError(t, iss.IssueCredentials(2))
+	var creds []issuer.InternalCredential
+	for _, cred := range iss.GetAllValidCreds() {
+		creds = append(creds, *cred)
+	}
+	now := time.Now().UTC().Unix()
+	epochs := []int64{now, now + 86400}
+	proofs, err := prover.GenProofs(context.Background(), creds, epochs, keyChallenge)
+	require.NoError(t, err)
+	require.Len(t, proofs, len(creds)*len(epochs))
+	for i, cred := range creds {
+		for _, epochUnix := range epochs {
+			key := ProofKey{Credential: i, Epoch: epochUnix}
+			proof, ok := proofs[key]
+			require.True(t, ok, "missing proof of credential %d for epoch %d", i, epochUnix)
+			require.NoError(t, prover.Verify(proof))
+			require.Equal(t, keyChallenge(key), proof.PublicInputs.Challenge)
+			token, _, err := cred.GenRevocationToken(epochUnix)
+			require.NoError(t, err)
+			require.Equal(t, new(big.Int).SetBytes(token), proof.PublicInputs.RevocationToken)
+			require.Equal(t, epochUnix, proof.PublicInputs.Epoch)
+		}
+	}
+
+	// A credential that cannot be proven fails the batch.
+	oneShow := issuer.NewIssuer(issuer.OneShow)
+	require.NoError(t, oneShow.IssueCredential(0))
+	oneShowCred, err := oneShow.GetCredentialCopy(0)
+	require.NoError(t, err)
+	_, err = prover.GenProofs(context.Background(), append(creds, oneShowCred), epochs[:1], keyChallenge)
+	require.ErrorContains(t, err, "credential 2")
+}
+
+func TestProverPool_ProveAll(t *testing.T) {
+	creds := make([]issuer.InternalCredential, 10)
+	epochs := []int64{1, 2, 3}
+
+	prover := &failingProver{failEpoch: -1}
+	proofs, err := NewProverPool(prover, 2).ProveAll(context.Background(), creds, epochs, keyChallenge)
+	require.NoError(t, err)
+	require.Len(t, proofs, len(creds)*len(epochs))
+	require.Equal(t, int32(2), prover.max.Load())
+	for key, proof := range proofs {
+		require.Equal(t, key.Epoch, proof.PublicInputs.Epoch)
+		require.Equal(t, keyChallenge(key), proof.PublicInputs.Challenge)
+	}
+	_, err = NewProverPool(prover, 2).ProveAll(context.Background(), creds, epochs, nil)
+	require.ErrorContains(t, err, "challenge")
+
+	// The batch runs on the pool's workers rather than a goroutine per proof.
+	many := make([]issuer.InternalCredential, 100)
+	baseline := int32(runtime.NumGoroutine())
+	prover = &failingProver{failEpoch: -1}
+	_, err = NewProverPool(prover, 2).ProveAll(context.Background(), many, epochs, keyChallenge)
+	require.NoError(t, err)
+	require.Less(t, prover.goroutines.Load(), baseline+int32(len(many)))
+
+	// The first error cancels the remaining proofs.
+	prover = &failingProver{failEpoch: 1}
+	proofs, err = NewProverPool(prover, 1).ProveAll(context.Background(), creds, epochs, keyChallenge)
+	require.ErrorContains(t, err, "prover failed")
+	require.Nil(t, proofs)
+	require.Less(t, prover.proofs.Load(), int32(len(creds)*len(epochs)-len(creds)))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	prover = &failingProver{failEpoch: -1}
+	_, err = NewProverPool(prover, 1).ProveAll(ctx, creds, epochs, keyChallenge)
+	require.ErrorIs(t, err, context.Canceled)
+	require.Zero(t, prover.proofs.Load())
+}
+
+func BenchmarkRevocationTokenProver_GenProofs(b *testing.B) {
+	prover, err := NewRevocationTokenProver("../zkp/sol/build/verifier.g16.pk", "../zkp/sol/build/verifier.g16.vk")
+	require.NoError(b, err)
+	iss := issuer.NewIssuer(issuer.MultiShow)
+	require.NoError(b, iss.IssueCredentials(4))
+	var creds []issuer.InternalCredential
+	for _, cred := range iss.GetAllValidCreds() {
+		creds = append(creds, *cred)
+	}
+	now := time.Now().UTC().Unix()
+	epochs := []int64{now, now + 86400, now + 2*86400}
+	challenge := zkp.Challenge([32]byte{1}, []byte("verifier"))
+
+	b.Run("Sequential", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, cred := range creds {
+				for _, epochUnix := range epochs {
+					_, err := prover.Prove(cred, epochUnix, challenge)
+					if err != nil {
+						b.Fatalf("Prove failed: %v", err)
+					}
+				}
+			}
+		}
+	})
+	b.Run("GenProofs", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_, err := prover.GenProofs(context.Background(), creds, epochs, func(ProofKey) *big.Int { return challenge })
+			if err != nil {
+				b.Fatalf("GenProofs failed: %v", err)
+			}
+		}
+	})
+}
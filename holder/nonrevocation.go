@@ -0,0 +1,165 @@
+package holder
+
+import (
+	"PrivacyPreservingRevocationCode/bloom"
+	"PrivacyPreservingRevocationCode/issuer"
+	"PrivacyPreservingRevocationCode/zkp"
+	"errors"
+	"fmt"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards/eddsa"
+	tedwards "github.com/consensys/gnark-crypto/ecc/twistededwards"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/native/twistededwards"
+	eddsaInCicuit "github.com/consensys/gnark/std/signature/eddsa"
+	"io"
+	"math/big"
+	"os"
+)
+
+// NonRevocationProver generates and verifies zero-knowledge proofs that a MultiShow credential is not revoked by a
+// cascade. Unlike the proofs of RevocationTokenProver, they keep the revocation token private and only reveal the
+// issuer, the epoch and the commitment to the cascade (see zkp.NonRevocationProof).
+type NonRevocationProver struct {
+	shape zkp.CascadeShape            // shape bounds the cascades the circuit can open.
+	cs    constraint.ConstraintSystem // cs represents the constraint system of the zkp.NonRevocationProof circuit.
+	pk    groth16.ProvingKey          // pk represents the Groth16 proving key used for generating zero-knowledge proofs.
+	vk    groth16.VerifyingKey        // vk represents the Groth16 verifying key used for verifying zero-knowledge proofs.
+}
+
+// NewNonRevocationProver compiles the circuit of the given shape and reads its Groth16 keys, as written by
+// zkp.SetupNonRevocation.
+func NewNonRevocationProver(shape zkp.CascadeShape, pkPath, vkPath string) (*NonRevocationProver, error) {
+	cs, err := zkp.CompileNonRevocation(shape)
+	if err != nil {
+		return nil, err
+	}
+
+	pk := groth16.NewProvingKey(ecc.BN254)
+	err = readKey(pkPath, pk)
+	if err != nil {
+		return nil, fmt.Errorf("reading proving key: %w", err)
+	}
+	vk := groth16.NewVerifyingKey(ecc.BN254)
+	err = readKey(vkPath, vk)
+	if err != nil {
+		return nil, fmt.Errorf("reading verifying key: %w", err)
+	}
+
+	return &NonRevocationProver{shape: shape, cs: cs, pk: pk, vk: vk}, nil
+}
+
+// readKey reads a proving or verifying key from the file at path.
+func readKey(path string, key io.ReaderFrom) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = key.ReadFrom(f)
+	return err
+}
+
+// Shape returns the cascade shape of the prover's circuit.
+func (p *NonRevocationProver) Shape() zkp.CascadeShape {
+	return p.shape
+}
+
+// GenProof proves that the revocation token of a MultiShow credential for the epoch is not accepted by the cascade,
+// which must hash with bloom.MiMCBN254 and fit the prover's shape. The proof is bound to the challenge of the verifier
+// it is presented to, see zkp.Challenge. It returns the proof, the proof in on-chain format, the full witness and the
+// public inputs [issuerX, issuerY, cascadeRoot, epoch, challenge] of the Solidity verifier.
+func (p *NonRevocationProver) GenProof(cred issuer.InternalCredential, cascade *bloom.BloomFilterCascade, epochUnix int64, challenge *big.Int) (proof groth16.Proof, proofBytes [8]*big.Int, fullWitness witness.Witness, publicInputs [5]*big.Int, err error) {
+	if challenge == nil {
+		return nil, proofBytes, nil, publicInputs, fmt.Errorf("missing verifier challenge")
+	}
+	if cred.Credential.Type != issuer.MultiShow {
+		return nil, proofBytes, nil, publicInputs, fmt.Errorf("credential type is not supported")
+	}
+	if cred.Credential.Expired(epochUnix) {
+		return nil, proofBytes, nil, publicInputs, fmt.Errorf("credential expired after epoch %d", cred.Credential.ValidUntil)
+	}
+	if cascade.Epoch() != 0 && cascade.Epoch() != epochUnix {
+		return nil, proofBytes, nil, publicInputs, fmt.Errorf("cascade is for epoch %d, not %d", cascade.Epoch(), epochUnix)
+	}
+
+	token, _, err := cred.GenRevocationToken(epochUnix)
+	if err != nil {
+		return nil, proofBytes, nil, publicInputs, err
+	}
+	if revoked, _ := cascade.Test(token); revoked {
+		return nil, proofBytes, nil, publicInputs, errors.New("credential is revoked")
+	}
+
+	root, err := zkp.CascadeCommitment(cascade, p.shape)
+	if err != nil {
+		return nil, proofBytes, nil, publicInputs, err
+	}
+	layerCount, layers, err := zkp.CascadeOpening(cascade, p.shape, token)
+	if err != nil {
+		return nil, proofBytes, nil, publicInputs, err
+	}
+
+	pkVrf, err := cred.VrfKeyPair.GetMultiShowPublicKey()
+	if err != nil {
+		return nil, proofBytes, nil, publicInputs, err
+	}
+	issPubKey := eddsa.PublicKey{}
+	_, err = issPubKey.SetBytes(cred.IssuerPublicKey)
+	if err != nil {
+		return nil, proofBytes, nil, publicInputs, err
+	}
+	icCredSigInCircuit := eddsaInCicuit.Signature{}
+	icCredSigInCircuit.Assign(tedwards.BN254, cred.Credential.Signature)
+
+	assignment := &zkp.NonRevocationProof{
+		VrfSecretKey:  cred.VrfKeyPair.PrivateKey,
+		VrfPublicKey:  eddsaInCicuit.PublicKey{A: twistededwards.Point{X: pkVrf.A.X, Y: pkVrf.A.Y}},
+		CredSignature: icCredSigInCircuit,
+		ValidUntil:    cred.Credential.ValidUntil,
+		LayerCount:    layerCount,
+		Layers:        layers,
+		IssuerPubKey:  eddsaInCicuit.PublicKey{A: twistededwards.Point{X: issPubKey.A.X, Y: issPubKey.A.Y}},
+		CascadeRoot:   root,
+		Epoch:         epochUnix,
+		Challenge:     challenge,
+	}
+
+	fullWitness, err = frontend.NewWitness(assignment, ecc.BN254.ScalarField())
+	if err != nil {
+		return nil, proofBytes, nil, publicInputs, err
+	}
+
+	proof, err = groth16.Prove(p.cs, p.pk, fullWitness)
+	if err != nil {
+		return nil, proofBytes, nil, publicInputs, err
+	}
+
+	proofBytes, err = groth16ProofToOnChainInput(proof)
+	if err != nil {
+		return nil, proofBytes, nil, publicInputs, err
+	}
+
+	publicInputs = [5]*big.Int{
+		issPubKey.A.X.BigInt(new(big.Int)),
+		issPubKey.A.Y.BigInt(new(big.Int)),
+		root,
+		big.NewInt(epochUnix),
+		new(big.Int).Set(challenge),
+	}
+	return proof, proofBytes, fullWitness, publicInputs, nil
+}
+
+// VerifyProof verifies a proof against its public witness.
+func (p *NonRevocationProver) VerifyProof(proof groth16.Proof, publicWitness witness.Witness) error {
+	return groth16.Verify(proof, p.vk, publicWitness)
+}
+
+// ExportSolidity writes the Solidity verifier contract of the prover's verifying key. Its verifyProof takes the
+// proof in on-chain format and the public inputs as returned by GenProof.
+func (p *NonRevocationProver) ExportSolidity(w io.Writer) error {
+	return p.vk.ExportSolidity(w)
+}
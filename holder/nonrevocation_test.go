@@ -0,0 +1,76 @@
+package holder
+
+import (
+	"PrivacyPreservingRevocationCode/bloom"
+	"PrivacyPreservingRevocationCode/issuer"
+	"PrivacyPreservingRevocationCode/zkp"
+	"bytes"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testCascadeShape fits the artifacts of a handful of credentials.
+var testCascadeShape = zkp.CascadeShape{Layers: 10, K: 3, Depth: 2}
+
+func TestNonRevocationProver(t *testing.T) {
+	dir := t.TempDir()
+	pkPath, vkPath := filepath.Join(dir, "nonrevocation.g16.pk"), filepath.Join(dir, "nonrevocation.g16.vk")
+	var pk, vk, sol bytes.Buffer
+	require.NoError(t, zkp.SetupNonRevocation(testCascadeShape, &pk, &vk, &sol))
+	require.NoError(t, os.WriteFile(pkPath, pk.Bytes(), 0o600))
+	require.NoError(t, os.WriteFile(vkPath, vk.Bytes(), 0o600))
+
+	prover, err := NewNonRevocationProver(testCascadeShape, pkPath, vkPath)
+	require.NoError(t, err)
+	require.Equal(t, testCascadeShape, prover.Shape())
+
+	iss := issuer.NewIssuer(issuer.MultiShow)
+	iss.SetArtifactHasher(bloom.MiMCBN254)
+	for id := uint(0); id < 4; id++ {
+		require.NoError(t, iss.IssueCredential(id))
+	}
+	require.NoError(t, iss.RevokeCredential(0))
+	cascade, _, _, epochUnix, err := iss.GenRevocationArtifact()
+	require.NoError(t, err)
+
+	valid, err := iss.GetCredentialCopy(1)
+	require.NoError(t, err)
+	challenge := zkp.Challenge([32]byte{1}, []byte("verifier"))
+	proof, proofBytes, fullWitness, publicInputs, err := prover.GenProof(valid, cascade, epochUnix, challenge)
+	require.NoError(t, err)
+	require.NotNil(t, proofBytes[0])
+
+	publicWitness, err := fullWitness.Public()
+	require.NoError(t, err)
+	require.NoError(t, prover.VerifyProof(proof, publicWitness))
+
+	// The proof does not verify for the challenge of another verifier, the last public input.
+	data, err := publicWitness.MarshalBinary()
+	require.NoError(t, err)
+	other := zkp.Challenge([32]byte{1}, []byte("other verifier"))
+	other.FillBytes(data[len(data)-32:])
+	require.NoError(t, publicWitness.UnmarshalBinary(data))
+	require.Error(t, prover.VerifyProof(proof, publicWitness))
+
+	// The public inputs are the issuer key, the cascade commitment, the epoch and the challenge, but not the token.
+	root, err := zkp.CascadeCommitment(cascade, testCascadeShape)
+	require.NoError(t, err)
+	require.Equal(t, root, publicInputs[2])
+	require.Equal(t, epochUnix, publicInputs[3].Int64())
+	require.Equal(t, challenge, publicInputs[4])
+	_, _, _, _, err = prover.GenProof(valid, cascade, epochUnix, nil)
+	require.ErrorContains(t, err, "challenge")
+
+	revoked, err := iss.GetCredentialCopy(0)
+	require.NoError(t, err)
+	_, _, _, _, err = prover.GenProof(revoked, cascade, epochUnix, challenge)
+	require.Error(t, err)
+	_, _, _, _, err = prover.GenProof(valid, cascade, epochUnix+1, challenge)
+	require.Error(t, err)
+
+	var exported bytes.Buffer
+	require.NoError(t, prover.ExportSolidity(&exported))
+	require.Equal(t, sol.String(), exported.String())
+}
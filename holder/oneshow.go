@@ -0,0 +1,199 @@
+package holder
+
+import (
+	"PrivacyPreservingRevocationCode/epoch"
+	"PrivacyPreservingRevocationCode/issuer"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/leandro-ro/go-ecvrf"
+	"math/big"
+)
+
+// vrfProofLen is the length of an ECVRF-SECP256K1-SHA256-TAI proof: compressed Gamma (33), c (16) and s (32).
+const vrfProofLen = 81
+
+// OneShowPresentation holds everything the OneShowVerifier contract needs to check a OneShow credential for an epoch,
+// for both checkCredential and checkCredentialFast.
+type OneShowPresentation struct {
+	PublicKey       []byte                 // PublicKey is the compressed VRF public key (33 bytes).
+	Signature       []byte                 // Signature is the issuer's credential signature, see OneShowSignature.
+	Proof           []byte                 // Proof is the VRF proof on the epoch (81 bytes).
+	Epoch           int64                  // Epoch is the unix epoch the proof was created for.
+	Nonce           [32]byte               // Nonce is the verifier's nonce the presentation is bound to.
+	HolderSignature []byte                 // HolderSignature is the VRF key's signature over OneShowBindingHash.
+	RevocationToken issuer.RevocationToken // RevocationToken is the VRF output the verifier tests against the artifact.
+	UPoint          [2]*big.Int            // UPoint is U = s*B - c*Y as expected by checkCredentialFast.
+	VComponents     [4]*big.Int            // VComponents are [sHx, sHy, cGammaX, cGammaY] as expected by checkCredentialFast.
+}
+
+// OneShowProver creates OneShow presentations. It is the OneShow counterpart of RevocationTokenProver.
+type OneShowProver struct{}
+
+// NewOneShowProver returns a new OneShowProver.
+func NewOneShowProver() *OneShowProver {
+	return &OneShowProver{}
+}
+
+// OneShowBindingHash returns the message a holder signs with its VRF key to bind a presentation to the verifier's
+// nonce, the verifier and the epoch: keccak256(nonce || verifierID || epoch), with the epoch as 32 byte big-endian
+// integer. The OneShowVerifier contract uses its address as identifier (see its bindingHash).
+func OneShowBindingHash(nonce [32]byte, verifierID []byte, epochUnix int64) []byte {
+	return crypto.Keccak256(nonce[:], verifierID, big.NewInt(epochUnix).FillBytes(make([]byte, 32)))
+}
+
+// OneShowSignature returns the credential signature a presentation carries: the issuer's 65 byte signature over
+// keccak256(PublicKey) for unbounded credentials, and for credentials with a validity bound the signature over
+// keccak256(keccak256(PublicKey) || ValidUntil) followed by the bound as 8 byte big-endian integer.
+func OneShowSignature(cred issuer.Credential) []byte {
+	signature := append([]byte(nil), cred.Signature...)
+	if cred.ValidUntil == 0 {
+		return signature
+	}
+	return append(signature, epoch.Bytes(cred.ValidUntil)...)
+}
+
+// GenProof evaluates the VRF of a OneShow credential on the epoch and returns the presentation including the
+// parameters for fast on-chain verification. The presentation is bound to the nonce handed out by the verifier
+// identified by verifierID with a signature of the VRF key, so an observer cannot present it again.
+func (o *OneShowProver) GenProof(cred issuer.InternalCredential, epochUnix int64, nonce [32]byte, verifierID []byte) (*OneShowPresentation, error) {
+	if cred.Credential.Type != issuer.OneShow {
+		return nil, fmt.Errorf("credential type is not supported")
+	}
+	if cred.VrfKeyPair == nil {
+		return nil, errors.New("credential holds no VRF key pair")
+	}
+	if cred.Credential.Expired(epochUnix) {
+		return nil, fmt.Errorf("credential expired after epoch %d", cred.Credential.ValidUntil)
+	}
+
+	token, proof, err := cred.GenRevocationToken(epochUnix)
+	if err != nil {
+		return nil, err
+	}
+	pubKey, err := cred.VrfKeyPair.GetPublicKeyForOnChain()
+	if err != nil {
+		return nil, err
+	}
+	uPoint, vComponents, err := FastVerifyParams(pubKey, proof, epochUnix)
+	if err != nil {
+		return nil, err
+	}
+
+	vrfKey, err := crypto.ToECDSA(cred.VrfKeyPair.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	holderSig, err := crypto.Sign(OneShowBindingHash(nonce, verifierID, epochUnix), vrfKey)
+	if err != nil {
+		return nil, err
+	}
+	holderSig[64] += 27 // ecrecover expects v in {27, 28}
+
+	return &OneShowPresentation{
+		PublicKey:       pubKey,
+		Signature:       OneShowSignature(cred.Credential),
+		Proof:           proof,
+		Epoch:           epochUnix,
+		Nonce:           nonce,
+		HolderSignature: holderSig,
+		RevocationToken: token,
+		UPoint:          uPoint,
+		VComponents:     vComponents,
+	}, nil
+}
+
+// VerifyProof verifies the VRF proof of a presentation and checks that it yields the presentation's revocation token.
+func (o *OneShowProver) VerifyProof(p *OneShowPresentation) error {
+	pk, err := secp256k1.ParsePubKey(p.PublicKey)
+	if err != nil {
+		return fmt.Errorf("invalid VRF public key: %w", err)
+	}
+	token, err := ecvrf.Secp256k1Sha256Tai.Verify(pk.ToECDSA(), epoch.Bytes(p.Epoch), p.Proof)
+	if err != nil {
+		return err
+	}
+	if string(token) != string(p.RevocationToken) {
+		return errors.New("VRF output does not match revocation token")
+	}
+	return nil
+}
+
+// FastVerifyParams computes the auxiliary points of checkCredentialFast off-chain, matching the contract's
+// getFastVerifyParams: U = s*B - c*Y and the components s*H and c*Gamma of V = s*H - c*Gamma, where H is the
+// try-and-increment hash of the public key and epoch.
+func FastVerifyParams(pubKey, proof []byte, epochUnix int64) (uPoint [2]*big.Int, vComponents [4]*big.Int, err error) {
+	if len(pubKey) != secp256k1.PubKeyBytesLenCompressed {
+		return uPoint, vComponents, errors.New("VRF public key must be compressed")
+	}
+	if len(proof) != vrfProofLen {
+		return uPoint, vComponents, fmt.Errorf("VRF proof must be %d bytes", vrfProofLen)
+	}
+	pk, err := secp256k1.ParsePubKey(pubKey)
+	if err != nil {
+		return uPoint, vComponents, fmt.Errorf("invalid VRF public key: %w", err)
+	}
+	gammaKey, err := secp256k1.ParsePubKey(proof[:33])
+	if err != nil {
+		return uPoint, vComponents, fmt.Errorf("invalid VRF proof: %w", err)
+	}
+	var c, s secp256k1.ModNScalar
+	c.SetByteSlice(proof[33:49])
+	if s.SetByteSlice(proof[49:81]) {
+		return uPoint, vComponents, errors.New("invalid VRF proof: s out of range")
+	}
+
+	h, err := hashToTryAndIncrement(pubKey, epoch.Bytes(epochUnix))
+	if err != nil {
+		return uPoint, vComponents, err
+	}
+
+	var y, gamma, sB, cY, u, sH, cGamma secp256k1.JacobianPoint
+	pk.AsJacobian(&y)
+	gammaKey.AsJacobian(&gamma)
+
+	secp256k1.ScalarBaseMultNonConst(&s, &sB)
+	secp256k1.ScalarMultNonConst(&c, &y, &cY)
+	cY.ToAffine()
+	cY.Y.Negate(1).Normalize()
+	secp256k1.AddNonConst(&sB, &cY, &u)
+
+	secp256k1.ScalarMultNonConst(&s, h, &sH)
+	secp256k1.ScalarMultNonConst(&c, &gamma, &cGamma)
+
+	ux, uy := affineBigInts(&u)
+	shx, shy := affineBigInts(&sH)
+	cgx, cgy := affineBigInts(&cGamma)
+	return [2]*big.Int{ux, uy}, [4]*big.Int{shx, shy, cgx, cgy}, nil
+}
+
+// hashToTryAndIncrement maps a compressed public key and message to a curve point as in VRF.sol: the x coordinate is
+// sha256(0xFE || 0x01 || pubKey || message || ctr) for the first counter yielding a point, with even y.
+func hashToTryAndIncrement(pubKey, message []byte) (*secp256k1.JacobianPoint, error) {
+	prefix := append(append([]byte{0xFE, 0x01}, pubKey...), message...)
+	for ctr := 0; ctr < 256; ctr++ {
+		digest := sha256.Sum256(append(prefix, byte(ctr)))
+		var x, y secp256k1.FieldVal
+		if x.SetByteSlice(digest[:]) || x.IsZero() {
+			continue
+		}
+		if !secp256k1.DecompressY(&x, false, &y) {
+			continue
+		}
+		var p secp256k1.JacobianPoint
+		p.X, p.Y = x, y
+		p.Z.SetInt(1)
+		return &p, nil
+	}
+	return nil, errors.New("no valid curve point found for VRF input")
+}
+
+// affineBigInts returns the affine coordinates of p as big integers.
+func affineBigInts(p *secp256k1.JacobianPoint) (*big.Int, *big.Int) {
+	a := *p
+	a.ToAffine()
+	x, y := a.X.Bytes(), a.Y.Bytes()
+	return new(big.Int).SetBytes(x[:]), new(big.Int).SetBytes(y[:])
+}
@@ -0,0 +1,57 @@
+package holder
+
+import (
+	"PrivacyPreservingRevocationCode/issuer"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestOneShowProver_GenProof(t *testing.T) {
+	iss := issuer.NewIssuer(issuer.OneShow)
+	require.NoError(t, iss.IssueCredential(0))
+	cred, err := iss.GetCredentialCopy(0)
+	require.NoError(t, err)
+
+	prover := NewOneShowProver()
+	epochUnix := time.Now().UTC().Unix()
+	nonce, verifierID := [32]byte{1}, []byte("verifier")
+	pres, err := prover.GenProof(cred, epochUnix, nonce, verifierID)
+	require.NoError(t, err)
+	require.Len(t, pres.PublicKey, 33)
+	require.Len(t, pres.Proof, vrfProofLen)
+	require.Equal(t, cred.Credential.Signature, pres.Signature)
+	require.Equal(t, epochUnix, pres.Epoch)
+	require.Equal(t, nonce, pres.Nonce)
+	require.NoError(t, prover.VerifyProof(pres))
+
+	// The holder signature binds the presentation to the nonce, the verifier and the epoch under the VRF key.
+	require.Len(t, pres.HolderSignature, 65)
+	sig := append([]byte(nil), pres.HolderSignature...)
+	sig[64] -= 27
+	signer, err := crypto.SigToPub(OneShowBindingHash(nonce, verifierID, epochUnix), sig)
+	require.NoError(t, err)
+	require.Equal(t, pres.PublicKey, crypto.CompressPubkey(signer))
+	require.NotEqual(t, OneShowBindingHash(nonce, verifierID, epochUnix), OneShowBindingHash([32]byte{2}, verifierID, epochUnix))
+	require.NotEqual(t, OneShowBindingHash(nonce, verifierID, epochUnix), OneShowBindingHash(nonce, []byte("other"), epochUnix))
+	require.NotEqual(t, OneShowBindingHash(nonce, verifierID, epochUnix), OneShowBindingHash(nonce, verifierID, epochUnix+1))
+
+	token, _, err := cred.GenRevocationToken(epochUnix)
+	require.NoError(t, err)
+	require.Equal(t, token, pres.RevocationToken)
+
+	// The proof does not verify for another epoch.
+	pres.Epoch++
+	require.Error(t, prover.VerifyProof(pres))
+
+	_, _, err = FastVerifyParams(pres.PublicKey, pres.Proof[:80], epochUnix)
+	require.Error(t, err)
+
+	multiShow := issuer.NewIssuer(issuer.MultiShow)
+	require.NoError(t, multiShow.IssueCredential(0))
+	multiShowCred, err := multiShow.GetCredentialCopy(0)
+	require.NoError(t, err)
+	_, err = prover.GenProof(multiShowCred, epochUnix, nonce, verifierID)
+	require.Error(t, err)
+}
@@ -0,0 +1,112 @@
+package holder
+
+import (
+	"PrivacyPreservingRevocationCode/issuer"
+	"PrivacyPreservingRevocationCode/zkp"
+	"bytes"
+	"fmt"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/backend/solidity"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/frontend"
+	"io"
+	"math/big"
+	"sync"
+)
+
+// PlonkRevocationTokenProver generates and verifies PLONK proofs of the revocation token of MultiShow credentials.
+// Its keys are derived from a universal KZG SRS (see zkp.SetupRevocationTokenPlonk) instead of a circuit-specific
+// trusted setup. On-chain, its proofs are verified by the PlonkVerifier contract of ExportSolidity.
+type PlonkRevocationTokenProver struct {
+	cs constraint.ConstraintSystem // cs represents the sparse constraint system of the zkp.RevocationTokenProof circuit.
+	pk plonk.ProvingKey            // pk represents the PLONK proving key, which includes the SRS.
+	vk plonk.VerifyingKey          // vk represents the PLONK verifying key.
+}
+
+// compiledPlonkRevocationToken compiles the sparse constraint system of the zkp.RevocationTokenProof circuit once and
+// shares it between all PLONK provers, which only read it.
+var compiledPlonkRevocationToken = sync.OnceValues(func() (constraint.ConstraintSystem, error) {
+	return zkp.CompileRevocationToken(zkp.Plonk)
+})
+
+// NewPlonkRevocationTokenProver reads the PLONK keys of the zkp.RevocationTokenProof circuit, as written by
+// zkp.SetupRevocationTokenPlonk.
+func NewPlonkRevocationTokenProver(pkPath, vkPath string) (*PlonkRevocationTokenProver, error) {
+	cs, err := compiledPlonkRevocationToken()
+	if err != nil {
+		return nil, err
+	}
+
+	pk := plonk.NewProvingKey(ecc.BN254)
+	err = readKey(pkPath, pk)
+	if err != nil {
+		return nil, fmt.Errorf("reading proving key: %w", err)
+	}
+	vk := plonk.NewVerifyingKey(ecc.BN254)
+	err = readKey(vkPath, vk)
+	if err != nil {
+		return nil, fmt.Errorf("reading verifying key: %w", err)
+	}
+
+	return &PlonkRevocationTokenProver{cs: cs, pk: pk, vk: vk}, nil
+}
+
+// Backend returns zkp.Plonk.
+func (p *PlonkRevocationTokenProver) Backend() zkp.Backend {
+	return zkp.Plonk
+}
+
+// Prove proves the revocation token of a MultiShow credential for the epoch, bound to the verifier's challenge.
+func (p *PlonkRevocationTokenProver) Prove(cred issuer.InternalCredential, epochUnix int64, challenge *big.Int) (*TokenProof, error) {
+	assignment, err := revocationTokenAssignment(cred, epochUnix, challenge)
+	if err != nil {
+		return nil, err
+	}
+
+	fullWitness, err := frontend.NewWitness(assignment, ecc.BN254.ScalarField())
+	if err != nil {
+		return nil, err
+	}
+
+	proof, err := plonk.Prove(p.cs, p.pk, fullWitness, solidity.WithProverTargetSolidityVerifier(backend.PLONK))
+	if err != nil {
+		return nil, err
+	}
+	encoded, err := encodeProof(proof)
+	if err != nil {
+		return nil, err
+	}
+
+	publicInputs, err := zkp.NewPublicInputs(assignment)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenProof{Backend: zkp.Plonk, Proof: encoded, PublicInputs: *publicInputs}, nil
+}
+
+// Verify verifies a PLONK proof as returned by Prove.
+func (p *PlonkRevocationTokenProver) Verify(proof *TokenProof) error {
+	if proof.Backend != zkp.Plonk {
+		return fmt.Errorf("proof is for backend %s, not %s", proof.Backend, zkp.Plonk)
+	}
+	decoded := plonk.NewProof(ecc.BN254)
+	_, err := decoded.ReadFrom(bytes.NewReader(proof.Proof))
+	if err != nil {
+		return err
+	}
+
+	publicWitness, err := proof.PublicInputs.Witness()
+	if err != nil {
+		return err
+	}
+	return plonk.Verify(decoded, p.vk, publicWitness, solidity.WithVerifierTargetSolidityVerifier(backend.PLONK))
+}
+
+// ExportSolidity writes the PlonkVerifier contract of the prover's verifying key, i.e.
+// revocationTokenPlonkVerifier.sol for the shipped keys. Its Verify takes TokenProof.Solidity and the public inputs.
+func (p *PlonkRevocationTokenProver) ExportSolidity(w io.Writer) error {
+	return p.vk.ExportSolidity(w)
+}
@@ -0,0 +1,47 @@
+package holder
+
+import (
+	"PrivacyPreservingRevocationCode/issuer"
+	"context"
+	"math/big"
+	"runtime"
+)
+
+// ProverPool shares a TokenProver between goroutines, e.g. the requests of a service proving for many holders, and
+// bounds the number of proofs generated at once. Each proof already uses all CPUs for parts of its work, so a few
+// workers suffice to keep them busy while bounding the memory of concurrent proofs.
+type ProverPool struct {
+	prover  TokenProver   // prover generates the proofs.
+	workers chan struct{} // workers holds a token for each proof in progress.
+}
+
+// NewProverPool returns a pool generating at most workers proofs with prover at once. If workers is not positive, it
+// defaults to the number of CPUs.
+func NewProverPool(prover TokenProver, workers int) *ProverPool {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	return &ProverPool{prover: prover, workers: make(chan struct{}, workers)}
+}
+
+// Workers returns the maximum number of proofs the pool generates at once.
+func (p *ProverPool) Workers() int {
+	return cap(p.workers)
+}
+
+// Prove waits for a free worker and proves like TokenProver.Prove. It returns the context's error if the context is
+// done before a worker is free; a proof in progress is not interrupted.
+func (p *ProverPool) Prove(ctx context.Context, cred issuer.InternalCredential, epochUnix int64, challenge *big.Int) (*TokenProof, error) {
+	select {
+	case p.workers <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	defer func() { <-p.workers }()
+
+	err := ctx.Err()
+	if err != nil {
+		return nil, err
+	}
+	return p.prover.Prove(cred, epochUnix, challenge)
+}
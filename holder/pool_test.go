@@ -0,0 +1,93 @@
+package holder
+
+import (
+	"PrivacyPreservingRevocationCode/issuer"
+	"PrivacyPreservingRevocationCode/zkp"
+	"context"
+	"github.com/stretchr/testify/require"
+	"io"
+	"math/big"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingProver records the maximum number of concurrent calls to Prove.
+type countingProver struct {
+	active, max atomic.Int32
+}
+
+func (c *countingProver) Backend() zkp.Backend { return zkp.Groth16 }
+
+func (c *countingProver) Prove(issuer.InternalCredential, int64, *big.Int) (*TokenProof, error) {
+	n := c.active.Add(1)
+	defer c.active.Add(-1)
+	for {
+		m := c.max.Load()
+		if n <= m || c.max.CompareAndSwap(m, n) {
+			break
+		}
+	}
+	time.Sleep(10 * time.Millisecond)
+	return &TokenProof{}, nil
+}
+
+func (c *countingProver) Verify(*TokenProof) error { return nil }
+
+func (c *countingProver) ExportSolidity(io.Writer) error { return nil }
+
+func TestProverPool_Bounded(t *testing.T) {
+	prover := &countingProver{}
+	pool := NewProverPool(prover, 3)
+	require.Equal(t, 3, pool.Workers())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := pool.Prove(context.Background(), issuer.InternalCredential{}, 0, big.NewInt(0))
+			require.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+	require.Equal(t, int32(3), prover.max.Load())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := pool.Prove(ctx, issuer.InternalCredential{}, 0, big.NewInt(0))
+	require.ErrorIs(t, err, context.Canceled)
+	require.Positive(t, NewProverPool(prover, 0).Workers())
+}
+
+func TestProverPool_Concurrent(t *testing.T) {
+	prover, err := NewRevocationTokenProver("../zkp/sol/build/verifier.g16.pk", "../zkp/sol/build/verifier.g16.vk")
+	require.NoError(t, err)
+	pool := NewProverPool(prover, 2)
+
+	iss := issuer.NewIssuer(issuer.MultiShow)
+	require.NoError(t, iss.IssueCredentials(4))
+	epochUnix := time.Now().UTC().Unix()
+	challenge := zkp.Challenge([32]byte{1}, []byte("verifier"))
+
+	creds := iss.GetAllValidCreds()
+	proofs := make([]*TokenProof, len(creds))
+	errs := make([]error, len(creds))
+	var wg sync.WaitGroup
+	for i, cred := range creds {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			proofs[i], errs[i] = pool.Prove(context.Background(), *cred, epochUnix, challenge)
+		}()
+	}
+	wg.Wait()
+	for i, cred := range creds {
+		require.NoError(t, errs[i])
+		require.NoError(t, prover.Verify(proofs[i]))
+		token, _, err := cred.GenRevocationToken(epochUnix)
+		require.NoError(t, err)
+		require.Equal(t, new(big.Int).SetBytes(token), proofs[i].PublicInputs.RevocationToken)
+	}
+}
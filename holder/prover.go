@@ -5,163 +5,203 @@ import (
 	"PrivacyPreservingRevocationCode/zkp"
 	"bytes"
 	"encoding/binary"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/consensys/gnark-crypto/ecc"
 	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards/eddsa"
 	tedwards "github.com/consensys/gnark-crypto/ecc/twistededwards"
 	"github.com/consensys/gnark/backend/groth16"
+	groth16bn254 "github.com/consensys/gnark/backend/groth16/bn254"
 	"github.com/consensys/gnark/backend/witness"
 	"github.com/consensys/gnark/constraint"
 	"github.com/consensys/gnark/frontend"
-	"github.com/consensys/gnark/frontend/cs/r1cs"
-	"github.com/consensys/gnark/frontend/schema"
 	"github.com/consensys/gnark/std/algebra/native/twistededwards"
 	eddsaInCicuit "github.com/consensys/gnark/std/signature/eddsa"
+	"io"
 	"math/big"
-	"os"
-	"reflect"
+	"sync"
 )
 
 // RevocationTokenProver is a struct for generating and verifying zero-knowledge proofs for credential revocation tokens.
 // It uses Groth16 proving and verifying keys, as well as a constraint system for proof construction and validation.
+// It is safe for concurrent use; ProverPool bounds the number of proofs generated at once.
 type RevocationTokenProver struct {
 	cs constraint.ConstraintSystem // cs represents the constraint system used in zero-knowledge proof generation.
 	pk groth16.ProvingKey          // pk represents the Groth16 proving key used for generating zero-knowledge proofs.
 	vk groth16.VerifyingKey        // vk represents the Groth16 verifying key used for verifying zero-knowledge proofs.
 }
 
-// NewRevocationTokenProver initializes and returns a new RevocationTokenProver instance for generating and verifying proofs.
+// compiledRevocationToken compiles the R1CS of the zkp.RevocationTokenProof circuit once and shares it between all
+// provers, which only read it.
+var compiledRevocationToken = sync.OnceValues(func() (constraint.ConstraintSystem, error) {
+	return zkp.CompileRevocationToken(zkp.Groth16)
+})
+
+// NewRevocationTokenProver reads the Groth16 keys of the zkp.RevocationTokenProof circuit from files, e.g. the shipped
+// verifier.g16.pk and verifier.g16.vk or the keys of a ceremony, and checks that they match the circuit.
 func NewRevocationTokenProver(pkPath, vkPath string) (*RevocationTokenProver, error) {
-	var circuit zkp.RevocationTokenProof
-	r1, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &circuit)
+	ccs, err := compiledRevocationToken()
 	if err != nil {
 		return nil, err
 	}
 
-	pkFile, err := os.Open(pkPath)
-	if err != nil {
-		return nil, err
-	}
 	pk := groth16.NewProvingKey(ecc.BN254)
-	_, err = pk.ReadFrom(pkFile)
-
-	vkFile, err := os.Open(vkPath)
+	err = readKey(pkPath, pk)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("reading proving key: %w", err)
 	}
 	vk := groth16.NewVerifyingKey(ecc.BN254)
-	_, err = vk.ReadFrom(vkFile)
+	err = readKey(vkPath, vk)
+	if err != nil {
+		return nil, fmt.Errorf("reading verifying key: %w", err)
+	}
 
-	return &RevocationTokenProver{r1, pk, vk}, nil
+	return newRevocationTokenProver(ccs, pk, vk)
 }
 
-// GenProof generates a zero-knowledge proof for a credential's revocation token based on the provided epoch timestamp.
-// It supports MultiShow credential types and returns the proof, proof in byte array, witness, and an error if any occur.
-func (r *RevocationTokenProver) GenProof(cred issuer.InternalCredential, epochUnix int64) (proof groth16.Proof, proofBytes [8]*big.Int, witness witness.Witness, witnessBytes [4]*big.Int, err error) {
-	if cred.Credential.Type != issuer.MultiShow {
-		return nil, [8]*big.Int{}, nil, [4]*big.Int{}, fmt.Errorf("credential type is not supported")
+// LoadRevocationTokenProver reads a serialized constraint system of the zkp.RevocationTokenProof circuit and its
+// Groth16 keys, e.g. the shipped verifier.g16.ccs, verifier.g16.pk and verifier.g16.vk, instead of compiling the
+// circuit. The constraint system and verifying key must have the zkp.KeyHash keyHash recorded at setup, which is
+// zkp.RevocationTokenKeyHash for the shipped files.
+func LoadRevocationTokenProver(ccsIn, pkIn, vkIn io.Reader, keyHash []byte) (*RevocationTokenProver, error) {
+	ccs := groth16.NewCS(ecc.BN254)
+	_, err := ccs.ReadFrom(ccsIn)
+	if err != nil {
+		return nil, fmt.Errorf("reading constraint system: %w", err)
 	}
-
-	token, _, err := cred.GenRevocationToken(epochUnix)
+	pk := groth16.NewProvingKey(ecc.BN254)
+	_, err = pk.ReadFrom(pkIn)
 	if err != nil {
-		return nil, [8]*big.Int{}, nil, [4]*big.Int{}, err
+		return nil, fmt.Errorf("reading proving key: %w", err)
 	}
-
-	pkVrf, err := cred.VrfKeyPair.GetMultiShowPublicKey()
+	vk := groth16.NewVerifyingKey(ecc.BN254)
+	_, err = vk.ReadFrom(vkIn)
 	if err != nil {
-		return nil, [8]*big.Int{}, nil, [4]*big.Int{}, err
+		return nil, fmt.Errorf("reading verifying key: %w", err)
 	}
 
-	icCredSigInCircuit := eddsaInCicuit.Signature{}
-	icCredSigInCircuit.Assign(tedwards.BN254, cred.Credential.Signature)
+	hash, err := zkp.KeyHash(ccs, vk)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(hash, keyHash) {
+		return nil, errors.New("constraint system and verifying key do not match the key hash")
+	}
+	return newRevocationTokenProver(ccs, pk, vk)
+}
 
-	icEpoch := make([]byte, 8)
-	binary.BigEndian.PutUint64(icEpoch, uint64(epochUnix))
+// newRevocationTokenProver returns a prover for the constraint system and keys after checking that the keys belong
+// to each other and fit the constraint system.
+func newRevocationTokenProver(ccs constraint.ConstraintSystem, pk groth16.ProvingKey, vk groth16.VerifyingKey) (*RevocationTokenProver, error) {
+	bpk, ok := pk.(*groth16bn254.ProvingKey)
+	if !ok {
+		return nil, errors.New("proving key is not on BN254")
+	}
+	bvk, ok := vk.(*groth16bn254.VerifyingKey)
+	if !ok {
+		return nil, errors.New("verifying key is not on BN254")
+	}
 
-	issPubKey := eddsa.PublicKey{}
-	_, err = issPubKey.SetBytes(cred.IssuerPublicKey)
-	if err != nil {
-		return nil, [8]*big.Int{}, nil, [4]*big.Int{}, err
+	if !bpk.G1.Alpha.Equal(&bvk.G1.Alpha) || !bpk.G1.Beta.Equal(&bvk.G1.Beta) || !bpk.G1.Delta.Equal(&bvk.G1.Delta) ||
+		!bpk.G2.Beta.Equal(&bvk.G2.Beta) || !bpk.G2.Delta.Equal(&bvk.G2.Delta) {
+		return nil, errors.New("proving and verifying key are from different setups")
+	}
+	nbWires := ccs.GetNbInternalVariables() + ccs.GetNbSecretVariables() + ccs.GetNbPublicVariables()
+	if bpk.Domain.Cardinality != ecc.NextPowerOfTwo(uint64(ccs.GetNbConstraints())) || len(bpk.InfinityA) != nbWires {
+		return nil, errors.New("proving key does not match the constraint system")
+	}
+	// The public variables of the constraint system include the constant one wire, the public witness does not.
+	if vk.NbPublicWitness() != ccs.GetNbPublicVariables()-1 {
+		return nil, errors.New("verifying key does not match the constraint system")
 	}
 
-	icVrfPublicKey := eddsaInCicuit.PublicKey{A: twistededwards.Point{X: pkVrf.A.X, Y: pkVrf.A.Y}}
-	icIssuerPublicKey := eddsaInCicuit.PublicKey{A: twistededwards.Point{X: issPubKey.A.X, Y: issPubKey.A.Y}}
-	icToken := big.NewInt(0).SetBytes(token)
+	return &RevocationTokenProver{ccs, pk, vk}, nil
+}
 
-	assignment := &zkp.RevocationTokenProof{
-		VrfSecretKey:    cred.VrfKeyPair.PrivateKey,
-		VrfPublicKey:    icVrfPublicKey,
-		IssuerPubKey:    icIssuerPublicKey,
-		CredSignature:   icCredSigInCircuit,
-		RevocationToken: icToken,
-		Epoch:           icEpoch,
+// GenProof generates a zero-knowledge proof for a credential's revocation token based on the provided epoch timestamp.
+// The proof is bound to the challenge of the verifier it is presented to, see zkp.Challenge.
+// It supports MultiShow credential types and returns the proof, proof in byte array, witness, and an error if any occur.
+func (r *RevocationTokenProver) GenProof(cred issuer.InternalCredential, epochUnix int64, challenge *big.Int) (proof groth16.Proof, proofBytes [8]*big.Int, witness witness.Witness, witnessBytes [5]*big.Int, err error) {
+	assignment, err := revocationTokenAssignment(cred, epochUnix, challenge)
+	if err != nil {
+		return nil, [8]*big.Int{}, nil, [5]*big.Int{}, err
 	}
 
 	fullWitness, err := frontend.NewWitness(assignment, ecc.BN254.ScalarField())
 	if err != nil {
-		return nil, [8]*big.Int{}, nil, [4]*big.Int{}, err
+		return nil, [8]*big.Int{}, nil, [5]*big.Int{}, err
 	}
 
 	proof, err = groth16.Prove(r.cs, r.pk, fullWitness)
 	if err != nil {
-		return nil, [8]*big.Int{}, nil, [4]*big.Int{}, err
+		return nil, [8]*big.Int{}, nil, [5]*big.Int{}, err
 	}
 
 	proofBytes, err = groth16ProofToOnChainInput(proof)
 	if err != nil {
-		return nil, [8]*big.Int{}, nil, [4]*big.Int{}, err
+		return nil, [8]*big.Int{}, nil, [5]*big.Int{}, err
 	}
 
-	tLeaf := reflect.TypeOf((*frontend.Variable)(nil)).Elem()
-	sch, err := schema.New(assignment, tLeaf)
+	publicInputs, err := zkp.NewPublicInputs(assignment)
 	if err != nil {
-		return nil, [8]*big.Int{}, nil, [4]*big.Int{}, err
+		return nil, [8]*big.Int{}, nil, [5]*big.Int{}, err
 	}
 
-	publicWitness, err := fullWitness.Public()
-	if err != nil {
-		return nil, [8]*big.Int{}, nil, [4]*big.Int{}, err
+	return proof, proofBytes, fullWitness, publicInputs.Encode(), nil
+}
+
+// revocationTokenAssignment assigns the zkp.RevocationTokenProof circuit for a MultiShow credential, the epoch and the
+// verifier's challenge.
+func revocationTokenAssignment(cred issuer.InternalCredential, epochUnix int64, challenge *big.Int) (*zkp.RevocationTokenProof, error) {
+	if challenge == nil {
+		return nil, fmt.Errorf("missing verifier challenge")
+	}
+	if cred.Credential.Type != issuer.MultiShow {
+		return nil, fmt.Errorf("credential type is not supported")
+	}
+	if cred.Credential.Expired(epochUnix) {
+		return nil, fmt.Errorf("credential expired after epoch %d", cred.Credential.ValidUntil)
 	}
 
-	jsonPub, err := publicWitness.ToJSON(sch)
+	token, _, err := cred.GenRevocationToken(epochUnix)
 	if err != nil {
-		return nil, [8]*big.Int{}, nil, [4]*big.Int{}, err
+		return nil, err
 	}
 
-	var parsed map[string]any
-	err = json.Unmarshal(jsonPub, &parsed)
+	pkVrf, err := cred.VrfKeyPair.GetMultiShowPublicKey()
 	if err != nil {
-		return nil, [8]*big.Int{}, nil, [4]*big.Int{}, err
+		return nil, err
 	}
 
-	xStr := parsed["IssuerPubKey"].(map[string]any)["A"].(map[string]any)["X"].(string)
-	x := new(big.Int)
-	_, ok := x.SetString(xStr, 10)
-	if !ok {
-		return nil, [8]*big.Int{}, nil, [4]*big.Int{}, fmt.Errorf("invalid revocation token")
-	}
+	icCredSigInCircuit := eddsaInCicuit.Signature{}
+	icCredSigInCircuit.Assign(tedwards.BN254, cred.Credential.Signature)
 
-	yStr := parsed["IssuerPubKey"].(map[string]any)["A"].(map[string]any)["Y"].(string)
-	y := new(big.Int)
-	_, ok = y.SetString(yStr, 10)
-	if !ok {
-		return nil, [8]*big.Int{}, nil, [4]*big.Int{}, fmt.Errorf("invalid revocation token")
-	}
+	icEpoch := make([]byte, 8)
+	binary.BigEndian.PutUint64(icEpoch, uint64(epochUnix))
 
-	rtStr := parsed["RevocationToken"].(string)
-	rt := new(big.Int)
-	_, ok = rt.SetString(rtStr, 10)
-	if !ok {
-		return nil, [8]*big.Int{}, nil, [4]*big.Int{}, fmt.Errorf("invalid revocation token")
+	issPubKey := eddsa.PublicKey{}
+	_, err = issPubKey.SetBytes(cred.IssuerPublicKey)
+	if err != nil {
+		return nil, err
 	}
 
-	epochFloat := parsed["Epoch"].(float64)
-	epochBig := big.NewInt(int64(epochFloat))
+	icVrfPublicKey := eddsaInCicuit.PublicKey{A: twistededwards.Point{X: pkVrf.A.X, Y: pkVrf.A.Y}}
+	icIssuerPublicKey := eddsaInCicuit.PublicKey{A: twistededwards.Point{X: issPubKey.A.X, Y: issPubKey.A.Y}}
+	icToken := big.NewInt(0).SetBytes(token)
 
-	return proof, proofBytes, fullWitness, [4]*big.Int{x, y, rt, epochBig}, nil
+	return &zkp.RevocationTokenProof{
+		VrfSecretKey:    cred.VrfKeyPair.PrivateKey,
+		VrfPublicKey:    icVrfPublicKey,
+		IssuerPubKey:    icIssuerPublicKey,
+		CredSignature:   icCredSigInCircuit,
+		ValidUntil:      cred.Credential.ValidUntil,
+		RevocationToken: icToken,
+		Epoch:           icEpoch,
+		Challenge:       challenge,
+	}, nil
 }
 
+// VerifyProof verifies a Groth16 proof as returned by GenProof against its public witness.
 func (r *RevocationTokenProver) VerifyProof(proof groth16.Proof, publicWitness witness.Witness) error {
 	return groth16.Verify(proof, r.vk, publicWitness)
 }
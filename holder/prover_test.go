@@ -2,9 +2,14 @@ package holder
 
 import (
 	"PrivacyPreservingRevocationCode/issuer"
+	"PrivacyPreservingRevocationCode/zkp"
+	"bytes"
 	"encoding/binary"
+	"encoding/hex"
+	"github.com/consensys/gnark/backend/groth16"
 	"github.com/stretchr/testify/require"
 	"github.com/vechain/go-ecvrf"
+	"os"
 	"testing"
 	"time"
 )
@@ -45,8 +50,10 @@ func TestProver_MultiShow(t *testing.T) {
 	require.NoError(t, err)
 
 	epochUnix := time.Now().UTC().Unix()
-	proof, _, witness, _, err := prover.GenProof(cred, epochUnix)
+	challenge := zkp.Challenge([32]byte{1}, []byte("verifier"))
+	proof, _, witness, publicInputs, err := prover.GenProof(cred, epochUnix, challenge)
 	require.NoError(t, err)
+	require.Equal(t, challenge, publicInputs[4])
 	require.NotNil(t, proof)
 	require.NotNil(t, witness)
 
@@ -56,6 +63,59 @@ func TestProver_MultiShow(t *testing.T) {
 
 	err = prover.VerifyProof(proof, publicWitness)
 	require.NoError(t, err)
+
+	_, _, _, _, err = prover.GenProof(cred, epochUnix, nil)
+	require.Error(t, err)
+}
+
+func TestLoadRevocationTokenProver(t *testing.T) {
+	ccs, err := os.ReadFile("../zkp/sol/build/verifier.g16.ccs")
+	require.NoError(t, err)
+	pk, err := os.ReadFile("../zkp/sol/build/verifier.g16.pk")
+	require.NoError(t, err)
+	vk, err := os.ReadFile("../zkp/sol/build/verifier.g16.vk")
+	require.NoError(t, err)
+	keyHash, err := hex.DecodeString(zkp.RevocationTokenKeyHash)
+	require.NoError(t, err)
+
+	prover, err := LoadRevocationTokenProver(bytes.NewReader(ccs), bytes.NewReader(pk), bytes.NewReader(vk), keyHash)
+	require.NoError(t, err)
+	iss := issuer.NewIssuer(issuer.MultiShow)
+	require.NoError(t, iss.IssueCredential(0))
+	cred, err := iss.GetCredentialCopy(0)
+	require.NoError(t, err)
+	proof, err := prover.Prove(cred, time.Now().UTC().Unix(), zkp.Challenge([32]byte{1}, []byte("verifier")))
+	require.NoError(t, err)
+	require.NoError(t, prover.Verify(proof))
+
+	// Truncated inputs and keys of another circuit are rejected.
+	_, err = LoadRevocationTokenProver(bytes.NewReader(ccs[:len(ccs)/2]), bytes.NewReader(pk), bytes.NewReader(vk), keyHash)
+	require.Error(t, err)
+	_, err = LoadRevocationTokenProver(bytes.NewReader(ccs), bytes.NewReader(pk[:len(pk)-1]), bytes.NewReader(vk), keyHash)
+	require.Error(t, err)
+	_, err = LoadRevocationTokenProver(bytes.NewReader(ccs), bytes.NewReader(pk), bytes.NewReader(vk), keyHash[1:])
+	require.Error(t, err)
+	_, err = NewRevocationTokenProver("../zkp/sol/build/verifier.g16.pk", "../zkp/sol/build/missing.vk")
+	require.Error(t, err)
+
+	// Keys of another setup of the same circuit are rejected when mixed.
+	r1, err := zkp.CompileRevocationToken(zkp.Groth16)
+	require.NoError(t, err)
+	_, otherVk, err := groth16.Setup(r1)
+	require.NoError(t, err)
+	var otherVkBytes bytes.Buffer
+	_, err = otherVk.WriteTo(&otherVkBytes)
+	require.NoError(t, err)
+	otherHash, err := zkp.KeyHash(r1, otherVk)
+	require.NoError(t, err)
+	_, err = LoadRevocationTokenProver(bytes.NewReader(ccs), bytes.NewReader(pk), &otherVkBytes, otherHash)
+	require.ErrorContains(t, err, "different setups")
+
+	// Keys do not fit another constraint system.
+	plonkCcs, err := zkp.CompileRevocationToken(zkp.Plonk)
+	require.NoError(t, err)
+	_, err = newRevocationTokenProver(plonkCcs, prover.pk, prover.vk)
+	require.ErrorContains(t, err, "does not match the constraint system")
 }
 
 func BenchmarkProver_GenProof_OneShow(b *testing.B) {
@@ -90,11 +150,12 @@ func BenchmarkProver_GenProof_MultiShow(b *testing.B) {
 	require.NoError(b, err)
 
 	epochUnix := time.Now().UTC().Unix()
+	challenge := zkp.Challenge([32]byte{1}, []byte("verifier"))
 
 	b.ReportAllocs()
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_, _, _, _, err := prover.GenProof(cred, epochUnix)
+		_, _, _, _, err := prover.GenProof(cred, epochUnix, challenge)
 		if err != nil {
 			b.Fatalf("GenProof failed: %v", err)
 		}
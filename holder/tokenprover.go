@@ -0,0 +1,126 @@
+package holder
+
+import (
+	"PrivacyPreservingRevocationCode/issuer"
+	"PrivacyPreservingRevocationCode/zkp"
+	"bytes"
+	"fmt"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	plonkbn254 "github.com/consensys/gnark/backend/plonk/bn254"
+	"io"
+	"math/big"
+)
+
+// TokenProver generates and verifies zero-knowledge proofs of the revocation token of MultiShow credentials,
+// independent of the proving backend. RevocationTokenProver implements it with Groth16 and
+// PlonkRevocationTokenProver with PLONK.
+type TokenProver interface {
+	// Backend returns the proving system of the prover.
+	Backend() zkp.Backend
+	// Prove proves the revocation token of a MultiShow credential for the epoch, bound to the verifier's challenge.
+	Prove(cred issuer.InternalCredential, epochUnix int64, challenge *big.Int) (*TokenProof, error)
+	// Verify verifies a proof of the prover's backend against its public inputs.
+	Verify(proof *TokenProof) error
+	// ExportSolidity writes the Solidity verifier contract of the prover's verifying key.
+	ExportSolidity(w io.Writer) error
+}
+
+var (
+	_ TokenProver = (*RevocationTokenProver)(nil)
+	_ TokenProver = (*PlonkRevocationTokenProver)(nil)
+)
+
+// TokenProof is a proof of a revocation token with its public inputs.
+type TokenProof struct {
+	Backend      zkp.Backend      // Backend is the proving system the proof was generated with.
+	Proof        []byte           // Proof is the proof in gnark's (compressed) binary encoding of the backend.
+	PublicInputs zkp.PublicInputs // PublicInputs are the public inputs the proof is verified against.
+}
+
+// Solidity returns the proof in the encoding of its backend's Solidity verifier: the eight words of the uint256[8]
+// proof for Groth16, and gnark's MarshalSolidity for PLONK.
+func (p *TokenProof) Solidity() ([]byte, error) {
+	switch p.Backend {
+	case zkp.Groth16:
+		proof := groth16.NewProof(ecc.BN254)
+		_, err := proof.ReadFrom(bytes.NewReader(p.Proof))
+		if err != nil {
+			return nil, err
+		}
+		words, err := groth16ProofToOnChainInput(proof)
+		if err != nil {
+			return nil, err
+		}
+		encoded := make([]byte, 0, len(words)*32)
+		for _, w := range words {
+			encoded = append(encoded, w.FillBytes(make([]byte, 32))...)
+		}
+		return encoded, nil
+	case zkp.Plonk:
+		proof := &plonkbn254.Proof{}
+		_, err := proof.ReadFrom(bytes.NewReader(p.Proof))
+		if err != nil {
+			return nil, err
+		}
+		return proof.MarshalSolidity(), nil
+	default:
+		return nil, fmt.Errorf("unsupported proving backend %s", p.Backend)
+	}
+}
+
+// encodeProof returns gnark's binary encoding of a proof.
+func encodeProof(proof io.WriterTo) ([]byte, error) {
+	var buf bytes.Buffer
+	_, err := proof.WriteTo(&buf)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Backend returns zkp.Groth16.
+func (r *RevocationTokenProver) Backend() zkp.Backend {
+	return zkp.Groth16
+}
+
+// Prove generates a Groth16 proof like GenProof and returns it in the backend-agnostic format.
+func (r *RevocationTokenProver) Prove(cred issuer.InternalCredential, epochUnix int64, challenge *big.Int) (*TokenProof, error) {
+	proof, _, _, encodedInputs, err := r.GenProof(cred, epochUnix, challenge)
+	if err != nil {
+		return nil, err
+	}
+	encoded, err := encodeProof(proof)
+	if err != nil {
+		return nil, err
+	}
+	publicInputs, err := zkp.DecodePublicInputs(encodedInputs)
+	if err != nil {
+		return nil, err
+	}
+	return &TokenProof{Backend: zkp.Groth16, Proof: encoded, PublicInputs: *publicInputs}, nil
+}
+
+// Verify verifies a Groth16 proof as returned by Prove.
+func (r *RevocationTokenProver) Verify(proof *TokenProof) error {
+	if proof.Backend != zkp.Groth16 {
+		return fmt.Errorf("proof is for backend %s, not %s", proof.Backend, zkp.Groth16)
+	}
+	p := groth16.NewProof(ecc.BN254)
+	_, err := p.ReadFrom(bytes.NewReader(proof.Proof))
+	if err != nil {
+		return err
+	}
+
+	publicWitness, err := proof.PublicInputs.Witness()
+	if err != nil {
+		return err
+	}
+	return groth16.Verify(p, r.vk, publicWitness)
+}
+
+// ExportSolidity writes the Solidity verifier contract of the prover's verifying key, i.e. revocationTokenVerifier.sol
+// for the shipped keys.
+func (r *RevocationTokenProver) ExportSolidity(w io.Writer) error {
+	return r.vk.ExportSolidity(w)
+}
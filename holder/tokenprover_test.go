@@ -0,0 +1,111 @@
+package holder
+
+import (
+	"PrivacyPreservingRevocationCode/issuer"
+	"PrivacyPreservingRevocationCode/zkp"
+	"bytes"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+)
+
+// newTokenProvers returns provers of all backends with the shipped keys.
+func newTokenProvers(tb testing.TB) []TokenProver {
+	groth16Prover, err := NewRevocationTokenProver("../zkp/sol/build/verifier.g16.pk", "../zkp/sol/build/verifier.g16.vk")
+	require.NoError(tb, err)
+	plonkProver, err := NewPlonkRevocationTokenProver("../zkp/sol/build/verifier.plonk.pk", "../zkp/sol/build/verifier.plonk.vk")
+	require.NoError(tb, err)
+	return []TokenProver{groth16Prover, plonkProver}
+}
+
+func TestTokenProver(t *testing.T) {
+	iss := issuer.NewIssuer(issuer.MultiShow)
+	require.NoError(t, iss.IssueCredential(0))
+	cred, err := iss.GetCredentialCopy(0)
+	require.NoError(t, err)
+	epochUnix := time.Now().UTC().Unix()
+	challenge := zkp.Challenge([32]byte{1}, []byte("verifier"))
+	token, _, err := cred.GenRevocationToken(epochUnix)
+	require.NoError(t, err)
+
+	solidity := map[zkp.Backend]string{
+		zkp.Groth16: "../zkp/sol/revocationTokenVerifier.sol",
+		zkp.Plonk:   "../zkp/sol/revocationTokenPlonkVerifier.sol",
+	}
+	provers := newTokenProvers(t)
+	for _, prover := range provers {
+		t.Run(prover.Backend().String(), func(t *testing.T) {
+			proof, err := prover.Prove(cred, epochUnix, challenge)
+			require.NoError(t, err)
+			require.Equal(t, prover.Backend(), proof.Backend)
+			require.Equal(t, new(big.Int).SetBytes(token), proof.PublicInputs.RevocationToken)
+			require.Equal(t, epochUnix, proof.PublicInputs.Epoch)
+			require.Equal(t, challenge, proof.PublicInputs.Challenge)
+			require.NoError(t, prover.Verify(proof))
+			onChain, err := proof.Solidity()
+			require.NoError(t, err)
+			t.Logf("%s proof: %d bytes, %d bytes on-chain", prover.Backend(), len(proof.Proof), len(onChain))
+
+			// The proof is bound to its public inputs and encoding.
+			forged := *proof
+			forged.PublicInputs.Challenge = new(big.Int).Add(challenge, big.NewInt(1))
+			require.Error(t, prover.Verify(&forged))
+			forged.PublicInputs.Challenge = new(big.Int).Add(challenge, ecc.BN254.ScalarField())
+			require.Error(t, prover.Verify(&forged), "expected public inputs outside the scalar field to be rejected")
+			forged = *proof
+			forged.Proof = append([]byte(nil), proof.Proof...)
+			forged.Proof[len(forged.Proof)-1] ^= 1
+			require.Error(t, prover.Verify(&forged))
+			forged.Proof = proof.Proof[:len(proof.Proof)-1]
+			require.Error(t, prover.Verify(&forged))
+
+			// Proofs of other backends are rejected.
+			for _, other := range provers {
+				if other.Backend() != prover.Backend() {
+					require.Error(t, other.Verify(proof))
+				}
+			}
+
+			var exported bytes.Buffer
+			require.NoError(t, prover.ExportSolidity(&exported))
+			shipped, err := os.ReadFile(solidity[prover.Backend()])
+			require.NoError(t, err)
+			require.Equal(t, string(shipped), exported.String())
+
+			_, err = prover.Prove(cred, epochUnix, nil)
+			require.Error(t, err)
+		})
+	}
+}
+
+func BenchmarkTokenProver_Prove(b *testing.B) {
+	iss := issuer.NewIssuer(issuer.MultiShow)
+	require.NoError(b, iss.IssueCredential(0))
+	cred, err := iss.GetCredentialCopy(0)
+	require.NoError(b, err)
+	epochUnix := time.Now().UTC().Unix()
+	challenge := zkp.Challenge([32]byte{1}, []byte("verifier"))
+
+	for _, prover := range newTokenProvers(b) {
+		b.Run(prover.Backend().String(), func(b *testing.B) {
+			var proofSize int
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				proof, err := prover.Prove(cred, epochUnix, challenge)
+				if err != nil {
+					b.Fatalf("Prove failed: %v", err)
+				}
+				onChain, err := proof.Solidity()
+				if err != nil {
+					b.Fatalf("Solidity failed: %v", err)
+				}
+				proofSize = len(onChain)
+			}
+			b.ReportMetric(float64(proofSize), "calldata-bytes")
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package holder
+
+import (
+	"PrivacyPreservingRevocationCode/issuer"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"slices"
+	"sync"
+)
+
+// Wallet holds the VRF key pairs a holder generated itself and the credentials issued on them.
+// The VRF secret key never leaves the wallet: the issuer only receives the VRF public key and the revocation tokens
+// of the registered epochs with their proofs. A Wallet lives in memory; MarshalJSON and UnmarshalJSON persist it,
+// including the VRF secret keys, so the encoding must be stored like a private key.
+type Wallet struct {
+	mu          sync.Mutex
+	pending     map[string]*issuer.VrfKeyPair // pending maps the VRF public key of open requests to its key pair.
+	credentials []*issuer.InternalCredential  // credentials holds all accepted credentials.
+}
+
+// NewWallet creates an empty Wallet.
+func NewWallet() *Wallet {
+	return &Wallet{pending: make(map[string]*issuer.VrfKeyPair)}
+}
+
+// RequestCredential generates a fresh VRF key pair and returns the IssuanceRequest to send to the issuer for its
+// RegistrationTicket.
+func (w *Wallet) RequestCredential(credentialType issuer.CredentialType, issuerPublicKey []byte) (*issuer.IssuanceRequest, error) {
+	vrfKeyPair, err := issuer.NewVrfKeyPair(credentialType)
+	if err != nil {
+		return nil, err
+	}
+	req, err := issuer.NewIssuanceRequest(vrfKeyPair, issuerPublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.pending[string(req.VrfPublicKey)] = vrfKeyPair
+	return req, nil
+}
+
+// RegisterTokens adds the revocation tokens of the ticket's epochs with their proofs to a pending request, which is
+// then sent to the issuer for the credential. MultiShow tokens are proven with the prover of either backend, which
+// the issuer needs the verifying key of; it may be nil for OneShow.
+func (w *Wallet) RegisterTokens(req *issuer.IssuanceRequest, ticket *issuer.RegistrationTicket, prover TokenProver) error {
+	w.mu.Lock()
+	vrfKeyPair, ok := w.pending[string(req.VrfPublicKey)]
+	w.mu.Unlock()
+	if !ok {
+		return errors.New("no pending request for VRF public key")
+	}
+
+	switch req.Type {
+	case issuer.OneShow:
+		return req.AddOneShowTokens(vrfKeyPair, ticket)
+	case issuer.MultiShow:
+		if prover == nil {
+			return errors.New("MultiShow tokens require a prover")
+		}
+		// The ticket's signature stands in for the credential, with the registration key as issuer.
+		cred := issuer.InternalCredential{
+			VrfKeyPair: vrfKeyPair,
+			Credential: issuer.Credential{
+				PublicKeyVrfHash: vrfKeyPair.PublicKeyVrfHash,
+				Signature:        ticket.Signature,
+				Type:             issuer.MultiShow,
+			},
+			IssuerPublicKey: ticket.RegistrationKey,
+		}
+		tokens := make([]issuer.EpochToken, 0, len(ticket.Epochs))
+		for _, e := range ticket.Epochs {
+			token, _, err := cred.GenRevocationToken(e)
+			if err != nil {
+				return err
+			}
+			proof, err := prover.Prove(cred, e, ticket.Challenge)
+			if err != nil {
+				return err
+			}
+			tokens = append(tokens, issuer.EpochToken{Epoch: e, Token: token, Proof: proof.Proof, Backend: proof.Backend})
+		}
+		req.Tokens = tokens
+		return nil
+	default:
+		return errors.New("unknown credential type")
+	}
+}
+
+// AcceptCredential verifies the credential issued for req against the issuer public key and stores it together
+// with the VRF key pair of the request.
+func (w *Wallet) AcceptCredential(req *issuer.IssuanceRequest, cred issuer.Credential, issuerPublicKey []byte) (*issuer.InternalCredential, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	vrfKeyPair, ok := w.pending[string(req.VrfPublicKey)]
+	if !ok {
+		return nil, errors.New("no pending request for VRF public key")
+	}
+	if cred.Type != vrfKeyPair.Type() {
+		return nil, errors.New("credential type does not match request")
+	}
+	if !bytes.Equal(cred.PublicKeyVrfHash, vrfKeyPair.PublicKeyVrfHash) {
+		return nil, errors.New("credential was not issued on the requested VRF public key")
+	}
+	valid, err := cred.Verify(issuerPublicKey)
+	if err != nil {
+		return nil, err
+	}
+	if !valid {
+		return nil, errors.New("invalid issuer signature")
+	}
+
+	internal := &issuer.InternalCredential{
+		VrfKeyPair:      vrfKeyPair,
+		Credential:      cred,
+		IssuerPublicKey: append([]byte(nil), issuerPublicKey...),
+	}
+	delete(w.pending, string(req.VrfPublicKey))
+	w.credentials = append(w.credentials, internal)
+	return internal, nil
+}
+
+// Credentials returns copies of all credentials accepted by the wallet.
+func (w *Wallet) Credentials() []issuer.InternalCredential {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	creds := make([]issuer.InternalCredential, len(w.credentials))
+	for i, c := range w.credentials {
+		creds[i] = *c
+	}
+	return creds
+}
+
+// walletJSON is the JSON encoding of a Wallet. Pending key pairs are persisted by their private key, credentials
+// with InternalCredential's encoding.
+type walletJSON struct {
+	Pending     []pendingKeyJSON             `json:"pending,omitempty"`
+	Credentials []*issuer.InternalCredential `json:"credentials"`
+}
+
+// pendingKeyJSON is the VRF key pair of an open request.
+type pendingKeyJSON struct {
+	Type          issuer.CredentialType `json:"type"`
+	VrfPrivateKey []byte                `json:"vrfPrivateKey"`
+}
+
+// MarshalJSON implements json.Marshaler interface.
+func (w *Wallet) MarshalJSON() ([]byte, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	j := walletJSON{Credentials: w.credentials}
+	if j.Credentials == nil {
+		j.Credentials = []*issuer.InternalCredential{}
+	}
+	for _, vrfKeyPair := range w.pending {
+		j.Pending = append(j.Pending, pendingKeyJSON{Type: vrfKeyPair.Type(), VrfPrivateKey: vrfKeyPair.PrivateKey})
+	}
+	slices.SortFunc(j.Pending, func(a, b pendingKeyJSON) int {
+		return bytes.Compare(a.VrfPrivateKey, b.VrfPrivateKey)
+	})
+	return json.Marshal(j)
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface.
+func (w *Wallet) UnmarshalJSON(data []byte) error {
+	var j walletJSON
+	err := json.Unmarshal(data, &j)
+	if err != nil {
+		return err
+	}
+
+	pending := make(map[string]*issuer.VrfKeyPair, len(j.Pending))
+	for _, p := range j.Pending {
+		vrfKeyPair, err := issuer.VrfKeyPairFromPrivateKey(p.Type, p.VrfPrivateKey)
+		if err != nil {
+			return err
+		}
+		pkBytes, err := vrfKeyPair.PublicKeyBytes()
+		if err != nil {
+			return err
+		}
+		pending[string(pkBytes)] = vrfKeyPair
+	}
+	for _, c := range j.Credentials {
+		if c == nil || c.VrfKeyPair == nil {
+			return errors.New("wallet credential holds no VRF key pair")
+		}
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.pending = pending
+	w.credentials = j.Credentials
+	return nil
+}
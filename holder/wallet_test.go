@@ -0,0 +1,171 @@
+package holder
+
+import (
+	"PrivacyPreservingRevocationCode/issuer"
+	"PrivacyPreservingRevocationCode/zkp"
+	"encoding/json"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+// registrationProver returns the prover of the shipped keys of the backend for MultiShow issuers, after giving the
+// issuer their verifying key, and nil for OneShow issuers.
+func registrationProver(t *testing.T, iss *issuer.Issuer, b zkp.Backend) TokenProver {
+	if iss.CredentialType() != issuer.MultiShow {
+		return nil
+	}
+	switch b {
+	case zkp.Groth16:
+		prover, err := NewRevocationTokenProver("../zkp/sol/build/verifier.g16.pk", "../zkp/sol/build/verifier.g16.vk")
+		require.NoError(t, err)
+		iss.SetTokenVerifyingKey(prover.vk)
+		return prover
+	default:
+		prover, err := NewPlonkRevocationTokenProver("../zkp/sol/build/verifier.plonk.pk", "../zkp/sol/build/verifier.plonk.vk")
+		require.NoError(t, err)
+		iss.SetPlonkTokenVerifyingKey(prover.vk)
+		return prover
+	}
+}
+
+func TestWallet_BlindIssuance(t *testing.T) {
+	for _, mode := range []issuer.CredentialType{issuer.OneShow, issuer.MultiShow} {
+		t.Run(mode.String(), func(t *testing.T) {
+			iss := issuer.NewIssuer(mode)
+			iss.SetRegistrationEpochs(2)
+			prover := registrationProver(t, iss, zkp.Groth16)
+			wallet := NewWallet()
+
+			req, err := wallet.RequestCredential(mode, iss.GetPublicKey())
+			require.NoError(t, err)
+			ticket, err := iss.RegistrationTicket(req)
+			require.NoError(t, err)
+			require.NoError(t, wallet.RegisterTokens(req, ticket, prover))
+			cred, err := iss.IssueBlindCredential(0, req)
+			require.NoError(t, err)
+
+			internal, err := wallet.AcceptCredential(req, cred, iss.GetPublicKey())
+			require.NoError(t, err)
+			require.NotNil(t, internal.VrfKeyPair)
+			require.Len(t, wallet.Credentials(), 1)
+			require.Equal(t, ticket.Epochs[1], internal.Credential.ValidUntil)
+
+			// The holder derives the same revocation token the issuer puts into the artifact.
+			require.NoError(t, iss.IssueCredentials(20))
+			require.NoError(t, iss.RevokeCredential(0))
+			artifact, revoked, _, err := iss.GenRevocationArtifactForEpoch(ticket.Epochs[1])
+			require.NoError(t, err)
+			token, _, err := internal.GenRevocationToken(ticket.Epochs[1])
+			require.NoError(t, err)
+			require.Equal(t, []issuer.RevocationToken{token}, revoked)
+			revokedInArtifact, _ := artifact.Test(token)
+			require.True(t, revokedInArtifact)
+
+			// A request can only be accepted once.
+			_, err = wallet.AcceptCredential(req, cred, iss.GetPublicKey())
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestWallet_RejectsForeignCredential(t *testing.T) {
+	iss := issuer.NewIssuer(issuer.MultiShow)
+	other := issuer.NewIssuer(issuer.MultiShow)
+	iss.SetRegistrationEpochs(1)
+	prover := registrationProver(t, iss, zkp.Groth16)
+	wallet := NewWallet()
+
+	req, err := wallet.RequestCredential(issuer.MultiShow, iss.GetPublicKey())
+	require.NoError(t, err)
+	ticket, err := iss.RegistrationTicket(req)
+	require.NoError(t, err)
+	require.NoError(t, wallet.RegisterTokens(req, ticket, prover))
+	cred, err := iss.IssueBlindCredential(0, req)
+	require.NoError(t, err)
+
+	_, err = wallet.AcceptCredential(req, cred, other.GetPublicKey())
+	require.Error(t, err)
+
+	// Token proofs are bound to the VRF public key of their request.
+	otherReq, err := wallet.RequestCredential(issuer.MultiShow, iss.GetPublicKey())
+	require.NoError(t, err)
+	otherReq.Tokens = req.Tokens
+	_, err = iss.IssueBlindCredential(1, otherReq)
+	require.Error(t, err)
+
+	require.NoError(t, other.IssueCredential(0))
+	foreign, err := other.GetCredentialCopy(0)
+	require.NoError(t, err)
+	_, err = wallet.AcceptCredential(req, foreign.Credential, other.GetPublicKey())
+	require.Error(t, err)
+}
+
+func TestWallet_PlonkRegistration(t *testing.T) {
+	iss := issuer.NewIssuer(issuer.MultiShow)
+	iss.SetRegistrationEpochs(1)
+	prover := registrationProver(t, iss, zkp.Plonk)
+	wallet := NewWallet()
+
+	req, err := wallet.RequestCredential(issuer.MultiShow, iss.GetPublicKey())
+	require.NoError(t, err)
+	ticket, err := iss.RegistrationTicket(req)
+	require.NoError(t, err)
+	require.NoError(t, wallet.RegisterTokens(req, ticket, prover))
+	require.Equal(t, zkp.Plonk, req.Tokens[0].Backend)
+	cred, err := iss.IssueBlindCredential(0, req)
+	require.NoError(t, err)
+	internal, err := wallet.AcceptCredential(req, cred, iss.GetPublicKey())
+	require.NoError(t, err)
+
+	// The credential proves its tokens until it expires.
+	proof, err := prover.Prove(*internal, ticket.Epochs[0], ticket.Challenge)
+	require.NoError(t, err)
+	require.NoError(t, prover.Verify(proof))
+	_, err = prover.Prove(*internal, iss.EpochPolicy().Next(ticket.Epochs[0]), ticket.Challenge)
+	require.ErrorContains(t, err, "expired")
+
+	// Without the PLONK verifying key, the issuer rejects PLONK token proofs.
+	other := issuer.NewIssuer(issuer.MultiShow)
+	other.SetRegistrationEpochs(1)
+	otherReq, err := wallet.RequestCredential(issuer.MultiShow, other.GetPublicKey())
+	require.NoError(t, err)
+	otherTicket, err := other.RegistrationTicket(otherReq)
+	require.NoError(t, err)
+	require.NoError(t, wallet.RegisterTokens(otherReq, otherTicket, prover))
+	_, err = other.IssueBlindCredential(0, otherReq)
+	require.ErrorContains(t, err, "no verifying key")
+}
+
+func TestWallet_JSON(t *testing.T) {
+	iss := issuer.NewIssuer(issuer.OneShow)
+	iss.SetRegistrationEpochs(2)
+	wallet := NewWallet()
+
+	accepted, err := wallet.RequestCredential(issuer.OneShow, iss.GetPublicKey())
+	require.NoError(t, err)
+	ticket, err := iss.RegistrationTicket(accepted)
+	require.NoError(t, err)
+	require.NoError(t, wallet.RegisterTokens(accepted, ticket, nil))
+	cred, err := iss.IssueBlindCredential(0, accepted)
+	require.NoError(t, err)
+	_, err = wallet.AcceptCredential(accepted, cred, iss.GetPublicKey())
+	require.NoError(t, err)
+	pending, err := wallet.RequestCredential(issuer.OneShow, iss.GetPublicKey())
+	require.NoError(t, err)
+
+	data, err := json.Marshal(wallet)
+	require.NoError(t, err)
+	restored := NewWallet()
+	require.NoError(t, json.Unmarshal(data, restored))
+	require.Equal(t, wallet.Credentials(), restored.Credentials())
+
+	// Open requests survive as well.
+	ticket, err = iss.RegistrationTicket(pending)
+	require.NoError(t, err)
+	require.NoError(t, restored.RegisterTokens(pending, ticket, nil))
+	cred, err = iss.IssueBlindCredential(1, pending)
+	require.NoError(t, err)
+	_, err = restored.AcceptCredential(pending, cred, iss.GetPublicKey())
+	require.NoError(t, err)
+	require.Len(t, restored.Credentials(), 2)
+}
@@ -0,0 +1,472 @@
+package issuer
+
+import (
+	"PrivacyPreservingRevocationCode/epoch"
+	"PrivacyPreservingRevocationCode/zkp"
+	"bytes"
+	crand "crypto/rand"
+	"errors"
+	"fmt"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards/eddsa"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/backend/solidity"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"math/big"
+)
+
+// popDomain separates proofs of possession from any other signature made with a VRF key.
+var popDomain = []byte("UPPR-VRF-PoP")
+
+// registrationDomain is used to derive the issuer's registration key from its signing key and the challenge of
+// MultiShow token proofs from the VRF public key.
+var registrationDomain = []byte("UPPR-VRF-Registration")
+
+// DefaultRegistrationEpochs is the number of epochs holders register revocation tokens for on blind issuance.
+const DefaultRegistrationEpochs = 7
+
+// EpochToken is the revocation token of a holder-generated VRF key for one epoch with the proof that it was
+// evaluated with that key: the ECVRF proof for OneShow and a zkp.RevocationTokenProof of Backend in gnark's binary
+// encoding for MultiShow.
+type EpochToken struct {
+	Epoch   int64           // Epoch is the unix time of the epoch.
+	Token   RevocationToken // Token is the revocation token of the epoch.
+	Proof   []byte          // Proof proves that Token belongs to the VRF public key of the request.
+	Backend zkp.Backend     // Backend is the proving system of a MultiShow proof.
+}
+
+// IssuanceRequest is sent by a holder to obtain a credential on a VRF key pair it generated itself.
+// It carries only public material: the VRF secret key stays with the holder, who registers the revocation tokens
+// of the epochs in the issuer's RegistrationTicket instead.
+type IssuanceRequest struct {
+	Type              CredentialType // Type is the requested credential type.
+	VrfPublicKey      []byte         // VrfPublicKey is the compressed VRF public key (secp256k1 or BabyJubJub).
+	ProofOfPossession []byte         // ProofOfPossession is a signature by the VRF secret key binding it to the issuer.
+	Tokens            []EpochToken   // Tokens are the revocation tokens of the epochs of the RegistrationTicket.
+}
+
+// RegistrationTicket tells a holder which revocation tokens to register. For MultiShow, it also carries a signature
+// of the issuer's registration key on the VRF public key hash, so the holder can prove its tokens with the
+// zkp.RevocationTokenProof circuit, using the registration key in place of the issuer key.
+type RegistrationTicket struct {
+	Epochs          []int64  // Epochs are the epochs to register revocation tokens for.
+	RegistrationKey []byte   // RegistrationKey is the public key of the issuer's registration key (MultiShow only).
+	Signature       []byte   // Signature is the registration key's signature on the VRF public key hash (MultiShow only).
+	Challenge       *big.Int // Challenge is the challenge the token proofs are bound to (MultiShow only).
+}
+
+// NewIssuanceRequest creates an IssuanceRequest for a holder-generated VRF key pair.
+// issuerPublicKey is the public key returned by Issuer.GetPublicKey. The revocation tokens are added after the
+// issuer returned its RegistrationTicket, with AddOneShowTokens or a MultiShow token prover.
+func NewIssuanceRequest(vrfKeyPair *VrfKeyPair, issuerPublicKey []byte) (*IssuanceRequest, error) {
+	pkBytes, err := vrfKeyPair.PublicKeyBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	pop, err := proveVrfKeyPossession(vrfKeyPair, pkBytes, issuerPublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &IssuanceRequest{
+		Type:              vrfKeyPair.version,
+		VrfPublicKey:      pkBytes,
+		ProofOfPossession: pop,
+	}, nil
+}
+
+// AddOneShowTokens evaluates the revocation tokens of the ticket's epochs with their ECVRF proofs and adds them to
+// the request.
+func (req *IssuanceRequest) AddOneShowTokens(vrfKeyPair *VrfKeyPair, ticket *RegistrationTicket) error {
+	if vrfKeyPair.version != OneShow || req.Type != OneShow {
+		return errors.New("credential type is not OneShow")
+	}
+	cred := InternalCredential{VrfKeyPair: vrfKeyPair, Credential: Credential{Type: OneShow}}
+	tokens := make([]EpochToken, 0, len(ticket.Epochs))
+	for _, e := range ticket.Epochs {
+		token, proof, err := cred.GenRevocationToken(e)
+		if err != nil {
+			return err
+		}
+		tokens = append(tokens, EpochToken{Epoch: e, Token: token, Proof: proof})
+	}
+	req.Tokens = tokens
+	return nil
+}
+
+// RegistrationChallenge returns the challenge MultiShow token proofs of a blind issuance are bound to. It binds the
+// proofs to the issuer and the requested VRF public key, see zkp.Challenge.
+func RegistrationChallenge(vrfPublicKey, issuerPublicKey []byte) *big.Int {
+	var nonce [32]byte
+	copy(nonce[:], ethcrypto.Keccak256(registrationDomain, vrfPublicKey))
+	return zkp.Challenge(nonce, issuerPublicKey)
+}
+
+// SetRegistrationEpochs sets the number of epochs, starting with the current one, holders register revocation
+// tokens for on blind issuance. DefaultRegistrationEpochs is the default.
+func (i *Issuer) SetRegistrationEpochs(n int) {
+	i.registrationEpochs = n
+}
+
+// SetTokenVerifyingKey sets the Groth16 verifying key of the zkp.RevocationTokenProof circuit, e.g. the shipped
+// verifier.g16.vk, which MultiShow issuers need to verify the token proofs of blind issuance requests.
+func (i *Issuer) SetTokenVerifyingKey(vk groth16.VerifyingKey) {
+	i.tokenVerifyingKey = vk
+}
+
+// SetPlonkTokenVerifyingKey sets the PLONK verifying key of the zkp.RevocationTokenProof circuit, e.g. the shipped
+// verifier.plonk.vk, so MultiShow issuers also accept blind issuance requests proven with PLONK.
+func (i *Issuer) SetPlonkTokenVerifyingKey(vk plonk.VerifyingKey) {
+	i.plonkVerifyingKey = vk
+}
+
+// RegistrationEpochs returns the epochs holders currently register revocation tokens for: the current epoch of the
+// issuer's epoch policy and the following ones.
+func (i *Issuer) RegistrationEpochs() ([]int64, error) {
+	if i.registrationEpochs < 1 {
+		return nil, errors.New("number of registration epochs must be positive")
+	}
+	e, err := i.CurrentEpoch()
+	if err != nil {
+		return nil, err
+	}
+	epochs := make([]int64, i.registrationEpochs)
+	for j := range epochs {
+		epochs[j] = e
+		e = i.epochPolicy.Next(e)
+	}
+	return epochs, nil
+}
+
+// RegistrationTicket verifies the proof of possession of a request and returns the ticket the holder needs to add
+// its revocation tokens to the request.
+func (i *Issuer) RegistrationTicket(req *IssuanceRequest) (*RegistrationTicket, error) {
+	if req.Type != i.credentialType {
+		return nil, fmt.Errorf("issuer does not issue %s credentials", req.Type)
+	}
+	publicKeyVrfHash, err := verifyVrfKeyPossession(req.Type, req.VrfPublicKey, req.ProofOfPossession, i.GetPublicKey())
+	if err != nil {
+		return nil, err
+	}
+	epochs, err := i.RegistrationEpochs()
+	if err != nil {
+		return nil, err
+	}
+
+	ticket := &RegistrationTicket{Epochs: epochs}
+	if i.credentialType == MultiShow {
+		ticket.Signature, err = signAttribute(i.registrationKey, publicKeyVrfHash, MultiShow)
+		if err != nil {
+			return nil, err
+		}
+		ticket.RegistrationKey, err = registrationPublicKey(i.registrationKey)
+		if err != nil {
+			return nil, err
+		}
+		ticket.Challenge = RegistrationChallenge(req.VrfPublicKey, i.GetPublicKey())
+	}
+	return ticket, nil
+}
+
+// deriveRegistrationKey derives the EdDSA key MultiShow issuers sign registration tickets with from the issuer key.
+// Verifiers do not trust it, so its signatures are no credentials.
+func deriveRegistrationKey(issuerKey []byte) ([]byte, error) {
+	key, err := eddsa.GenerateKey(bytes.NewReader(ethcrypto.Keccak256(registrationDomain, issuerKey)))
+	if err != nil {
+		return nil, err
+	}
+	return key.Bytes(), nil
+}
+
+// registrationPublicKey returns the public key of a registration key.
+func registrationPublicKey(registrationKey []byte) ([]byte, error) {
+	var key eddsa.PrivateKey
+	_, err := key.SetBytes(registrationKey)
+	if err != nil {
+		return nil, err
+	}
+	return key.PublicKey.Bytes(), nil
+}
+
+// IssueBlindCredential issues a credential on a holder-generated VRF public key.
+// It verifies the proof of possession and the revocation tokens of the current RegistrationEpochs, then signs the
+// VRF public key hash together with the last registered epoch as validity bound (Credential.ValidUntil). The issuer
+// never learns the VRF secret key: it keeps the registered tokens and can only revoke the credential in their epochs.
+// The bound makes the credential expire with the last of them, as verifiers reject presentations for later epochs,
+// so holders request a new credential with fresh tokens before.
+func (i *Issuer) IssueBlindCredential(id uint, req *IssuanceRequest) (Credential, error) {
+	if req.Type != i.credentialType {
+		return Credential{}, fmt.Errorf("issuer does not issue %s credentials", req.Type)
+	}
+	if _, exists := i.issuedCredentials[id]; exists {
+		return Credential{}, errors.New("credential id already assigned")
+	}
+
+	publicKeyVrfHash, err := verifyVrfKeyPossession(req.Type, req.VrfPublicKey, req.ProofOfPossession, i.GetPublicKey())
+	if err != nil {
+		return Credential{}, err
+	}
+
+	tokens, err := i.verifyEpochTokens(req)
+	if err != nil {
+		return Credential{}, err
+	}
+
+	validUntil := req.Tokens[len(req.Tokens)-1].Epoch
+	msg, err := credentialMessage(i.credentialType, publicKeyVrfHash, validUntil)
+	if err != nil {
+		return Credential{}, err
+	}
+	signature, err := signAttribute(i.key, msg, i.credentialType)
+	if err != nil {
+		return Credential{}, err
+	}
+
+	cred := &InternalCredential{
+		ID:      id,
+		Revoked: false,
+		Credential: Credential{
+			PublicKeyVrfHash: publicKeyVrfHash,
+			Signature:        signature,
+			Type:             i.credentialType,
+			ValidUntil:       validUntil,
+		},
+		IssuerPublicKey: i.GetPublicKey(),
+		VrfPublicKey:    append([]byte(nil), req.VrfPublicKey...),
+		Tokens:          tokens,
+	}
+	err = i.store.AddCredential(cred)
+	if err != nil {
+		return Credential{}, err
+	}
+	i.issuedCredentials[id] = cred
+	return cred.Credential, nil
+}
+
+// verifyEpochTokens checks that the request registers a token for each of the current RegistrationEpochs and
+// verifies their proofs against the VRF public key of the request. It returns the tokens by epoch.
+func (i *Issuer) verifyEpochTokens(req *IssuanceRequest) (map[int64]RevocationToken, error) {
+	epochs, err := i.RegistrationEpochs()
+	if err != nil {
+		return nil, err
+	}
+	if len(req.Tokens) != len(epochs) {
+		return nil, fmt.Errorf("request registers %d revocation tokens, not %d", len(req.Tokens), len(epochs))
+	}
+
+	var registrationKey []byte
+	if req.Type == MultiShow {
+		registrationKey, err = registrationPublicKey(i.registrationKey)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	tokens := make(map[int64]RevocationToken, len(epochs))
+	for j, et := range req.Tokens {
+		if et.Epoch != epochs[j] {
+			return nil, fmt.Errorf("revocation token %d is for epoch %d, not %d", j, et.Epoch, epochs[j])
+		}
+		switch req.Type {
+		case OneShow:
+			err = verifyOneShowToken(req.VrfPublicKey, et)
+		case MultiShow:
+			err = i.verifyMultiShowToken(registrationKey, RegistrationChallenge(req.VrfPublicKey, i.GetPublicKey()), et)
+		default:
+			err = errors.New("unknown credential type")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("revocation token for epoch %d: %w", et.Epoch, err)
+		}
+		tokens[et.Epoch] = append(RevocationToken(nil), et.Token...)
+	}
+	return tokens, nil
+}
+
+// verifyOneShowToken verifies the ECVRF proof of a OneShow token for the compressed VRF public key.
+func verifyOneShowToken(vrfPublicKey []byte, et EpochToken) error {
+	pk, err := secp256k1.ParsePubKey(vrfPublicKey)
+	if err != nil {
+		return err
+	}
+	beta, err := vrf.Verify(pk.ToECDSA(), epoch.Bytes(et.Epoch), et.Proof)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(beta, et.Token) {
+		return errors.New("token does not match VRF proof")
+	}
+	return nil
+}
+
+// verifyMultiShowToken verifies the Groth16 or PLONK proof of a MultiShow token with the issuer's verifying key of
+// its backend. The public inputs bind it to the registration key, which only signed the VRF public key hash of the
+// request, and to the challenge of the request.
+func (i *Issuer) verifyMultiShowToken(registrationKey []byte, challenge *big.Int, et EpochToken) error {
+	var pk eddsa.PublicKey
+	_, err := pk.SetBytes(registrationKey)
+	if err != nil {
+		return err
+	}
+	publicInputs := zkp.PublicInputs{
+		IssuerX:         pk.A.X.BigInt(new(big.Int)),
+		IssuerY:         pk.A.Y.BigInt(new(big.Int)),
+		RevocationToken: new(big.Int).SetBytes(et.Token),
+		Epoch:           et.Epoch,
+		Challenge:       challenge,
+	}
+	publicWitness, err := publicInputs.Witness()
+	if err != nil {
+		return err
+	}
+
+	switch et.Backend {
+	case zkp.Groth16:
+		if i.tokenVerifyingKey == nil {
+			return errors.New("no verifying key for Groth16 revocation token proofs")
+		}
+		proof := groth16.NewProof(ecc.BN254)
+		_, err = proof.ReadFrom(bytes.NewReader(et.Proof))
+		if err != nil {
+			return err
+		}
+		return groth16.Verify(proof, i.tokenVerifyingKey, publicWitness)
+	case zkp.Plonk:
+		if i.plonkVerifyingKey == nil {
+			return errors.New("no verifying key for PLONK revocation token proofs")
+		}
+		proof := plonk.NewProof(ecc.BN254)
+		_, err = proof.ReadFrom(bytes.NewReader(et.Proof))
+		if err != nil {
+			return err
+		}
+		return plonk.Verify(proof, i.plonkVerifyingKey, publicWitness, solidity.WithVerifierTargetSolidityVerifier(backend.PLONK))
+	default:
+		return fmt.Errorf("unsupported proving backend %s", et.Backend)
+	}
+}
+
+// VrfPublicKeyHash computes the credential attribute (PublicKeyVrfHash) of a compressed VRF public key.
+func VrfPublicKeyHash(version CredentialType, vrfPublicKey []byte) ([]byte, error) {
+	switch version {
+	case OneShow:
+		pk, err := secp256k1.ParsePubKey(vrfPublicKey)
+		if err != nil {
+			return nil, err
+		}
+		return ethcrypto.Keccak256(pk.SerializeCompressed()), nil
+	case MultiShow:
+		var pk twistededwards.PointAffine
+		_, err := pk.SetBytes(vrfPublicKey)
+		if err != nil {
+			return nil, err
+		}
+		return zkp.HashEddsaPublicKey(zkp.EddsaForCircuitPublicKey(pk))
+	default:
+		return nil, errors.New("unknown credential type")
+	}
+}
+
+// proveVrfKeyPossession signs the VRF public key and the issuer public key with the VRF secret key.
+// OneShow keys produce a 65-byte ECDSA signature, MultiShow keys a 64-byte Schnorr signature (R || s) on BabyJubJub.
+func proveVrfKeyPossession(vrfKeyPair *VrfKeyPair, vrfPublicKey, issuerPublicKey []byte) ([]byte, error) {
+	msg := ethcrypto.Keccak256(popDomain, vrfPublicKey, issuerPublicKey)
+
+	switch vrfKeyPair.version {
+	case OneShow:
+		ecdsaKey, err := vrfKeyPair.GetEcdsaVersion()
+		if err != nil {
+			return nil, err
+		}
+		return ethcrypto.Sign(msg, ecdsaKey)
+	case MultiShow:
+		curve := twistededwards.GetEdwardsCurve()
+
+		r, err := crand.Int(crand.Reader, &curve.Order)
+		if err != nil {
+			return nil, err
+		}
+		var R twistededwards.PointAffine
+		R.ScalarMultiplication(&curve.Base, r)
+		rBytes := R.Bytes()
+
+		c := schnorrChallenge(rBytes[:], msg)
+		s := new(big.Int).Mul(c, new(big.Int).SetBytes(vrfKeyPair.PrivateKey))
+		s.Add(s, r).Mod(s, &curve.Order)
+
+		sig := make([]byte, 64)
+		copy(sig[:32], rBytes[:])
+		s.FillBytes(sig[32:])
+		return sig, nil
+	default:
+		return nil, errors.New("unknown credential type")
+	}
+}
+
+// verifyVrfKeyPossession checks a proof of possession created by proveVrfKeyPossession and returns the
+// PublicKeyVrfHash of the VRF public key.
+func verifyVrfKeyPossession(version CredentialType, vrfPublicKey, pop, issuerPublicKey []byte) ([]byte, error) {
+	publicKeyVrfHash, err := VrfPublicKeyHash(version, vrfPublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid VRF public key: %w", err)
+	}
+	msg := ethcrypto.Keccak256(popDomain, vrfPublicKey, issuerPublicKey)
+
+	switch version {
+	case OneShow:
+		if len(pop) != 65 {
+			return nil, errors.New("proof of possession must be 65 bytes")
+		}
+		recovered, err := ethcrypto.SigToPub(msg, pop)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proof of possession: %w", err)
+		}
+		if !bytes.Equal(ethcrypto.CompressPubkey(recovered), vrfPublicKey) {
+			return nil, errors.New("invalid proof of possession")
+		}
+	case MultiShow:
+		if len(pop) != 64 {
+			return nil, errors.New("proof of possession must be 64 bytes")
+		}
+		curve := twistededwards.GetEdwardsCurve()
+
+		var R, pk twistededwards.PointAffine
+		_, err = R.SetBytes(pop[:32])
+		if err != nil {
+			return nil, fmt.Errorf("invalid proof of possession: %w", err)
+		}
+		_, err = pk.SetBytes(vrfPublicKey)
+		if err != nil {
+			return nil, err
+		}
+		s := new(big.Int).SetBytes(pop[32:])
+		if s.Cmp(&curve.Order) >= 0 {
+			return nil, errors.New("invalid proof of possession")
+		}
+
+		// Check s·B == R + c·PK
+		c := schnorrChallenge(pop[:32], msg)
+		var lhs, cPk, rhs twistededwards.PointAffine
+		lhs.ScalarMultiplication(&curve.Base, s)
+		cPk.ScalarMultiplication(&pk, c)
+		rhs.Add(&R, &cPk)
+		if !lhs.Equal(&rhs) {
+			return nil, errors.New("invalid proof of possession")
+		}
+	default:
+		return nil, errors.New("unknown credential type")
+	}
+	return publicKeyVrfHash, nil
+}
+
+// schnorrChallenge derives the Schnorr challenge from the commitment R and the message.
+func schnorrChallenge(r, msg []byte) *big.Int {
+	curve := twistededwards.GetEdwardsCurve()
+	c := new(big.Int).SetBytes(ethcrypto.Keccak256(r, msg))
+	return c.Mod(c, &curve.Order)
+}
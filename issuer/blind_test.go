@@ -0,0 +1,179 @@
+package issuer
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+// blindRequest creates a OneShow issuance request with the tokens of the issuer's registration ticket.
+func blindRequest(t *testing.T, iss *Issuer, vrfKeyPair *VrfKeyPair) *IssuanceRequest {
+	req, err := NewIssuanceRequest(vrfKeyPair, iss.GetPublicKey())
+	require.NoError(t, err)
+	ticket, err := iss.RegistrationTicket(req)
+	require.NoError(t, err)
+	require.NoError(t, req.AddOneShowTokens(vrfKeyPair, ticket))
+	return req
+}
+
+func TestBlind_IssueBlindCredential(t *testing.T) {
+	dir := t.TempDir()
+	store, err := OpenFileStore(dir)
+	require.NoError(t, err)
+	iss, err := NewIssuerWithStore(OneShow, store)
+	require.NoError(t, err)
+	iss.SetRegistrationEpochs(3)
+
+	vrfKeyPair, err := NewVrfKeyPair(OneShow)
+	require.NoError(t, err)
+	req := blindRequest(t, iss, vrfKeyPair)
+	require.Len(t, req.Tokens, 3)
+
+	cred, err := iss.IssueBlindCredential(7, req)
+	require.NoError(t, err)
+	require.Equal(t, vrfKeyPair.PublicKeyVrfHash, cred.PublicKeyVrfHash)
+	require.Equal(t, req.Tokens[2].Epoch, cred.ValidUntil)
+	valid, err := cred.Verify(iss.GetPublicKey())
+	require.NoError(t, err)
+	require.True(t, valid)
+
+	// The signature covers the validity bound.
+	extended := cred
+	extended.ValidUntil = iss.EpochPolicy().Next(cred.ValidUntil)
+	valid, err = extended.Verify(iss.GetPublicKey())
+	require.NoError(t, err)
+	require.False(t, valid)
+	extended.ValidUntil = 0
+	valid, err = extended.Verify(iss.GetPublicKey())
+	require.NoError(t, err)
+	require.False(t, valid)
+
+	// The issuer does not learn the VRF secret key.
+	stored, err := iss.GetCredentialCopy(7)
+	require.NoError(t, err)
+	require.Nil(t, stored.VrfKeyPair)
+	require.Len(t, stored.Tokens, 3)
+
+	_, err = iss.IssueBlindCredential(7, req)
+	require.Error(t, err, "id reuse must fail")
+
+	// Revocation tokens of blindly issued credentials are the registered ones.
+	require.NoError(t, iss.IssueCredentials(5))
+	require.NoError(t, iss.RevokeCredential(7))
+	holderCred := InternalCredential{VrfKeyPair: vrfKeyPair, Credential: cred}
+	epochs, err := iss.RegistrationEpochs()
+	require.NoError(t, err)
+	expected, _, err := holderCred.GenRevocationToken(epochs[2])
+	require.NoError(t, err)
+	revoked, _, err := iss.genRevocationTokens(epochs[2])
+	require.NoError(t, err)
+	require.Equal(t, []RevocationToken{expected}, revoked)
+
+	// Once expired, the credential is left out of the artifact.
+	revoked, valids, err := iss.genRevocationTokens(iss.EpochPolicy().Next(epochs[2]))
+	require.NoError(t, err)
+	require.Empty(t, revoked)
+	require.Len(t, valids, 5)
+
+	// Before, an epoch without registered token fails the artifact instead of leaving the credential out.
+	_, _, err = iss.genRevocationTokens(epochs[0] - 1)
+	require.ErrorContains(t, err, "no revocation token")
+	_, err = iss.GenRevocationArtifactStreamed(epochs[0] - int64(iss.EpochPolicy().Length/time.Second))
+	require.ErrorContains(t, err, "no revocation token")
+
+	// The registered tokens survive a reopen of the store.
+	require.NoError(t, iss.Close())
+	store, err = OpenFileStore(dir)
+	require.NoError(t, err)
+	reopened, err := NewIssuerWithStore(OneShow, store)
+	require.NoError(t, err)
+	defer reopened.Close()
+	revoked, _, err = reopened.genRevocationTokens(epochs[2])
+	require.NoError(t, err)
+	require.Equal(t, []RevocationToken{expected}, revoked)
+}
+
+func TestBlind_RegistrationTicket(t *testing.T) {
+	iss := NewIssuer(MultiShow)
+	iss.SetRegistrationEpochs(2)
+	vrfKeyPair, err := NewVrfKeyPair(MultiShow)
+	require.NoError(t, err)
+	req, err := NewIssuanceRequest(vrfKeyPair, iss.GetPublicKey())
+	require.NoError(t, err)
+
+	ticket, err := iss.RegistrationTicket(req)
+	require.NoError(t, err)
+	epochs, err := iss.RegistrationEpochs()
+	require.NoError(t, err)
+	require.Equal(t, epochs, ticket.Epochs)
+	require.Equal(t, RegistrationChallenge(req.VrfPublicKey, iss.GetPublicKey()), ticket.Challenge)
+
+	// The ticket is signed by the registration key, so it is no credential of the issuer.
+	require.False(t, bytes.Equal(iss.GetPublicKey(), ticket.RegistrationKey))
+	ticketCred := Credential{PublicKeyVrfHash: vrfKeyPair.PublicKeyVrfHash, Signature: ticket.Signature, Type: MultiShow}
+	valid, err := ticketCred.Verify(ticket.RegistrationKey)
+	require.NoError(t, err)
+	require.True(t, valid)
+	valid, _ = ticketCred.Verify(iss.GetPublicKey())
+	require.False(t, valid)
+
+	// MultiShow tokens cannot be verified without the verifying key.
+	req.Tokens = []EpochToken{{Epoch: epochs[0]}, {Epoch: epochs[1]}}
+	_, err = iss.IssueBlindCredential(0, req)
+	require.Error(t, err)
+
+	// OneShow tickets only carry the epochs.
+	oneShow := NewIssuer(OneShow)
+	oneShowKeyPair, err := NewVrfKeyPair(OneShow)
+	require.NoError(t, err)
+	req, err = NewIssuanceRequest(oneShowKeyPair, oneShow.GetPublicKey())
+	require.NoError(t, err)
+	ticket, err = oneShow.RegistrationTicket(req)
+	require.NoError(t, err)
+	require.Len(t, ticket.Epochs, DefaultRegistrationEpochs)
+	require.Nil(t, ticket.Signature)
+	require.Nil(t, ticket.Challenge)
+}
+
+func TestBlind_RejectsInvalidRequests(t *testing.T) {
+	iss := NewIssuer(OneShow)
+	other := NewIssuer(OneShow)
+
+	vrfKeyPair, err := NewVrfKeyPair(OneShow)
+	require.NoError(t, err)
+	otherKeyPair, err := NewVrfKeyPair(OneShow)
+	require.NoError(t, err)
+
+	for name, modify := range map[string]func(req *IssuanceRequest){
+		"proof of possession bound to another issuer": func(req *IssuanceRequest) {
+			bound, err := NewIssuanceRequest(vrfKeyPair, other.GetPublicKey())
+			require.NoError(t, err)
+			req.ProofOfPossession = bound.ProofOfPossession
+		},
+		"tampered proof of possession": func(req *IssuanceRequest) {
+			req.ProofOfPossession[len(req.ProofOfPossession)-2] ^= 0x01
+		},
+		"tokens of another VRF key": func(req *IssuanceRequest) {
+			req.Tokens = blindRequest(t, iss, otherKeyPair).Tokens
+		},
+		"tampered token": func(req *IssuanceRequest) {
+			req.Tokens[1].Token[0] ^= 0x01
+		},
+		"token for another epoch": func(req *IssuanceRequest) {
+			req.Tokens[0], req.Tokens[1] = req.Tokens[1], req.Tokens[0]
+		},
+		"missing token": func(req *IssuanceRequest) {
+			req.Tokens = req.Tokens[1:]
+		},
+	} {
+		req := blindRequest(t, iss, vrfKeyPair)
+		modify(req)
+		_, err = iss.IssueBlindCredential(0, req)
+		require.Error(t, err, name)
+	}
+
+	_, err = NewIssuer(MultiShow).RegistrationTicket(blindRequest(t, iss, vrfKeyPair))
+	require.Error(t, err, "wrong credential type")
+	require.Equal(t, 0, iss.AmountIssued())
+}
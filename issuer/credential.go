@@ -1,7 +1,10 @@
 package issuer
 
 import (
-	"encoding/binary"
+	"PrivacyPreservingRevocationCode/epoch"
+	"PrivacyPreservingRevocationCode/zkp"
+	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/consensys/gnark-crypto/ecc/bn254/fr/mimc"
@@ -9,6 +12,7 @@ import (
 	"github.com/decred/dcrd/dcrec/secp256k1/v4"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/leandro-ro/go-ecvrf"
+	"math/big"
 )
 
 type CredentialType uint8
@@ -34,11 +38,49 @@ func (ct CredentialType) String() string {
 // Credential represents a credential containing a single VRF public key hash as attribute and a corresponding signature.
 type Credential struct {
 	PublicKeyVrfHash []byte         // PublicKeyVrfHash (attribute) is the hash of the VRF public key
-	Signature        []byte         // Signature over the credential attribute (i.e. PublicKeyVrfHash)
+	Signature        []byte         // Signature over the credential attribute (i.e. PublicKeyVrfHash) and ValidUntil
 	Type             CredentialType // Type denotes the specific category of CredentialType used within InternalCredential.
+	ValidUntil       int64          // ValidUntil is the last unix epoch the credential is valid for, or 0 if it is unbounded.
+}
+
+// SignedMessage returns the message the issuer signed for the credential, see credentialMessage.
+func (c *Credential) SignedMessage() ([]byte, error) {
+	return credentialMessage(c.Type, c.PublicKeyVrfHash, c.ValidUntil)
+}
+
+// Expired reports whether the unix epoch is after the credential's validity bound. Unbounded credentials never expire.
+func (c *Credential) Expired(epochUnix int64) bool {
+	return c.ValidUntil != 0 && epochUnix > c.ValidUntil
+}
+
+// credentialMessage returns the message an issuer signs for a credential on publicKeyVrfHash that is valid up to
+// and including the unix epoch validUntil. Unbounded credentials (validUntil 0) are signed on publicKeyVrfHash
+// itself. Bounded OneShow credentials are signed on keccak256(publicKeyVrfHash || validUntil), with the bound as
+// 32 byte big-endian integer as in the OneShowVerifier contract, and bounded MultiShow credentials on
+// zkp.CredentialMessage, which the MultiShow circuits verify.
+func credentialMessage(version CredentialType, publicKeyVrfHash []byte, validUntil int64) ([]byte, error) {
+	if validUntil < 0 {
+		return nil, errors.New("validity bound must not be negative")
+	}
+	if validUntil == 0 {
+		return publicKeyVrfHash, nil
+	}
+	switch version {
+	case OneShow:
+		return crypto.Keccak256(publicKeyVrfHash, big.NewInt(validUntil).FillBytes(make([]byte, 32))), nil
+	case MultiShow:
+		return zkp.CredentialMessage(publicKeyVrfHash, validUntil)
+	default:
+		return nil, errors.New("unknown credential type")
+	}
 }
 
 func (c *Credential) Verify(issuerPublicKey []byte) (bool, error) {
+	msg, err := c.SignedMessage()
+	if err != nil {
+		return false, err
+	}
+
 	switch c.Type {
 	case OneShow:
 		if len(c.Signature) != 65 {
@@ -54,7 +96,7 @@ func (c *Credential) Verify(issuerPublicKey []byte) (bool, error) {
 		}
 
 		// Recover uncompressed pubkey (65 bytes) from signature
-		recoveredPubkeyBytes, err := crypto.Ecrecover(c.PublicKeyVrfHash, sig)
+		recoveredPubkeyBytes, err := crypto.Ecrecover(msg, sig)
 		if err != nil {
 			return false, fmt.Errorf("ecrecover failed: %w", err)
 		}
@@ -79,7 +121,7 @@ func (c *Credential) Verify(issuerPublicKey []byte) (bool, error) {
 			return false, err
 		}
 
-		sigValid, err := issuerPk.Verify(c.Signature, c.PublicKeyVrfHash, mimc.NewMiMC())
+		sigValid, err := issuerPk.Verify(c.Signature, msg, mimc.NewMiMC())
 		if err != nil {
 			return false, err
 		}
@@ -92,11 +134,106 @@ func (c *Credential) Verify(issuerPublicKey []byte) (bool, error) {
 // InternalCredential represents a structured internal credential containing its id, VRF, status, and associated Credential.
 // Instances of this structure are hold by the issuer internally.
 type InternalCredential struct {
-	ID              uint        // ID is the issuer internal identifier for the Credential.
-	Revoked         bool        // Revoked is the issuer internal revocation status.
-	VrfKeyPair      *VrfKeyPair // PrivateKeyVrf is the VRF associated with the Credential.
-	Credential      Credential  // Credential is the Credential associated with the InternalCredential.
-	IssuerPublicKey []byte      // IssuerPublicKey is the public key of the credential issuer used to verify Credential.
+	ID              uint                      // ID is the issuer internal identifier for the Credential.
+	Revoked         bool                      // Revoked is the issuer internal revocation status.
+	VrfKeyPair      *VrfKeyPair               // PrivateKeyVrf is the VRF associated with the Credential.
+	Credential      Credential                // Credential is the Credential associated with the InternalCredential.
+	IssuerPublicKey []byte                    // IssuerPublicKey is the public key of the credential issuer used to verify Credential.
+	VrfPublicKey    []byte                    // VrfPublicKey is the compressed VRF public key of a blindly issued credential.
+	Tokens          map[int64]RevocationToken // Tokens are the registered revocation tokens by epoch of a blindly issued credential.
+}
+
+// internalCredentialJSON is an unexported type for marshaling/unmarshaling InternalCredential struct.
+// The VRF key pair is persisted by its private key only and restored via VrfKeyPairFromPrivateKey.
+// Blindly issued credentials are persisted with their VRF public key and registered revocation tokens instead.
+type internalCredentialJSON struct {
+	ID               uint             `json:"id"`
+	Revoked          bool             `json:"revoked"`
+	Type             CredentialType   `json:"type"`
+	VrfPrivateKey    []byte           `json:"vrfPrivateKey,omitempty"`
+	VrfPublicKey     []byte           `json:"vrfPublicKey,omitempty"`
+	Tokens           map[int64][]byte `json:"tokens,omitempty"`
+	PublicKeyVrfHash []byte           `json:"publicKeyVrfHash"`
+	Signature        []byte           `json:"signature"`
+	ValidUntil       int64            `json:"validUntil,omitempty"`
+	IssuerPublicKey  []byte           `json:"issuerPublicKey"`
+}
+
+// MarshalJSON implements json.Marshaler interface.
+func (ic InternalCredential) MarshalJSON() ([]byte, error) {
+	j := internalCredentialJSON{
+		ID:               ic.ID,
+		Revoked:          ic.Revoked,
+		Type:             ic.Credential.Type,
+		PublicKeyVrfHash: ic.Credential.PublicKeyVrfHash,
+		Signature:        ic.Credential.Signature,
+		ValidUntil:       ic.Credential.ValidUntil,
+		IssuerPublicKey:  ic.IssuerPublicKey,
+	}
+	switch {
+	case ic.VrfKeyPair != nil:
+		j.VrfPrivateKey = ic.VrfKeyPair.PrivateKey
+	case ic.Tokens != nil:
+		j.VrfPublicKey = ic.VrfPublicKey
+		j.Tokens = make(map[int64][]byte, len(ic.Tokens))
+		for e, token := range ic.Tokens {
+			j.Tokens[e] = token
+		}
+	default:
+		return nil, errors.New("credential has neither a VRF key pair nor revocation tokens")
+	}
+	return json.Marshal(j)
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface.
+func (ic *InternalCredential) UnmarshalJSON(data []byte) error {
+	var j internalCredentialJSON
+	err := json.Unmarshal(data, &j)
+	if err != nil {
+		return err
+	}
+
+	var vrfKeyPair *VrfKeyPair
+	if j.VrfPrivateKey != nil {
+		vrfKeyPair, err = VrfKeyPairFromPrivateKey(j.Type, j.VrfPrivateKey)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(vrfKeyPair.PublicKeyVrfHash, j.PublicKeyVrfHash) {
+			return errors.New("VRF public key hash does not match VRF private key")
+		}
+	} else {
+		if j.Tokens == nil {
+			return errors.New("credential has neither a VRF private key nor revocation tokens")
+		}
+		publicKeyVrfHash, err := VrfPublicKeyHash(j.Type, j.VrfPublicKey)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(publicKeyVrfHash, j.PublicKeyVrfHash) {
+			return errors.New("VRF public key hash does not match VRF public key")
+		}
+	}
+
+	ic.ID = j.ID
+	ic.Revoked = j.Revoked
+	ic.VrfKeyPair = vrfKeyPair
+	ic.Credential = Credential{
+		PublicKeyVrfHash: j.PublicKeyVrfHash,
+		Signature:        j.Signature,
+		Type:             j.Type,
+		ValidUntil:       j.ValidUntil,
+	}
+	ic.IssuerPublicKey = j.IssuerPublicKey
+	ic.VrfPublicKey = j.VrfPublicKey
+	ic.Tokens = nil
+	if j.Tokens != nil {
+		ic.Tokens = make(map[int64]RevocationToken, len(j.Tokens))
+		for e, token := range j.Tokens {
+			ic.Tokens[e] = token
+		}
+	}
+	return nil
 }
 
 func NewInternalCredential(version CredentialType, id uint, issuerPrivateKey []byte) (*InternalCredential, error) {
@@ -143,14 +280,13 @@ func NewInternalCredential(version CredentialType, id uint, issuerPrivateKey []b
 // GenRevocationToken generates a revocation token and its proof based on a given unix epoch and credential type.
 // It supports OneShow and MultiShow credential types. Errors if the type is unknown or token generation fails.
 func (ic *InternalCredential) GenRevocationToken(unixEpoch int64) (token RevocationToken, proof []byte, error error) {
-	epoch := make([]byte, 8)
-	binary.BigEndian.PutUint64(epoch, uint64(unixEpoch))
+	epochBytes := epoch.Bytes(unixEpoch)
 
 	switch ic.Credential.Type {
 	case OneShow:
 		ecdsaKey := secp256k1.PrivKeyFromBytes(ic.VrfKeyPair.PrivateKey).ToECDSA()
 
-		t, p, err := vrf.Prove(ecdsaKey, epoch)
+		t, p, err := vrf.Prove(ecdsaKey, epochBytes)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -159,7 +295,7 @@ func (ic *InternalCredential) GenRevocationToken(unixEpoch int64) (token Revocat
 	case MultiShow:
 		// Compute revocationToken = Hash(epoch || vrf secret key)
 		hf := mimc.NewMiMC()
-		_, err := hf.Write(epoch)
+		_, err := hf.Write(epochBytes)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -173,6 +309,20 @@ func (ic *InternalCredential) GenRevocationToken(unixEpoch int64) (token Revocat
 	}
 }
 
+// GenCurrentRevocationToken generates a revocation token and its proof for the current epoch of the given policy.
+// It returns the epoch the token was generated for alongside token and proof.
+func (ic *InternalCredential) GenCurrentRevocationToken(policy epoch.Policy) (token RevocationToken, proof []byte, epochUnix int64, err error) {
+	epochUnix, err = policy.Current()
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	token, proof, err = ic.GenRevocationToken(epochUnix)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	return token, proof, epochUnix, nil
+}
+
 // GenRevocationTokenNoProof generates a revocation token based on a given unix epoch without including a proof.
 // It supports OneShow and MultiShow credential types, returning an error if the type is unknown or token generation fails.
 func (ic *InternalCredential) GenRevocationTokenNoProof(epochByte []byte) (token RevocationToken, error error) {
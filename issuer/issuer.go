@@ -2,14 +2,19 @@ package issuer
 
 import (
 	"PrivacyPreservingRevocationCode/bloom"
+	"PrivacyPreservingRevocationCode/epoch"
+	"cmp"
 	crand "crypto/rand"
-	"encoding/binary"
 	"errors"
+	"fmt"
 	"github.com/consensys/gnark-crypto/ecc/bn254/fr/mimc"
 	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards/eddsa"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/plonk"
 	ethcrypto "github.com/ethereum/go-ethereum/crypto"
 	mrand "math/rand"
 	"runtime"
+	"slices"
 	"sync"
 )
 
@@ -19,34 +24,135 @@ type Issuer struct {
 	credentialType     CredentialType               // credentialType represents the specific category of CredentialType managed by the issuer.
 	issuedCredentials  map[uint]*InternalCredential // issuedCredentials holds all issued credentials (including revoked)
 	revokedCredentials map[uint]bool                // revokedCredentials holds the uint ids of revoked creds in issuedCredentials
+	epochPolicy        epoch.Policy                 // epochPolicy determines the epoch windows revocation artifacts are generated for.
+	store              Store                        // store persists the key, issued credentials and revocations.
+	registrationKey    []byte                       // registrationKey signs the registration tickets of MultiShow blind issuance.
+	registrationEpochs int                          // registrationEpochs is the number of epochs holders register revocation tokens for.
+	tokenVerifyingKey  groth16.VerifyingKey         // tokenVerifyingKey verifies the MultiShow token proofs of blind issuance.
+	plonkVerifyingKey  plonk.VerifyingKey           // plonkVerifyingKey verifies MultiShow token proofs of blind issuance made with PLONK.
+	artifactHasher     bloom.Hasher                 // artifactHasher is the hash function of the revocation artifact's layers.
+	artifactLayers     bloom.LayerKind              // artifactLayers is the filter type of the revocation artifact's layers.
+	artifactParams     bloom.CascadeParams          // artifactParams are the false positive rates and sizes of the revocation artifact's layers.
 }
 
 // NewIssuer creates a new Issuer with a generated key appropriate to the credential type.
+// Its state is kept in memory only; use NewIssuerWithStore to persist it.
 func NewIssuer(credentialType CredentialType) *Issuer {
-	var key []byte
+	i, err := NewIssuerWithStore(credentialType, NewMemoryStore())
+	if err != nil {
+		panic(err)
+	}
+	return i
+}
+
+// NewIssuerWithStore creates an Issuer backed by the given store.
+// If the store already holds an issuer state, the issuer is reopened with its key, credentials and revocations.
+// Otherwise, a new key appropriate to the credential type is generated and persisted.
+func NewIssuerWithStore(credentialType CredentialType, store Store) (*Issuer, error) {
+	state, err := store.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	if state == nil {
+		key, err := genIssuerKey(credentialType)
+		if err != nil {
+			return nil, err
+		}
+		err = store.Init(credentialType, key)
+		if err != nil {
+			return nil, err
+		}
+		state = &State{CredentialType: credentialType, Key: key, Credentials: make(map[uint]*InternalCredential)}
+	} else if state.CredentialType != credentialType {
+		return nil, fmt.Errorf("store holds a %s issuer, not %s", state.CredentialType, credentialType)
+	}
+
+	var registrationKey []byte
+	if state.CredentialType == MultiShow {
+		registrationKey, err = deriveRegistrationKey(state.Key)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	i := &Issuer{
+		key:                state.Key,
+		credentialType:     state.CredentialType,
+		issuedCredentials:  state.Credentials,
+		revokedCredentials: make(map[uint]bool),
+		epochPolicy:        epoch.Default,
+		store:              store,
+		registrationKey:    registrationKey,
+		registrationEpochs: DefaultRegistrationEpochs,
+		artifactHasher:     bloom.Keccak256,
+		artifactParams:     bloom.DefaultCascadeParams,
+	}
+	for id, cred := range state.Credentials {
+		if cred.Revoked {
+			i.revokedCredentials[id] = true
+		}
+	}
+	return i, nil
+}
+
+// genIssuerKey generates an issuer key appropriate to the credential type.
+func genIssuerKey(credentialType CredentialType) ([]byte, error) {
 	switch credentialType {
 	case OneShow:
 		privKey, err := ethcrypto.GenerateKey()
 		if err != nil {
-			panic(err)
+			return nil, err
 		}
-		key = ethcrypto.FromECDSA(privKey) // returns 32-byte secp256k1 private key
+		return ethcrypto.FromECDSA(privKey), nil // returns 32-byte secp256k1 private key
 	case MultiShow:
 		eddsaKey, err := eddsa.GenerateKey(crand.Reader)
 		if err != nil {
-			panic(err)
+			return nil, err
 		}
-		key = eddsaKey.Bytes()
+		return eddsaKey.Bytes(), nil
 	default:
-		panic("unknown credential type")
+		return nil, errors.New("unknown credential type")
 	}
+}
 
-	return &Issuer{
-		key:                key,
-		credentialType:     credentialType,
-		issuedCredentials:  make(map[uint]*InternalCredential),
-		revokedCredentials: make(map[uint]bool),
-	}
+// Close closes the underlying store of the issuer.
+func (i *Issuer) Close() error {
+	return i.store.Close()
+}
+
+// SetEpochPolicy sets the policy used to derive the epoch of revocation artifacts.
+// Holders and verifiers must use the same policy to agree on the current epoch.
+func (i *Issuer) SetEpochPolicy(policy epoch.Policy) {
+	i.epochPolicy = policy
+}
+
+// EpochPolicy returns the policy used to derive the epoch of revocation artifacts.
+func (i *Issuer) EpochPolicy() epoch.Policy {
+	return i.epochPolicy
+}
+
+// SetArtifactHasher sets the hash function of the revocation artifacts' layers. Keccak256, the default, is required
+// by the on-chain cascade; MultiShow issuers use MiMCBN254 for artifacts checked inside NonRevocationProof circuits.
+func (i *Issuer) SetArtifactHasher(h bloom.Hasher) {
+	i.artifactHasher = h
+}
+
+// SetArtifactLayers sets the filter type of the revocation artifacts' layers. Bloom layers, the default, are required
+// for patches and NonRevocationProof circuits; binary fuse layers are smaller and checked by CascadingBinaryFuseFilter.
+func (i *Issuer) SetArtifactLayers(kind bloom.LayerKind) {
+	i.artifactLayers = kind
+}
+
+// SetArtifactParams sets the parameters of the revocation artifacts' layers, e.g. a preset such as
+// bloom.MinLayersCascadeParams or the result of bloom.OptimizeCascadeParams. DefaultCascadeParams is the default.
+func (i *Issuer) SetArtifactParams(params bloom.CascadeParams) {
+	i.artifactParams = params
+}
+
+// CurrentEpoch returns the current epoch according to the issuer's epoch policy.
+func (i *Issuer) CurrentEpoch() (int64, error) {
+	return i.epochPolicy.Current()
 }
 
 // IssueCredentials generates and stores a number of credentials.
@@ -106,6 +212,10 @@ func (i *Issuer) IssueCredential(id uint) error {
 	if err != nil {
 		return err
 	}
+	err = i.store.AddCredential(cred)
+	if err != nil {
+		return err
+	}
 	i.issuedCredentials[id] = cred
 	return nil
 }
@@ -144,82 +254,194 @@ func (i *Issuer) RevokeCredential(id uint) error {
 	if !ok {
 		return errors.New("credential not found")
 	}
+	err := i.store.Revoke(id)
+	if err != nil {
+		return err
+	}
 	cred.Revoked = true
 	i.revokedCredentials[id] = true
 	return nil
 }
 
-func (i *Issuer) genRevocationTokens() (revoked, valid []RevocationToken, epoch int64, err error) {
-	epochBytes := make([]byte, 8)
-	binary.BigEndian.PutUint64(epochBytes, uint64(epoch))
+// tokenBatchSize is the number of revocation tokens revocationTokenSource evaluates at once.
+const tokenBatchSize = 4096
 
-	type result struct {
-		token   RevocationToken
-		revoked bool
-		err     error
+// credentialsByID returns the issued credentials with the given revocation status, ordered by id, so that revocation
+// tokens are produced in the same order on every build.
+func (i *Issuer) credentialsByID(revoked bool) []*InternalCredential {
+	creds := make([]*InternalCredential, 0, len(i.issuedCredentials))
+	for _, cred := range i.issuedCredentials {
+		if cred.Revoked == revoked {
+			creds = append(creds, cred)
+		}
 	}
+	slices.SortFunc(creds, func(a, b *InternalCredential) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return creds
+}
 
+// genRevocationTokens evaluates the revocation tokens of all issued credentials for the given epoch
+// and splits them into revoked and valid tokens, each ordered by credential id.
+func (i *Issuer) genRevocationTokens(epochUnix int64) (revoked, valid []RevocationToken, err error) {
+	epochBytes := epoch.Bytes(epochUnix)
+
+	revokedCreds := i.credentialsByID(true)
+	revoked = make([]RevocationToken, len(revokedCreds))
+	err = i.evalRevocationTokens(revokedCreds, epochUnix, epochBytes, revoked)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	validCreds := i.credentialsByID(false)
+	valid = make([]RevocationToken, len(validCreds))
+	err = i.evalRevocationTokens(validCreds, epochUnix, epochBytes, valid)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Expired blindly issued credentials have no token.
+	isNil := func(token RevocationToken) bool { return token == nil }
+	return slices.DeleteFunc(revoked, isNil), slices.DeleteFunc(valid, isNil), nil
+}
+
+// evalRevocationTokens evaluates the revocation tokens of creds in parallel and stores them at the same index of
+// tokens.
+func (i *Issuer) evalRevocationTokens(creds []*InternalCredential, epochUnix int64, epochBytes []byte, tokens []RevocationToken) error {
 	numWorkers := runtime.NumCPU()
-	jobs := make(chan *InternalCredential, len(i.issuedCredentials))
-	results := make(chan result, len(i.issuedCredentials))
+	jobs := make(chan int, len(creds))
+	for j := range creds {
+		jobs <- j
+	}
+	close(jobs)
 
+	errs := make([]error, numWorkers)
 	var wg sync.WaitGroup
 	wg.Add(numWorkers)
-
 	for w := 0; w < numWorkers; w++ {
-		go func() {
+		go func(w int) {
 			defer wg.Done()
-			for cred := range jobs {
-				token, err := cred.GenRevocationTokenNoProof(epochBytes)
+			for j := range jobs {
+				token, err := i.evalRevocationToken(creds[j], epochUnix, epochBytes)
 				if err != nil {
-					results <- result{err: err}
-					continue
+					errs[w] = err
+					return
 				}
-				results <- result{token: token, revoked: cred.Revoked}
+				tokens[j] = token
 			}
-		}()
+		}(w)
 	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
 
-	for _, cred := range i.issuedCredentials {
-		jobs <- cred
+// evalRevocationToken evaluates the revocation token of a credential for an epoch. Blindly issued credentials hold
+// no VRF key pair; their registered token is returned instead, or nil once they expired, as verifiers reject them
+// anyway. A credential without a token in an epoch it is valid in could not be revoked, so it fails the artifact.
+func (i *Issuer) evalRevocationToken(cred *InternalCredential, epochUnix int64, epochBytes []byte) (RevocationToken, error) {
+	if cred.VrfKeyPair != nil {
+		return cred.GenRevocationTokenNoProof(epochBytes)
 	}
-	close(jobs)
-
-	go func() {
-		wg.Wait()
-		close(results)
-	}()
-
-	revoked = make([]RevocationToken, 0, i.AmountRevoked())
-	valid = make([]RevocationToken, 0, i.AmountIssued()-i.AmountRevoked())
+	token, ok := cred.Tokens[epochUnix]
+	if ok {
+		return token, nil
+	}
+	if cred.Credential.Expired(epochUnix) {
+		return nil, nil
+	}
+	return nil, fmt.Errorf("credential %d has no revocation token for epoch %d", cred.ID, epochUnix)
+}
 
-	for res := range results {
-		if res.err != nil {
-			return nil, nil, -1, res.err
-		}
-		if res.revoked {
-			revoked = append(revoked, res.token)
-		} else {
-			valid = append(valid, res.token)
+// revocationTokenSource returns a re-iterable source of the revocation tokens of either the revoked or the valid
+// credentials for an epoch, ordered by credential id. Tokens are evaluated in parallel batches on every iteration and
+// not retained.
+func (i *Issuer) revocationTokenSource(epochUnix int64, revoked bool) bloom.TokenSource {
+	epochBytes := epoch.Bytes(epochUnix)
+
+	return func(yield func([]byte, error) bool) {
+		creds := i.credentialsByID(revoked)
+		batch := make([]RevocationToken, min(len(creds), tokenBatchSize))
+		for start := 0; start < len(creds); start += tokenBatchSize {
+			tokens := batch[:min(len(creds)-start, tokenBatchSize)]
+			err := i.evalRevocationTokens(creds[start:start+len(tokens)], epochUnix, epochBytes, tokens)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			for _, token := range tokens {
+				if token == nil {
+					continue
+				}
+				if !yield(token, nil) {
+					return
+				}
+			}
 		}
 	}
+}
+
+// GenRevocationArtifactStreamed generates the revocation artifact for the given epoch like
+// GenRevocationArtifactForEpoch, but without materializing the revocation tokens: they are evaluated on demand in
+// each pass of the cascade construction, so memory stays proportional to the filter sizes instead of the number of
+// issued credentials. Revoked credentials are evaluated twice.
+func (i *Issuer) GenRevocationArtifactStreamed(epochUnix int64) (*bloom.BloomFilterCascade, error) {
+	err := i.epochPolicy.Validate(epochUnix)
+	if err != nil {
+		return nil, err
+	}
 
-	return revoked, valid, epoch, nil
+	cascade, err := bloom.NewCascadeWithParams(i.AmountIssued(), i.AmountRevoked(), i.artifactLayers, i.artifactHasher, i.artifactParams)
+	if err != nil {
+		return nil, err
+	}
+	err = cascade.UpdateFromSources(i.revocationTokenSource(epochUnix, true), i.revocationTokenSource(epochUnix, false))
+	if err != nil {
+		return nil, err
+	}
+	cascade.SetEpoch(epochUnix)
+	cascade.SetIssuerID(i.GetPublicKey())
+	return cascade, nil
 }
 
-// GenRevocationArtifact calls genRevocationTokens() an returns a BloomFilterCascade
-func (i *Issuer) GenRevocationArtifact() (artifact *bloom.BloomFilterCascade, revoked, valid []RevocationToken, epoch int64, error error) {
-	revoked, valid, epoch, err := i.genRevocationTokens()
+// GenRevocationArtifact generates the revocation artifact for the current epoch of the issuer's epoch policy.
+// It returns the BloomFilterCascade together with the revoked and valid tokens and the epoch it was built for.
+func (i *Issuer) GenRevocationArtifact() (artifact *bloom.BloomFilterCascade, revoked, valid []RevocationToken, epochUnix int64, error error) {
+	epochUnix, err := i.CurrentEpoch()
 	if err != nil {
 		return nil, nil, nil, -1, err
 	}
 
-	cascade := bloom.NewCascade(i.AmountIssued(), i.AmountRevoked())
-	err = cascade.Update(RevocationTokensToByteSlices(revoked), RevocationTokensToByteSlices(valid))
+	artifact, revoked, valid, err = i.GenRevocationArtifactForEpoch(epochUnix)
 	if err != nil {
 		return nil, nil, nil, -1, err
 	}
-	return cascade, revoked, valid, epoch, nil
+	return artifact, revoked, valid, epochUnix, nil
+}
+
+// GenRevocationArtifactForEpoch calls genRevocationTokens() for the given epoch and returns a BloomFilterCascade.
+// The epoch must be aligned to the issuer's epoch policy. The cascade records the epoch and the issuer public key.
+func (i *Issuer) GenRevocationArtifactForEpoch(epochUnix int64) (artifact *bloom.BloomFilterCascade, revoked, valid []RevocationToken, err error) {
+	err = i.epochPolicy.Validate(epochUnix)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	revoked, valid, err = i.genRevocationTokens(epochUnix)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	cascade, err := bloom.NewCascadeWithParams(i.AmountIssued(), i.AmountRevoked(), i.artifactLayers, i.artifactHasher, i.artifactParams)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	err = cascade.UpdateParallel(RevocationTokensToByteSlices(revoked), RevocationTokensToByteSlices(valid), runtime.NumCPU())
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	cascade.SetEpoch(epochUnix)
+	cascade.SetIssuerID(i.GetPublicKey())
+	return cascade, revoked, valid, nil
 }
 
 func (i *Issuer) GetRevocationStatus(id uint) bool {
@@ -1,9 +1,12 @@
 package issuer
 
 import (
+	"PrivacyPreservingRevocationCode/bloom"
+	"PrivacyPreservingRevocationCode/epoch"
 	"fmt"
 	"github.com/stretchr/testify/require"
 	"testing"
+	"time"
 )
 
 func TestIssuer_IssueAndRevokeCredentials(t *testing.T) {
@@ -58,7 +61,10 @@ func TestIssuer_GenRevocationTokensOneShow(t *testing.T) {
 	err = issuer.RevokeRandomCredentials(20)
 	require.NoError(t, err)
 
-	revokedTokens, validTokens, _, err := issuer.genRevocationTokens()
+	epoch, err := issuer.CurrentEpoch()
+	require.NoError(t, err)
+
+	revokedTokens, validTokens, err := issuer.genRevocationTokens(epoch)
 	require.NoError(t, err)
 	require.Equal(t, 20, len(revokedTokens))
 	require.Equal(t, 980, len(validTokens))
@@ -73,7 +79,10 @@ func TestIssuer_GenRevocationTokensMultiShow(t *testing.T) {
 	err = issuer.RevokeRandomCredentials(20)
 	require.NoError(t, err)
 
-	revokedTokens, validTokens, _, err := issuer.genRevocationTokens()
+	epoch, err := issuer.CurrentEpoch()
+	require.NoError(t, err)
+
+	revokedTokens, validTokens, err := issuer.genRevocationTokens(epoch)
 	require.NoError(t, err)
 	require.Equal(t, 20, len(revokedTokens))
 	require.Equal(t, 980, len(validTokens))
@@ -119,6 +128,61 @@ func TestIssuer_GenRevocationArtifactMultiShow(t *testing.T) {
 	}
 }
 
+func TestIssuer_GenRevocationArtifactStreamed(t *testing.T) {
+	for _, ct := range []CredentialType{OneShow, MultiShow} {
+		iss := NewIssuer(ct)
+		require.NoError(t, iss.IssueCredentials(500))
+		require.NoError(t, iss.RevokeRandomCredentials(50))
+
+		epochUnix, err := iss.CurrentEpoch()
+		require.NoError(t, err)
+
+		expected, _, _, err := iss.GenRevocationArtifactForEpoch(epochUnix)
+		require.NoError(t, err)
+		streamed, err := iss.GenRevocationArtifactStreamed(epochUnix)
+		require.NoError(t, err)
+		require.True(t, expected.Equal(streamed), "%s artifacts differ", ct)
+	}
+}
+
+func TestIssuer_GenRevocationArtifactForEpoch(t *testing.T) {
+	iss := NewIssuer(MultiShow)
+	policy, err := epoch.NewPolicy(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), time.Hour)
+	require.NoError(t, err)
+	iss.SetEpochPolicy(policy)
+
+	require.NoError(t, iss.IssueCredentials(100))
+	require.NoError(t, iss.RevokeRandomCredentials(10))
+
+	current, err := iss.CurrentEpoch()
+	require.NoError(t, err)
+	next := policy.Next(current)
+
+	artifact, revokedTokens, _, err := iss.GenRevocationArtifactForEpoch(next)
+	require.NoError(t, err)
+	require.Equal(t, next, artifact.Epoch())
+	require.Equal(t, iss.GetPublicKey(), artifact.IssuerID())
+
+	// Tokens generated by the holders for the same epoch must match the artifact.
+	for _, cred := range iss.GetAllRevokedCreds() {
+		token, _, err := cred.GenRevocationToken(next)
+		require.NoError(t, err)
+		ok, _ := artifact.Test(token)
+		require.True(t, ok)
+	}
+
+	// Tokens of the current epoch differ from those of the next epoch.
+	_, _, currentEpoch, err := iss.GetAllRevokedCreds()[0].GenCurrentRevocationToken(policy)
+	require.NoError(t, err)
+	require.Equal(t, current, currentEpoch)
+	currentToken, _, err := iss.GetAllRevokedCreds()[0].GenRevocationToken(current)
+	require.NoError(t, err)
+	require.NotContains(t, revokedTokens, currentToken)
+
+	_, _, _, err = iss.GenRevocationArtifactForEpoch(next + 1)
+	require.Error(t, err, "unaligned epoch must be rejected")
+}
+
 func BenchmarkIssuer_GenRevocationArtifact(b *testing.B) {
 	domains := []int{50_000, 100_000, 200_000, 300_000, 400_000, 500_000, 600_000, 700_000, 800_000, 900_000, 1_000_000}
 	rates := []float64{0.10} // Does not affect the generation of the revocation artifact
@@ -158,3 +222,103 @@ func BenchmarkIssuer_GenRevocationArtifact(b *testing.B) {
 		}
 	}
 }
+
+func TestIssuer_SetArtifactLayers(t *testing.T) {
+	iss := NewIssuer(OneShow)
+	require.NoError(t, iss.IssueCredentials(200))
+	require.NoError(t, iss.RevokeRandomCredentials(20))
+
+	iss.SetArtifactLayers(bloom.BinaryFuseLayers)
+	artifact, revokedTokens, validTokens, epochUnix, err := iss.GenRevocationArtifact()
+	require.NoError(t, err)
+	require.Equal(t, bloom.BinaryFuseLayers, artifact.LayerKind())
+	for _, token := range revokedTokens {
+		b, _ := artifact.Test(token.ToBytes())
+		require.True(t, b)
+	}
+	for _, token := range validTokens {
+		b, _ := artifact.Test(token.ToBytes())
+		require.False(t, b)
+	}
+	streamed, err := iss.GenRevocationArtifactStreamed(epochUnix)
+	require.NoError(t, err)
+	require.True(t, artifact.Equal(streamed))
+
+	iss.SetArtifactLayers(bloom.LayerKind(2))
+	_, _, _, _, err = iss.GenRevocationArtifact()
+	require.Error(t, err)
+}
+
+func TestIssuer_DeterministicArtifact(t *testing.T) {
+	iss := NewIssuer(OneShow)
+	require.NoError(t, iss.IssueCredentials(300))
+	require.NoError(t, iss.RevokeRandomCredentials(30))
+	epochUnix, err := iss.CurrentEpoch()
+	require.NoError(t, err)
+
+	for _, kind := range []bloom.LayerKind{bloom.BloomLayers, bloom.BinaryFuseLayers} {
+		iss.SetArtifactLayers(kind)
+		artifact, revoked, valid, err := iss.GenRevocationArtifactForEpoch(epochUnix)
+		require.NoError(t, err)
+		expected, err := artifact.MarshalBinary()
+		require.NoError(t, err)
+
+		// Repeated builds of the same state produce identical tokens and artifacts, streamed or not.
+		for j := 0; j < 3; j++ {
+			rebuilt, rebuiltRevoked, rebuiltValid, err := iss.GenRevocationArtifactForEpoch(epochUnix)
+			require.NoError(t, err)
+			require.Equal(t, revoked, rebuiltRevoked)
+			require.Equal(t, valid, rebuiltValid)
+			data, err := rebuilt.MarshalBinary()
+			require.NoError(t, err)
+			require.Equal(t, expected, data, kind)
+
+			streamed, err := iss.GenRevocationArtifactStreamed(epochUnix)
+			require.NoError(t, err)
+			data, err = streamed.MarshalBinary()
+			require.NoError(t, err)
+			require.Equal(t, expected, data, kind)
+		}
+	}
+}
+
+func TestIssuer_SetArtifactParams(t *testing.T) {
+	iss := NewIssuer(OneShow)
+	require.NoError(t, iss.IssueCredentials(200))
+	require.NoError(t, iss.RevokeRandomCredentials(20))
+
+	iss.SetArtifactParams(bloom.MinLayersCascadeParams)
+	artifact, revokedTokens, validTokens, epochUnix, err := iss.GenRevocationArtifact()
+	require.NoError(t, err)
+	require.Equal(t, bloom.MinLayersCascadeParams, artifact.Params())
+	for _, token := range revokedTokens {
+		b, _ := artifact.Test(token.ToBytes())
+		require.True(t, b)
+	}
+	for _, token := range validTokens {
+		b, _ := artifact.Test(token.ToBytes())
+		require.False(t, b)
+	}
+	streamed, err := iss.GenRevocationArtifactStreamed(epochUnix)
+	require.NoError(t, err)
+	require.True(t, artifact.Equal(streamed))
+
+	iss.SetArtifactParams(bloom.CascadeParams{})
+	_, _, _, _, err = iss.GenRevocationArtifact()
+	require.Error(t, err)
+}
+
+func TestIssuer_SetArtifactHasher(t *testing.T) {
+	iss := NewIssuer(MultiShow)
+	require.NoError(t, iss.IssueCredentials(50))
+	require.NoError(t, iss.RevokeRandomCredentials(5))
+
+	iss.SetArtifactHasher(bloom.MiMCBN254)
+	artifact, revokedTokens, _, _, err := iss.GenRevocationArtifact()
+	require.NoError(t, err)
+	require.Equal(t, bloom.MiMCBN254ID, artifact.Hasher().ID())
+	for _, token := range revokedTokens {
+		b, _ := artifact.Test(token.ToBytes())
+		require.True(t, b)
+	}
+}
@@ -0,0 +1,462 @@
+package issuer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Store persists the state of an Issuer, i.e. its key, issued credentials and their revocation status.
+type Store interface {
+	// Load returns the persisted issuer state or nil if the store has not been initialized yet.
+	Load() (*State, error)
+	// Init persists the credential type and key of a new issuer.
+	Init(credentialType CredentialType, key []byte) error
+	// AddCredential persists a newly issued credential.
+	AddCredential(cred *InternalCredential) error
+	// Revoke persists the revocation of the credential with the given id.
+	Revoke(id uint) error
+	// Close flushes pending writes and releases all resources held by the store.
+	Close() error
+}
+
+// State is the persisted state of an Issuer.
+type State struct {
+	CredentialType CredentialType               `json:"credentialType"`
+	Key            []byte                       `json:"key"`
+	Credentials    map[uint]*InternalCredential `json:"credentials"`
+}
+
+// copyState returns a copy of s that does not share credentials with s.
+func (s *State) copyState() *State {
+	c := &State{
+		CredentialType: s.CredentialType,
+		Key:            append([]byte(nil), s.Key...),
+		Credentials:    make(map[uint]*InternalCredential, len(s.Credentials)),
+	}
+	for id, cred := range s.Credentials {
+		credCopy := *cred
+		c.Credentials[id] = &credCopy
+	}
+	return c
+}
+
+// MemoryStore is a Store that keeps the issuer state in memory only.
+type MemoryStore struct {
+	mu    sync.Mutex
+	state *State // state is nil until Init is called.
+}
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+// Load implements Store.
+func (m *MemoryStore) Load() (*State, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.state == nil {
+		return nil, nil
+	}
+	return m.state.copyState(), nil
+}
+
+// Init implements Store.
+func (m *MemoryStore) Init(credentialType CredentialType, key []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.state != nil {
+		return errors.New("store already initialized")
+	}
+	m.state = &State{
+		CredentialType: credentialType,
+		Key:            append([]byte(nil), key...),
+		Credentials:    make(map[uint]*InternalCredential),
+	}
+	return nil
+}
+
+// AddCredential implements Store.
+func (m *MemoryStore) AddCredential(cred *InternalCredential) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.state == nil {
+		return errors.New("store not initialized")
+	}
+	if _, exists := m.state.Credentials[cred.ID]; exists {
+		return errors.New("credential id already assigned")
+	}
+	credCopy := *cred
+	m.state.Credentials[cred.ID] = &credCopy
+	return nil
+}
+
+// Revoke implements Store.
+func (m *MemoryStore) Revoke(id uint) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.state == nil {
+		return errors.New("store not initialized")
+	}
+	cred, ok := m.state.Credentials[id]
+	if !ok {
+		return errors.New("credential not found")
+	}
+	cred.Revoked = true
+	return nil
+}
+
+// Close implements Store.
+func (m *MemoryStore) Close() error {
+	return nil
+}
+
+const (
+	snapshotFileName = "snapshot.json" // snapshotFileName is the name of the snapshot file in a FileStore directory.
+	logFileName      = "log.jsonl"     // logFileName is the name of the append-only log in a FileStore directory.
+	lockFileName     = "lock"          // lockFileName is the name of the file a FileStore holds a lock on while open.
+
+	// DefaultSnapshotThreshold is the number of log records after which a FileStore compacts its log into a snapshot.
+	DefaultSnapshotThreshold = 10_000
+)
+
+// logOp identifies the type of record in the append-only log.
+type logOp string
+
+const (
+	opInit   logOp = "init"
+	opIssue  logOp = "issue"
+	opRevoke logOp = "revoke"
+)
+
+// logRecord is a single entry of the append-only log.
+type logRecord struct {
+	Op             logOp               `json:"op"`
+	CredentialType CredentialType      `json:"credentialType,omitempty"`
+	Key            []byte              `json:"key,omitempty"`
+	Credential     *InternalCredential `json:"credential,omitempty"`
+	ID             uint                `json:"id,omitempty"`
+}
+
+// FileStore is a Store that persists the issuer state in a directory on disk.
+// Every change is appended to a log file. Once the log exceeds SnapshotThreshold records it is compacted into
+// a snapshot of the full state. On open, the snapshot is loaded and the log is replayed on top of it.
+//
+// Records are flushed to the operating system on every write but only synced to stable storage on Snapshot,
+// Sync and Close. A record is applied to the in-memory state only once it is written.
+//
+// An open FileStore holds an exclusive lock on its directory, so a second OpenFileStore of the directory fails until
+// the store is closed, also in other processes.
+type FileStore struct {
+	SnapshotThreshold int // SnapshotThreshold is the number of log records that triggers a snapshot. Zero disables it.
+
+	mu      sync.Mutex
+	dir     string
+	mem     *MemoryStore  // mem mirrors the persisted state and is used to write snapshots.
+	lock    *os.File      // lock is the locked lock file, see lockFile.
+	log     *os.File      // log is the append-only log file.
+	w       *bufio.Writer // w buffers writes to log.
+	size    int64         // size is the length of the log of complete records.
+	records int           // records is the number of records in the log since the last snapshot.
+}
+
+// OpenFileStore opens the FileStore in dir, creating the directory if it does not exist.
+func OpenFileStore(dir string) (*FileStore, error) {
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		return nil, err
+	}
+
+	lock, err := lockFile(filepath.Join(dir, lockFileName))
+	if err != nil {
+		return nil, err
+	}
+	fs, err := openLockedFileStore(dir)
+	if err != nil {
+		_ = lock.Close()
+		return nil, err
+	}
+	fs.lock = lock
+	return fs, nil
+}
+
+// openLockedFileStore opens the FileStore in dir, whose lock the caller holds.
+func openLockedFileStore(dir string) (*FileStore, error) {
+	fs := &FileStore{SnapshotThreshold: DefaultSnapshotThreshold, dir: dir, mem: NewMemoryStore()}
+
+	err := fs.loadSnapshot()
+	if err != nil {
+		return nil, err
+	}
+
+	size, err := fs.replayLog()
+	if err != nil {
+		return nil, err
+	}
+
+	fs.log, err = os.OpenFile(filepath.Join(dir, logFileName), os.O_RDWR|os.O_CREATE, 0600)
+	if err == nil {
+		err = syncDir(dir)
+	}
+	if err == nil {
+		// Drop a partially written trailing record, e.g. after a crash during a write.
+		fs.w = bufio.NewWriter(fs.log)
+		err = fs.truncate(size)
+	}
+	if err != nil {
+		if fs.log != nil {
+			_ = fs.log.Close()
+		}
+		return nil, err
+	}
+	return fs, nil
+}
+
+// loadSnapshot reads the snapshot file into the in-memory mirror, if present.
+func (fs *FileStore) loadSnapshot() error {
+	data, err := os.ReadFile(filepath.Join(fs.dir, snapshotFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var state State
+	err = json.Unmarshal(data, &state)
+	if err != nil {
+		return fmt.Errorf("corrupted snapshot: %w", err)
+	}
+	if state.Credentials == nil {
+		state.Credentials = make(map[uint]*InternalCredential)
+	}
+	fs.mem.state = &state
+	return nil
+}
+
+// replayLog applies all complete records of the log file to the in-memory mirror.
+// It returns the size in bytes of the log prefix consisting of complete records.
+func (fs *FileStore) replayLog() (int64, error) {
+	data, err := os.ReadFile(filepath.Join(fs.dir, logFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	var offset int64
+	for len(data) > 0 {
+		end := bytes.IndexByte(data, '\n')
+		if end < 0 {
+			break // incomplete trailing record
+		}
+
+		var rec logRecord
+		err = json.Unmarshal(data[:end], &rec)
+		if err != nil {
+			return 0, fmt.Errorf("corrupted log record at offset %d: %w", offset, err)
+		}
+		err = fs.apply(rec)
+		if err != nil {
+			return 0, fmt.Errorf("invalid log record at offset %d: %w", offset, err)
+		}
+
+		fs.records++
+		offset += int64(end + 1)
+		data = data[end+1:]
+	}
+	return offset, nil
+}
+
+// apply applies a log record to the in-memory mirror.
+func (fs *FileStore) apply(rec logRecord) error {
+	switch rec.Op {
+	case opInit:
+		return fs.mem.Init(rec.CredentialType, rec.Key)
+	case opIssue:
+		if rec.Credential == nil {
+			return errors.New("issue record without credential")
+		}
+		return fs.mem.AddCredential(rec.Credential)
+	case opRevoke:
+		return fs.mem.Revoke(rec.ID)
+	default:
+		return fmt.Errorf("unknown operation %q", rec.Op)
+	}
+}
+
+// append appends rec to the log and applies it to the in-memory mirror. If either fails, the record is removed from
+// the log again.
+func (fs *FileStore) append(rec logRecord) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	if fs.log == nil {
+		return errors.New("store closed")
+	}
+
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+
+	_, err = fs.w.Write(append(line, '\n'))
+	if err == nil {
+		err = fs.w.Flush()
+	}
+	if err == nil {
+		err = fs.apply(rec)
+	}
+	if err != nil {
+		return errors.Join(err, fs.truncate(fs.size))
+	}
+
+	fs.size += int64(len(line) + 1)
+	fs.records++
+	if fs.SnapshotThreshold > 0 && fs.records >= fs.SnapshotThreshold {
+		return fs.snapshot()
+	}
+	return nil
+}
+
+// Load implements Store.
+func (fs *FileStore) Load() (*State, error) {
+	return fs.mem.Load()
+}
+
+// Init implements Store.
+func (fs *FileStore) Init(credentialType CredentialType, key []byte) error {
+	return fs.append(logRecord{Op: opInit, CredentialType: credentialType, Key: key})
+}
+
+// AddCredential implements Store.
+func (fs *FileStore) AddCredential(cred *InternalCredential) error {
+	return fs.append(logRecord{Op: opIssue, Credential: cred})
+}
+
+// Revoke implements Store.
+func (fs *FileStore) Revoke(id uint) error {
+	return fs.append(logRecord{Op: opRevoke, ID: id})
+}
+
+// Snapshot writes the full state to the snapshot file and truncates the log.
+func (fs *FileStore) Snapshot() error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	if fs.log == nil {
+		return errors.New("store closed")
+	}
+	return fs.snapshot()
+}
+
+// snapshot writes the snapshot atomically via a temporary file and truncates the log. Callers must hold fs.mu.
+func (fs *FileStore) snapshot() error {
+	state, err := fs.mem.Load()
+	if err != nil || state == nil {
+		return err
+	}
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	tmpPath := filepath.Join(fs.dir, snapshotFileName+".tmp")
+	tmp, err := os.OpenFile(tmpPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	err = os.Rename(tmpPath, filepath.Join(fs.dir, snapshotFileName))
+	if err != nil {
+		return err
+	}
+	// Only once the rename is durable, the snapshot replaces the log.
+	err = syncDir(fs.dir)
+	if err != nil {
+		return err
+	}
+
+	// The snapshot now contains every logged record, so the log can start over.
+	err = fs.truncate(0)
+	if err != nil {
+		return err
+	}
+	fs.records = 0
+	return fs.log.Sync()
+}
+
+// truncate cuts the log to size bytes and continues writing there. Callers must hold fs.mu.
+func (fs *FileStore) truncate(size int64) error {
+	err := fs.log.Truncate(size)
+	if err != nil {
+		return err
+	}
+	_, err = fs.log.Seek(size, io.SeekStart)
+	if err != nil {
+		return err
+	}
+	fs.w.Reset(fs.log)
+	fs.size = size
+	return nil
+}
+
+// Sync commits the log to stable storage.
+func (fs *FileStore) Sync() error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	if fs.log == nil {
+		return errors.New("store closed")
+	}
+	err := fs.w.Flush()
+	if err != nil {
+		return err
+	}
+	return fs.log.Sync()
+}
+
+// Close implements Store.
+func (fs *FileStore) Close() error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	if fs.log == nil {
+		return nil
+	}
+	err := fs.w.Flush()
+	if err == nil {
+		err = fs.log.Sync()
+	}
+	if closeErr := fs.log.Close(); err == nil {
+		err = closeErr
+	}
+	// Closing the lock file releases the lock.
+	if closeErr := fs.lock.Close(); err == nil {
+		err = closeErr
+	}
+	fs.log = nil
+	return err
+}
@@ -0,0 +1,15 @@
+//go:build !unix
+
+package issuer
+
+import "os"
+
+// lockFile opens the file at path, creating it if needed. Without flock, the store directory is not locked.
+func lockFile(path string) (*os.File, error) {
+	return os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600)
+}
+
+// syncDir does nothing, as directories cannot be synced on these platforms.
+func syncDir(string) error {
+	return nil
+}
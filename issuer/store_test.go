@@ -0,0 +1,189 @@
+package issuer
+
+import (
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestStore_FileStoreReopen(t *testing.T) {
+	for _, mode := range []CredentialType{OneShow, MultiShow} {
+		t.Run(mode.String(), func(t *testing.T) {
+			dir := t.TempDir()
+
+			store, err := OpenFileStore(dir)
+			require.NoError(t, err)
+			iss, err := NewIssuerWithStore(mode, store)
+			require.NoError(t, err)
+
+			require.NoError(t, iss.IssueCredentials(50))
+			require.NoError(t, iss.RevokeRandomCredentials(5))
+			require.NoError(t, iss.Close())
+
+			store, err = OpenFileStore(dir)
+			require.NoError(t, err)
+			reopened, err := NewIssuerWithStore(mode, store)
+			require.NoError(t, err)
+			defer reopened.Close()
+
+			require.Equal(t, iss.GetPrivateKey(), reopened.GetPrivateKey())
+			require.Equal(t, iss.GetPublicKey(), reopened.GetPublicKey())
+			require.Equal(t, 50, reopened.AmountIssued())
+			require.Equal(t, 5, reopened.AmountRevoked())
+
+			for id, cred := range iss.issuedCredentials {
+				restored, err := reopened.GetCredentialCopy(id)
+				require.NoError(t, err)
+				require.Equal(t, cred.Revoked, restored.Revoked)
+				require.Equal(t, cred.Revoked, reopened.GetRevocationStatus(id))
+				require.Equal(t, cred.Credential, restored.Credential)
+				require.Equal(t, cred.VrfKeyPair.PrivateKey, restored.VrfKeyPair.PrivateKey)
+
+				// Restored credentials must produce the same revocation tokens.
+				expected, _, err := cred.GenRevocationToken(86400)
+				require.NoError(t, err)
+				actual, _, err := restored.GenRevocationToken(86400)
+				require.NoError(t, err)
+				require.Equal(t, expected, actual)
+			}
+
+			// The reopened issuer keeps extending the same state.
+			require.NoError(t, reopened.IssueCredential(1<<40))
+			require.Equal(t, 51, reopened.AmountIssued())
+		})
+	}
+}
+
+func TestStore_FileStoreSnapshot(t *testing.T) {
+	dir := t.TempDir()
+
+	store, err := OpenFileStore(dir)
+	require.NoError(t, err)
+	store.SnapshotThreshold = 10
+	iss, err := NewIssuerWithStore(MultiShow, store)
+	require.NoError(t, err)
+
+	require.NoError(t, iss.IssueCredentials(25))
+	require.NoError(t, iss.RevokeRandomCredentials(3))
+	require.FileExists(t, filepath.Join(dir, snapshotFileName))
+	require.NoError(t, iss.Close())
+
+	store, err = OpenFileStore(dir)
+	require.NoError(t, err)
+	reopened, err := NewIssuerWithStore(MultiShow, store)
+	require.NoError(t, err)
+	defer reopened.Close()
+
+	require.Equal(t, 25, reopened.AmountIssued())
+	require.Equal(t, 3, reopened.AmountRevoked())
+
+	// An explicit snapshot leaves an empty log behind.
+	require.NoError(t, store.Snapshot())
+	info, err := os.Stat(filepath.Join(dir, logFileName))
+	require.NoError(t, err)
+	require.Zero(t, info.Size())
+}
+
+func TestStore_FileStoreTruncatedRecord(t *testing.T) {
+	dir := t.TempDir()
+
+	store, err := OpenFileStore(dir)
+	require.NoError(t, err)
+	iss, err := NewIssuerWithStore(OneShow, store)
+	require.NoError(t, err)
+	require.NoError(t, iss.IssueCredentials(3))
+	require.NoError(t, iss.Close())
+
+	// Simulate a crash in the middle of writing a record.
+	f, err := os.OpenFile(filepath.Join(dir, logFileName), os.O_APPEND|os.O_WRONLY, 0600)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"op":"revoke","id":`)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	store, err = OpenFileStore(dir)
+	require.NoError(t, err)
+	reopened, err := NewIssuerWithStore(OneShow, store)
+	require.NoError(t, err)
+	require.Equal(t, 3, reopened.AmountIssued())
+	require.Equal(t, 0, reopened.AmountRevoked())
+
+	// New records are appended after the last complete record.
+	require.NoError(t, reopened.RevokeRandomCredentials(1))
+	require.NoError(t, reopened.Close())
+
+	store, err = OpenFileStore(dir)
+	require.NoError(t, err)
+	reopened, err = NewIssuerWithStore(OneShow, store)
+	require.NoError(t, err)
+	defer reopened.Close()
+	require.Equal(t, 1, reopened.AmountRevoked())
+}
+
+func TestStore_MemoryStore(t *testing.T) {
+	store := NewMemoryStore()
+	iss, err := NewIssuerWithStore(OneShow, store)
+	require.NoError(t, err)
+	require.NoError(t, iss.IssueCredentials(10))
+	require.NoError(t, iss.RevokeRandomCredentials(2))
+
+	reopened, err := NewIssuerWithStore(OneShow, store)
+	require.NoError(t, err)
+	require.Equal(t, iss.GetPrivateKey(), reopened.GetPrivateKey())
+	require.Equal(t, 10, reopened.AmountIssued())
+	require.Equal(t, 2, reopened.AmountRevoked())
+
+	_, err = NewIssuerWithStore(MultiShow, store)
+	require.Error(t, err, "reopening with a different credential type must fail")
+}
+
+func TestStore_FileStoreLock(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("store directories are only locked with flock")
+	}
+	dir := t.TempDir()
+
+	store, err := OpenFileStore(dir)
+	require.NoError(t, err)
+	_, err = OpenFileStore(dir)
+	require.Error(t, err, "a store directory must only be opened once")
+
+	require.NoError(t, store.Close())
+	store, err = OpenFileStore(dir)
+	require.NoError(t, err)
+	require.NoError(t, store.Close())
+}
+
+func TestStore_FileStoreRejectedRecord(t *testing.T) {
+	dir := t.TempDir()
+
+	store, err := OpenFileStore(dir)
+	require.NoError(t, err)
+	iss, err := NewIssuerWithStore(OneShow, store)
+	require.NoError(t, err)
+	require.NoError(t, iss.IssueCredential(0))
+	require.NoError(t, iss.IssueCredential(1))
+	cred, err := iss.GetCredentialCopy(0)
+	require.NoError(t, err)
+	info, err := os.Stat(filepath.Join(dir, logFileName))
+	require.NoError(t, err)
+
+	// Records the state rejects are not kept in the log, so the store still opens and later records are replayed.
+	require.Error(t, store.AddCredential(&cred), "id reuse must fail")
+	require.Error(t, store.Revoke(1<<40), "unknown credential")
+	rejected, err := os.Stat(filepath.Join(dir, logFileName))
+	require.NoError(t, err)
+	require.Equal(t, info.Size(), rejected.Size())
+	require.NoError(t, iss.RevokeCredential(1))
+	require.NoError(t, iss.Close())
+
+	store, err = OpenFileStore(dir)
+	require.NoError(t, err)
+	reopened, err := NewIssuerWithStore(OneShow, store)
+	require.NoError(t, err)
+	defer reopened.Close()
+	require.Equal(t, 2, reopened.AmountIssued())
+	require.True(t, reopened.GetRevocationStatus(1))
+}
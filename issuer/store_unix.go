@@ -0,0 +1,40 @@
+//go:build unix
+
+package issuer
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// lockFile opens the file at path, creating it if needed, and takes an exclusive lock on it. The lock is released
+// when the returned file is closed or the process exits.
+func lockFile(path string) (*os.File, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	err = syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+	if err != nil {
+		_ = f.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return nil, errors.New("store is in use by another issuer")
+		}
+		return nil, err
+	}
+	return f, nil
+}
+
+// syncDir commits the entries of the directory, e.g. a created or renamed file, to stable storage.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	err = d.Sync()
+	if closeErr := d.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
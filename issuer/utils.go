@@ -67,6 +67,28 @@ func (v *VrfKeyPair) GetPublicKeyForOnChain() ([]byte, error) {
 	return pubKey.SerializeCompressed(), nil
 }
 
+// PublicKeyBytes returns the compressed VRF public key: 33 bytes secp256k1 for OneShow and 32 bytes BabyJubJub
+// for MultiShow credentials.
+func (v *VrfKeyPair) PublicKeyBytes() ([]byte, error) {
+	switch v.version {
+	case OneShow:
+		return v.GetPublicKeyForOnChain()
+	case MultiShow:
+		pk, err := v.GetMultiShowPublicKey()
+		if err != nil {
+			return nil, err
+		}
+		return pk.Bytes(), nil
+	default:
+		return nil, errors.New("unknown credential type")
+	}
+}
+
+// Type returns the credential type the VRF key pair belongs to.
+func (v *VrfKeyPair) Type() CredentialType {
+	return v.version
+}
+
 func (v *VrfKeyPair) GetEcdsaVersion() (*ecdsa.PrivateKey, error) {
 	if v.version != OneShow {
 		return nil, errors.New("GetEcdsaVersion only supported for OneShow credentials")
@@ -75,29 +97,48 @@ func (v *VrfKeyPair) GetEcdsaVersion() (*ecdsa.PrivateKey, error) {
 }
 
 func NewVrfKeyPair(version CredentialType) (*VrfKeyPair, error) {
-	var privateKey []byte
+	switch version {
+	case OneShow:
+		sk, err := secp256k1.GeneratePrivateKey()
+		if err != nil {
+			return nil, err
+		}
+		return VrfKeyPairFromPrivateKey(version, sk.Serialize())
+
+	case MultiShow:
+		sk, err := zkp.EddsaForCircuitKeyGen()
+		if err != nil {
+			return nil, err
+		}
+		return VrfKeyPairFromPrivateKey(version, sk.Sk)
+
+	default:
+		return nil, errors.New("unknown credential type")
+	}
+}
+
+// VrfKeyPairFromPrivateKey restores the VrfKeyPair of the given credential type from its private key.
+func VrfKeyPairFromPrivateKey(version CredentialType, privateKey []byte) (*VrfKeyPair, error) {
 	var publicKeyHash []byte
 	var xBytes, yBytes []byte
 	var ecdsaKey *ecdsa.PrivateKey
 
 	switch version {
 	case OneShow:
-		sk, err := secp256k1.GeneratePrivateKey()
-		if err != nil {
-			return nil, err
+		if len(privateKey) != secp256k1.PrivKeyBytesLen {
+			return nil, errors.New("invalid secp256k1 private key length")
 		}
+		sk := secp256k1.PrivKeyFromBytes(privateKey)
 		xBytes = sk.PubKey().X().Bytes()
 		yBytes = sk.PubKey().Y().Bytes()
 
-		privateKey = sk.Serialize()
-
 		compressed := sk.PubKey().SerializeCompressed() // 33 bytes: 0x02/0x03 || X
 		publicKeyHash = crypto.Keccak256(compressed)
 
 		ecdsaKey = sk.ToECDSA()
 
 	case MultiShow:
-		sk, err := zkp.EddsaForCircuitKeyGen()
+		sk, err := zkp.EddsaForCircuitKeyFromSecret(privateKey)
 		if err != nil {
 			return nil, err
 		}
@@ -110,7 +151,6 @@ func NewVrfKeyPair(version CredentialType) (*VrfKeyPair, error) {
 
 		xBytes = xBig.Bytes()
 		yBytes = yBig.Bytes()
-		privateKey = sk.Sk
 		publicKeyHash, err = zkp.HashEddsaPublicKey(sk.Pk)
 		if err != nil {
 			return nil, err
@@ -122,7 +162,7 @@ func NewVrfKeyPair(version CredentialType) (*VrfKeyPair, error) {
 	}
 
 	return &VrfKeyPair{
-		PrivateKey:       privateKey,
+		PrivateKey:       append([]byte(nil), privateKey...),
 		publicKey:        VrfPublicKey{xBytes, yBytes},
 		PublicKeyVrfHash: publicKeyHash,
 		version:          version,
@@ -0,0 +1,234 @@
+package verifier
+
+import (
+	onchainVerifier "PrivacyPreservingRevocationCode/verifier/multishow/build"
+	"PrivacyPreservingRevocationCode/zkp"
+	"errors"
+	"fmt"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	groth16bn254 "github.com/consensys/gnark/backend/groth16/bn254"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"math/big"
+)
+
+// Presentation is a MultiShow presentation in the form CheckCredential and the contract take it.
+type Presentation struct {
+	Proof [8]*big.Int // Proof is the Groth16 proof in its on-chain form.
+	Token *big.Int    // Token is the revocation token the proof is for.
+	Epoch int64       // Epoch is the unix epoch the proof is for.
+	Nonce [32]byte    // Nonce is the nonce the proof's challenge was derived from.
+}
+
+// NewPresentation returns the presentation of a proof and its public inputs in their on-chain form, as returned by
+// holder.RevocationTokenProver.GenProof for the verifier's challenge of nonce.
+func NewPresentation(proof [8]*big.Int, publicInputs [5]*big.Int, nonce [32]byte) (Presentation, error) {
+	inputs, err := zkp.DecodePublicInputs(publicInputs)
+	if err != nil {
+		return Presentation{}, err
+	}
+	for _, w := range proof {
+		if w == nil {
+			return Presentation{}, errors.New("proof is incomplete")
+		}
+	}
+	return Presentation{Proof: proof, Token: inputs.RevocationToken, Epoch: inputs.Epoch, Nonce: nonce}, nil
+}
+
+// CheckCredentialsCalldata returns the calldata of the MultiShowVerifier contract's checkCredentials for the
+// presentations, e.g. for an aggregator that settles many presentations in one transaction.
+func CheckCredentialsCalldata(presentations []Presentation) ([]byte, error) {
+	parsed, err := onchainVerifier.VerifierMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	proofs := make([][8]*big.Int, len(presentations))
+	tokens := make([]*big.Int, len(presentations))
+	epochs := make([]*big.Int, len(presentations))
+	nonces := make([][32]byte, len(presentations))
+	for i, p := range presentations {
+		if p.Token == nil || p.Epoch < 0 {
+			return nil, fmt.Errorf("presentation %d: token or epoch out of range", i)
+		}
+		proofs[i] = p.Proof
+		tokens[i] = p.Token
+		epochs[i] = big.NewInt(p.Epoch)
+		nonces[i] = p.Nonce
+	}
+	return parsed.Pack("checkCredentials", proofs, tokens, epochs, nonces)
+}
+
+// CheckCredentials verifies MultiShow presentations like the contract's checkCredentials: the results equal calling
+// CheckCredential for each presentation in order, but the Groth16 proofs are verified in one batch with a single
+// pairing check. Only if the batch does not verify, the proofs are verified one by one to find the invalid ones.
+func (v *MultiShowVerifier) CheckCredentials(presentations []Presentation) ([]bool, []MultiShowCode) {
+	valid := make([]bool, len(presentations))
+	codes := make([]MultiShowCode, len(presentations))
+
+	// Presentations whose proofs need to be verified, and whether their proofs are valid.
+	var pending []int
+	proofValid := make([]bool, len(presentations))
+	var batchProofs [][8]*big.Int
+	var batchInputs [][5]*big.Int
+	for i, p := range presentations {
+		switch {
+		case !v.cascade.accepts(p.Epoch):
+			codes[i] = MultiShowEpochInvalid
+			continue
+		case v.nonces.contains(p.Nonce):
+			codes[i] = MultiShowNonceUsed
+			continue
+		}
+		pending = append(pending, i)
+		publicInputs := &zkp.PublicInputs{
+			IssuerX:         v.issuerX,
+			IssuerY:         v.issuerY,
+			RevocationToken: p.Token,
+			Epoch:           p.Epoch,
+			Challenge:       v.Challenge(p.Nonce),
+		}
+		// Malformed proofs and inputs fail the contract's batch and are rejected individually, so leave them out.
+		_, err := proofFromOnChainInput(p.Proof)
+		if err != nil || !inScalarField(p.Token) || p.Epoch < 0 {
+			continue
+		}
+		proofValid[i] = true
+		batchProofs = append(batchProofs, p.Proof)
+		batchInputs = append(batchInputs, publicInputs.Encode())
+	}
+	if len(batchProofs) > 0 && batchVerify(v.vk.(*groth16bn254.VerifyingKey), batchProofs, batchInputs) != nil {
+		for _, i := range pending {
+			p := presentations[i]
+			proofValid[i] = proofValid[i] && v.verifyProof(p.Proof, p.Token, p.Epoch, p.Nonce) == nil
+		}
+	}
+
+	for _, i := range pending {
+		p := presentations[i]
+		// An earlier presentation of the batch may have consumed the nonce.
+		if v.nonces.contains(p.Nonce) {
+			codes[i] = MultiShowNonceUsed
+			continue
+		}
+		if !proofValid[i] {
+			codes[i] = MultiShowProofInvalid
+			continue
+		}
+		if v.cascade.revoked(p.Token.FillBytes(make([]byte, 32))) {
+			codes[i] = MultiShowRevoked
+			continue
+		}
+		if !v.nonces.consume(p.Nonce) {
+			codes[i] = MultiShowNonceUsed
+			continue
+		}
+		valid[i] = true
+	}
+	return valid, codes
+}
+
+// batchArguments are the parameters of BatchVerifier.verifyProofs, whose ABI encoding seeds the batch weights.
+var batchArguments = abi.Arguments{{Type: mustNewType("uint256[8][]")}, {Type: mustNewType("uint256[5][]")}}
+
+func mustNewType(t string) abi.Type {
+	typ, err := abi.NewType(t, "", nil)
+	if err != nil {
+		panic(err)
+	}
+	return typ
+}
+
+// batchWeights returns the random weights of BatchVerifier.verifyProofs: r_i = keccak256(seed || i) mod R with
+// seed = keccak256(abi.encode(proofs, inputs)). As the weights depend on all proofs, a prover cannot choose proofs
+// whose errors cancel out in the combined pairing equation.
+func batchWeights(proofs [][8]*big.Int, inputs [][5]*big.Int) ([]*big.Int, error) {
+	encoded, err := batchArguments.Pack(proofs, inputs)
+	if err != nil {
+		return nil, err
+	}
+	seed := crypto.Keccak256(encoded)
+	weights := make([]*big.Int, len(proofs))
+	for i := range proofs {
+		r := new(big.Int).SetBytes(crypto.Keccak256(seed, common.LeftPadBytes(big.NewInt(int64(i)).Bytes(), 32)))
+		weights[i] = r.Mod(r, fr.Modulus())
+	}
+	return weights, nil
+}
+
+// batchVerify verifies Groth16 proofs in their on-chain form against their public inputs in the order of
+// zkp.PublicInputs.Encode like BatchVerifier.verifyProofs, i.e. it checks
+// prod_i e(r_i A_i, B_i) * e(sum_i r_i C_i, -δ) * e((sum_i r_i) α, -β) * e(sum_i r_i L_i, -γ) = 1.
+func batchVerify(vk *groth16bn254.VerifyingKey, proofs [][8]*big.Int, inputs [][5]*big.Int) error {
+	if len(proofs) != len(inputs) {
+		return errors.New("number of proofs and inputs differ")
+	}
+	if len(vk.G1.K) != len(inputs[0])+1 || len(vk.PublicAndCommitmentCommitted) != 0 {
+		return errors.New("verifying key does not match the public inputs")
+	}
+	weights, err := batchWeights(proofs, inputs)
+	if err != nil {
+		return err
+	}
+
+	g1 := make([]bn254.G1Affine, 0, len(proofs)+3)
+	g2 := make([]bn254.G2Affine, 0, len(proofs)+3)
+	scalars := make([]fr.Element, len(vk.G1.K)) // scalars[0] = sum_i r_i, scalars[j+1] = sum_i r_i * inputs[i][j]
+	var c bn254.G1Jac                           // sum_i r_i C_i
+	for i, words := range proofs {
+		p, err := proofFromOnChainInput(words)
+		if err != nil {
+			return fmt.Errorf("proof %d: %w", i, err)
+		}
+		proof := p.(*groth16bn254.Proof)
+		var r fr.Element
+		r.SetBigInt(weights[i])
+		scalars[0].Add(&scalars[0], &r)
+		for j, s := range inputs[i] {
+			if !inScalarField(s) {
+				return fmt.Errorf("proof %d: public input out of range", i)
+			}
+			var rs fr.Element
+			rs.SetBigInt(s)
+			rs.Mul(&rs, &r)
+			scalars[j+1].Add(&scalars[j+1], &rs)
+		}
+
+		var a, rc bn254.G1Affine
+		a.ScalarMultiplication(&proof.Ar, weights[i])
+		g1 = append(g1, a)
+		g2 = append(g2, proof.Bs)
+		rc.ScalarMultiplication(&proof.Krs, weights[i])
+		c.AddMixed(&rc)
+	}
+
+	var cAff, alpha, l bn254.G1Affine
+	cAff.FromJacobian(&c)
+	alpha.ScalarMultiplication(&vk.G1.Alpha, scalars[0].BigInt(new(big.Int)))
+	_, err = l.MultiExp(vk.G1.K, scalars, ecc.MultiExpConfig{})
+	if err != nil {
+		return err
+	}
+	var deltaNeg, betaNeg, gammaNeg bn254.G2Affine
+	deltaNeg.Neg(&vk.G2.Delta)
+	betaNeg.Neg(&vk.G2.Beta)
+	gammaNeg.Neg(&vk.G2.Gamma)
+	g1 = append(g1, cAff, alpha, l)
+	g2 = append(g2, deltaNeg, betaNeg, gammaNeg)
+
+	ok, err := bn254.PairingCheck(g1, g2)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("batch of proofs is invalid")
+	}
+	return nil
+}
+
+// inScalarField returns whether v is an element of the BN254 scalar field, which the contract requires of inputs.
+func inScalarField(v *big.Int) bool {
+	return v != nil && v.Sign() >= 0 && v.Cmp(fr.Modulus()) < 0
+}
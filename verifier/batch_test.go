@@ -0,0 +1,308 @@
+package verifier
+
+import (
+	"PrivacyPreservingRevocationCode/holder"
+	"PrivacyPreservingRevocationCode/issuer"
+	onchainVerifier "PrivacyPreservingRevocationCode/verifier/multishow/build"
+	"context"
+	"github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	groth16bn254 "github.com/consensys/gnark/backend/groth16/bn254"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"testing"
+)
+
+// newBatchTestVerifier returns a constructor of verifiers for an issuer with revoked credentials, and presentations
+// of two valid and one revoked credential for the artifact's epoch.
+func newBatchTestVerifier(t *testing.T) (func() *MultiShowVerifier, []Presentation) {
+	prover, err := holder.NewRevocationTokenProver("../zkp/sol/build/verifier.g16.pk", "../zkp/sol/build/verifier.g16.vk")
+	require.NoError(t, err)
+	iss := issuer.NewIssuer(issuer.MultiShow)
+	require.NoError(t, iss.IssueCredentials(20))
+	require.NoError(t, iss.RevokeRandomCredentials(5))
+	artifact, _, _, epochUnix, err := iss.GenRevocationArtifact()
+	require.NoError(t, err)
+	vk := readVerifyingKey(t, "../zkp/sol/build/verifier.g16.vk")
+	newVerifier := func() *MultiShowVerifier {
+		v, err := NewMultiShowVerifier(iss.GetPublicKey(), []byte("verifier"), vk, artifact)
+		require.NoError(t, err)
+		return v
+	}
+	v := newVerifier()
+
+	var presentations []Presentation
+	for _, cred := range append(iss.GetAllValidCreds()[:2], iss.GetAllRevokedCreds()[0]) {
+		nonce, err := NewNonce()
+		require.NoError(t, err)
+		_, proof, _, publicInputs, err := prover.GenProof(*cred, epochUnix, v.Challenge(nonce))
+		require.NoError(t, err)
+		p, err := NewPresentation(proof, publicInputs, nonce)
+		require.NoError(t, err)
+		presentations = append(presentations, p)
+	}
+	return newVerifier, presentations
+}
+
+func TestVerifier_CheckCredentials(t *testing.T) {
+	newVerifier, presentations := newBatchTestVerifier(t)
+	valid0, revoked := presentations[0], presentations[2]
+
+	tampered := presentations[1]
+	tampered.Proof[0] = new(big.Int).Add(tampered.Proof[0], big.NewInt(1))
+	wrongEpoch := presentations[1]
+	wrongEpoch.Epoch += 86400
+	otherNonce := presentations[1]
+	otherNonce.Nonce = revoked.Nonce
+
+	batch := []Presentation{valid0, revoked, tampered, wrongEpoch, otherNonce, valid0, presentations[1]}
+	want := []MultiShowCode{MultiShowValid, MultiShowRevoked, MultiShowProofInvalid, MultiShowEpochInvalid, MultiShowProofInvalid, MultiShowNonceUsed, MultiShowValid}
+
+	// The results equal checking the presentations one by one.
+	sequential := newVerifier()
+	for i, p := range batch {
+		ok, code := sequential.CheckCredential(p.Proof, p.Token, p.Epoch, p.Nonce)
+		require.Equal(t, want[i], code, "presentation %d", i)
+		require.Equal(t, code == MultiShowValid, ok)
+	}
+
+	valid, codes := newVerifier().CheckCredentials(batch)
+	require.Equal(t, want, codes)
+	for i := range batch {
+		require.Equal(t, codes[i] == MultiShowValid, valid[i])
+	}
+
+	// A batch of valid presentations passes the batch verification, and cannot be replayed.
+	v := newVerifier()
+	valid, codes = v.CheckCredentials(presentations)
+	require.Equal(t, []bool{true, true, false}, valid)
+	require.Equal(t, []MultiShowCode{MultiShowValid, MultiShowValid, MultiShowRevoked}, codes)
+	_, codes = v.CheckCredentials(presentations[:2])
+	require.Equal(t, []MultiShowCode{MultiShowNonceUsed, MultiShowNonceUsed}, codes)
+
+	valid, codes = v.CheckCredentials(nil)
+	require.Empty(t, valid)
+	require.Empty(t, codes)
+}
+
+func TestBatchVerify(t *testing.T) {
+	newVerifier, presentations := newBatchTestVerifier(t)
+	v := newVerifier()
+	vk := v.vk.(*groth16bn254.VerifyingKey)
+	var proofs [][8]*big.Int
+	var inputs [][5]*big.Int
+	for _, p := range presentations {
+		proofs = append(proofs, p.Proof)
+		inputs = append(inputs, [5]*big.Int{v.issuerX, v.issuerY, p.Token, big.NewInt(p.Epoch), v.Challenge(p.Nonce)})
+	}
+	require.NoError(t, batchVerify(vk, proofs, inputs))
+	require.NoError(t, batchVerify(vk, proofs[:1], inputs[:1]))
+
+	// Swapping the inputs of two proofs breaks the batch.
+	swapped := append([][5]*big.Int(nil), inputs...)
+	swapped[0], swapped[1] = inputs[1], inputs[0]
+	require.Error(t, batchVerify(vk, proofs, swapped))
+	require.Error(t, batchVerify(vk, proofs, inputs[:2]))
+
+	// The weights depend on all proofs and inputs.
+	weights, err := batchWeights(proofs, inputs)
+	require.NoError(t, err)
+	require.Len(t, weights, len(proofs))
+	swappedWeights, err := batchWeights(proofs, swapped)
+	require.NoError(t, err)
+	require.NotEqual(t, weights[2], swappedWeights[2])
+}
+
+// TestBatchVerify_Precompiles executes the pairing combination of BatchVerifier.verifyProofs step by step on the
+// EVM's BN254 precompiles of a simulated chain, including the per-proof fallback of checkCredentials for a batch
+// with invalid proofs.
+func TestBatchVerify_Precompiles(t *testing.T) {
+	newVerifier, presentations := newBatchTestVerifier(t)
+	v := newVerifier()
+	vk := v.vk.(*groth16bn254.VerifyingKey)
+	evm := newPrecompileCaller(t)
+
+	var proofs [][8]*big.Int
+	var inputs [][5]*big.Int
+	for _, p := range presentations {
+		proofs = append(proofs, p.Proof)
+		inputs = append(inputs, [5]*big.Int{v.issuerX, v.issuerY, p.Token, big.NewInt(p.Epoch), v.Challenge(p.Nonce)})
+	}
+	require.True(t, evm.verifyProofs(vk, proofs, inputs))
+	require.True(t, evm.verifyProofs(vk, proofs[:1], inputs[:1]))
+
+	// A mixed batch with a proof for other inputs and a proof that is not on the curve fails as a whole, and
+	// verifying each proof on its own finds the valid ones like the Go verifier.
+	wrongInputs := inputs[1]
+	wrongInputs[3] = big.NewInt(presentations[1].Epoch + 86400)
+	offCurve := presentations[0].Proof
+	offCurve[0] = new(big.Int).Add(offCurve[0], big.NewInt(1))
+	mixedProofs := [][8]*big.Int{proofs[0], proofs[1], proofs[2], offCurve}
+	mixedInputs := [][5]*big.Int{inputs[0], wrongInputs, inputs[2], inputs[0]}
+	require.False(t, evm.verifyProofs(vk, mixedProofs, mixedInputs))
+	require.Error(t, batchVerify(vk, mixedProofs, mixedInputs))
+	for i := range mixedProofs {
+		valid := evm.verifyProofs(vk, mixedProofs[i:i+1], mixedInputs[i:i+1])
+		require.Equal(t, i == 0 || i == 2, valid, "proof %d", i)
+		require.Equal(t, valid, batchVerify(vk, mixedProofs[i:i+1], mixedInputs[i:i+1]) == nil, "proof %d", i)
+	}
+}
+
+// precompileCaller calls the BN254 precompiles of a simulated chain.
+type precompileCaller struct {
+	t   *testing.T
+	sim *backends.SimulatedBackend
+}
+
+func newPrecompileCaller(t *testing.T) *precompileCaller {
+	sim := backends.NewSimulatedBackend(core.GenesisAlloc{}, 30_000_000)
+	t.Cleanup(func() { _ = sim.Close() })
+	return &precompileCaller{t, sim}
+}
+
+// call calls the precompile at the address with the words as input. It returns false if the precompile fails, e.g.
+// for points that are not on the curve.
+func (c *precompileCaller) call(address byte, words ...*big.Int) ([]*big.Int, bool) {
+	var input []byte
+	for _, w := range words {
+		input = append(input, common.LeftPadBytes(w.Bytes(), 32)...)
+	}
+	to := common.BytesToAddress([]byte{address})
+	output, err := c.sim.CallContract(context.Background(), ethereum.CallMsg{To: &to, Data: input, Gas: 10_000_000}, nil)
+	if err != nil || len(output) == 0 {
+		return nil, false
+	}
+	result := make([]*big.Int, len(output)/32)
+	for i := range result {
+		result[i] = new(big.Int).SetBytes(output[32*i : 32*(i+1)])
+	}
+	return result, true
+}
+
+// g1Mul multiplies a G1 point by a scalar with ecMul (0x07).
+func (c *precompileCaller) g1Mul(x, y, s *big.Int) (*big.Int, *big.Int, bool) {
+	r, ok := c.call(0x07, x, y, s)
+	if !ok {
+		return nil, nil, false
+	}
+	return r[0], r[1], true
+}
+
+// g1Add adds two G1 points with ecAdd (0x06).
+func (c *precompileCaller) g1Add(x1, y1, x2, y2 *big.Int) (*big.Int, *big.Int, bool) {
+	r, ok := c.call(0x06, x1, y1, x2, y2)
+	if !ok {
+		return nil, nil, false
+	}
+	return r[0], r[1], true
+}
+
+// verifyProofs performs the steps of BatchVerifier.verifyProofs with the precompiles and reports whether it would
+// succeed: it multiplies and adds the points with ecMul and ecAdd and checks the n + 3 pairings with ecPairing
+// (0x08).
+func (c *precompileCaller) verifyProofs(vk *groth16bn254.VerifyingKey, proofs [][8]*big.Int, inputs [][5]*big.Int) bool {
+	weights, err := batchWeights(proofs, inputs)
+	require.NoError(c.t, err)
+
+	R := fr.Modulus()
+	weightSum := new(big.Int)
+	inputSums := make([]*big.Int, 5)
+	for j := range inputSums {
+		inputSums[j] = new(big.Int)
+	}
+	cx, cy := new(big.Int), new(big.Int)
+	var pairing []*big.Int
+	for i, r := range weights {
+		weightSum.Add(weightSum, r).Mod(weightSum, R)
+		for j, s := range inputs[i] {
+			if !inScalarField(s) {
+				return false
+			}
+			inputSums[j].Add(inputSums[j], new(big.Int).Mul(r, s)).Mod(inputSums[j], R)
+		}
+		ax, ay, ok := c.g1Mul(proofs[i][0], proofs[i][1], r)
+		if !ok {
+			return false
+		}
+		pairing = append(pairing, ax, ay, proofs[i][2], proofs[i][3], proofs[i][4], proofs[i][5])
+		rcx, rcy, ok := c.g1Mul(proofs[i][6], proofs[i][7], r)
+		if !ok {
+			return false
+		}
+		cx, cy, ok = c.g1Add(cx, cy, rcx, rcy)
+		if !ok {
+			return false
+		}
+	}
+
+	alphaX, alphaY, ok := c.g1Mul(g1Words(vk.G1.Alpha)[0], g1Words(vk.G1.Alpha)[1], weightSum)
+	require.True(c.t, ok)
+	k0 := g1Words(vk.G1.K[0])
+	lx, ly, ok := c.g1Mul(k0[0], k0[1], weightSum)
+	require.True(c.t, ok)
+	for j, s := range inputSums {
+		kj := g1Words(vk.G1.K[j+1])
+		px, py, ok := c.g1Mul(kj[0], kj[1], s)
+		require.True(c.t, ok)
+		lx, ly, ok = c.g1Add(lx, ly, px, py)
+		require.True(c.t, ok)
+	}
+
+	var deltaNeg, betaNeg, gammaNeg bn254.G2Affine
+	deltaNeg.Neg(&vk.G2.Delta)
+	betaNeg.Neg(&vk.G2.Beta)
+	gammaNeg.Neg(&vk.G2.Gamma)
+	pairing = append(pairing, cx, cy)
+	pairing = append(pairing, g2Words(deltaNeg)...)
+	pairing = append(pairing, alphaX, alphaY)
+	pairing = append(pairing, g2Words(betaNeg)...)
+	pairing = append(pairing, lx, ly)
+	pairing = append(pairing, g2Words(gammaNeg)...)
+	require.Len(c.t, pairing, 6*(len(proofs)+3))
+
+	result, ok := c.call(0x08, pairing...)
+	return ok && len(result) == 1 && result[0].Cmp(big.NewInt(1)) == 0
+}
+
+// g1Words returns the coordinates of a G1 point.
+func g1Words(p bn254.G1Affine) [2]*big.Int {
+	return [2]*big.Int{p.X.BigInt(new(big.Int)), p.Y.BigInt(new(big.Int))}
+}
+
+// g2Words returns a G2 point in the EIP-197 encoding of the precompile, i.e. with the imaginary parts first.
+func g2Words(p bn254.G2Affine) []*big.Int {
+	return []*big.Int{
+		p.X.A1.BigInt(new(big.Int)), p.X.A0.BigInt(new(big.Int)),
+		p.Y.A1.BigInt(new(big.Int)), p.Y.A0.BigInt(new(big.Int)),
+	}
+}
+
+func TestCheckCredentialsCalldata(t *testing.T) {
+	_, presentations := newBatchTestVerifier(t)
+	calldata, err := CheckCredentialsCalldata(presentations)
+	require.NoError(t, err)
+
+	parsed, err := onchainVerifier.VerifierMetaData.GetAbi()
+	require.NoError(t, err)
+	method, err := parsed.MethodById(calldata[:4])
+	require.NoError(t, err)
+	require.Equal(t, "checkCredentials", method.Name)
+	args, err := method.Inputs.Unpack(calldata[4:])
+	require.NoError(t, err)
+	proofs, tokens, epochs, nonces := args[0].([][8]*big.Int), args[1].([]*big.Int), args[2].([]*big.Int), args[3].([][32]byte)
+	for i, p := range presentations {
+		require.Equal(t, p.Proof, proofs[i])
+		require.Equal(t, p.Token, tokens[i])
+		require.Equal(t, p.Epoch, epochs[i].Int64())
+		require.Equal(t, p.Nonce, nonces[i])
+	}
+
+	_, err = NewPresentation(presentations[0].Proof, [5]*big.Int{}, presentations[0].Nonce)
+	require.Error(t, err)
+	_, err = CheckCredentialsCalldata([]Presentation{{}})
+	require.Error(t, err)
+}
@@ -0,0 +1,157 @@
+package verifier
+
+import (
+	"PrivacyPreservingRevocationCode/bloom"
+	"PrivacyPreservingRevocationCode/epoch"
+	"PrivacyPreservingRevocationCode/zkp"
+	"errors"
+	"fmt"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards/eddsa"
+	"github.com/consensys/gnark/backend/groth16"
+	groth16bn254 "github.com/consensys/gnark/backend/groth16/bn254"
+	"math/big"
+	"time"
+)
+
+// MultiShowVerifier verifies MultiShow credentials like the MultiShowVerifier contract: it verifies the Groth16
+// proof on the public inputs (issuer public key, revocation token, epoch, challenge) and tests the revocation token
+// against the loaded revocation artifact. The challenge binds each presentation to the verifier and a nonce, which a
+// valid presentation consumes.
+type MultiShowVerifier struct {
+	issuerX    *big.Int             // issuerX is the x-coordinate of the issuer's EdDSA key.
+	issuerY    *big.Int             // issuerY is the y-coordinate of the issuer's EdDSA key.
+	verifierID []byte               // verifierID identifies the verifier in the challenge, see zkp.Challenge.
+	vk         groth16.VerifyingKey // vk is the Groth16 verifying key of the revocation token circuit.
+	cascade    cascadeHolder        // cascade is the revocation artifact tokens are tested against.
+	nonces     nonceSet             // nonces holds the nonces consumed by valid presentations.
+}
+
+// NewMultiShowVerifier returns a verifier for credentials signed by issuerPublicKey (compressed EdDSA BN254 key),
+// proven with the circuit of vk, that checks revocation against cascade. Proofs must be bound to challenges for
+// verifierID; to accept the same presentations as a MultiShowVerifier contract, pass the contract's address.
+func NewMultiShowVerifier(issuerPublicKey, verifierID []byte, vk groth16.VerifyingKey, cascade *bloom.BloomFilterCascade) (*MultiShowVerifier, error) {
+	if len(verifierID) == 0 {
+		return nil, errors.New("verifier id is empty")
+	}
+	if vk == nil {
+		return nil, errors.New("verifying key is nil")
+	}
+	if vk.CurveID() != ecc.BN254 {
+		return nil, fmt.Errorf("verifying key is for curve %s, want %s", vk.CurveID(), ecc.BN254)
+	}
+	var pk eddsa.PublicKey
+	_, err := pk.SetBytes(issuerPublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid issuer public key: %w", err)
+	}
+
+	v := &MultiShowVerifier{
+		issuerX:    pk.A.X.BigInt(new(big.Int)),
+		issuerY:    pk.A.Y.BigInt(new(big.Int)),
+		verifierID: append([]byte(nil), verifierID...),
+		vk:         vk,
+	}
+	err = v.Update(cascade)
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// Update replaces the revocation artifact, e.g. when a new epoch starts.
+func (v *MultiShowVerifier) Update(cascade *bloom.BloomFilterCascade) error {
+	return v.cascade.set(cascade)
+}
+
+// SetEpochWindow makes the verifier reject all presentations once the epoch of the artifact under policy and the
+// grace window are over, like the contract deployed with policy's length and the grace window.
+func (v *MultiShowVerifier) SetEpochWindow(policy epoch.Policy, grace time.Duration) error {
+	return v.cascade.setWindow(policy, grace)
+}
+
+// Cascade returns the revocation artifact the verifier currently checks against.
+func (v *MultiShowVerifier) Cascade() *bloom.BloomFilterCascade {
+	return v.cascade.get()
+}
+
+// Challenge returns the challenge a holder must bind its proof to when presenting to this verifier with nonce.
+func (v *MultiShowVerifier) Challenge(nonce [32]byte) *big.Int {
+	return zkp.Challenge(nonce, v.verifierID)
+}
+
+// CheckCredential verifies a MultiShow presentation for the unix epoch epochUnix and the nonce the verifier handed
+// to the holder. proof is the Groth16 proof in its on-chain form and token the revocation token, both as returned by
+// holder.RevocationTokenProver.GenProof for the challenge Challenge(nonce). Returns whether the credential is valid
+// and not revoked, and the same error code the contract's checkCredential returns. Epochs the artifact does not cover
+// yield MultiShowEpochInvalid and consumed nonces MultiShowNonceUsed before the proof is verified. A valid
+// presentation consumes the nonce.
+func (v *MultiShowVerifier) CheckCredential(proof [8]*big.Int, token *big.Int, epochUnix int64, nonce [32]byte) (bool, MultiShowCode) {
+	if !v.cascade.accepts(epochUnix) {
+		return false, MultiShowEpochInvalid
+	}
+	if v.nonces.contains(nonce) {
+		return false, MultiShowNonceUsed
+	}
+	if v.verifyProof(proof, token, epochUnix, nonce) != nil {
+		return false, MultiShowProofInvalid
+	}
+
+	// Tokens are inserted into the artifact as 32 byte field elements, i.e. bytes32(token) on-chain.
+	if v.cascade.revoked(token.FillBytes(make([]byte, 32))) {
+		return false, MultiShowRevoked
+	}
+	if !v.nonces.consume(nonce) {
+		return false, MultiShowNonceUsed
+	}
+	return true, MultiShowValid
+}
+
+// verifyProof verifies a Groth16 proof in its on-chain form for the token, epoch and the challenge of nonce.
+func (v *MultiShowVerifier) verifyProof(proof [8]*big.Int, token *big.Int, epochUnix int64, nonce [32]byte) error {
+	p, err := proofFromOnChainInput(proof)
+	if err != nil {
+		return err
+	}
+	publicInputs := &zkp.PublicInputs{
+		IssuerX:         v.issuerX,
+		IssuerY:         v.issuerY,
+		RevocationToken: token,
+		Epoch:           epochUnix,
+		Challenge:       v.Challenge(nonce),
+	}
+	// Like the contract's verifier, Witness rejects public inputs outside the scalar field.
+	publicWitness, err := publicInputs.Witness()
+	if err != nil {
+		return err
+	}
+	return groth16.Verify(p, v.vk, publicWitness)
+}
+
+// proofFromOnChainInput is the inverse of the holder's conversion of a Groth16 proof into its on-chain form:
+// the eight words are the uncompressed encodings of Ar, Bs and Krs.
+func proofFromOnChainInput(words [8]*big.Int) (groth16.Proof, error) {
+	const fp = 32
+	raw := make([]byte, 8*fp)
+	for i, w := range words {
+		if w == nil || w.Sign() < 0 || w.BitLen() > 8*fp {
+			return nil, errors.New("proof element out of range")
+		}
+		w.FillBytes(raw[i*fp : (i+1)*fp])
+	}
+
+	proof := &groth16bn254.Proof{}
+	_, err := proof.Ar.SetBytes(raw[0*fp : 2*fp])
+	if err != nil {
+		return nil, err
+	}
+	_, err = proof.Bs.SetBytes(raw[2*fp : 6*fp])
+	if err != nil {
+		return nil, err
+	}
+	_, err = proof.Krs.SetBytes(raw[6*fp : 8*fp])
+	if err != nil {
+		return nil, err
+	}
+	return proof, nil
+}
@@ -0,0 +1,80 @@
+package multishow
+
+import (
+	"PrivacyPreservingRevocationCode/aggregator"
+	"PrivacyPreservingRevocationCode/holder"
+	"bytes"
+	"context"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMultiShowAggregate_EndToEnd(t *testing.T) {
+	_, err := exec.LookPath("solc")
+	if err != nil {
+		t.Skip("solc is not installed")
+	}
+	// The keys of the aggregation circuit of one proof take minutes to set up.
+	dir := os.Getenv("AGGREGATION_KEYS")
+	if dir == "" {
+		t.Skip("set AGGREGATION_KEYS to the keys of zkp.SetupAggregation(1, ...)")
+	}
+	const n = 1
+	const innerPkPath, innerVkPath = "../../zkp/sol/build/verifier.g16.pk", "../../zkp/sol/build/verifier.g16.vk"
+	agg, err := aggregator.NewAggregator(n, innerVkPath, filepath.Join(dir, "aggregation.g16.pk"), filepath.Join(dir, "aggregation.g16.vk"))
+	require.NoError(t, err)
+
+	// Export the aggregate verifier under a name that does not clash with the revocation token verifier.
+	var sol bytes.Buffer
+	require.NoError(t, agg.ExportSolidity(&sol))
+	solPath := filepath.Join(t.TempDir(), "aggregateVerifier.sol")
+	require.NoError(t, os.WriteFile(solPath, []byte(strings.Replace(sol.String(), "contract Verifier", "contract AggregateVerifier", 1)), 0o600))
+
+	contracts := compileWithSolc(t, "multiShowAggregateVerifier.sol", solPath)
+	d := deployMultiShowWith(t, contracts, "MultiShowAggregateVerifier", 100, 10, func(deploy deployFunc) []interface{} {
+		zkpAddr, _ := deploy("BatchVerifier")
+		aggregateAddr, _ := deploy("AggregateVerifier")
+		return []interface{}{zkpAddr, aggregateAddr, big.NewInt(n)}
+	})
+
+	prover, err := holder.NewRevocationTokenProver(innerPkPath, innerVkPath)
+	require.NoError(t, err)
+	nonce, challenge := newNonce(t, d.addr)
+	proof, err := prover.Prove(*d.issuer.GetAllValidCreds()[0], d.epoch, challenge)
+	require.NoError(t, err)
+	aggregate, err := agg.Aggregate([]*holder.TokenProof{proof})
+	require.NoError(t, err)
+	words, commitments, commitmentPok, err := aggregate.Solidity()
+	require.NoError(t, err)
+
+	checkAggregate := func(nonces [][32]byte) ([]bool, []uint8) {
+		var out []interface{}
+		err := d.contract.Call(&bind.CallOpts{}, &out, "checkAggregate", words, commitments, commitmentPok, aggregate.Tokens, big.NewInt(aggregate.Epoch), nonces)
+		require.NoError(t, err)
+		return out[0].([]bool), out[1].([]uint8)
+	}
+	valid, codes := checkAggregate([][32]byte{nonce})
+	require.Equal(t, []uint8{0}, codes)
+	require.Equal(t, []bool{true}, valid)
+
+	// The aggregate proof is bound to the nonces.
+	otherNonce, _ := newNonce(t, d.addr)
+	_, codes = checkAggregate([][32]byte{otherNonce})
+	require.Equal(t, []uint8{1}, codes)
+
+	// An accepted aggregate consumes its nonces.
+	tx, err := d.contract.Transact(d.auth, "checkAggregate", words, commitments, commitmentPok, aggregate.Tokens, big.NewInt(aggregate.Epoch), [][32]byte{nonce})
+	require.NoError(t, err)
+	d.sim.Commit()
+	receipt, err := d.sim.TransactionReceipt(context.Background(), tx.Hash())
+	require.NoError(t, err)
+	t.Logf("checkAggregate of %d presentations: %d gas", n, receipt.GasUsed)
+	_, codes = checkAggregate([][32]byte{nonce})
+	require.Equal(t, []uint8{4}, codes)
+}
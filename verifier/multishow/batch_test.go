@@ -0,0 +1,118 @@
+package multishow
+
+import (
+	"PrivacyPreservingRevocationCode/holder"
+	"PrivacyPreservingRevocationCode/issuer"
+	"PrivacyPreservingRevocationCode/verifier"
+	onchainVerifier "PrivacyPreservingRevocationCode/verifier/multishow/build"
+	"context"
+	"fmt"
+	"github.com/ethereum/go-ethereum"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"testing"
+)
+
+// presentation proves the credential for the deployment's epoch and a fresh nonce.
+func (d *multiShowDeployment) presentation(tb testing.TB, prover *holder.RevocationTokenProver, cred *issuer.InternalCredential) verifier.Presentation {
+	nonce, challenge := newNonce(tb, d.addr)
+	_, proof, _, publicInputs, err := prover.GenProof(*cred, d.epoch, challenge)
+	require.NoError(tb, err)
+	p, err := verifier.NewPresentation(proof, publicInputs, nonce)
+	require.NoError(tb, err)
+	return p
+}
+
+// callBatch calls checkCredentials without consuming the nonces and returns its result.
+func (d *multiShowDeployment) callBatch(tb testing.TB, presentations []verifier.Presentation) ([]bool, []uint8) {
+	calldata, err := verifier.CheckCredentialsCalldata(presentations)
+	require.NoError(tb, err)
+	out, err := d.sim.CallContract(context.Background(), ethereum.CallMsg{From: d.auth.From, To: &d.addr, Data: calldata}, nil)
+	require.NoError(tb, err)
+	var result struct {
+		Valid      []bool
+		ErrorCodes []uint8
+	}
+	parsed, err := onchainVerifier.VerifierMetaData.GetAbi()
+	require.NoError(tb, err)
+	require.NoError(tb, parsed.UnpackIntoInterface(&result, "checkCredentials", out))
+	return result.Valid, result.ErrorCodes
+}
+
+// transactBatch sends checkCredentials and returns the gas it used.
+func (d *multiShowDeployment) transactBatch(tb testing.TB, presentations []verifier.Presentation) uint64 {
+	calldata, err := verifier.CheckCredentialsCalldata(presentations)
+	require.NoError(tb, err)
+	tx, err := d.contract.RawTransact(d.auth, calldata)
+	require.NoError(tb, err)
+	d.sim.Commit()
+	receipt, err := d.sim.TransactionReceipt(context.Background(), tx.Hash())
+	require.NoError(tb, err)
+	return receipt.GasUsed
+}
+
+func TestMultiShow_CheckCredentials(t *testing.T) {
+	contracts := compileWithSolc(t, "multiShowVerifier.sol")
+	d := deployMultiShow(t, contracts, "MultiShowVerifier", "BatchVerifier", 100, 10)
+	prover, err := holder.NewRevocationTokenProver("../../zkp/sol/build/verifier.g16.pk", "../../zkp/sol/build/verifier.g16.vk")
+	require.NoError(t, err)
+
+	valid0 := d.presentation(t, prover, d.issuer.GetAllValidCreds()[0])
+	valid1 := d.presentation(t, prover, d.issuer.GetAllValidCreds()[1])
+	revoked := d.presentation(t, prover, d.issuer.GetAllRevokedCreds()[0])
+
+	// A batch of valid proofs passes the shared pairing check.
+	valid, codes := d.callBatch(t, []verifier.Presentation{valid0, valid1, revoked})
+	require.Equal(t, []bool{true, true, false}, valid)
+	require.Equal(t, []uint8{0, 0, 2}, codes)
+
+	// An invalid proof fails the batch, the others are still accepted.
+	tampered := valid1
+	tampered.Token = revoked.Token
+	wrongEpoch := valid1
+	wrongEpoch.Epoch += 86400
+	valid, codes = d.callBatch(t, []verifier.Presentation{valid0, tampered, wrongEpoch, valid0, valid1})
+	require.Equal(t, []bool{true, false, false, false, true}, valid)
+	require.Equal(t, []uint8{0, 1, 3, 4, 0}, codes)
+
+	// The contract still verifies single proofs.
+	valid2 := d.presentation(t, prover, d.issuer.GetAllValidCreds()[2])
+	ok, code := d.call(t, []interface{}{valid2.Proof, valid2.Token, big.NewInt(valid2.Epoch), valid2.Nonce})
+	require.True(t, ok, "CheckCredential: Expected valid credential, got error code %d", code)
+
+	// A batch consumes the nonces of its valid presentations.
+	t.Logf("checkCredentials: %d gas", d.transactBatch(t, []verifier.Presentation{valid0, valid1}))
+	_, codes = d.callBatch(t, []verifier.Presentation{valid0, valid1, revoked})
+	require.Equal(t, []uint8{4, 4, 2}, codes)
+}
+
+func BenchmarkMultiShow_GasCheckCredentials(b *testing.B) {
+	contracts := compileWithSolc(b, "multiShowVerifier.sol")
+	prover, err := holder.NewRevocationTokenProver("../../zkp/sol/build/verifier.g16.pk", "../../zkp/sol/build/verifier.g16.vk")
+	require.NoError(b, err)
+
+	fmt.Println("Benchmark Gas Consumption of MultiShow CheckCredentials against CheckCredential per Credential:")
+	fmt.Println("| Batch Size | Total Gas | Avg Gas per Credential | CheckCredential Gas | Savings |")
+	fmt.Println("|------------|-----------|------------------------|---------------------|---------|")
+	for _, n := range []int{1, 2, 5, 10, 20} {
+		d := deployMultiShow(b, contracts, "MultiShowVerifier", "BatchVerifier", 1_000, 50)
+		creds := d.issuer.GetAllValidCreds()
+		var batch, single []verifier.Presentation
+		for i := 0; i < n; i++ {
+			batch = append(batch, d.presentation(b, prover, creds[i]))
+			single = append(single, d.presentation(b, prover, creds[n+i]))
+		}
+		valid, _ := d.callBatch(b, batch)
+		require.NotContains(b, valid, false)
+
+		batchGas := d.transactBatch(b, batch)
+		var singleGas uint64
+		for _, p := range single {
+			singleGas += d.transact(b, []interface{}{p.Proof, p.Token, big.NewInt(p.Epoch), p.Nonce})
+		}
+		perCredential := batchGas / uint64(n)
+		avgSingle := singleGas / uint64(n)
+		fmt.Printf("| %10d | %9d | %22d | %19d | %6.1f%% |\n",
+			n, batchGas, perCredential, avgSingle, 100*(1-float64(perCredential)/float64(avgSingle)))
+	}
+}
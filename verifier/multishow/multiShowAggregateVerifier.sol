@@ -0,0 +1,116 @@
+// SPDX-License-Identifier: MIT
+pragma solidity ^0.8.0;
+
+import {MultiShowVerifier} from "./multiShowVerifier.sol";
+
+/// @title MultiShowAggregateVerifier
+/// @notice Variant of MultiShowVerifier that also accepts one aggregate proof for a fixed number of presentations of
+/// the same epoch (zkp.RevocationTokenAggregation), so a relayer can post one proof per block instead of one per
+/// presentation. The aggregate proof is verified by the Solidity verifier exported from the aggregation circuit's keys
+/// (aggregator.ExportSolidity).
+contract MultiShowAggregateVerifier is MultiShowVerifier {
+    address public aggregateVerifier; // Verifier contract of the aggregation circuit
+    uint256 public aggregateSize;     // Number of presentations an aggregate proof covers
+    bytes4 aggregateSelector;         // Selector of the aggregate verifier's verifyProof for aggregateSize proofs
+
+    /// @notice Deploys the verifier like MultiShowVerifier, with the verifier of the aggregation circuit.
+    /// @param _aggregateVerifier Address of the aggregation circuit's verifier contract.
+    /// @param _aggregateSize Number of presentations the aggregation circuit covers.
+    constructor(
+        address _bloom,
+        address _zkpVerifier,
+        address _aggregateVerifier,
+        uint256 _aggregateSize,
+        uint256 _x,
+        uint256 _y,
+        uint256 _epochLength,
+        uint256 _graceWindow
+    ) MultiShowVerifier(_bloom, _zkpVerifier, _x, _y, _epochLength, _graceWindow) {
+        require(_aggregateSize > 0, "Invalid aggregate size");
+        require(_aggregateVerifier.code.length > 0, "Aggregate verifier is not a contract");
+        aggregateVerifier = _aggregateVerifier;
+        aggregateSize = _aggregateSize;
+        // The circuit's range checks add one commitment to the proof.
+        aggregateSelector = bytes4(keccak256(abi.encodePacked(
+            "verifyProof(uint256[8],uint256[2],uint256[2],uint256[", toDecimal(2 * _aggregateSize + 3), "])"
+        )));
+    }
+
+    /// @notice Checks aggregateSize MultiShow credentials of one epoch with one aggregate proof. The results equal
+    /// calling checkCredential for each presentation in order with a proof that is valid if and only if the aggregate
+    /// proof is valid; in particular, a nonce used twice is only consumed by its first presentation.
+    /// @param proof Aggregate zkSNARK proof (AggregateProof.Solidity).
+    /// @param commitments Commitment of the aggregate proof.
+    /// @param commitmentPok Proof of knowledge of the commitment.
+    /// @param tokens Revocation tokens of the aggregated proofs, in their order.
+    /// @param epoch Epoch of all aggregated proofs.
+    /// @param nonces Nonces the aggregated proofs are bound to, in their order.
+    /// @return valid Whether each credential is valid and not revoked.
+    /// @return errorCodes The error code of each credential as returned by checkCredential.
+    function checkAggregate(
+        uint256[8] calldata proof,
+        uint256[2] calldata commitments,
+        uint256[2] calldata commitmentPok,
+        uint256[] calldata tokens,
+        uint256 epoch,
+        bytes32[] calldata nonces
+    )
+    external
+    returns (
+        bool[] memory valid,
+        uint8[] memory errorCodes
+    )
+    {
+        uint256 n = aggregateSize;
+        require(tokens.length == n && nonces.length == n, "Length mismatch");
+        valid = new bool[](n);
+        errorCodes = new uint8[](n);
+        if (!isAcceptedEpoch(epoch)) {
+            for (uint256 i = 0; i < n; i++) {
+                errorCodes[i] = 3;
+            }
+            return (valid, errorCodes);
+        }
+
+        // Public inputs in the order of the aggregation circuit: issuer key, tokens, epoch, challenges.
+        uint256[] memory input = new uint256[](2 * n + 3);
+        input[0] = issuerPubKeyX;
+        input[1] = issuerPubKeyY;
+        input[2 + n] = epoch;
+        for (uint256 i = 0; i < n; i++) {
+            input[2 + i] = tokens[i];
+            input[3 + n + i] = challenge(nonces[i]);
+        }
+        // Static arrays are encoded in place, so the packed words are the ABI encoding of verifyProof's arguments.
+        (bool proofValid, ) = aggregateVerifier.staticcall(
+            abi.encodePacked(aggregateSelector, proof, commitments, commitmentPok, input)
+        );
+
+        for (uint256 i = 0; i < n; i++) {
+            if (consumedNonces[nonces[i]]) {
+                errorCodes[i] = 4;
+                continue;
+            }
+            if (!proofValid) {
+                errorCodes[i] = 1;
+                continue;
+            }
+            (bool revoked, ) = bloom.testToken(abi.encodePacked(bytes32(tokens[i])));
+            if (revoked) {
+                errorCodes[i] = 2;
+                continue;
+            }
+            consumedNonces[nonces[i]] = true;
+            emit NonceConsumed(nonces[i]);
+            valid[i] = true;
+        }
+    }
+
+    /// @notice Returns the decimal representation of v.
+    function toDecimal(uint256 v) internal pure returns (bytes memory s) {
+        do {
+            s = abi.encodePacked(bytes1(uint8(48 + v % 10)), s);
+            v /= 10;
+        } while (v > 0);
+    }
+}
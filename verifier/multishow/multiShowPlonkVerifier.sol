@@ -0,0 +1,151 @@
+// SPDX-License-Identifier: MIT
+pragma solidity ^0.8.0;
+
+import {CascadingBloomFilter} from "bloom/sol/cascadingBloomFilter.sol";
+import {PlonkVerifier} from "zkp/sol/revocationTokenPlonkVerifier.sol";
+
+/// @title MultiShowPlonkVerifier
+/// @notice Variant of MultiShowVerifier that verifies PLONK proofs of the revocation token circuit, whose keys only
+/// depend on a universal SRS instead of a circuit-specific trusted setup.
+contract MultiShowPlonkVerifier {
+    CascadingBloomFilter public bloom;
+    PlonkVerifier public verifier;
+
+    address public issuer;
+    uint256 public issuerPubKeyX;
+    uint256 public issuerPubKeyY;
+
+    uint256 public artifactEpoch; // Epoch (unix start time) of the current revocation artifact
+    uint256 public epochLength;   // Length of an epoch in seconds
+    uint256 public graceWindow;   // Seconds the artifact stays valid after the end of its epoch
+
+    mapping(bytes32 => bool) public consumedNonces; // Nonces of accepted presentations
+
+    // Order of the BN254 scalar field, in which the challenge public input lives
+    uint256 constant R = 21888242871839275222246405745257275088548364400416034343698204186575808495617;
+
+    event NonceConsumed(bytes32 indexed nonce);
+
+    /// @notice Deploys the verifier with a reference to Bloom filter and ZK proof verifier.
+    /// @param _bloom Address of the Bloom filter contract.
+    /// @param _zkpVerifier Address of the PLONK verifier contract.
+    /// @param _x X coordinate of issuer’s eddsa bn254 public key. (used for cred signing)
+    /// @param _y Y coordinate of issuer’s eddsa bn254 public key. (used for cred signing)
+    /// @param _epochLength Length of an epoch in seconds.
+    /// @param _graceWindow Seconds an artifact is accepted after the end of its epoch, until the next update.
+    constructor(
+        address _bloom,
+        address _zkpVerifier,
+        uint256 _x,
+        uint256 _y,
+        uint256 _epochLength,
+        uint256 _graceWindow
+    ) {
+        require(_epochLength > 0, "Invalid epoch length");
+        issuer = msg.sender;
+        bloom = CascadingBloomFilter(_bloom);
+        verifier = PlonkVerifier(_zkpVerifier);
+        issuerPubKeyX = _x;
+        issuerPubKeyY = _y;
+        epochLength = _epochLength;
+        graceWindow = _graceWindow;
+    }
+
+    modifier onlyIssuer() {
+        require(msg.sender == issuer, "Not issuer");
+        _;
+    }
+
+    /// @notice Updates the Bloom filter cascade to the revocation artifact of an epoch.
+    /// @param newFilters Packed Bloom filter layers
+    /// @param ks Number of hash functions per layer
+    /// @param bitLens Number of valid bits per layer
+    /// @param seeds Hash seed per layer
+    /// @param newEpoch Epoch the artifact was built for; must not precede the current artifact's epoch
+    function update(
+        bytes[] calldata newFilters,
+        uint256[] calldata ks,
+        uint256[] calldata bitLens,
+        uint256[] calldata seeds,
+        uint256 newEpoch
+    ) external onlyIssuer {
+        require(newEpoch >= artifactEpoch, "Stale artifact epoch");
+        bloom.updateCascade(newFilters, ks, bitLens, seeds);
+        artifactEpoch = newEpoch;
+    }
+
+    /// @notice Sets the number of seconds an artifact is accepted after the end of its epoch.
+    function setGraceWindow(uint256 _graceWindow) external onlyIssuer {
+        graceWindow = _graceWindow;
+    }
+
+    /// @notice Returns whether presentations for `epoch` are accepted: `epoch` must be the epoch of the current
+    /// artifact, and the artifact must not have expired, i.e. the end of its epoch plus the grace window has not passed.
+    function isAcceptedEpoch(uint256 epoch) public view returns (bool) {
+        return epoch == artifactEpoch && block.timestamp < artifactEpoch + epochLength + graceWindow;
+    }
+
+    /// @notice Returns the challenge a proof presented to this contract must be bound to for `nonce`, i.e. the
+    /// nonce and the contract's address hashed into the BN254 scalar field (see zkp.Challenge).
+    function challenge(bytes32 nonce) public view returns (uint256) {
+        return uint256(keccak256(abi.encodePacked(nonce, address(this)))) % R;
+    }
+
+    /// @notice Verifies a MultiShow credential using a PLONK proof and checks revocation. A successful check
+    /// consumes the nonce, so a presentation cannot be replayed.
+    /// @param proof PLONK proof as encoded by gnark's MarshalSolidity, bound to `challenge(nonce)`.
+    /// @param token Revocation token (as input to Bloom filter and zkSNARK).
+    /// @param epoch Epoch associated with the credential.
+    /// @param nonce Fresh nonce the verifier handed to the holder.
+    /// @return valid True if credential is valid and not revoked.
+    /// @return errorCode Code in [0–4] indicating the verification result
+    ///                  (0: success, 1: PLONK proof invalid, 2: revoked,
+    ///                   3: epoch is not the current artifact's epoch or the artifact expired,
+    ///                   4: nonce already consumed)
+    function checkCredential(
+        bytes calldata proof,
+        uint256 token,
+        uint256 epoch,
+        bytes32 nonce
+    )
+    public
+    returns (
+        bool valid,
+        uint8 errorCode
+    )
+    {
+        if (!isAcceptedEpoch(epoch)) {
+            return (false, 3);
+        }
+        if (consumedNonces[nonce]) {
+            return (false, 4);
+        }
+
+        uint256[] memory input = new uint256[](5);
+        input[0] = issuerPubKeyX;
+        input[1] = issuerPubKeyY;
+        input[2] = token;
+        input[3] = epoch;
+        input[4] = challenge(nonce);
+
+        // The verifier returns false for invalid proofs and reverts on malformed ones.
+        try verifier.Verify(proof, input) returns (bool success) {
+            if (!success) {
+                return (false, 1);
+            }
+        } catch {
+            return (false, 1);
+        }
+
+        // Check Bloom filter
+        (bool revoked, ) = bloom.testToken(abi.encodePacked(bytes32(token)));
+        if (revoked) {
+          return (false, 2);
+        }
+
+        consumedNonces[nonce] = true;
+        emit NonceConsumed(nonce);
+        return (true, 0);
+    }
+
+}
\ No newline at end of file
@@ -2,35 +2,53 @@
 pragma solidity ^0.8.0;
 
 import {CascadingBloomFilter} from "bloom/sol/cascadingBloomFilter.sol";
-import {Verifier} from "zkp/sol/revocationTokenVerifier.sol";
+import {BatchVerifier} from "zkp/sol/revocationTokenBatchVerifier.sol";
 import "../../external/vrf/VRF.sol";
 
 /// @title MultiShowVerifier
 /// @notice Verifies revocation status of MultiShow credentials via zkSNARK proof and Bloom filter.
 contract MultiShowVerifier {
     CascadingBloomFilter public bloom;
-    Verifier public verifier;
+    BatchVerifier public verifier;
 
     address public issuer;
     uint256 public issuerPubKeyX;
     uint256 public issuerPubKeyY;
 
+    uint256 public artifactEpoch; // Epoch (unix start time) of the current revocation artifact
+    uint256 public epochLength;   // Length of an epoch in seconds
+    uint256 public graceWindow;   // Seconds the artifact stays valid after the end of its epoch
+
+    mapping(bytes32 => bool) public consumedNonces; // Nonces of accepted presentations
+
+    // Order of the BN254 scalar field, in which the challenge public input lives
+    uint256 constant R = 21888242871839275222246405745257275088548364400416034343698204186575808495617;
+
+    event NonceConsumed(bytes32 indexed nonce);
+
     /// @notice Deploys the verifier with a reference to Bloom filter and ZK proof verifier.
     /// @param _bloom Address of the Bloom filter contract.
-    /// @param _zkpVerifier Address of the ZKP verifier contract.
+    /// @param _zkpVerifier Address of the ZKP verifier contract; checkCredentials requires a BatchVerifier.
     /// @param _x X coordinate of issuer’s eddsa bn254 public key. (used for cred signing)
     /// @param _y Y coordinate of issuer’s eddsa bn254 public key. (used for cred signing)
+    /// @param _epochLength Length of an epoch in seconds.
+    /// @param _graceWindow Seconds an artifact is accepted after the end of its epoch, until the next update.
     constructor(
         address _bloom,
         address _zkpVerifier,
         uint256 _x,
-        uint256 _y
+        uint256 _y,
+        uint256 _epochLength,
+        uint256 _graceWindow
     ) {
+        require(_epochLength > 0, "Invalid epoch length");
         issuer = msg.sender;
         bloom = CascadingBloomFilter(_bloom);
-        verifier = Verifier(_zkpVerifier);
+        verifier = BatchVerifier(_zkpVerifier);
         issuerPubKeyX = _x;
         issuerPubKeyY = _y;
+        epochLength = _epochLength;
+        graceWindow = _graceWindow;
     }
 
     modifier onlyIssuer() {
@@ -38,42 +56,77 @@ contract MultiShowVerifier {
         _;
     }
 
-    /// @notice Updates the Bloom filter cascade.
+    /// @notice Updates the Bloom filter cascade to the revocation artifact of an epoch.
     /// @param newFilters Packed Bloom filter layers
     /// @param ks Number of hash functions per layer
     /// @param bitLens Number of valid bits per layer
+    /// @param seeds Hash seed per layer
+    /// @param newEpoch Epoch the artifact was built for; must not precede the current artifact's epoch
     function update(
         bytes[] calldata newFilters,
         uint256[] calldata ks,
-        uint256[] calldata bitLens
+        uint256[] calldata bitLens,
+        uint256[] calldata seeds,
+        uint256 newEpoch
     ) external onlyIssuer {
-        bloom.updateCascade(newFilters, ks, bitLens);
+        require(newEpoch >= artifactEpoch, "Stale artifact epoch");
+        bloom.updateCascade(newFilters, ks, bitLens, seeds);
+        artifactEpoch = newEpoch;
     }
 
-    /// @notice Verifies a MultiShow credential using a zkSNARK proof and checks revocation.
-    /// @param proof zkSNARK proof.
+    /// @notice Sets the number of seconds an artifact is accepted after the end of its epoch.
+    function setGraceWindow(uint256 _graceWindow) external onlyIssuer {
+        graceWindow = _graceWindow;
+    }
+
+    /// @notice Returns whether presentations for `epoch` are accepted: `epoch` must be the epoch of the current
+    /// artifact, and the artifact must not have expired, i.e. the end of its epoch plus the grace window has not passed.
+    function isAcceptedEpoch(uint256 epoch) public view returns (bool) {
+        return epoch == artifactEpoch && block.timestamp < artifactEpoch + epochLength + graceWindow;
+    }
+
+    /// @notice Returns the challenge a proof presented to this contract must be bound to for `nonce`, i.e. the
+    /// nonce and the contract's address hashed into the BN254 scalar field (see zkp.Challenge).
+    function challenge(bytes32 nonce) public view returns (uint256) {
+        return uint256(keccak256(abi.encodePacked(nonce, address(this)))) % R;
+    }
+
+    /// @notice Verifies a MultiShow credential using a zkSNARK proof and checks revocation. A successful check
+    /// consumes the nonce, so a presentation cannot be replayed.
+    /// @param proof zkSNARK proof, bound to `challenge(nonce)`.
     /// @param token Revocation token (as input to Bloom filter and zkSNARK).
     /// @param epoch Epoch associated with the credential.
+    /// @param nonce Fresh nonce the verifier handed to the holder.
     /// @return valid True if credential is valid and not revoked.
-    /// @return errorCode Code in [0–2] indicating the verification result
-    ///                  (0: success, 1: zkSNARK proof invalid, 2: revoked)
+    /// @return errorCode Code in [0–4] indicating the verification result
+    ///                  (0: success, 1: zkSNARK proof invalid, 2: revoked,
+    ///                   3: epoch is not the current artifact's epoch or the artifact expired,
+    ///                   4: nonce already consumed)
     function checkCredential(
         uint256[8] calldata proof,
         uint256 token,
-        uint256 epoch
+        uint256 epoch,
+        bytes32 nonce
     )
     public
-    view
     returns (
         bool valid,
         uint8 errorCode
     )
     {
-        uint256[4] memory input = [
+        if (!isAcceptedEpoch(epoch)) {
+            return (false, 3);
+        }
+        if (consumedNonces[nonce]) {
+            return (false, 4);
+        }
+
+        uint256[5] memory input = [
                     issuerPubKeyX,
                     issuerPubKeyY,
                     token,
-                    epoch
+                    epoch,
+                    challenge(nonce)
             ];
 
         try verifier.verifyProof(proof, input) {
@@ -88,16 +141,103 @@ contract MultiShowVerifier {
           return (false, 2);
         }
 
+        consumedNonces[nonce] = true;
+        emit NonceConsumed(nonce);
         return (true, 0);
     }
 
-    /// @notice Gas-measurable variant of `checkCredential`, intended for benchmarking only.
-    function measureCheckCredentialGas(
-        uint256[8] calldata proof,
-        uint256 token,
-        uint256 epoch
-    ) external returns (bool valid, uint8 errorCode) {
-        return checkCredential(proof, token, epoch);
+    /// @notice Checks many MultiShow credentials, e.g. presentations settled by an aggregator, with the same results
+    /// as calling checkCredential for each in order. The zkSNARK proofs are verified in one batch with a shared
+    /// pairing check (see BatchVerifier.verifyProofs); only if the batch is invalid, the proofs are verified one by
+    /// one to find the invalid ones.
+    /// @param proofs zkSNARK proofs, each bound to `challenge(nonces[i])`.
+    /// @param tokens Revocation tokens of the proofs.
+    /// @param epochs Epochs associated with the credentials.
+    /// @param nonces Nonces the verifier handed to the holders.
+    /// @return valid Whether each credential is valid and not revoked.
+    /// @return errorCodes The error code of each credential as returned by checkCredential.
+    function checkCredentials(
+        uint256[8][] calldata proofs,
+        uint256[] calldata tokens,
+        uint256[] calldata epochs,
+        bytes32[] calldata nonces
+    )
+    external
+    returns (
+        bool[] memory valid,
+        uint8[] memory errorCodes
+    )
+    {
+        uint256 n = proofs.length;
+        require(tokens.length == n && epochs.length == n && nonces.length == n, "Length mismatch");
+        valid = new bool[](n);
+        errorCodes = new uint8[](n);
+
+        // Presentations whose proofs need to be verified.
+        uint256[] memory pending = new uint256[](n);
+        uint256 m;
+        for (uint256 i = 0; i < n; i++) {
+            if (!isAcceptedEpoch(epochs[i])) {
+                errorCodes[i] = 3;
+            } else if (consumedNonces[nonces[i]]) {
+                errorCodes[i] = 4;
+            } else {
+                pending[m++] = i;
+            }
+        }
+        if (m == 0) {
+            return (valid, errorCodes);
+        }
+
+        uint256[8][] memory batchProofs = new uint256[8][](m);
+        uint256[5][] memory batchInputs = new uint256[5][](m);
+        for (uint256 k = 0; k < m; k++) {
+            uint256 i = pending[k];
+            batchProofs[k] = proofs[i];
+            batchInputs[k] = [
+                        issuerPubKeyX,
+                        issuerPubKeyY,
+                        tokens[i],
+                        epochs[i],
+                        challenge(nonces[i])
+                ];
+        }
+        bool batchValid = true;
+        try verifier.verifyProofs(batchProofs, batchInputs) {
+            // All proofs are valid
+        } catch {
+            batchValid = false;
+        }
+
+        for (uint256 k = 0; k < m; k++) {
+            uint256 i = pending[k];
+            // An earlier presentation of the batch may have consumed the nonce.
+            if (consumedNonces[nonces[i]]) {
+                errorCodes[i] = 4;
+                continue;
+            }
+            if (!batchValid && !isValidProof(batchProofs[k], batchInputs[k])) {
+                errorCodes[i] = 1;
+                continue;
+            }
+            (bool revoked, ) = bloom.testToken(abi.encodePacked(bytes32(tokens[i])));
+            if (revoked) {
+                errorCodes[i] = 2;
+                continue;
+            }
+            consumedNonces[nonces[i]] = true;
+            emit NonceConsumed(nonces[i]);
+            valid[i] = true;
+        }
+    }
+
+    /// @notice Returns whether a single zkSNARK proof is valid for its public inputs.
+    function isValidProof(uint256[8] memory proof, uint256[5] memory input) internal view returns (bool) {
+        try verifier.verifyProof(proof, input) {
+            return true;
+        } catch {
+            return false;
+        }
     }
 
 }
\ No newline at end of file
@@ -4,17 +4,22 @@ import (
 	onchainBloom "PrivacyPreservingRevocationCode/bloom/sol/build"
 	"PrivacyPreservingRevocationCode/holder"
 	"PrivacyPreservingRevocationCode/issuer"
+	"PrivacyPreservingRevocationCode/verifier"
 	onchainVerifier "PrivacyPreservingRevocationCode/verifier/multishow/build"
+	"PrivacyPreservingRevocationCode/zkp"
 	zkpContract "PrivacyPreservingRevocationCode/zkp/sol/build"
 	"context"
 	"fmt"
 	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards/eddsa"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/stretchr/testify/require"
 	"math/big"
+	"os/exec"
+	"strings"
 	"testing"
 	"time"
 )
@@ -69,7 +74,47 @@ func TestMultiShow_CompileAndGenBindings(t *testing.T) {
 }
 */
 
+// Epoch length and grace window in seconds the verifier contract is deployed with.
+var (
+	epochLength = big.NewInt(86_400)
+	graceWindow = big.NewInt(3_600)
+)
+
+// requireCurrentBytecode makes the deploy functions of the bindings deploy the current sources. If the shipped
+// verifier predates the checkCredential signature with verifier nonces (0x8d406619), multiShowVerifier.sol and the
+// contracts it depends on are compiled with solc instead, and the test skips without solc until the bindings are
+// regenerated with TestMultiShow_CompileAndGenBindings.
+func requireCurrentBytecode(tb testing.TB) {
+	if strings.Contains(onchainVerifier.VerifierBin, "638d406619") {
+		return
+	}
+	if _, err := exec.LookPath("solc"); err != nil {
+		tb.Skip("shipped verifier predates verifier nonces and solc is not installed; regenerate the bindings with TestMultiShow_CompileAndGenBindings")
+	}
+	contracts := compileWithSolc(tb, "multiShowVerifier.sol")
+	onchainVerifier.VerifierBin = common.Bytes2Hex(contracts["MultiShowVerifier"].bin)
+	onchainBloom.BloomBin = common.Bytes2Hex(contracts["CascadingBloomFilter"].bin)
+	zkpContract.ZkpBin = common.Bytes2Hex(contracts["BatchVerifier"].bin)
+}
+
+// checkCredential calls checkCredential without consuming the nonce and returns its result.
+func checkCredential(tb testing.TB, contract *onchainVerifier.Verifier, proof [8]*big.Int, token, epoch *big.Int, nonce [32]byte) (bool, uint8) {
+	var out []interface{}
+	err := (&onchainVerifier.VerifierRaw{Contract: contract}).Call(&bind.CallOpts{}, &out, "checkCredential", proof, token, epoch, nonce)
+	require.NoError(tb, err)
+	return out[0].(bool), out[1].(uint8)
+}
+
+// newNonce returns a fresh nonce and the challenge of the contract at addr for it.
+func newNonce(tb testing.TB, addr common.Address) ([32]byte, *big.Int) {
+	nonce, err := verifier.NewNonce()
+	require.NoError(tb, err)
+	return nonce, zkp.Challenge(nonce, addr.Bytes())
+}
+
 func TestMultiShow_EndToEnd(t *testing.T) {
+	requireCurrentBytecode(t)
+
 	testIssuer := issuer.NewIssuer(issuer.MultiShow)
 	issuerPubKey := eddsa.PublicKey{}
 	_, err := issuerPubKey.SetBytes(testIssuer.GetPublicKey())
@@ -104,7 +149,7 @@ func TestMultiShow_EndToEnd(t *testing.T) {
 	issuerPubKey.A.Y.BigInt(y)
 
 	// Deploy verifier contract
-	verifierAddress, _, verifierContract, err := onchainVerifier.DeployVerifier(auth, sim, bloomAddr, zkpVerifierAddr, x, y)
+	verifierAddress, _, verifierContract, err := onchainVerifier.DeployVerifier(auth, sim, bloomAddr, zkpVerifierAddr, x, y, epochLength, graceWindow)
 	require.NoError(t, err)
 	sim.Commit()
 
@@ -128,8 +173,8 @@ func TestMultiShow_EndToEnd(t *testing.T) {
 	artifact, _, _, epoch, err := testIssuer.GenRevocationArtifact()
 	require.NoError(t, err)
 
-	filter, hf, bitlen := artifact.GetOnChainFilter()
-	_, err = verifierContract.Update(auth, filter, hf, bitlen)
+	filter, hf, bitlen, seeds := artifact.GetOnChainFilter()
+	_, err = verifierContract.Update(auth, filter, hf, bitlen, seeds, big.NewInt(epoch))
 	require.NoError(t, err)
 	sim.Commit()
 
@@ -138,32 +183,78 @@ func TestMultiShow_EndToEnd(t *testing.T) {
 
 	// --- Test valid credentials ---
 	for _, cred := range testIssuer.GetAllValidCreds() {
-		proof, proofBytes, witness, witnessBytes, err := prover.GenProof(*cred, epoch)
+		nonce, challenge := newNonce(t, verifierAddress)
+		proof, proofBytes, witness, witnessBytes, err := prover.GenProof(*cred, epoch, challenge)
 		require.NoError(t, err)
 		require.NotNil(t, proof)
 		require.NotNil(t, witness)
 
-		result, err := verifierContract.CheckCredential(&bind.CallOpts{}, proofBytes, witnessBytes[2], witnessBytes[3])
-		require.NoError(t, err)
-		require.Zero(t, result.ErrorCode, "CheckCredential: Expected valid credential, got error code %d", result.ErrorCode)
-		require.True(t, result.Valid, "CheckCredential: Expected credential to be valid")
+		valid, code := checkCredential(t, verifierContract, proofBytes, witnessBytes[2], witnessBytes[3], nonce)
+		require.Zero(t, code, "CheckCredential: Expected valid credential, got error code %d", code)
+		require.True(t, valid, "CheckCredential: Expected credential to be valid")
 	}
 
 	// --- Test revoked credentials ---
 	for _, cred := range testIssuer.GetAllRevokedCreds() {
-		proof, proofBytes, witness, witnessBytes, err := prover.GenProof(*cred, epoch)
+		nonce, challenge := newNonce(t, verifierAddress)
+		proof, proofBytes, witness, witnessBytes, err := prover.GenProof(*cred, epoch, challenge)
 		require.NoError(t, err)
 		require.NotNil(t, proof)
 		require.NotNil(t, witness)
 
-		result, err := verifierContract.CheckCredential(&bind.CallOpts{}, proofBytes, witnessBytes[2], witnessBytes[3])
-		require.NoError(t, err)
-		require.Equal(t, uint8(2), result.ErrorCode, "CheckCredential: Expected revoked credential (code 2), got %d", result.ErrorCode)
-		require.False(t, result.Valid, "CheckCredential: Expected credential to be revoked")
+		valid, code := checkCredential(t, verifierContract, proofBytes, witnessBytes[2], witnessBytes[3], nonce)
+		require.Equal(t, uint8(2), code, "CheckCredential: Expected revoked credential (code 2), got %d", code)
+		require.False(t, valid, "CheckCredential: Expected credential to be revoked")
 	}
+
+	// --- Test stale epochs ---
+	// A revoked holder proving a token of the previous epoch is rejected, as that token is not in the artifact.
+	revokedCred := testIssuer.GetAllRevokedCreds()[0]
+	nonce, challenge := newNonce(t, verifierAddress)
+	_, proofBytes, _, witnessBytes, err := prover.GenProof(*revokedCred, epoch-86_400, challenge)
+	require.NoError(t, err)
+	valid, code := checkCredential(t, verifierContract, proofBytes, witnessBytes[2], witnessBytes[3], nonce)
+	require.Equal(t, uint8(3), code, "CheckCredential: Expected stale epoch (code 3), got %d", code)
+	require.False(t, valid)
+
+	// --- Test verifier binding and replays ---
+	validCred := testIssuer.GetAllValidCreds()[0]
+	nonce, challenge = newNonce(t, verifierAddress)
+	_, proofBytes, _, witnessBytes, err = prover.GenProof(*validCred, epoch, challenge)
+	require.NoError(t, err)
+
+	// A proof for another nonce or verifier does not verify.
+	otherNonce, _ := newNonce(t, verifierAddress)
+	_, code = checkCredential(t, verifierContract, proofBytes, witnessBytes[2], witnessBytes[3], otherNonce)
+	require.Equal(t, uint8(1), code, "CheckCredential: Expected proof for another nonce to be invalid (code 1), got %d", code)
+	onChainChallenge, err := verifierContract.Challenge(&bind.CallOpts{}, nonce)
+	require.NoError(t, err)
+	require.Equal(t, challenge, onChainChallenge)
+	_, otherChallenge := newNonce(t, bloomAddr)
+	_, otherProof, _, otherInputs, err := prover.GenProof(*validCred, epoch, otherChallenge)
+	require.NoError(t, err)
+	_, code = checkCredential(t, verifierContract, otherProof, otherInputs[2], otherInputs[3], nonce)
+	require.Equal(t, uint8(1), code, "CheckCredential: Expected proof for another verifier to be invalid (code 1), got %d", code)
+
+	// A valid presentation consumes its nonce.
+	tx, err = verifierContract.CheckCredential(auth, proofBytes, witnessBytes[2], witnessBytes[3], nonce)
+	require.NoError(t, err)
+	sim.Commit()
+	receipt, err := sim.TransactionReceipt(context.Background(), tx.Hash())
+	require.NoError(t, err)
+	require.Len(t, receipt.Logs, 1)
+	consumed, err := verifierContract.ConsumedNonces(&bind.CallOpts{}, nonce)
+	require.NoError(t, err)
+	require.True(t, consumed)
+
+	valid, code = checkCredential(t, verifierContract, proofBytes, witnessBytes[2], witnessBytes[3], nonce)
+	require.Equal(t, uint8(4), code, "CheckCredential: Expected consumed nonce (code 4), got %d", code)
+	require.False(t, valid)
 }
 
 func BenchmarkMultiShow_GasCheckCredential(b *testing.B) {
+	requireCurrentBytecode(b)
+
 	configs := []struct {
 		name     string
 		domain   int
@@ -237,7 +328,7 @@ func runMultiShowBenchmark(domain, capacity int) (uint64, time.Duration, error)
 	}
 	sim.Commit()
 
-	verifierAddr, _, verifier, err := onchainVerifier.DeployVerifier(auth, sim, bloomAddr, zkpVerifierAddr, x, y)
+	verifierAddr, _, verifierContract, err := onchainVerifier.DeployVerifier(auth, sim, bloomAddr, zkpVerifierAddr, x, y, epochLength, graceWindow)
 	if err != nil {
 		return 0, 0, err
 	}
@@ -269,8 +360,8 @@ func runMultiShowBenchmark(domain, capacity int) (uint64, time.Duration, error)
 		return 0, 0, err
 	}
 
-	filter, hf, bitlen := artifact.GetOnChainFilter()
-	_, err = verifier.Update(auth, filter, hf, bitlen)
+	filter, hf, bitlen, seeds := artifact.GetOnChainFilter()
+	_, err = verifierContract.Update(auth, filter, hf, bitlen, seeds, big.NewInt(epoch))
 	if err != nil {
 		return 0, 0, err
 	}
@@ -293,33 +384,39 @@ func runMultiShowBenchmark(domain, capacity int) (uint64, time.Duration, error)
 	var totalGas uint64
 	for i := 0; i < n; i++ {
 		cred := validCreds[i]
-		_, proofBytes, _, witnessBytes, err := prover.GenProof(*cred, epoch)
+		nonce, err := verifier.NewNonce()
 		if err != nil {
 			return 0, 0, err
 		}
-
-		tx, err := verifier.MeasureCheckCredentialGas(auth, proofBytes, witnessBytes[2], witnessBytes[3])
+		_, proofBytes, _, witnessBytes, err := prover.GenProof(*cred, epoch, zkp.Challenge(nonce, verifierAddr.Bytes()))
 		if err != nil {
 			return 0, 0, err
 		}
-		sim.Commit()
 
-		receipt, err := sim.TransactionReceipt(context.Background(), tx.Hash())
+		// Call before transacting, as the transaction consumes the nonce.
+		var out []interface{}
+		start := time.Now()
+		err = (&onchainVerifier.VerifierRaw{Contract: verifierContract}).Call(&bind.CallOpts{}, &out, "checkCredential", proofBytes, witnessBytes[2], witnessBytes[3], nonce)
+		elapsed := time.Since(start)
+		// end time here
 		if err != nil {
 			return 0, 0, err
 		}
-		totalGas += receipt.GasUsed
+		if !out[0].(bool) {
+			return 0, 0, fmt.Errorf("expected valid credential, got error code %d", out[1].(uint8))
+		}
 
-		start := time.Now()
-		res, err := verifier.CheckCredential(&bind.CallOpts{}, proofBytes, witnessBytes[2], witnessBytes[3])
-		elapsed := time.Since(start)
-		// end time here
+		tx, err := verifierContract.CheckCredential(auth, proofBytes, witnessBytes[2], witnessBytes[3], nonce)
 		if err != nil {
 			return 0, 0, err
 		}
-		if !res.Valid {
-			return 0, 0, fmt.Errorf("expected valid credential, got revoked credential")
+		sim.Commit()
+
+		receipt, err := sim.TransactionReceipt(context.Background(), tx.Hash())
+		if err != nil {
+			return 0, 0, err
 		}
+		totalGas += receipt.GasUsed
 
 		totalTime += elapsed
 	}
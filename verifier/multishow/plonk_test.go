@@ -0,0 +1,238 @@
+package multishow
+
+import (
+	"PrivacyPreservingRevocationCode/holder"
+	"PrivacyPreservingRevocationCode/issuer"
+	"PrivacyPreservingRevocationCode/zkp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards/eddsa"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// solcContract is a contract compiled by solc.
+type solcContract struct {
+	abi abi.ABI
+	bin []byte
+}
+
+// compileWithSolc compiles the given contracts of this directory with their imports and returns all compiled
+// contracts by name. No build artifacts are shipped for the PLONK variant, so it skips if solc is not installed.
+func compileWithSolc(tb testing.TB, files ...string) map[string]solcContract {
+	solc, err := exec.LookPath("solc")
+	if err != nil {
+		tb.Skip("solc is not installed")
+	}
+	args := append([]string{"--combined-json", "abi,bin", "--evm-version", "istanbul", "--via-ir", "--base-path", "../../"}, files...)
+	out, err := exec.Command(solc, args...).Output()
+	require.NoError(tb, err, "solc failed")
+
+	var combined struct {
+		Contracts map[string]struct {
+			Abi json.RawMessage `json:"abi"`
+			Bin string          `json:"bin"`
+		} `json:"contracts"`
+	}
+	require.NoError(tb, json.Unmarshal(out, &combined))
+
+	contracts := make(map[string]solcContract)
+	for id, c := range combined.Contracts {
+		// Older solc versions encode the ABI as a JSON string.
+		abiJSON := string(c.Abi)
+		var s string
+		if json.Unmarshal(c.Abi, &s) == nil {
+			abiJSON = s
+		}
+		parsed, err := abi.JSON(strings.NewReader(abiJSON))
+		require.NoError(tb, err)
+		contracts[id[strings.LastIndex(id, ":")+1:]] = solcContract{abi: parsed, bin: common.FromHex(c.Bin)}
+	}
+	return contracts
+}
+
+// multiShowDeployment is a MultiShow verifier contract with its revocation artifact on a simulated chain.
+type multiShowDeployment struct {
+	sim      *backends.SimulatedBackend
+	auth     *bind.TransactOpts
+	addr     common.Address
+	contract *bind.BoundContract
+	issuer   *issuer.Issuer
+	epoch    int64
+}
+
+// deployFunc deploys a compiled contract with the constructor parameters and returns its address.
+type deployFunc func(name string, params ...interface{}) (common.Address, *bind.BoundContract)
+
+// deployMultiShow deploys the compiled verifier contract with the compiled zkSNARK verifier zkpVerifier, and updates
+// it to the artifact of an issuer with domain credentials of which capacity are revoked.
+func deployMultiShow(tb testing.TB, contracts map[string]solcContract, verifierName, zkpVerifierName string, domain, capacity int) *multiShowDeployment {
+	return deployMultiShowWith(tb, contracts, verifierName, domain, capacity, func(deploy deployFunc) []interface{} {
+		zkpAddr, _ := deploy(zkpVerifierName)
+		return []interface{}{zkpAddr}
+	})
+}
+
+// deployMultiShowWith is deployMultiShow for verifier contracts whose constructor takes the parameters returned by
+// verifierParams between the Bloom filter's address and the issuer's key.
+func deployMultiShowWith(tb testing.TB, contracts map[string]solcContract, verifierName string, domain, capacity int, verifierParams func(deploy deployFunc) []interface{}) *multiShowDeployment {
+	testIssuer := issuer.NewIssuer(issuer.MultiShow)
+	issuerPubKey := eddsa.PublicKey{}
+	_, err := issuerPubKey.SetBytes(testIssuer.GetPublicKey())
+	require.NoError(tb, err)
+	x, y := big.NewInt(0), big.NewInt(0)
+	issuerPubKey.A.X.BigInt(x)
+	issuerPubKey.A.Y.BigInt(y)
+
+	key, err := crypto.GenerateKey()
+	require.NoError(tb, err)
+	auth, err := bind.NewKeyedTransactorWithChainID(key, big.NewInt(1337))
+	require.NoError(tb, err)
+	sim := backends.NewSimulatedBackend(core.GenesisAlloc{auth.From: {Balance: big.NewInt(1_000_000_000_000_000_000)}}, 30_000_000)
+
+	deploy := func(name string, params ...interface{}) (common.Address, *bind.BoundContract) {
+		c, ok := contracts[name]
+		require.True(tb, ok, "contract %s was not compiled", name)
+		addr, _, bound, err := bind.DeployContract(auth, c.abi, c.bin, sim, params...)
+		require.NoError(tb, err)
+		sim.Commit()
+		return addr, bound
+	}
+	bloomAddr, bloomContract := deploy("CascadingBloomFilter")
+	params := append([]interface{}{bloomAddr}, verifierParams(deploy)...)
+	addr, contract := deploy(verifierName, append(params, x, y, epochLength, graceWindow)...)
+
+	_, err = bloomContract.Transact(auth, "transferOwnership", addr)
+	require.NoError(tb, err)
+	sim.Commit()
+
+	require.NoError(tb, testIssuer.IssueCredentials(uint(domain)))
+	require.NoError(tb, testIssuer.RevokeRandomCredentials(uint(capacity)))
+	artifact, _, _, epoch, err := testIssuer.GenRevocationArtifact()
+	require.NoError(tb, err)
+	filter, hf, bitlen, seeds := artifact.GetOnChainFilter()
+	_, err = contract.Transact(auth, "update", filter, hf, bitlen, seeds, big.NewInt(epoch))
+	require.NoError(tb, err)
+	sim.Commit()
+
+	return &multiShowDeployment{sim: sim, auth: auth, addr: addr, contract: contract, issuer: testIssuer, epoch: epoch}
+}
+
+// checkCredentialArgs returns the arguments of the contract's checkCredential for a proof of the backend.
+func checkCredentialArgs(tb testing.TB, proof *holder.TokenProof, nonce [32]byte) []interface{} {
+	onChain, err := proof.Solidity()
+	require.NoError(tb, err)
+	var encoded interface{} = onChain
+	if proof.Backend == zkp.Groth16 {
+		var words [8]*big.Int
+		for i := range words {
+			words[i] = new(big.Int).SetBytes(onChain[i*32 : (i+1)*32])
+		}
+		encoded = words
+	}
+	return []interface{}{encoded, proof.PublicInputs.RevocationToken, big.NewInt(proof.PublicInputs.Epoch), nonce}
+}
+
+// call calls checkCredential without consuming the nonce and returns its result.
+func (d *multiShowDeployment) call(tb testing.TB, args []interface{}) (bool, uint8) {
+	var out []interface{}
+	require.NoError(tb, d.contract.Call(&bind.CallOpts{}, &out, "checkCredential", args...))
+	return out[0].(bool), out[1].(uint8)
+}
+
+// transact sends checkCredential and returns the gas it used.
+func (d *multiShowDeployment) transact(tb testing.TB, args []interface{}) uint64 {
+	tx, err := d.contract.Transact(d.auth, "checkCredential", args...)
+	require.NoError(tb, err)
+	d.sim.Commit()
+	receipt, err := d.sim.TransactionReceipt(context.Background(), tx.Hash())
+	require.NoError(tb, err)
+	return receipt.GasUsed
+}
+
+func TestMultiShowPlonk_EndToEnd(t *testing.T) {
+	contracts := compileWithSolc(t, "multiShowPlonkVerifier.sol")
+	d := deployMultiShow(t, contracts, "MultiShowPlonkVerifier", "PlonkVerifier", 100, 10)
+
+	prover, err := holder.NewPlonkRevocationTokenProver("../../zkp/sol/build/verifier.plonk.pk", "../../zkp/sol/build/verifier.plonk.vk")
+	require.NoError(t, err)
+
+	validCred := d.issuer.GetAllValidCreds()[0]
+	nonce, challenge := newNonce(t, d.addr)
+	proof, err := prover.Prove(*validCred, d.epoch, challenge)
+	require.NoError(t, err)
+	args := checkCredentialArgs(t, proof, nonce)
+	valid, code := d.call(t, args)
+	require.Zero(t, code, "CheckCredential: Expected valid credential, got error code %d", code)
+	require.True(t, valid)
+
+	// A proof for another nonce does not verify.
+	otherNonce, _ := newNonce(t, d.addr)
+	_, code = d.call(t, checkCredentialArgs(t, proof, otherNonce))
+	require.Equal(t, uint8(1), code, "CheckCredential: Expected proof for another nonce to be invalid (code 1), got %d", code)
+
+	// Revoked credentials are rejected.
+	revokedCred := d.issuer.GetAllRevokedCreds()[0]
+	revokedNonce, revokedChallenge := newNonce(t, d.addr)
+	revokedProof, err := prover.Prove(*revokedCred, d.epoch, revokedChallenge)
+	require.NoError(t, err)
+	_, code = d.call(t, checkCredentialArgs(t, revokedProof, revokedNonce))
+	require.Equal(t, uint8(2), code, "CheckCredential: Expected revoked credential (code 2), got %d", code)
+
+	// A valid presentation consumes its nonce.
+	t.Logf("checkCredential: %d gas", d.transact(t, args))
+	valid, code = d.call(t, args)
+	require.Equal(t, uint8(4), code, "CheckCredential: Expected consumed nonce (code 4), got %d", code)
+	require.False(t, valid)
+}
+
+func BenchmarkMultiShow_GasCheckCredentialByBackend(b *testing.B) {
+	contracts := compileWithSolc(b, "multiShowVerifier.sol", "multiShowPlonkVerifier.sol")
+	groth16Prover, err := holder.NewRevocationTokenProver("../../zkp/sol/build/verifier.g16.pk", "../../zkp/sol/build/verifier.g16.vk")
+	require.NoError(b, err)
+	plonkProver, err := holder.NewPlonkRevocationTokenProver("../../zkp/sol/build/verifier.plonk.pk", "../../zkp/sol/build/verifier.plonk.vk")
+	require.NoError(b, err)
+
+	configs := []struct {
+		prover       holder.TokenProver
+		verifierName string
+		zkpName      string
+	}{
+		{groth16Prover, "MultiShowVerifier", "Verifier"},
+		{plonkProver, "MultiShowPlonkVerifier", "PlonkVerifier"},
+	}
+
+	const n = 20
+	fmt.Printf("Benchmark Gas Consumption of MultiShow CheckCredential by Proving Backend (N = %d credentials):\n", n)
+	fmt.Println("| Backend  | Proof Calldata [bytes] | Avg Gas Used |")
+	fmt.Println("|----------|------------------------|--------------|")
+	for _, backend := range configs {
+		d := deployMultiShow(b, contracts, backend.verifierName, backend.zkpName, 1_000, 50)
+		var totalGas uint64
+		var calldata int
+		for _, cred := range d.issuer.GetAllValidCreds()[:n] {
+			nonce, challenge := newNonce(b, d.addr)
+			proof, err := backend.prover.Prove(*cred, d.epoch, challenge)
+			require.NoError(b, err)
+			onChain, err := proof.Solidity()
+			require.NoError(b, err)
+			calldata = len(onChain)
+
+			args := checkCredentialArgs(b, proof, nonce)
+			valid, code := d.call(b, args)
+			require.True(b, valid, "expected valid credential, got error code %d", code)
+			totalGas += d.transact(b, args)
+		}
+		fmt.Printf("| %-8s | %22d | %12d |\n", backend.prover.Backend(), calldata, totalGas/n)
+	}
+}
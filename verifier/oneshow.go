@@ -0,0 +1,141 @@
+package verifier
+
+import (
+	"PrivacyPreservingRevocationCode/bloom"
+	"PrivacyPreservingRevocationCode/epoch"
+	"PrivacyPreservingRevocationCode/holder"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/leandro-ro/go-ecvrf"
+	"math/big"
+	"time"
+)
+
+// OneShowVerifier verifies OneShow credentials like the OneShowVerifier contract: it checks the issuer's ECDSA
+// signature on the VRF public key and the holder's signature binding the presentation to a nonce, verifies the VRF
+// proof on the epoch and tests the resulting revocation token against the loaded revocation artifact.
+type OneShowVerifier struct {
+	issuer     common.Address // issuer is the address of the issuer's signing key, as recovered by ecrecover.
+	verifierID []byte         // verifierID identifies the verifier in the holder's binding, see holder.OneShowBindingHash.
+	cascade    cascadeHolder  // cascade is the revocation artifact tokens are tested against.
+	nonces     nonceSet       // nonces holds the nonces consumed by valid presentations.
+}
+
+// NewOneShowVerifier returns a verifier for credentials signed by issuerPublicKey (compressed or uncompressed
+// secp256k1) that checks revocation against cascade. Presentations must be bound to verifierID; to accept the same
+// presentations as a OneShowVerifier contract, pass the contract's address.
+func NewOneShowVerifier(issuerPublicKey, verifierID []byte, cascade *bloom.BloomFilterCascade) (*OneShowVerifier, error) {
+	if len(verifierID) == 0 {
+		return nil, errors.New("verifier id is empty")
+	}
+	pk, err := secp256k1.ParsePubKey(issuerPublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid issuer public key: %w", err)
+	}
+	v := &OneShowVerifier{
+		issuer:     crypto.PubkeyToAddress(*pk.ToECDSA()),
+		verifierID: append([]byte(nil), verifierID...),
+	}
+	err = v.Update(cascade)
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// Update replaces the revocation artifact, e.g. when a new epoch starts.
+func (v *OneShowVerifier) Update(cascade *bloom.BloomFilterCascade) error {
+	return v.cascade.set(cascade)
+}
+
+// SetEpochWindow makes the verifier reject all presentations once the epoch of the artifact under policy and the
+// grace window are over, like the contract deployed with policy's length and the grace window.
+func (v *OneShowVerifier) SetEpochWindow(policy epoch.Policy, grace time.Duration) error {
+	return v.cascade.setWindow(policy, grace)
+}
+
+// Cascade returns the revocation artifact the verifier currently checks against.
+func (v *OneShowVerifier) Cascade() *bloom.BloomFilterCascade {
+	return v.cascade.get()
+}
+
+// CheckCredential verifies a OneShow presentation for the unix epoch epochUnix and the nonce the verifier handed
+// to the holder. pubKey is the compressed VRF public key (33 bytes), signature the issuer's signature with v in
+// {27, 28} as returned by holder.OneShowSignature, i.e. followed by the validity bound for bounded credentials, which
+// expire after it (OneShowExpired), vrfProof the 81 byte VRF proof on the epoch and holderSignature the VRF key's
+// signature over holder.OneShowBindingHash, both as returned by holder.OneShowProver.GenProof. Returns whether the
+// credential is valid and not revoked, and the same error code the contract's checkCredential returns. Inputs the
+// contract rejects by reverting (an undecodable public key or proof) yield OneShowVrfFailed. Epochs the artifact does
+// not cover yield OneShowEpochInvalid and consumed nonces OneShowNonceUsed before anything else is checked. A valid
+// presentation consumes the nonce.
+func (v *OneShowVerifier) CheckCredential(pubKey, signature, vrfProof []byte, epochUnix int64, nonce [32]byte, holderSignature []byte) (bool, OneShowCode) {
+	if !v.cascade.accepts(epochUnix) {
+		return false, OneShowEpochInvalid
+	}
+	if v.nonces.contains(nonce) {
+		return false, OneShowNonceUsed
+	}
+	if len(signature) != 65 && len(signature) != 65+8 {
+		return false, OneShowSignatureFormat
+	}
+
+	message := crypto.Keccak256(pubKey)
+	var validUntil uint64
+	if len(signature) > 65 {
+		validUntil = binary.BigEndian.Uint64(signature[65:])
+		message = crypto.Keccak256(message, new(big.Int).SetUint64(validUntil).FillBytes(make([]byte, 32)))
+	}
+	recovered, err := ecrecover(message, signature[:65])
+	if err != nil || recovered != v.issuer {
+		return false, OneShowSignatureInvalid
+	}
+	if validUntil != 0 && uint64(epochUnix) > validUntil {
+		return false, OneShowExpired
+	}
+
+	vrfPk, err := secp256k1.ParsePubKey(pubKey)
+	if err != nil || len(pubKey) != secp256k1.PubKeyBytesLenCompressed {
+		return false, OneShowVrfFailed
+	}
+	if len(holderSignature) != 65 {
+		return false, OneShowHolderInvalid
+	}
+	holderAddr, err := ecrecover(holder.OneShowBindingHash(nonce, v.verifierID, epochUnix), holderSignature)
+	if err != nil || holderAddr != crypto.PubkeyToAddress(*vrfPk.ToECDSA()) {
+		return false, OneShowHolderInvalid
+	}
+
+	token, err := ecvrf.Secp256k1Sha256Tai.Verify(vrfPk.ToECDSA(), epoch.Bytes(epochUnix), vrfProof)
+	if err != nil {
+		return false, OneShowVrfFailed
+	}
+
+	if v.cascade.revoked(token) {
+		return false, OneShowRevoked
+	}
+	if !v.nonces.consume(nonce) {
+		return false, OneShowNonceUsed
+	}
+	return true, OneShowValid
+}
+
+// ecrecover recovers the address that produced an Ethereum style signature (r || s || v, v in {27, 28}) on hash,
+// following the semantics of the EVM's ecrecover precompile.
+func ecrecover(hash, signature []byte) (common.Address, error) {
+	sig := make([]byte, 65)
+	copy(sig, signature)
+	if sig[64] != 27 && sig[64] != 28 {
+		return common.Address{}, errors.New("invalid recovery id")
+	}
+	sig[64] -= 27
+
+	pub, err := crypto.SigToPub(hash, sig)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return crypto.PubkeyToAddress(*pub), nil
+}
@@ -10,9 +10,23 @@ contract OneShowVerifier {
     CascadingBloomFilter public bloom;
     address public issuer;
 
-    constructor(address _bloom) {
+    uint256 public artifactEpoch; // Epoch (unix start time) of the current revocation artifact
+    uint256 public epochLength;   // Length of an epoch in seconds
+    uint256 public graceWindow;   // Seconds the artifact stays valid after the end of its epoch
+
+    mapping(bytes32 => bool) public consumedNonces; // Nonces of accepted presentations
+
+    event NonceConsumed(bytes32 indexed nonce);
+
+    /// @param _bloom Address of the Bloom filter contract.
+    /// @param _epochLength Length of an epoch in seconds.
+    /// @param _graceWindow Seconds an artifact is accepted after the end of its epoch, until the next update.
+    constructor(address _bloom, uint256 _epochLength, uint256 _graceWindow) {
+        require(_epochLength > 0, "Invalid epoch length");
         bloom = CascadingBloomFilter(_bloom);
         issuer = msg.sender;
+        epochLength = _epochLength;
+        graceWindow = _graceWindow;
     }
 
     modifier onlyIssuer() {
@@ -20,124 +34,157 @@ contract OneShowVerifier {
         _;
     }
 
-    /// @notice Updates the Bloom filter cascade.
+    /// @notice Updates the Bloom filter cascade to the revocation artifact of an epoch.
     /// @param newFilters Packed Bloom filter layers
     /// @param ks Number of hash functions per layer
     /// @param bitLens Number of valid bits per layer
+    /// @param seeds Hash seed per layer
+    /// @param newEpoch Epoch the artifact was built for; must not precede the current artifact's epoch
     function update(
         bytes[] calldata newFilters,
         uint256[] calldata ks,
-        uint256[] calldata bitLens
+        uint256[] calldata bitLens,
+        uint256[] calldata seeds,
+        uint256 newEpoch
     ) external onlyIssuer {
-        bloom.updateCascade(newFilters, ks, bitLens);
+        require(newEpoch >= artifactEpoch, "Stale artifact epoch");
+        bloom.updateCascade(newFilters, ks, bitLens, seeds);
+        artifactEpoch = newEpoch;
+    }
+
+    /// @notice Sets the number of seconds an artifact is accepted after the end of its epoch.
+    function setGraceWindow(uint256 _graceWindow) external onlyIssuer {
+        graceWindow = _graceWindow;
+    }
+
+    /// @notice Returns whether presentations for `epoch` are accepted: `epoch` must be the epoch of the current
+    /// artifact, and the artifact must not have expired, i.e. the end of its epoch plus the grace window has not passed.
+    function isAcceptedEpoch(uint256 epoch) public view returns (bool) {
+        return epoch == artifactEpoch && block.timestamp < artifactEpoch + epochLength + graceWindow;
     }
 
-    /// @notice Verifies a credential by checking issuer authenticity, VRF validity, and non-revocation.
-    /// @dev Off-chain calls are gas-free; on-chain usage incurs cost.
+    /// @notice Returns the message a holder signs with its VRF key to bind a presentation to `nonce`, this contract
+    /// and `epoch` (see holder.OneShowBindingHash).
+    function bindingHash(bytes32 nonce, uint256 epoch) public view returns (bytes32) {
+        return keccak256(abi.encodePacked(nonce, address(this), epoch));
+    }
+
+    /// @notice Verifies a credential by checking issuer authenticity, holder binding, VRF validity, and non-revocation.
+    /// A successful check consumes the nonce, so a presentation cannot be replayed.
     /// @param pubKey Compressed VRF public key (33 bytes, SEC1 format)
-    /// @param signature ECDSA signature over keccak256(pubKey), signed by the issuer
+    /// @param signature ECDSA signature over keccak256(pubKey), signed by the issuer, or for a credential with a
+    /// validity bound the signature over keccak256(keccak256(pubKey) || validUntil) followed by the 8 byte big-endian
+    /// bound (73 bytes)
     /// @param proof VRF proof (81 bytes)
     /// @param epoch 64-bit challenge input (big-endian encoded)
+    /// @param nonce Fresh nonce the verifier handed to the holder
+    /// @param holderSignature ECDSA signature over bindingHash(nonce, epoch) by the VRF key
     /// @return valid True if credential is valid and not revoked
-    /// @return errorCode Code in [0–4] indicating the verification result
-    /// (0: success, 1: signature format invalid, 2: signature invalid, 3: VRF verification failed, 4: revoked)
+    /// @return errorCode Code in [0–8] indicating the verification result
+    /// (0: success, 1: signature format invalid, 2: signature invalid, 3: VRF verification failed, 4: revoked,
+    /// 5: epoch is not the current artifact's epoch or the artifact expired, 6: holder signature invalid,
+    /// 7: nonce already consumed, 8: credential expired)
     function checkCredential(
         bytes calldata pubKey,
         bytes calldata signature,
         bytes calldata proof,
-        uint256 epoch
-    ) public view returns (bool valid, uint8 errorCode) {
-        if (signature.length != 65) return (false, 1);
-
-        address recovered = ecrecover(
-            keccak256(pubKey),
-            uint8(signature[64]),
-            bytes32(signature[0:32]),
-            bytes32(signature[32:64])
-        );
-        if (recovered != issuer) return (false, 2);
+        uint256 epoch,
+        bytes32 nonce,
+        bytes calldata holderSignature
+    ) public returns (bool valid, uint8 errorCode) {
+        uint256[2] memory pubkeyXY;
+        (pubkeyXY, errorCode) = checkPresentation(pubKey, signature, epoch, nonce, holderSignature);
+        if (errorCode != 0) return (false, errorCode);
 
-        uint256[2] memory pubkeyXY = VRF.decodePoint(pubKey);
         uint256[4] memory decodedProof = VRF.decodeProof(proof);
+        if (!VRF.verify(pubkeyXY, decodedProof, epochMessage(epoch))) return (false, 3);
 
-        bytes memory message = new bytes(8);
-        for (uint8 i = 0; i < 8; i++) {
-            message[7 - i] = bytes1(uint8(epoch >> (i * 8)));
-        }
-
-        if (!VRF.verify(pubkeyXY, decodedProof, message)) return (false, 3);
-
-        bytes32 token = VRF.gammaToHash(decodedProof[0], decodedProof[1]);
-        (bool revoked, ) = bloom.testToken(abi.encodePacked(token));
-
-        return revoked ? (false, 4) : (true, 0);
-    }
-
-    /// @notice Gas-measurable variant of `checkCredential`, intended for benchmarking only.
-    function measureCheckCredentialGas(
-        bytes calldata pubKey,
-        bytes calldata signature,
-        bytes calldata proof,
-        uint256 epoch
-    ) external returns (bool valid, uint8 errorCode) {
-        return checkCredential(pubKey, signature, proof, epoch);
+        return checkToken(decodedProof, nonce);
     }
 
     /// @notice Efficient on-chain verification using precomputed elliptic curve data.
     /// @dev Saves gas by avoiding repeated EC operations.
     /// @param pubKey Compressed VRF public key (33 bytes)
-    /// @param signature ECDSA signature over keccak256(pubKey)
+    /// @param signature ECDSA signature over keccak256(pubKey), followed by the validity bound, as for checkCredential
     /// @param proof VRF proof: [gammaX, gammaY, c, s]
     /// @param epoch 64-bit challenge input (big-endian)
+    /// @param nonce Fresh nonce the verifier handed to the holder
+    /// @param holderSignature ECDSA signature over bindingHash(nonce, epoch) by the VRF key
     /// @param uPoint Precomputed U = sB - cY
     /// @param vComponents Precomputed [Hx, Hy, cGammaX, cGammaY] for V = sH - cGamma
     /// @return valid True if credential is valid and not revoked
-    /// @return errorCode Code in [0–4] indicating the verification result
-    /// (0: success, 1: signature format invalid, 2: signature invalid, 3: VRF verification failed, 4: revoked)
+    /// @return errorCode Code in [0–8] indicating the verification result, as for checkCredential
     function checkCredentialFast(
         bytes calldata pubKey,
         bytes calldata signature,
         bytes calldata proof,
         uint256 epoch,
+        bytes32 nonce,
+        bytes calldata holderSignature,
         uint256[2] calldata uPoint,
         uint256[4] calldata vComponents
-    ) public view returns (bool valid, uint8 errorCode) {
-        if (signature.length != 65) return (false, 1);
+    ) public returns (bool valid, uint8 errorCode) {
+        uint256[2] memory pubkeyXY;
+        (pubkeyXY, errorCode) = checkPresentation(pubKey, signature, epoch, nonce, holderSignature);
+        if (errorCode != 0) return (false, errorCode);
 
-        address recovered = ecrecover(
-            keccak256(pubKey),
-            uint8(signature[64]),
-            bytes32(signature[0:32]),
-            bytes32(signature[32:64])
-        );
-        if (recovered != issuer) return (false, 2);
-
-        uint256[2] memory pubkeyXY = VRF.decodePoint(pubKey);
         uint256[4] memory decodedProof = VRF.decodeProof(proof);
+        if (!VRF.fastVerify(pubkeyXY, decodedProof, epochMessage(epoch), uPoint, vComponents)) return (false, 3);
 
-        bytes memory message = new bytes(8);
-        for (uint8 i = 0; i < 8; i++) {
-            message[7 - i] = bytes1(uint8(epoch >> (i * 8)));
-        }
+        return checkToken(decodedProof, nonce);
+    }
 
-        if (!VRF.fastVerify(pubkeyXY, decodedProof, message, uPoint, vComponents)) return (false, 3);
+    /// @dev Checks the epoch, the nonce, the issuer's signature on the VRF public key and validity bound, the bound
+    /// and the holder's signature on the binding hash. Returns the decoded VRF public key and 0, or the error code of
+    /// the first failing check.
+    function checkPresentation(
+        bytes calldata pubKey,
+        bytes calldata signature,
+        uint256 epoch,
+        bytes32 nonce,
+        bytes calldata holderSignature
+    ) internal view returns (uint256[2] memory pubkeyXY, uint8 errorCode) {
+        if (!isAcceptedEpoch(epoch)) return (pubkeyXY, 5);
+        if (consumedNonces[nonce]) return (pubkeyXY, 7);
+        if (signature.length != 65 && signature.length != 73) return (pubkeyXY, 1);
+        bytes32 message = keccak256(pubKey);
+        uint64 validUntil;
+        if (signature.length == 73) {
+            validUntil = uint64(bytes8(signature[65:73]));
+            message = keccak256(abi.encodePacked(message, uint256(validUntil)));
+        }
+        if (recoverSigner(message, signature) != issuer) return (pubkeyXY, 2);
+        if (validUntil != 0 && epoch > validUntil) return (pubkeyXY, 8);
+
+        pubkeyXY = VRF.decodePoint(pubKey);
+        if (holderSignature.length != 65) return (pubkeyXY, 6);
+        address holder = address(uint160(uint256(keccak256(abi.encodePacked(pubkeyXY[0], pubkeyXY[1])))));
+        if (recoverSigner(bindingHash(nonce, epoch), holderSignature) != holder) return (pubkeyXY, 6);
+        return (pubkeyXY, 0);
+    }
 
+    /// @dev Tests the VRF output against the revocation artifact and consumes the nonce of a valid presentation.
+    function checkToken(uint256[4] memory decodedProof, bytes32 nonce) internal returns (bool valid, uint8 errorCode) {
         bytes32 token = VRF.gammaToHash(decodedProof[0], decodedProof[1]);
         (bool revoked, ) = bloom.testToken(abi.encodePacked(token));
+        if (revoked) return (false, 4);
 
-        return revoked ? (false, 4) : (true, 0);
+        consumedNonces[nonce] = true;
+        emit NonceConsumed(nonce);
+        return (true, 0);
     }
 
-    /// @notice Gas-measurable variant of `checkCredentialFast`, intended for benchmarking only.
-    function measureCheckCredentialFastGas(
-        bytes calldata pubKey,
-        bytes calldata signature,
-        bytes calldata proof,
-        uint256 epoch,
-        uint256[2] calldata uPoint,
-        uint256[4] calldata vComponents
-    ) external returns (bool valid, uint8 errorCode) {
-        return checkCredentialFast(pubKey, signature, proof, epoch, uPoint, vComponents);
+    /// @dev Recovers the signer of a 65 byte signature (r || s || v) on hash.
+    function recoverSigner(bytes32 hash, bytes calldata sig) internal pure returns (address) {
+        return ecrecover(hash, uint8(sig[64]), bytes32(sig[0:32]), bytes32(sig[32:64]));
+    }
+
+    /// @dev Encodes the epoch as the 8 byte big-endian VRF message.
+    function epochMessage(uint256 epoch) internal pure returns (bytes memory message) {
+        message = new bytes(8);
+        for (uint8 i = 0; i < 8; i++) {
+            message[7 - i] = bytes1(uint8(epoch >> (i * 8)));
+        }
     }
 
     /// @notice Computes auxiliary EC points required for fast on-chain verification.
@@ -155,11 +202,6 @@ contract OneShowVerifier {
         uint256[2] memory pubkeyXY = VRF.decodePoint(pubKey);
         uint256[4] memory decodedProof = VRF.decodeProof(proof);
 
-        bytes memory message = new bytes(8);
-        for (uint8 i = 0; i < 8; i++) {
-            message[7 - i] = bytes1(uint8(epoch >> (i * 8)));
-        }
-
-        return VRF.computeFastVerifyParams(pubkeyXY, decodedProof, message);
+        return VRF.computeFastVerifyParams(pubkeyXY, decodedProof, epochMessage(epoch));
     }
 }
\ No newline at end of file
@@ -2,17 +2,23 @@ package oneshow
 
 import (
 	onchainBloom "PrivacyPreservingRevocationCode/bloom/sol/build"
+	"PrivacyPreservingRevocationCode/holder"
 	"PrivacyPreservingRevocationCode/issuer"
+	"PrivacyPreservingRevocationCode/verifier"
 	onchainVerifier "PrivacyPreservingRevocationCode/verifier/oneshow/build"
 	"context"
+	"encoding/json"
 	"fmt"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/stretchr/testify/require"
 	"math/big"
+	"os/exec"
+	"strings"
 	"testing"
 	"time"
 )
@@ -67,7 +73,75 @@ func TestOneShow_CompileAndGenBindings(t *testing.T) {
 }
 */
 
+// Epoch length and grace window in seconds the verifier contract is deployed with.
+var (
+	epochLength = big.NewInt(86_400)
+	graceWindow = big.NewInt(3_600)
+)
+
+// requireCurrentBytecode makes the deploy functions of the bindings deploy the current sources. If the shipped
+// verifier predates the checkCredential signature with holder binding (0x0c1aa541), oneShowVerifier.sol and the
+// cascade it depends on are compiled with solc instead, and the test skips without solc until the bindings are
+// regenerated with TestOneShow_CompileAndGenBindings.
+func requireCurrentBytecode(tb testing.TB) {
+	if strings.Contains(onchainVerifier.VerifierBin, "630c1aa541") {
+		return
+	}
+	if _, err := exec.LookPath("solc"); err != nil {
+		tb.Skip("shipped verifier predates holder binding and solc is not installed; regenerate the bindings with TestOneShow_CompileAndGenBindings")
+	}
+	bins := compileWithSolc(tb, "oneShowVerifier.sol")
+	onchainVerifier.VerifierBin = bins["OneShowVerifier"]
+	onchainBloom.BloomBin = bins["CascadingBloomFilter"]
+}
+
+// compileWithSolc compiles the Solidity files with the settings of the shipped bindings and returns the hex encoded
+// bytecode of each contract by name.
+func compileWithSolc(tb testing.TB, files ...string) map[string]string {
+	args := append([]string{"--combined-json", "bin", "--evm-version", "istanbul", "--via-ir", "--base-path", "../../"}, files...)
+	out, err := exec.Command("solc", args...).Output()
+	require.NoError(tb, err, "solc failed")
+
+	var combined struct {
+		Contracts map[string]struct {
+			Bin string `json:"bin"`
+		} `json:"contracts"`
+	}
+	require.NoError(tb, json.Unmarshal(out, &combined))
+	bins := make(map[string]string)
+	for id, c := range combined.Contracts {
+		bins[id[strings.LastIndex(id, ":")+1:]] = c.Bin
+	}
+	return bins
+}
+
+// present creates a presentation of cred for the epoch, bound to a fresh nonce of the contract at addr.
+func present(tb testing.TB, cred *issuer.InternalCredential, epoch int64, addr common.Address) *holder.OneShowPresentation {
+	nonce, err := verifier.NewNonce()
+	require.NoError(tb, err)
+	pres, err := holder.NewOneShowProver().GenProof(*cred, epoch, nonce, addr.Bytes())
+	require.NoError(tb, err)
+	return pres
+}
+
+// checkCredential calls checkCredential, or checkCredentialFast with the presentation's parameters, without
+// consuming the nonce and returns its result.
+func checkCredential(tb testing.TB, contract *onchainVerifier.Verifier, pres *holder.OneShowPresentation, fast bool) (bool, uint8) {
+	var out []interface{}
+	var err error
+	raw := &onchainVerifier.VerifierRaw{Contract: contract}
+	if fast {
+		err = raw.Call(&bind.CallOpts{}, &out, "checkCredentialFast", pres.PublicKey, pres.Signature, pres.Proof, big.NewInt(pres.Epoch), pres.Nonce, pres.HolderSignature, pres.UPoint, pres.VComponents)
+	} else {
+		err = raw.Call(&bind.CallOpts{}, &out, "checkCredential", pres.PublicKey, pres.Signature, pres.Proof, big.NewInt(pres.Epoch), pres.Nonce, pres.HolderSignature)
+	}
+	require.NoError(tb, err)
+	return out[0].(bool), out[1].(uint8)
+}
+
 func TestOneShow_EndToEnd(t *testing.T) {
+	requireCurrentBytecode(t)
+
 	iss := issuer.NewIssuer(issuer.OneShow)
 	privKey, err := crypto.ToECDSA(iss.GetPrivateKey())
 	require.NoError(t, err)
@@ -86,7 +160,7 @@ func TestOneShow_EndToEnd(t *testing.T) {
 	sim.Commit()
 
 	// Deploy verifier contract
-	verifierAddress, _, verifierContract, err := onchainVerifier.DeployVerifier(auth, sim, bloomAddr)
+	verifierAddress, _, verifierContract, err := onchainVerifier.DeployVerifier(auth, sim, bloomAddr, epochLength, graceWindow)
 	require.NoError(t, err)
 	sim.Commit()
 
@@ -110,63 +184,121 @@ func TestOneShow_EndToEnd(t *testing.T) {
 	artifact, _, _, epoch, err := iss.GenRevocationArtifact()
 	require.NoError(t, err)
 
-	filter, hf, bitlen := artifact.GetOnChainFilter()
-	_, err = verifierContract.Update(auth, filter, hf, bitlen)
+	filter, hf, bitlen, seeds := artifact.GetOnChainFilter()
+	_, err = verifierContract.Update(auth, filter, hf, bitlen, seeds, big.NewInt(epoch))
 	require.NoError(t, err)
 	sim.Commit()
 
 	// --- Test valid credentials ---
 	for _, cred := range iss.GetAllValidCreds() {
-		_, proof, err := cred.GenRevocationToken(epoch)
-		require.NoError(t, err)
-
 		ok, err := cred.Credential.Verify(iss.GetPublicKey())
 		require.NoError(t, err)
 		require.True(t, ok)
 
-		pubkey, err := cred.VrfKeyPair.GetPublicKeyForOnChain()
-		require.NoError(t, err)
-		result, err := verifierContract.CheckCredential(&bind.CallOpts{}, pubkey, cred.Credential.Signature, proof, big.NewInt(epoch))
-		require.NoError(t, err)
-		require.Zero(t, result.ErrorCode, "CheckCredential: Expected valid credential, got error code %d", result.ErrorCode)
-		require.True(t, result.Valid, "CheckCredential: Expected credential to be valid")
+		valid, code := checkCredential(t, verifierContract, present(t, cred, epoch, verifierAddress), false)
+		require.Zero(t, code, "CheckCredential: Expected valid credential, got error code %d", code)
+		require.True(t, valid, "CheckCredential: Expected credential to be valid")
 	}
 
 	// --- Test revoked credentials ---
 	for _, cred := range iss.GetAllRevokedCreds() {
-		_, proof, err := cred.GenRevocationToken(epoch)
-		require.NoError(t, err)
-
 		ok, err := cred.Credential.Verify(iss.GetPublicKey())
 		require.NoError(t, err)
 		require.True(t, ok)
 
-		pubkey, err := cred.VrfKeyPair.GetPublicKeyForOnChain()
-		require.NoError(t, err)
-
-		result, err := verifierContract.CheckCredential(&bind.CallOpts{}, pubkey, cred.Credential.Signature, proof, big.NewInt(epoch))
-		require.NoError(t, err)
-		require.Equal(t, uint8(4), result.ErrorCode, "CheckCredential: Expected revoked credential (code 3), got %d", result.ErrorCode)
-		require.False(t, result.Valid, "CheckCredential: Expected credential to be revoked")
+		valid, code := checkCredential(t, verifierContract, present(t, cred, epoch, verifierAddress), false)
+		require.Equal(t, uint8(4), code, "CheckCredential: Expected revoked credential (code 4), got %d", code)
+		require.False(t, valid, "CheckCredential: Expected credential to be revoked")
 	}
 
 	otherIss := issuer.NewIssuer(issuer.OneShow)
 	err = otherIss.IssueCredentials(1)
 	require.NoError(t, err)
 	foreignCred := otherIss.GetAllValidCreds()[0]
-	_, proof, err := foreignCred.GenRevocationToken(epoch)
+
+	valid, code := checkCredential(t, verifierContract, present(t, foreignCred, epoch, verifierAddress), false)
+	require.Equal(t, uint8(2), code, "Credential from another issuer should fail signature check")
+	require.False(t, valid)
+}
+
+func TestOneShow_HolderBinding(t *testing.T) {
+	requireCurrentBytecode(t)
+
+	iss := issuer.NewIssuer(issuer.OneShow)
+	privKey, err := crypto.ToECDSA(iss.GetPrivateKey())
+	require.NoError(t, err)
+
+	auth, err := bind.NewKeyedTransactorWithChainID(privKey, big.NewInt(1337))
+	require.NoError(t, err)
+
+	alloc := core.GenesisAlloc{
+		auth.From: {Balance: big.NewInt(1_000_000_000_000_000_000)},
+	}
+	sim := backends.NewSimulatedBackend(alloc, 3_000_000_000)
+
+	bloomAddr, _, bloomContract, err := onchainBloom.DeployBloom(auth, sim)
 	require.NoError(t, err)
+	sim.Commit()
 
-	onchainKey, err := foreignCred.VrfKeyPair.GetPublicKeyForOnChain()
+	verifierAddress, _, verifierContract, err := onchainVerifier.DeployVerifier(auth, sim, bloomAddr, epochLength, graceWindow)
 	require.NoError(t, err)
+	sim.Commit()
 
-	result, err := verifierContract.CheckCredential(&bind.CallOpts{}, onchainKey, foreignCred.Credential.Signature, proof, big.NewInt(epoch))
+	_, err = bloomContract.TransferOwnership(auth, verifierAddress)
 	require.NoError(t, err)
-	require.Equal(t, uint8(2), result.ErrorCode, "Credential from another issuer should fail signature check")
-	require.False(t, result.Valid)
+	sim.Commit()
+
+	require.NoError(t, iss.IssueCredentials(10))
+	artifact, _, _, epoch, err := iss.GenRevocationArtifact()
+	require.NoError(t, err)
+
+	filter, hf, bitlen, seeds := artifact.GetOnChainFilter()
+	_, err = verifierContract.Update(auth, filter, hf, bitlen, seeds, big.NewInt(epoch))
+	require.NoError(t, err)
+	sim.Commit()
+
+	cred := iss.GetAllValidCreds()[0]
+	pres := present(t, cred, epoch, verifierAddress)
+
+	// The contract hashes the same binding message as the holder.
+	onChainHash, err := verifierContract.BindingHash(&bind.CallOpts{}, pres.Nonce, big.NewInt(epoch))
+	require.NoError(t, err)
+	require.Equal(t, holder.OneShowBindingHash(pres.Nonce, verifierAddress.Bytes(), epoch), onChainHash[:])
+
+	// Presentations bound to another nonce or verifier, or without a holder signature, are rejected.
+	otherNonce := *pres
+	otherNonce.Nonce = [32]byte{1}
+	_, code := checkCredential(t, verifierContract, &otherNonce, false)
+	require.Equal(t, uint8(6), code)
+	_, code = checkCredential(t, verifierContract, present(t, cred, epoch, bloomAddr), true)
+	require.Equal(t, uint8(6), code)
+	unsigned := *pres
+	unsigned.HolderSignature = nil
+	_, code = checkCredential(t, verifierContract, &unsigned, false)
+	require.Equal(t, uint8(6), code)
+
+	// A valid presentation consumes its nonce, so replaying it fails on both check functions.
+	tx, err := verifierContract.CheckCredential(auth, pres.PublicKey, pres.Signature, pres.Proof, big.NewInt(epoch), pres.Nonce, pres.HolderSignature)
+	require.NoError(t, err)
+	sim.Commit()
+	receipt, err := sim.TransactionReceipt(context.Background(), tx.Hash())
+	require.NoError(t, err)
+	require.Equal(t, types.ReceiptStatusSuccessful, receipt.Status)
+	require.Len(t, receipt.Logs, 1)
+	consumed, err := verifierContract.ConsumedNonces(&bind.CallOpts{}, pres.Nonce)
+	require.NoError(t, err)
+	require.True(t, consumed)
+
+	for _, fast := range []bool{false, true} {
+		valid, code := checkCredential(t, verifierContract, pres, fast)
+		require.Equal(t, uint8(7), code)
+		require.False(t, valid)
+	}
 }
 
 func TestOneShow_EndToEndFast(t *testing.T) {
+	requireCurrentBytecode(t)
+
 	iss := issuer.NewIssuer(issuer.OneShow)
 	privKey, err := crypto.ToECDSA(iss.GetPrivateKey())
 	require.NoError(t, err)
@@ -185,7 +317,7 @@ func TestOneShow_EndToEndFast(t *testing.T) {
 	sim.Commit()
 
 	// Deploy verifier contract
-	verifierAddress, _, verifierContract, err := onchainVerifier.DeployVerifier(auth, sim, bloomAddr)
+	verifierAddress, _, verifierContract, err := onchainVerifier.DeployVerifier(auth, sim, bloomAddr, epochLength, graceWindow)
 	require.NoError(t, err)
 	sim.Commit()
 
@@ -209,74 +341,188 @@ func TestOneShow_EndToEndFast(t *testing.T) {
 	artifact, _, _, epoch, err := iss.GenRevocationArtifact()
 	require.NoError(t, err)
 
-	filter, hf, bitlen := artifact.GetOnChainFilter()
-	_, err = verifierContract.Update(auth, filter, hf, bitlen)
+	filter, hf, bitlen, seeds := artifact.GetOnChainFilter()
+	_, err = verifierContract.Update(auth, filter, hf, bitlen, seeds, big.NewInt(epoch))
 	require.NoError(t, err)
 	sim.Commit()
 
+	// checkFast checks a presentation with the fast verification parameters computed by the contract.
+	checkFast := func(pres *holder.OneShowPresentation) (bool, uint8) {
+		precomputedParams, err := verifierContract.GetFastVerifyParams(&bind.CallOpts{}, pres.PublicKey, pres.Proof, big.NewInt(epoch))
+		require.NoError(t, err)
+		pres.UPoint, pres.VComponents = precomputedParams.UPoint, precomputedParams.VComponents
+		return checkCredential(t, verifierContract, pres, true)
+	}
+
 	// --- Test valid credentials ---
 	for _, cred := range iss.GetAllValidCreds() {
-		_, proof, err := cred.GenRevocationToken(epoch)
+		ok, err := cred.Credential.Verify(iss.GetPublicKey())
 		require.NoError(t, err)
+		require.True(t, ok)
+
+		valid, code := checkFast(present(t, cred, epoch, verifierAddress))
+		require.Zero(t, code, "CheckCredentialFast: Expected valid credential, got error code %d", code)
+		require.True(t, valid, "CheckCredentialFast: Expected credential to be valid")
+	}
 
+	// --- Test revoked credentials ---
+	for _, cred := range iss.GetAllRevokedCreds() {
 		ok, err := cred.Credential.Verify(iss.GetPublicKey())
 		require.NoError(t, err)
 		require.True(t, ok)
 
-		pubkey, err := cred.VrfKeyPair.GetPublicKeyForOnChain()
-		require.NoError(t, err)
+		valid, code := checkFast(present(t, cred, epoch, verifierAddress))
+		require.Equal(t, uint8(4), code, "CheckCredentialFast: Expected revoked credential (code 4), got %d", code)
+		require.False(t, valid, "CheckCredentialFast: Expected credential to be revoked")
+	}
 
-		precomputedParams, err := verifierContract.GetFastVerifyParams(&bind.CallOpts{}, pubkey, proof, big.NewInt(epoch))
-		require.NoError(t, err)
+	otherIss := issuer.NewIssuer(issuer.OneShow)
+	err = otherIss.IssueCredentials(1)
+	require.NoError(t, err)
+	foreignCred := otherIss.GetAllValidCreds()[0]
+
+	valid, code := checkFast(present(t, foreignCred, epoch, verifierAddress))
+	require.Equal(t, uint8(2), code, "Credential from another issuer should fail signature check")
+	require.False(t, valid)
+}
+
+func TestOneShow_HolderPresentation(t *testing.T) {
+	requireCurrentBytecode(t)
+
+	iss := issuer.NewIssuer(issuer.OneShow)
+	privKey, err := crypto.ToECDSA(iss.GetPrivateKey())
+	require.NoError(t, err)
+
+	auth, err := bind.NewKeyedTransactorWithChainID(privKey, big.NewInt(1337))
+	require.NoError(t, err)
+
+	alloc := core.GenesisAlloc{
+		auth.From: {Balance: big.NewInt(1_000_000_000_000_000_000)},
+	}
+	sim := backends.NewSimulatedBackend(alloc, 3_000_000_000)
+
+	bloomAddr, _, bloomContract, err := onchainBloom.DeployBloom(auth, sim)
+	require.NoError(t, err)
+	sim.Commit()
+
+	verifierAddress, _, verifierContract, err := onchainVerifier.DeployVerifier(auth, sim, bloomAddr, epochLength, graceWindow)
+	require.NoError(t, err)
+	sim.Commit()
+
+	_, err = bloomContract.TransferOwnership(auth, verifierAddress)
+	require.NoError(t, err)
+	sim.Commit()
+
+	require.NoError(t, iss.IssueCredentials(50))
+	require.NoError(t, iss.RevokeRandomCredentials(5))
 
-		resultFast, err := verifierContract.CheckCredentialFast(&bind.CallOpts{}, pubkey, cred.Credential.Signature, proof, big.NewInt(epoch), precomputedParams.UPoint, precomputedParams.VComponents)
+	artifact, _, _, epoch, err := iss.GenRevocationArtifact()
+	require.NoError(t, err)
+
+	filter, hf, bitlen, seeds := artifact.GetOnChainFilter()
+	_, err = verifierContract.Update(auth, filter, hf, bitlen, seeds, big.NewInt(epoch))
+	require.NoError(t, err)
+	sim.Commit()
+
+	prover := holder.NewOneShowProver()
+	check := func(cred *issuer.InternalCredential, expectedCode uint8) {
+		pres := present(t, cred, epoch, verifierAddress)
+		require.NoError(t, prover.VerifyProof(pres))
+
+		// The off-chain fast verification parameters equal the ones computed by the contract.
+		onChainParams, err := verifierContract.GetFastVerifyParams(&bind.CallOpts{}, pres.PublicKey, pres.Proof, big.NewInt(epoch))
 		require.NoError(t, err)
-		require.Zero(t, resultFast.ErrorCode, "CheckCredentialFast: Expected valid credential, got error code %d", resultFast.ErrorCode)
-		require.True(t, resultFast.Valid, "CheckCredentialFast: Expected credential to be valid")
+		require.Equal(t, onChainParams.UPoint, pres.UPoint)
+		require.Equal(t, onChainParams.VComponents, pres.VComponents)
+
+		_, code := checkCredential(t, verifierContract, pres, false)
+		require.Equal(t, expectedCode, code)
+
+		_, code = checkCredential(t, verifierContract, pres, true)
+		require.Equal(t, expectedCode, code)
 	}
 
-	// --- Test revoked credentials ---
+	for _, cred := range iss.GetAllValidCreds()[:5] {
+		check(cred, 0)
+	}
 	for _, cred := range iss.GetAllRevokedCreds() {
-		_, proof, err := cred.GenRevocationToken(epoch)
-		require.NoError(t, err)
+		check(cred, 4)
+	}
+}
 
-		ok, err := cred.Credential.Verify(iss.GetPublicKey())
-		require.NoError(t, err)
-		require.True(t, ok)
+func TestOneShow_StaleEpoch(t *testing.T) {
+	requireCurrentBytecode(t)
 
-		pubkey, err := cred.VrfKeyPair.GetPublicKeyForOnChain()
-		require.NoError(t, err)
+	iss := issuer.NewIssuer(issuer.OneShow)
+	privKey, err := crypto.ToECDSA(iss.GetPrivateKey())
+	require.NoError(t, err)
 
-		precomputedParams, err := verifierContract.GetFastVerifyParams(&bind.CallOpts{}, pubkey, proof, big.NewInt(epoch))
-		require.NoError(t, err)
+	auth, err := bind.NewKeyedTransactorWithChainID(privKey, big.NewInt(1337))
+	require.NoError(t, err)
 
-		resultFast, err := verifierContract.CheckCredentialFast(&bind.CallOpts{}, pubkey, cred.Credential.Signature, proof, big.NewInt(epoch), precomputedParams.UPoint, precomputedParams.VComponents)
-		require.NoError(t, err)
-		require.Equal(t, uint8(4), resultFast.ErrorCode, "CheckCredentialFast: Expected revoked credential (code 3), got %d", resultFast.ErrorCode)
-		require.False(t, resultFast.Valid, "CheckCredentialFast: Expected credential to be valid")
+	alloc := core.GenesisAlloc{
+		auth.From: {Balance: big.NewInt(1_000_000_000_000_000_000)},
 	}
+	sim := backends.NewSimulatedBackend(alloc, 3_000_000_000)
 
-	otherIss := issuer.NewIssuer(issuer.OneShow)
-	err = otherIss.IssueCredentials(1)
+	bloomAddr, _, bloomContract, err := onchainBloom.DeployBloom(auth, sim)
 	require.NoError(t, err)
-	foreignCred := otherIss.GetAllValidCreds()[0]
-	_, proof, err := foreignCred.GenRevocationToken(epoch)
+	sim.Commit()
+
+	verifierAddress, _, verifierContract, err := onchainVerifier.DeployVerifier(auth, sim, bloomAddr, epochLength, graceWindow)
+	require.NoError(t, err)
+	sim.Commit()
+
+	_, err = bloomContract.TransferOwnership(auth, verifierAddress)
 	require.NoError(t, err)
+	sim.Commit()
+
+	require.NoError(t, iss.IssueCredentials(50))
+	require.NoError(t, iss.RevokeRandomCredentials(5))
 
-	onchainKey, err := foreignCred.VrfKeyPair.GetPublicKeyForOnChain()
+	artifact, _, _, epoch, err := iss.GenRevocationArtifact()
 	require.NoError(t, err)
 
-	precomputedParams, err := verifierContract.GetFastVerifyParams(&bind.CallOpts{}, onchainKey, proof, big.NewInt(epoch))
+	filter, hf, bitlen, seeds := artifact.GetOnChainFilter()
+	_, err = verifierContract.Update(auth, filter, hf, bitlen, seeds, big.NewInt(epoch))
 	require.NoError(t, err)
+	sim.Commit()
 
-	result, err := verifierContract.CheckCredentialFast(&bind.CallOpts{}, onchainKey, foreignCred.Credential.Signature, proof, big.NewInt(epoch), precomputedParams.UPoint, precomputedParams.VComponents)
+	stored, err := verifierContract.ArtifactEpoch(&bind.CallOpts{})
 	require.NoError(t, err)
-	require.Equal(t, uint8(2), result.ErrorCode, "Credential from another issuer should fail signature check")
-	require.False(t, result.Valid)
+	require.Equal(t, big.NewInt(epoch), stored)
+
+	check := func(cred *issuer.InternalCredential, epoch int64) uint8 {
+		valid, code := checkCredential(t, verifierContract, present(t, cred, epoch, verifierAddress), false)
+		require.Equal(t, code == 0, valid)
+		return code
+	}
+
+	// A revoked holder replaying a token of the previous epoch is rejected, as is a token of a future epoch.
+	revoked := iss.GetAllRevokedCreds()[0]
+	require.Equal(t, uint8(4), check(revoked, epoch))
+	require.Equal(t, uint8(5), check(revoked, epoch-86_400))
+	valid := iss.GetAllValidCreds()[0]
+	require.Equal(t, uint8(0), check(valid, epoch))
+	require.Equal(t, uint8(5), check(valid, epoch+86_400))
+
+	// The artifact of an older epoch cannot replace the current one.
+	_, err = verifierContract.Update(auth, filter, hf, bitlen, seeds, big.NewInt(epoch-86_400))
+	require.Error(t, err)
+
+	// Once its epoch and the grace window are over, the artifact expires.
+	header, err := sim.HeaderByNumber(context.Background(), nil)
+	require.NoError(t, err)
+	expiry := epoch + epochLength.Int64() + graceWindow.Int64()
+	require.NoError(t, sim.AdjustTime(time.Duration(expiry-int64(header.Time))*time.Second))
+	sim.Commit()
+	require.Equal(t, uint8(5), check(valid, epoch))
 }
 
 // BenchmarkOneShow_PrecomputeFastParams benchmarks the generation of fast verification parameters off-chain.
 func BenchmarkOneShow_PrecomputeFastParams(b *testing.B) {
+	requireCurrentBytecode(b)
+
 	domain := 10_000
 	capacity := 1_000
 
@@ -302,7 +548,7 @@ func BenchmarkOneShow_PrecomputeFastParams(b *testing.B) {
 	}
 	sim.Commit()
 
-	verifierAddr, _, verifier, err := onchainVerifier.DeployVerifier(auth, sim, bloomAddr)
+	verifierAddr, _, verifierContract, err := onchainVerifier.DeployVerifier(auth, sim, bloomAddr, epochLength, graceWindow)
 	if err != nil {
 		b.Fatalf("Failed to deploy verifier: %v", err)
 	}
@@ -330,8 +576,8 @@ func BenchmarkOneShow_PrecomputeFastParams(b *testing.B) {
 		b.Fatalf("GenRevocationArtifact failed: %v", err)
 	}
 
-	filter, ks, lens := artifact.GetOnChainFilter()
-	_, err = verifier.Update(auth, filter, ks, lens)
+	filter, ks, lens, seeds := artifact.GetOnChainFilter()
+	_, err = verifierContract.Update(auth, filter, ks, lens, seeds, big.NewInt(epoch))
 	if err != nil {
 		b.Fatalf("Update bloom failed: %v", err)
 	}
@@ -355,7 +601,7 @@ func BenchmarkOneShow_PrecomputeFastParams(b *testing.B) {
 	// Benchmark loop
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_, err := verifier.GetFastVerifyParams(&bind.CallOpts{}, pubKey, proof, big.NewInt(epoch))
+		_, err := verifierContract.GetFastVerifyParams(&bind.CallOpts{}, pubKey, proof, big.NewInt(epoch))
 		if err != nil {
 			b.Fatalf("GetFastVerifyParams failed: %v", err)
 		}
@@ -363,6 +609,8 @@ func BenchmarkOneShow_PrecomputeFastParams(b *testing.B) {
 }
 
 func BenchmarkOneShow_GasCheckCredential(b *testing.B) {
+	requireCurrentBytecode(b)
+
 	configs := []struct {
 		name     string
 		domain   int
@@ -422,7 +670,7 @@ func runOneShowBenchmark(domain, capacity int, fast bool) (uint64, time.Duration
 	}
 	sim.Commit()
 
-	verifierAddr, _, verifier, err := onchainVerifier.DeployVerifier(auth, sim, bloomAddr)
+	verifierAddr, _, verifierContract, err := onchainVerifier.DeployVerifier(auth, sim, bloomAddr, epochLength, graceWindow)
 	if err != nil {
 		return 0, 0, err
 	}
@@ -452,8 +700,8 @@ func runOneShowBenchmark(domain, capacity int, fast bool) (uint64, time.Duration
 		return 0, 0, err
 	}
 
-	filter, ks, lens := artifact.GetOnChainFilter()
-	_, err = verifier.Update(auth, filter, ks, lens)
+	filter, ks, lens, seeds := artifact.GetOnChainFilter()
+	_, err = verifierContract.Update(auth, filter, ks, lens, seeds, big.NewInt(epoch))
 	if err != nil {
 		return 0, 0, err
 	}
@@ -468,51 +716,42 @@ func runOneShowBenchmark(domain, capacity int, fast bool) (uint64, time.Duration
 	var totalGas uint64
 	var totalTime time.Duration
 
+	prover := holder.NewOneShowProver()
 	for i := 0; i < n; i++ {
-		cred := validCreds[i]
-		_, proof, err := cred.GenRevocationToken(epoch)
+		nonce, err := verifier.NewNonce()
 		if err != nil {
 			return 0, 0, err
 		}
-		pubKey, err := cred.VrfKeyPair.GetPublicKeyForOnChain()
+		pres, err := prover.GenProof(*validCreds[i], epoch, nonce, verifierAddr.Bytes())
 		if err != nil {
 			return 0, 0, err
 		}
 
+		// Local runtime, measured by a call before the transaction consumes the nonce.
+		var out []interface{}
 		var tx *types.Transaction
+		raw := &onchainVerifier.VerifierRaw{Contract: verifierContract}
 		start := time.Now()
+		if fast {
+			err = raw.Call(&bind.CallOpts{}, &out, "checkCredentialFast", pres.PublicKey, pres.Signature, pres.Proof, big.NewInt(epoch), pres.Nonce, pres.HolderSignature, pres.UPoint, pres.VComponents)
+		} else {
+			err = raw.Call(&bind.CallOpts{}, &out, "checkCredential", pres.PublicKey, pres.Signature, pres.Proof, big.NewInt(epoch), pres.Nonce, pres.HolderSignature)
+		}
 		elapsed := time.Since(start)
+		if err != nil {
+			return 0, 0, err
+		}
+		if !out[0].(bool) {
+			return 0, 0, fmt.Errorf("expected valid credential, got error code %d", out[1].(uint8))
+		}
+
 		if fast {
-			precomputedParams, err := verifier.GetFastVerifyParams(&bind.CallOpts{}, pubKey, proof, big.NewInt(epoch))
-			if err != nil {
-				return 0, 0, err
-			}
-
-			tx, err = verifier.MeasureCheckCredentialFastGas(auth, pubKey, cred.Credential.Signature, proof, big.NewInt(epoch), precomputedParams.UPoint, precomputedParams.VComponents)
-			if err != nil {
-				return 0, 0, err
-			}
-
-			// Local runtime
-			start = time.Now()
-			_, err = verifier.CheckCredentialFast(&bind.CallOpts{}, pubKey, cred.Credential.Signature, proof, big.NewInt(epoch), precomputedParams.UPoint, precomputedParams.VComponents)
-			elapsed = time.Since(start)
-			if err != nil {
-				return 0, 0, err
-			}
+			tx, err = verifierContract.CheckCredentialFast(auth, pres.PublicKey, pres.Signature, pres.Proof, big.NewInt(epoch), pres.Nonce, pres.HolderSignature, pres.UPoint, pres.VComponents)
 		} else {
-			tx, err = verifier.MeasureCheckCredentialGas(auth, pubKey, cred.Credential.Signature, proof, big.NewInt(epoch))
-			if err != nil {
-				return 0, 0, err
-			}
-
-			// Local runtime
-			start = time.Now()
-			_, err = verifier.CheckCredential(&bind.CallOpts{}, pubKey, cred.Credential.Signature, proof, big.NewInt(epoch))
-			elapsed = time.Since(start)
-			if err != nil {
-				return 0, 0, err
-			}
+			tx, err = verifierContract.CheckCredential(auth, pres.PublicKey, pres.Signature, pres.Proof, big.NewInt(epoch), pres.Nonce, pres.HolderSignature)
+		}
+		if err != nil {
+			return 0, 0, err
 		}
 
 		totalTime += elapsed
@@ -0,0 +1,189 @@
+// Package verifier checks credential presentations off-chain against a revocation artifact.
+//
+// OneShowVerifier and MultiShowVerifier mirror the OneShowVerifier and MultiShowVerifier contracts: they take the
+// same inputs as the contracts' checkCredential functions and return the same validity flag and error code, so a
+// presentation accepted off-chain is accepted on-chain for the same artifact and vice versa.
+//
+// Like the contracts, both verifiers only accept presentations for the epoch of the loaded artifact. Once
+// SetEpochWindow is called, they also reject all presentations after the artifact's epoch and a grace window are over.
+// Presentations are bound to a nonce handed out by the verifier (NewNonce) and to the verifier's id, which is the
+// contract's address on-chain. A valid presentation consumes its nonce, so it cannot be replayed.
+package verifier
+
+import (
+	"PrivacyPreservingRevocationCode/bloom"
+	"PrivacyPreservingRevocationCode/epoch"
+	"crypto/rand"
+	"errors"
+	"sync"
+	"time"
+)
+
+// OneShowCode is the error code returned by OneShowVerifier.CheckCredential.
+type OneShowCode uint8
+
+// Error codes of OneShow verification, identical to the codes of the OneShowVerifier contract.
+const (
+	OneShowValid            OneShowCode = 0 // OneShowValid signals a valid, non-revoked credential.
+	OneShowSignatureFormat  OneShowCode = 1 // OneShowSignatureFormat signals a malformed issuer signature.
+	OneShowSignatureInvalid OneShowCode = 2 // OneShowSignatureInvalid signals an issuer signature that does not verify.
+	OneShowVrfFailed        OneShowCode = 3 // OneShowVrfFailed signals a VRF public key or proof that does not verify.
+	OneShowRevoked          OneShowCode = 4 // OneShowRevoked signals a revoked credential.
+	OneShowEpochInvalid     OneShowCode = 5 // OneShowEpochInvalid signals an epoch other than the artifact's or an expired artifact.
+	OneShowHolderInvalid    OneShowCode = 6 // OneShowHolderInvalid signals a holder signature that does not bind the VRF key to the nonce.
+	OneShowNonceUsed        OneShowCode = 7 // OneShowNonceUsed signals a nonce that was already consumed by a valid presentation.
+	OneShowExpired          OneShowCode = 8 // OneShowExpired signals a credential whose validity bound lies before the epoch.
+)
+
+// String returns a short description of the code.
+func (c OneShowCode) String() string {
+	switch c {
+	case OneShowValid:
+		return "valid"
+	case OneShowSignatureFormat:
+		return "signature format invalid"
+	case OneShowSignatureInvalid:
+		return "signature invalid"
+	case OneShowVrfFailed:
+		return "VRF verification failed"
+	case OneShowRevoked:
+		return "revoked"
+	case OneShowEpochInvalid:
+		return "epoch not accepted"
+	case OneShowHolderInvalid:
+		return "holder signature invalid"
+	case OneShowNonceUsed:
+		return "nonce already used"
+	case OneShowExpired:
+		return "credential expired"
+	default:
+		return "unknown"
+	}
+}
+
+// MultiShowCode is the error code returned by MultiShowVerifier.CheckCredential.
+type MultiShowCode uint8
+
+// Error codes of MultiShow verification, identical to the codes of the MultiShowVerifier contract.
+const (
+	MultiShowValid        MultiShowCode = 0 // MultiShowValid signals a valid, non-revoked credential.
+	MultiShowProofInvalid MultiShowCode = 1 // MultiShowProofInvalid signals a zero-knowledge proof that does not verify.
+	MultiShowRevoked      MultiShowCode = 2 // MultiShowRevoked signals a revoked credential.
+	MultiShowEpochInvalid MultiShowCode = 3 // MultiShowEpochInvalid signals an epoch other than the artifact's or an expired artifact.
+	MultiShowNonceUsed    MultiShowCode = 4 // MultiShowNonceUsed signals a nonce that was already consumed by a valid presentation.
+)
+
+// String returns a short description of the code.
+func (c MultiShowCode) String() string {
+	switch c {
+	case MultiShowValid:
+		return "valid"
+	case MultiShowProofInvalid:
+		return "zkSNARK proof invalid"
+	case MultiShowRevoked:
+		return "revoked"
+	case MultiShowEpochInvalid:
+		return "epoch not accepted"
+	case MultiShowNonceUsed:
+		return "nonce already used"
+	default:
+		return "unknown"
+	}
+}
+
+// cascadeHolder holds the revocation artifact a verifier checks against and the window in which it is accepted.
+// Both may be replaced while checks run.
+type cascadeHolder struct {
+	mu      sync.RWMutex
+	cascade *bloom.BloomFilterCascade
+	length  time.Duration    // length is the epoch length of the artifact.
+	grace   time.Duration    // grace is how long the artifact is accepted after its epoch is over.
+	now     func() time.Time // now returns the current time; if nil, artifacts do not expire.
+}
+
+// set replaces the artifact.
+func (h *cascadeHolder) set(cascade *bloom.BloomFilterCascade) error {
+	if cascade == nil {
+		return errors.New("revocation artifact is nil")
+	}
+	h.mu.Lock()
+	h.cascade = cascade
+	h.mu.Unlock()
+	return nil
+}
+
+// setWindow makes the artifact expire once its epoch under policy and the grace window are over.
+func (h *cascadeHolder) setWindow(policy epoch.Policy, grace time.Duration) error {
+	if policy.Length <= 0 {
+		return errors.New("epoch length must be positive")
+	}
+	if grace < 0 {
+		return errors.New("grace window must not be negative")
+	}
+	h.mu.Lock()
+	h.length, h.grace, h.now = policy.Length, grace, time.Now
+	h.mu.Unlock()
+	return nil
+}
+
+// accepts reports whether presentations for epochUnix are checked against the current artifact, like the contracts'
+// isAcceptedEpoch: the epoch must be the artifact's and, if a window is set, the window must not be over.
+func (h *cascadeHolder) accepts(epochUnix int64) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if epochUnix != h.cascade.Epoch() {
+		return false
+	}
+	if h.now == nil {
+		return true
+	}
+	return h.now().Before(time.Unix(epochUnix, 0).Add(h.length + h.grace))
+}
+
+// get returns the current artifact.
+func (h *cascadeHolder) get() *bloom.BloomFilterCascade {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.cascade
+}
+
+// revoked reports whether the token is contained in the current artifact.
+func (h *cascadeHolder) revoked(token []byte) bool {
+	revoked, _ := h.get().Test(token)
+	return revoked
+}
+
+// NewNonce returns a random nonce for a verifier to hand to a holder.
+func NewNonce() ([32]byte, error) {
+	var nonce [32]byte
+	_, err := rand.Read(nonce[:])
+	return nonce, err
+}
+
+// nonceSet holds the nonces consumed by valid presentations, like the contracts' consumedNonces.
+type nonceSet struct {
+	mu   sync.Mutex
+	used map[[32]byte]struct{}
+}
+
+// contains reports whether the nonce was consumed.
+func (s *nonceSet) contains(nonce [32]byte) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, used := s.used[nonce]
+	return used
+}
+
+// consume marks the nonce as consumed. It returns false if it already was, e.g. by a concurrent check.
+func (s *nonceSet) consume(nonce [32]byte) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, used := s.used[nonce]; used {
+		return false
+	}
+	if s.used == nil {
+		s.used = make(map[[32]byte]struct{})
+	}
+	s.used[nonce] = struct{}{}
+	return true
+}
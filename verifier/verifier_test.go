@@ -0,0 +1,258 @@
+package verifier
+
+import (
+	"PrivacyPreservingRevocationCode/epoch"
+	"PrivacyPreservingRevocationCode/holder"
+	"PrivacyPreservingRevocationCode/issuer"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestVerifier_OneShow(t *testing.T) {
+	iss := issuer.NewIssuer(issuer.OneShow)
+	require.NoError(t, iss.IssueCredentials(50))
+	require.NoError(t, iss.RevokeRandomCredentials(10))
+
+	artifact, _, _, epochUnix, err := iss.GenRevocationArtifact()
+	require.NoError(t, err)
+
+	verifierID := []byte("verifier")
+	v, err := NewOneShowVerifier(iss.GetPublicKey(), verifierID, artifact)
+	require.NoError(t, err)
+
+	prover := holder.NewOneShowProver()
+	present := func(cred *issuer.InternalCredential, epochUnix int64) *holder.OneShowPresentation {
+		nonce, err := NewNonce()
+		require.NoError(t, err)
+		pres, err := prover.GenProof(*cred, epochUnix, nonce, verifierID)
+		require.NoError(t, err)
+		return pres
+	}
+	check := func(v *OneShowVerifier, pres *holder.OneShowPresentation) (bool, OneShowCode) {
+		return v.CheckCredential(pres.PublicKey, pres.Signature, pres.Proof, pres.Epoch, pres.Nonce, pres.HolderSignature)
+	}
+
+	for _, cred := range iss.GetAllValidCreds() {
+		valid, code := check(v, present(cred, epochUnix))
+		require.Equal(t, OneShowValid, code)
+		require.True(t, valid)
+	}
+	for _, cred := range iss.GetAllRevokedCreds() {
+		valid, code := check(v, present(cred, epochUnix))
+		require.Equal(t, OneShowRevoked, code)
+		require.False(t, valid)
+	}
+
+	pres := present(iss.GetAllValidCreds()[0], epochUnix)
+
+	_, code := v.CheckCredential(pres.PublicKey, pres.Signature[:64], pres.Proof, epochUnix, pres.Nonce, pres.HolderSignature)
+	require.Equal(t, OneShowSignatureFormat, code)
+
+	tampered := append([]byte(nil), pres.Signature...)
+	tampered[10] ^= 0xff
+	_, code = v.CheckCredential(pres.PublicKey, tampered, pres.Proof, epochUnix, pres.Nonce, pres.HolderSignature)
+	require.Equal(t, OneShowSignatureInvalid, code)
+
+	other := issuer.NewIssuer(issuer.OneShow)
+	otherVerifier, err := NewOneShowVerifier(other.GetPublicKey(), verifierID, artifact)
+	require.NoError(t, err)
+	_, code = check(otherVerifier, pres)
+	require.Equal(t, OneShowSignatureInvalid, code)
+
+	_, code = v.CheckCredential(pres.PublicKey, pres.Signature, pres.Proof, epochUnix+86400, pres.Nonce, pres.HolderSignature)
+	require.Equal(t, OneShowEpochInvalid, code)
+	_, code = v.CheckCredential(pres.PublicKey, pres.Signature, pres.Proof[:40], epochUnix, pres.Nonce, pres.HolderSignature)
+	require.Equal(t, OneShowVrfFailed, code)
+
+	// The holder signature binds the presentation to the nonce and the verifier.
+	_, code = v.CheckCredential(pres.PublicKey, pres.Signature, pres.Proof, epochUnix, [32]byte{1}, pres.HolderSignature)
+	require.Equal(t, OneShowHolderInvalid, code)
+	_, code = v.CheckCredential(pres.PublicKey, pres.Signature, pres.Proof, epochUnix, pres.Nonce, pres.HolderSignature[:64])
+	require.Equal(t, OneShowHolderInvalid, code)
+	elsewhere, err := NewOneShowVerifier(iss.GetPublicKey(), []byte("other verifier"), artifact)
+	require.NoError(t, err)
+	_, code = check(elsewhere, pres)
+	require.Equal(t, OneShowHolderInvalid, code)
+
+	// An observer copying a presentation cannot present it again.
+	valid, code := check(v, pres)
+	require.Equal(t, OneShowValid, code)
+	require.True(t, valid)
+	valid, code = check(v, pres)
+	require.Equal(t, OneShowNonceUsed, code)
+	require.False(t, valid)
+
+	_, err = NewOneShowVerifier(iss.GetPublicKey(), nil, artifact)
+	require.Error(t, err)
+}
+
+func TestVerifier_OneShowValidUntil(t *testing.T) {
+	iss := issuer.NewIssuer(issuer.OneShow)
+	iss.SetRegistrationEpochs(2)
+	require.NoError(t, iss.IssueCredentials(20))
+	wallet := holder.NewWallet()
+	req, err := wallet.RequestCredential(issuer.OneShow, iss.GetPublicKey())
+	require.NoError(t, err)
+	ticket, err := iss.RegistrationTicket(req)
+	require.NoError(t, err)
+	require.NoError(t, wallet.RegisterTokens(req, ticket, nil))
+	blind, err := iss.IssueBlindCredential(100, req)
+	require.NoError(t, err)
+	cred, err := wallet.AcceptCredential(req, blind, iss.GetPublicKey())
+	require.NoError(t, err)
+
+	verifierID := []byte("verifier")
+	prover := holder.NewOneShowProver()
+	check := func(cred issuer.InternalCredential, epochUnix int64) (bool, OneShowCode) {
+		artifact, _, _, err := iss.GenRevocationArtifactForEpoch(epochUnix)
+		require.NoError(t, err)
+		v, err := NewOneShowVerifier(iss.GetPublicKey(), verifierID, artifact)
+		require.NoError(t, err)
+		nonce, err := NewNonce()
+		require.NoError(t, err)
+		pres, err := prover.GenProof(cred, epochUnix, nonce, verifierID)
+		require.NoError(t, err)
+		pres.Signature = holder.OneShowSignature(blind)
+		return v.CheckCredential(pres.PublicKey, pres.Signature, pres.Proof, pres.Epoch, pres.Nonce, pres.HolderSignature)
+	}
+
+	valid, code := check(*cred, blind.ValidUntil)
+	require.Equal(t, OneShowValid, code)
+	require.True(t, valid)
+
+	// After its validity bound, the holder cannot present the credential, and the verifier rejects it even if the
+	// issuer left it out of the artifact.
+	expired := iss.EpochPolicy().Next(blind.ValidUntil)
+	_, err = prover.GenProof(*cred, expired, [32]byte{}, verifierID)
+	require.ErrorContains(t, err, "expired")
+	require.NoError(t, iss.RevokeCredential(100))
+	unbounded := *cred
+	unbounded.Credential.ValidUntil = 0
+	valid, code = check(unbounded, expired)
+	require.Equal(t, OneShowExpired, code)
+	require.False(t, valid)
+}
+
+func TestVerifier_MultiShow(t *testing.T) {
+	prover, err := holder.NewRevocationTokenProver("../zkp/sol/build/verifier.g16.pk", "../zkp/sol/build/verifier.g16.vk")
+	require.NoError(t, err)
+
+	iss := issuer.NewIssuer(issuer.MultiShow)
+	require.NoError(t, iss.IssueCredentials(50))
+	require.NoError(t, iss.RevokeRandomCredentials(10))
+
+	artifact, _, _, epochUnix, err := iss.GenRevocationArtifact()
+	require.NoError(t, err)
+
+	vk := readVerifyingKey(t, "../zkp/sol/build/verifier.g16.vk")
+	v, err := NewMultiShowVerifier(iss.GetPublicKey(), []byte("verifier"), vk, artifact)
+	require.NoError(t, err)
+
+	nonce, err := NewNonce()
+	require.NoError(t, err)
+	validCred := iss.GetAllValidCreds()[0]
+	_, proof, _, publicInputs, err := prover.GenProof(*validCred, epochUnix, v.Challenge(nonce))
+	require.NoError(t, err)
+
+	revokedNonce, err := NewNonce()
+	require.NoError(t, err)
+	revokedCred := iss.GetAllRevokedCreds()[0]
+	_, revokedProof, _, revokedInputs, err := prover.GenProof(*revokedCred, epochUnix, v.Challenge(revokedNonce))
+	require.NoError(t, err)
+	valid, code := v.CheckCredential(revokedProof, revokedInputs[2], epochUnix, revokedNonce)
+	require.Equal(t, MultiShowRevoked, code)
+	require.False(t, valid)
+
+	_, code = v.CheckCredential(proof, publicInputs[2], epochUnix+86400, nonce)
+	require.Equal(t, MultiShowEpochInvalid, code)
+
+	_, code = v.CheckCredential(proof, revokedInputs[2], epochUnix, nonce)
+	require.Equal(t, MultiShowProofInvalid, code)
+
+	tampered := proof
+	tampered[0] = new(big.Int).Add(proof[0], big.NewInt(1))
+	_, code = v.CheckCredential(tampered, publicInputs[2], epochUnix, nonce)
+	require.Equal(t, MultiShowProofInvalid, code)
+
+	_, code = v.CheckCredential(proof, new(big.Int).Add(publicInputs[2], ecc.BN254.ScalarField()), epochUnix, nonce)
+	require.Equal(t, MultiShowProofInvalid, code)
+
+	// The proof is bound to the nonce and the verifier.
+	_, code = v.CheckCredential(proof, publicInputs[2], epochUnix, revokedNonce)
+	require.Equal(t, MultiShowProofInvalid, code)
+	other, err := NewMultiShowVerifier(iss.GetPublicKey(), []byte("other verifier"), vk, artifact)
+	require.NoError(t, err)
+	_, code = other.CheckCredential(proof, publicInputs[2], epochUnix, nonce)
+	require.Equal(t, MultiShowProofInvalid, code)
+
+	valid, code = v.CheckCredential(proof, publicInputs[2], epochUnix, nonce)
+	require.Equal(t, MultiShowValid, code)
+	require.True(t, valid)
+
+	// A valid presentation consumes its nonce, so it cannot be replayed.
+	valid, code = v.CheckCredential(proof, publicInputs[2], epochUnix, nonce)
+	require.Equal(t, MultiShowNonceUsed, code)
+	require.False(t, valid)
+
+	_, err = NewMultiShowVerifier(iss.GetPublicKey(), nil, vk, artifact)
+	require.Error(t, err)
+}
+
+func TestVerifier_EpochWindow(t *testing.T) {
+	iss := issuer.NewIssuer(issuer.OneShow)
+	require.NoError(t, iss.IssueCredentials(10))
+	require.NoError(t, iss.RevokeRandomCredentials(2))
+
+	epochUnix := int64(1_700_006_400)
+	artifact, _, _, err := iss.GenRevocationArtifactForEpoch(epochUnix)
+	require.NoError(t, err)
+
+	verifierID := []byte("verifier")
+	v, err := NewOneShowVerifier(iss.GetPublicKey(), verifierID, artifact)
+	require.NoError(t, err)
+
+	revoked := iss.GetAllRevokedCreds()[0]
+	prover := holder.NewOneShowProver()
+	previous, err := prover.GenProof(*revoked, epochUnix-86400, [32]byte{1}, verifierID)
+	require.NoError(t, err)
+	pres, err := prover.GenProof(*revoked, epochUnix, [32]byte{2}, verifierID)
+	require.NoError(t, err)
+	check := func(pres *holder.OneShowPresentation) OneShowCode {
+		_, code := v.CheckCredential(pres.PublicKey, pres.Signature, pres.Proof, pres.Epoch, pres.Nonce, pres.HolderSignature)
+		return code
+	}
+
+	// A revoked holder cannot present the token of an epoch the artifact does not cover.
+	require.Equal(t, OneShowEpochInvalid, check(previous))
+
+	// Without a window, the artifact's epoch is accepted at any time.
+	require.Equal(t, OneShowRevoked, check(pres))
+
+	require.Error(t, v.SetEpochWindow(epoch.Daily, -time.Second))
+	require.NoError(t, v.SetEpochWindow(epoch.Daily, time.Hour))
+
+	for offset, want := range map[time.Duration]OneShowCode{
+		0:                             OneShowRevoked,
+		24*time.Hour + 59*time.Minute: OneShowRevoked,
+		25 * time.Hour:                OneShowEpochInvalid,
+	} {
+		now := time.Unix(epochUnix, 0).Add(offset)
+		v.cascade.now = func() time.Time { return now }
+		require.Equal(t, want, check(pres), "offset %s", offset)
+	}
+}
+
+func readVerifyingKey(t *testing.T, path string) groth16.VerifyingKey {
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	vk := groth16.NewVerifyingKey(ecc.BN254)
+	_, err = vk.ReadFrom(f)
+	require.NoError(t, err)
+	return vk
+}
@@ -0,0 +1,116 @@
+package zkp
+
+import (
+	"errors"
+	"fmt"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_bn254"
+	"github.com/consensys/gnark/std/math/emulated"
+	stdgroth16 "github.com/consensys/gnark/std/recursion/groth16"
+	"io"
+)
+
+// RevocationTokenAggregation verifies the Groth16 proofs of n RevocationTokenProof circuits in one proof, so a relayer
+// can post one proof for many presentations. All proofs must be for the same issuer and epoch; their tokens and
+// challenges are public inputs, so the verifier can still test each token for revocation and consume each nonce.
+// The inner verifying key is fixed when the circuit is created, see NewRevocationTokenAggregation.
+type RevocationTokenAggregation struct {
+	Proofs       []stdgroth16.Proof[sw_bn254.G1Affine, sw_bn254.G2Affine]                     // Groth16 proofs of the RevocationTokenProof circuit
+	VerifyingKey stdgroth16.VerifyingKey[sw_bn254.G1Affine, sw_bn254.G2Affine, sw_bn254.GTEl] `gnark:"-"` // Verifying key of the proofs, a circuit constant
+
+	IssuerX    frontend.Variable   `gnark:",public"` // Issuer Public Key x-coordinate
+	IssuerY    frontend.Variable   `gnark:",public"` // Issuer Public Key y-coordinate
+	Tokens     []frontend.Variable `gnark:",public"` // Revocation Token of each proof
+	Epoch      frontend.Variable   `gnark:",public"` // Epoch of all proofs
+	Challenges []frontend.Variable `gnark:",public"` // Challenge of each proof, see Challenge
+}
+
+// NewRevocationTokenAggregation returns the circuit aggregating n proofs that verify under vk, the Groth16 verifying
+// key of the RevocationTokenProof circuit, e.g. for compilation.
+func NewRevocationTokenAggregation(n int, vk groth16.VerifyingKey) (*RevocationTokenAggregation, error) {
+	if n < 1 {
+		return nil, errors.New("aggregation needs at least one proof")
+	}
+	fixed, err := stdgroth16.ValueOfVerifyingKeyFixed[sw_bn254.G1Affine, sw_bn254.G2Affine, sw_bn254.GTEl](vk)
+	if err != nil {
+		return nil, err
+	}
+	if len(fixed.G1.K) != 6 || len(fixed.CommitmentKeys) != 0 {
+		return nil, errors.New("verifying key is not of the revocation token circuit")
+	}
+	return &RevocationTokenAggregation{
+		Proofs:       make([]stdgroth16.Proof[sw_bn254.G1Affine, sw_bn254.G2Affine], n),
+		VerifyingKey: fixed,
+		Tokens:       make([]frontend.Variable, n),
+		Challenges:   make([]frontend.Variable, n),
+	}, nil
+}
+
+// CompileAggregation compiles the RevocationTokenAggregation circuit of n proofs verifying under vk. Each proof adds
+// an emulated BN254 pairing check of a few million constraints.
+func CompileAggregation(n int, vk groth16.VerifyingKey) (constraint.ConstraintSystem, error) {
+	circuit, err := NewRevocationTokenAggregation(n, vk)
+	if err != nil {
+		return nil, err
+	}
+	return frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, circuit)
+}
+
+// SetupAggregation runs a Groth16 setup of the RevocationTokenAggregation circuit of n proofs verifying under vk and
+// writes the raw proving key, the raw verifying key and the Solidity verifier contract of the verifying key. The
+// setup samples its toxic waste locally, so its keys are only suited for testing.
+func SetupAggregation(n int, vk groth16.VerifyingKey, pkOut, vkOut, solOut io.Writer) error {
+	ccs, err := CompileAggregation(n, vk)
+	if err != nil {
+		return err
+	}
+	aggPk, aggVk, err := groth16.Setup(ccs)
+	if err != nil {
+		return err
+	}
+	_, err = aggPk.WriteRawTo(pkOut)
+	if err != nil {
+		return err
+	}
+	_, err = aggVk.WriteRawTo(vkOut)
+	if err != nil {
+		return err
+	}
+	return aggVk.ExportSolidity(solOut)
+}
+
+// Define implements frontend.Circuit interface.
+func (a *RevocationTokenAggregation) Define(api frontend.API) error {
+	if len(a.Proofs) == 0 || len(a.Tokens) != len(a.Proofs) || len(a.Challenges) != len(a.Proofs) {
+		return errors.New("aggregation needs a token and a challenge per proof")
+	}
+	verifier, err := stdgroth16.NewVerifier[sw_bn254.ScalarField, sw_bn254.G1Affine, sw_bn254.G2Affine, sw_bn254.GTEl](api)
+	if err != nil {
+		return err
+	}
+	scalars, err := emulated.NewField[sw_bn254.ScalarField](api)
+	if err != nil {
+		return err
+	}
+	// The inner proofs are over the native field, so their public inputs are the native variables' bits.
+	toScalar := func(v frontend.Variable) emulated.Element[sw_bn254.ScalarField] {
+		return *scalars.FromBits(api.ToBinary(v)...)
+	}
+
+	issuerX, issuerY, epoch := toScalar(a.IssuerX), toScalar(a.IssuerY), toScalar(a.Epoch)
+	for i, proof := range a.Proofs {
+		// Public inputs in the order of PublicInputs.Encode.
+		witness := stdgroth16.Witness[sw_bn254.ScalarField]{
+			Public: []emulated.Element[sw_bn254.ScalarField]{issuerX, issuerY, toScalar(a.Tokens[i]), epoch, toScalar(a.Challenges[i])},
+		}
+		err = verifier.AssertProof(a.VerifyingKey, proof, witness)
+		if err != nil {
+			return fmt.Errorf("proof %d: %w", i, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package zkp
+
+import (
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_bn254"
+	stdgroth16 "github.com/consensys/gnark/std/recursion/groth16"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/require"
+	"io"
+	"math/big"
+	"os"
+	"testing"
+)
+
+// readShippedKeys reads the shipped Groth16 keys of the RevocationTokenProof circuit.
+func readShippedKeys(t *testing.T) (groth16.ProvingKey, groth16.VerifyingKey) {
+	pk := groth16.NewProvingKey(ecc.BN254)
+	vk := groth16.NewVerifyingKey(ecc.BN254)
+	for path, key := range map[string]io.ReaderFrom{"sol/build/verifier.g16.pk": pk, "sol/build/verifier.g16.vk": vk} {
+		f, err := os.Open(path)
+		require.NoError(t, err)
+		_, err = key.ReadFrom(f)
+		f.Close()
+		require.NoError(t, err)
+	}
+	return pk, vk
+}
+
+func TestRevocationTokenAggregation(t *testing.T) {
+	pk, vk := readShippedKeys(t)
+	ccs, err := CompileRevocationToken(Groth16)
+	require.NoError(t, err)
+
+	inner := newTestAssignment(t, 1_700_000_000)
+	fullWitness, err := frontend.NewWitness(inner, ecc.BN254.ScalarField())
+	require.NoError(t, err)
+	proof, err := groth16.Prove(ccs, pk, fullWitness)
+	require.NoError(t, err)
+	inputs, err := NewPublicInputs(inner)
+	require.NoError(t, err)
+
+	circuit, err := NewRevocationTokenAggregation(1, vk)
+	require.NoError(t, err)
+	assignment, err := NewRevocationTokenAggregation(1, vk)
+	require.NoError(t, err)
+	assignment.Proofs[0], err = stdgroth16.ValueOfProof[sw_bn254.G1Affine, sw_bn254.G2Affine](proof)
+	require.NoError(t, err)
+	assignment.IssuerX, assignment.IssuerY, assignment.Epoch = inputs.IssuerX, inputs.IssuerY, inputs.Epoch
+	assignment.Tokens[0], assignment.Challenges[0] = inputs.RevocationToken, inputs.Challenge
+	require.NoError(t, test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()))
+
+	// The aggregate binds the tokens of the proofs.
+	assignment.Tokens[0] = new(big.Int).Add(inputs.RevocationToken, big.NewInt(1))
+	require.Error(t, test.IsSolved(circuit, assignment, ecc.BN254.ScalarField()))
+}
+
+func TestNewRevocationTokenAggregation(t *testing.T) {
+	_, vk := readShippedKeys(t)
+	_, err := NewRevocationTokenAggregation(0, vk)
+	require.Error(t, err)
+
+	// Only verifying keys of the revocation token circuit are accepted.
+	other, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &TokenHashProof{})
+	require.NoError(t, err)
+	_, otherVk, err := groth16.Setup(other)
+	require.NoError(t, err)
+	_, err = NewRevocationTokenAggregation(1, otherVk)
+	require.Error(t, err)
+}
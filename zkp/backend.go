@@ -0,0 +1,93 @@
+package zkp
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/kzg"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	"io"
+)
+
+// Backend is a proving system for the RevocationTokenProof circuit.
+type Backend int
+
+const (
+	// Groth16 has the smallest proofs and the cheapest on-chain verification, but needs a circuit-specific setup.
+	Groth16 Backend = iota
+	// Plonk commits with KZG and only needs a universal SRS, which does not depend on the circuit.
+	Plonk
+)
+
+// String returns the name of the backend.
+func (b Backend) String() string {
+	switch b {
+	case Groth16:
+		return "groth16"
+	case Plonk:
+		return "plonk"
+	default:
+		return fmt.Sprintf("Backend(%d)", int(b))
+	}
+}
+
+// CompileRevocationToken compiles the RevocationTokenProof circuit for the backend: into an R1CS for Groth16 and a
+// sparse R1CS for Plonk.
+func CompileRevocationToken(b Backend) (constraint.ConstraintSystem, error) {
+	var circuit RevocationTokenProof
+	switch b {
+	case Groth16:
+		return frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &circuit)
+	case Plonk:
+		return frontend.Compile(ecc.BN254.ScalarField(), scs.NewBuilder, &circuit)
+	default:
+		return nil, fmt.Errorf("unsupported proving backend %s", b)
+	}
+}
+
+// RevocationTokenKeyHash is the hex encoded KeyHash of the shipped Groth16 constraint system verifier.g16.ccs and
+// verifying key verifier.g16.vk.
+const RevocationTokenKeyHash = "9972aa8d6a55d8c85a812ade03179beedfc6a364ec055e517346f104105e69d9"
+
+// KeyHash returns the SHA-256 hash of a serialized constraint system and its verifying key, in gnark's encoding.
+// Recorded at setup, it lets provers check that keys loaded later belong to the constraint system they load.
+func KeyHash(ccs, vk io.WriterTo) ([]byte, error) {
+	h := sha256.New()
+	_, err := ccs.WriteTo(h)
+	if err != nil {
+		return nil, err
+	}
+	_, err = vk.WriteTo(h)
+	if err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// SetupRevocationTokenPlonk runs the PLONK setup of the RevocationTokenProof circuit and writes the proving key, the
+// verifying key and the Solidity verifier contract of the verifying key. srs and srsLagrange are the canonical and
+// Lagrange form of a KZG SRS on BN254, e.g. from a public ceremony, with at least the sizes plonk.SRSSize requires for
+// the circuit. Unlike the Groth16 setup, no secrets are sampled, so anyone can reproduce the keys from the SRS.
+func SetupRevocationTokenPlonk(srs, srsLagrange kzg.SRS, pkOut, vkOut, solOut io.Writer) error {
+	ccs, err := CompileRevocationToken(Plonk)
+	if err != nil {
+		return err
+	}
+	pk, vk, err := plonk.Setup(ccs, srs, srsLagrange)
+	if err != nil {
+		return err
+	}
+	_, err = pk.WriteTo(pkOut)
+	if err != nil {
+		return err
+	}
+	_, err = vk.WriteTo(vkOut)
+	if err != nil {
+		return err
+	}
+	return vk.ExportSolidity(solOut)
+}
@@ -0,0 +1,72 @@
+package zkp
+
+import (
+	"bytes"
+	"encoding/hex"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/test/unsafekzg"
+	"github.com/stretchr/testify/require"
+	"os"
+	"testing"
+)
+
+func TestCompileRevocationToken(t *testing.T) {
+	for _, b := range []Backend{Groth16, Plonk} {
+		ccs, err := CompileRevocationToken(b)
+		require.NoError(t, err)
+		t.Logf("%s: %d constraints", b, ccs.GetNbConstraints())
+	}
+
+	_, err := CompileRevocationToken(Backend(2))
+	require.Error(t, err)
+	require.Equal(t, "Backend(2)", Backend(2).String())
+}
+
+func TestSetupRevocationTokenPlonk(t *testing.T) {
+	ccs, err := CompileRevocationToken(Plonk)
+	require.NoError(t, err)
+	srs, srsLagrange, err := unsafekzg.NewSRS(ccs)
+	require.NoError(t, err)
+
+	var pk, vk, sol bytes.Buffer
+	require.NoError(t, SetupRevocationTokenPlonk(srs, srsLagrange, &pk, &vk, &sol))
+	require.NotZero(t, pk.Len())
+	require.NotZero(t, vk.Len())
+	require.Contains(t, sol.String(), "contract PlonkVerifier")
+
+	// The keys only depend on the SRS.
+	var pk2, vk2, sol2 bytes.Buffer
+	require.NoError(t, SetupRevocationTokenPlonk(srs, srsLagrange, &pk2, &vk2, &sol2))
+	require.Equal(t, vk.Bytes(), vk2.Bytes())
+	require.Equal(t, sol.String(), sol2.String())
+}
+
+func TestKeyHash(t *testing.T) {
+	// The shipped constraint system is the compiled circuit.
+	ccs, err := CompileRevocationToken(Groth16)
+	require.NoError(t, err)
+	var compiled bytes.Buffer
+	_, err = ccs.WriteTo(&compiled)
+	require.NoError(t, err)
+	shipped, err := os.ReadFile("sol/build/verifier.g16.ccs")
+	require.NoError(t, err)
+	require.Equal(t, shipped, compiled.Bytes(), "verifier.g16.ccs is outdated")
+
+	vkFile, err := os.Open("sol/build/verifier.g16.vk")
+	require.NoError(t, err)
+	defer vkFile.Close()
+	vk := groth16.NewVerifyingKey(ecc.BN254)
+	_, err = vk.ReadFrom(vkFile)
+	require.NoError(t, err)
+	hash, err := KeyHash(ccs, vk)
+	require.NoError(t, err)
+	require.Equal(t, RevocationTokenKeyHash, hex.EncodeToString(hash))
+
+	// The hash depends on the circuit.
+	plonkCcs, err := CompileRevocationToken(Plonk)
+	require.NoError(t, err)
+	other, err := KeyHash(plonkCcs, vk)
+	require.NoError(t, err)
+	require.NotEqual(t, hash, other)
+}
@@ -0,0 +1,429 @@
+// Package ceremony runs a multi-party Groth16 phase-2 ceremony for the zkp.RevocationTokenProof circuit, built on
+// gnark's MPC setup. A coordinator initializes the ceremony from the result of a phase-1 (powers of tau) ceremony,
+// participants contribute in turn to the latest state, and the coordinator verifies and accepts each contribution
+// before finalizing the keys. The keys are secure as long as one participant of either phase discarded its
+// randomness.
+//
+// The ceremony is kept in a directory: the phase-1 result, the circuit evaluations derived from it, the initial
+// phase-2 state phase2-0000.bin and the accepted contributions phase2-0001.bin, phase2-0002.bin, and so on.
+package ceremony
+
+import (
+	"PrivacyPreservingRevocationCode/zkp"
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"github.com/consensys/gnark-crypto/ecc"
+	curve "github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark/backend/groth16/bn254/mpcsetup"
+	cs "github.com/consensys/gnark/constraint/bn254"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+const (
+	phase1File      = "phase1.bin"      // phase1File holds the phase-1 result the ceremony was initialized with.
+	evaluationsFile = "evaluations.bin" // evaluationsFile holds the circuit evaluations of InitPhase2.
+)
+
+// phase2File returns the name of the i-th phase-2 state; state 0 is the initial one.
+func phase2File(i int) string {
+	return fmt.Sprintf("phase2-%04d.bin", i)
+}
+
+// Ceremony is a phase-2 ceremony for a circuit, kept in a directory.
+type Ceremony struct {
+	dir string   // dir is the directory of the ceremony files.
+	ccs *cs.R1CS // ccs is the constraint system of the circuit.
+}
+
+// Init initializes a ceremony for the zkp.RevocationTokenProof circuit in dir, which must not contain a ceremony yet.
+// phase1 is a serialized mpcsetup.Phase1 of exactly the circuit's domain size, i.e. 2^14 powers of tau. Deriving the
+// initial phase-2 state takes about a minute.
+func Init(dir string, phase1 io.Reader) (*Ceremony, error) {
+	ccs, err := compileRevocationToken()
+	if err != nil {
+		return nil, err
+	}
+	return initCeremony(dir, phase1, ccs)
+}
+
+// Open opens the ceremony for the zkp.RevocationTokenProof circuit in dir.
+func Open(dir string) (*Ceremony, error) {
+	ccs, err := compileRevocationToken()
+	if err != nil {
+		return nil, err
+	}
+	c := &Ceremony{dir: dir, ccs: ccs}
+	_, err = os.Stat(c.path(phase2File(0)))
+	if err != nil {
+		return nil, fmt.Errorf("no ceremony in %s: %w", dir, err)
+	}
+	return c, nil
+}
+
+// compileRevocationToken compiles the R1CS of the zkp.RevocationTokenProof circuit.
+func compileRevocationToken() (*cs.R1CS, error) {
+	ccs, err := zkp.CompileRevocationToken(zkp.Groth16)
+	if err != nil {
+		return nil, err
+	}
+	return ccs.(*cs.R1CS), nil
+}
+
+// initCeremony initializes a ceremony for the circuit ccs in dir.
+func initCeremony(dir string, phase1 io.Reader, ccs *cs.R1CS) (*Ceremony, error) {
+	raw, err := io.ReadAll(phase1)
+	if err != nil {
+		return nil, err
+	}
+	var srs1 mpcsetup.Phase1
+	err = decode(raw, &srs1)
+	if err != nil {
+		return nil, fmt.Errorf("invalid phase 1: %w", err)
+	}
+	// The keys are extracted for the circuit's domain, so phase 1 must have been run for exactly that size.
+	domain := ecc.NextPowerOfTwo(uint64(ccs.GetNbConstraints()))
+	if uint64(len(srs1.Parameters.G1.AlphaTau)) != domain {
+		return nil, fmt.Errorf("phase 1 has %d powers of tau, the circuit needs %d", len(srs1.Parameters.G1.AlphaTau), domain)
+	}
+
+	err = os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return nil, err
+	}
+	c := &Ceremony{dir: dir, ccs: ccs}
+	_, err = os.Stat(c.path(phase2File(0)))
+	if err == nil {
+		return nil, fmt.Errorf("%s already contains a ceremony", dir)
+	}
+
+	srs2, evals := mpcsetup.InitPhase2(ccs, &srs1)
+	err = os.WriteFile(c.path(phase1File), raw, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	err = c.write(evaluationsFile, (*evaluations)(&evals))
+	if err != nil {
+		return nil, err
+	}
+	err = c.write(phase2File(0), &srs2)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Contributions returns the number of accepted contributions.
+func (c *Ceremony) Contributions() (int, error) {
+	n := 0
+	for {
+		_, err := os.Stat(c.path(phase2File(n + 1)))
+		if errors.Is(err, os.ErrNotExist) {
+			return n, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		n++
+	}
+}
+
+// Latest returns the path of the latest phase-2 state, which the next participant contributes to.
+func (c *Ceremony) Latest() (string, error) {
+	n, err := c.Contributions()
+	if err != nil {
+		return "", err
+	}
+	return c.path(phase2File(n)), nil
+}
+
+// Contribute reads a phase-2 state, adds fresh randomness to it and writes the contribution. It returns the hash of
+// the contribution, which the participant can look up in the ceremony afterward. The randomness is discarded when
+// Contribute returns.
+func Contribute(in io.Reader, out io.Writer) ([]byte, error) {
+	raw, err := io.ReadAll(in)
+	if err != nil {
+		return nil, err
+	}
+	var srs2 mpcsetup.Phase2
+	err = decode(raw, &srs2)
+	if err != nil {
+		return nil, fmt.Errorf("invalid phase 2 state: %w", err)
+	}
+	srs2.Contribute()
+	_, err = srs2.WriteTo(out)
+	if err != nil {
+		return nil, err
+	}
+	return srs2.Hash, nil
+}
+
+// Accept verifies a contribution to the latest state and stores it as the new latest state. It returns the hash of
+// the contribution.
+func (c *Ceremony) Accept(contribution io.Reader) ([]byte, error) {
+	raw, err := io.ReadAll(contribution)
+	if err != nil {
+		return nil, err
+	}
+	var next mpcsetup.Phase2
+	err = decode(raw, &next)
+	if err != nil {
+		return nil, fmt.Errorf("invalid contribution: %w", err)
+	}
+
+	n, err := c.Contributions()
+	if err != nil {
+		return nil, err
+	}
+	var latest mpcsetup.Phase2
+	err = c.read(phase2File(n), &latest)
+	if err != nil {
+		return nil, err
+	}
+	err = verifyContribution(&latest, &next)
+	if err != nil {
+		return nil, fmt.Errorf("contribution %d: %w", n+1, err)
+	}
+
+	f, err := os.OpenFile(c.path(phase2File(n+1)), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	_, err = f.Write(raw)
+	if err != nil {
+		return nil, err
+	}
+	return next.Hash, f.Close()
+}
+
+// Verify checks the whole ceremony: that the initial state and evaluations derive from the phase-1 result and the
+// circuit, which takes as long as Init, and that each contribution builds on the previous state. It returns the
+// hashes of the contributions in order.
+func (c *Ceremony) Verify() ([][]byte, error) {
+	var srs1 mpcsetup.Phase1
+	err := c.read(phase1File, &srs1)
+	if err != nil {
+		return nil, err
+	}
+	srs2, evals := mpcsetup.InitPhase2(c.ccs, &srs1)
+
+	// The public key of the initial state is random, so only its parameters are compared.
+	var stored mpcsetup.Phase2
+	err = c.read(phase2File(0), &stored)
+	if err != nil {
+		return nil, err
+	}
+	if !sameParameters(&stored, &srs2) {
+		return nil, errors.New("initial phase 2 state does not derive from phase 1 and the circuit")
+	}
+	want, err := encode((*evaluations)(&evals))
+	if err != nil {
+		return nil, err
+	}
+	got, err := os.ReadFile(c.path(evaluationsFile))
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(got, want) {
+		return nil, errors.New("evaluations do not derive from phase 1 and the circuit")
+	}
+
+	_, hashes, err := c.verifyContributions()
+	return hashes, err
+}
+
+// Finalize verifies the contributions and writes the raw proving key, the raw verifying key and the Solidity verifier
+// contract of the ceremony's keys, as read by holder.NewRevocationTokenProver. It returns the transcript hash, the
+// SHA-256 hash of all ceremony files in order, which commits to the phase-1 result and all contributions. At least
+// one contribution is required.
+func (c *Ceremony) Finalize(pkOut, vkOut, solOut io.Writer) ([]byte, error) {
+	last, hashes, err := c.verifyContributions()
+	if err != nil {
+		return nil, err
+	}
+	if len(hashes) == 0 {
+		return nil, errors.New("ceremony has no contributions")
+	}
+	var srs1 mpcsetup.Phase1
+	err = c.read(phase1File, &srs1)
+	if err != nil {
+		return nil, err
+	}
+	var evals mpcsetup.Phase2Evaluations
+	err = c.read(evaluationsFile, (*evaluations)(&evals))
+	if err != nil {
+		return nil, err
+	}
+
+	pk, vk := mpcsetup.ExtractKeys(&srs1, last, &evals, c.ccs.GetNbConstraints())
+	_, err = pk.WriteRawTo(pkOut)
+	if err != nil {
+		return nil, err
+	}
+	_, err = vk.WriteRawTo(vkOut)
+	if err != nil {
+		return nil, err
+	}
+	err = vk.ExportSolidity(solOut)
+	if err != nil {
+		return nil, err
+	}
+	return c.transcriptHash(len(hashes))
+}
+
+// verifyContributions verifies that each contribution builds on the previous state. It returns the latest state and
+// the hashes of the contributions.
+func (c *Ceremony) verifyContributions() (*mpcsetup.Phase2, [][]byte, error) {
+	n, err := c.Contributions()
+	if err != nil {
+		return nil, nil, err
+	}
+	prev := &mpcsetup.Phase2{}
+	err = c.read(phase2File(0), prev)
+	if err != nil {
+		return nil, nil, err
+	}
+	hashes := make([][]byte, 0, n)
+	for i := 1; i <= n; i++ {
+		next := &mpcsetup.Phase2{}
+		err = c.read(phase2File(i), next)
+		if err != nil {
+			return nil, nil, err
+		}
+		err = verifyContribution(prev, next)
+		if err != nil {
+			return nil, nil, fmt.Errorf("contribution %d: %w", i, err)
+		}
+		hashes = append(hashes, next.Hash)
+		prev = next
+	}
+	return prev, hashes, nil
+}
+
+// verifyContribution verifies that next is a contribution to prev.
+func verifyContribution(prev, next *mpcsetup.Phase2) error {
+	if len(next.Parameters.G1.L) != len(prev.Parameters.G1.L) || len(next.Parameters.G1.Z) != len(prev.Parameters.G1.Z) {
+		return errors.New("contribution is for another circuit")
+	}
+	return mpcsetup.VerifyPhase2(prev, next)
+}
+
+// sameParameters returns whether two phase-2 states have the same parameters.
+func sameParameters(a, b *mpcsetup.Phase2) bool {
+	if !a.Parameters.G1.Delta.Equal(&b.Parameters.G1.Delta) || !a.Parameters.G2.Delta.Equal(&b.Parameters.G2.Delta) {
+		return false
+	}
+	return samePoints(a.Parameters.G1.L, b.Parameters.G1.L) && samePoints(a.Parameters.G1.Z, b.Parameters.G1.Z)
+}
+
+// samePoints returns whether two lists of points are equal.
+func samePoints(a, b []curve.G1Affine) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !a[i].Equal(&b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// evaluations encodes mpcsetup.Phase2Evaluations including VKK, the evaluations of the public inputs, which gnark's
+// encoding omits but the verifying key needs.
+type evaluations mpcsetup.Phase2Evaluations
+
+// WriteTo implements io.WriterTo.
+func (e *evaluations) WriteTo(w io.Writer) (int64, error) {
+	enc := curve.NewEncoder(w)
+	for _, v := range []interface{}{e.G1.A, e.G1.B, e.G2.B, e.G1.VKK} {
+		err := enc.Encode(v)
+		if err != nil {
+			return enc.BytesWritten(), err
+		}
+	}
+	return enc.BytesWritten(), nil
+}
+
+// ReadFrom implements io.ReaderFrom.
+func (e *evaluations) ReadFrom(r io.Reader) (int64, error) {
+	dec := curve.NewDecoder(r)
+	for _, v := range []interface{}{&e.G1.A, &e.G1.B, &e.G2.B, &e.G1.VKK} {
+		err := dec.Decode(v)
+		if err != nil {
+			return dec.BytesRead(), err
+		}
+	}
+	return dec.BytesRead(), nil
+}
+
+// transcriptHash returns the SHA-256 hash of the ceremony files up to the n-th contribution.
+func (c *Ceremony) transcriptHash(n int) ([]byte, error) {
+	files := []string{phase1File, evaluationsFile}
+	for i := 0; i <= n; i++ {
+		files = append(files, phase2File(i))
+	}
+	h := sha256.New()
+	for _, name := range files {
+		f, err := os.Open(c.path(name))
+		if err != nil {
+			return nil, err
+		}
+		_, err = io.Copy(h, f)
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return h.Sum(nil), nil
+}
+
+// path returns the path of a ceremony file.
+func (c *Ceremony) path(name string) string {
+	return filepath.Join(c.dir, name)
+}
+
+// read decodes a ceremony file into v.
+func (c *Ceremony) read(name string, v io.ReaderFrom) error {
+	raw, err := os.ReadFile(c.path(name))
+	if err != nil {
+		return err
+	}
+	err = decode(raw, v)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", name, err)
+	}
+	return nil
+}
+
+// write encodes v into a new ceremony file.
+func (c *Ceremony) write(name string, v io.WriterTo) error {
+	raw, err := encode(v)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(c.path(name), raw, 0o644)
+}
+
+// decode decodes raw into v, which must consume all of it.
+func decode(raw []byte, v io.ReaderFrom) error {
+	n, err := v.ReadFrom(bytes.NewReader(raw))
+	if err != nil {
+		return err
+	}
+	if n != int64(len(raw)) {
+		return fmt.Errorf("%d trailing bytes", int64(len(raw))-n)
+	}
+	return nil
+}
+
+// encode returns the encoding of v.
+func encode(v io.WriterTo) ([]byte, error) {
+	var buf bytes.Buffer
+	_, err := v.WriteTo(&buf)
+	return buf.Bytes(), err
+}
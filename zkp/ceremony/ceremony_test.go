@@ -0,0 +1,190 @@
+package ceremony
+
+import (
+	"PrivacyPreservingRevocationCode/holder"
+	"PrivacyPreservingRevocationCode/issuer"
+	"PrivacyPreservingRevocationCode/zkp"
+	"bytes"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/groth16/bn254/mpcsetup"
+	cs "github.com/consensys/gnark/constraint/bn254"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/stretchr/testify/require"
+	"math/bits"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// cubicCircuit proves knowledge of X with X^3 + X + 5 = Y.
+type cubicCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (c *cubicCircuit) Define(api frontend.API) error {
+	x3 := api.Mul(c.X, c.X, c.X)
+	api.AssertIsEqual(c.Y, api.Add(x3, c.X, 5))
+	return nil
+}
+
+// newPhase1 returns a serialized phase-1 result with one contribution for the domain of ccs.
+func newPhase1(t *testing.T, ccs *cs.R1CS) []byte {
+	domain := ecc.NextPowerOfTwo(uint64(ccs.GetNbConstraints()))
+	srs1 := mpcsetup.InitPhase1(bits.Len64(domain - 1))
+	srs1.Contribute()
+	raw, err := encode(&srs1)
+	require.NoError(t, err)
+	return raw
+}
+
+// contribute contributes to the latest state of the ceremony and returns the contribution.
+func contribute(t *testing.T, c *Ceremony) []byte {
+	latest, err := c.Latest()
+	require.NoError(t, err)
+	f, err := os.Open(latest)
+	require.NoError(t, err)
+	defer f.Close()
+	var contribution bytes.Buffer
+	_, err = Contribute(f, &contribution)
+	require.NoError(t, err)
+	return contribution.Bytes()
+}
+
+func TestCeremony(t *testing.T) {
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &cubicCircuit{})
+	require.NoError(t, err)
+	phase1 := newPhase1(t, ccs.(*cs.R1CS))
+	dir := t.TempDir()
+
+	c, err := initCeremony(dir, bytes.NewReader(phase1), ccs.(*cs.R1CS))
+	require.NoError(t, err)
+	_, err = initCeremony(dir, bytes.NewReader(phase1), ccs.(*cs.R1CS))
+	require.Error(t, err, "expected an existing ceremony to be kept")
+	_, err = c.Finalize(&bytes.Buffer{}, &bytes.Buffer{}, &bytes.Buffer{})
+	require.Error(t, err, "expected a ceremony without contributions not to finalize")
+
+	// Three participants contribute in turn.
+	var hashes [][]byte
+	for i := 0; i < 3; i++ {
+		contribution := contribute(t, c)
+		hash, err := c.Accept(bytes.NewReader(contribution))
+		require.NoError(t, err)
+		hashes = append(hashes, hash)
+
+		// Replayed contributions are rejected.
+		_, err = c.Accept(bytes.NewReader(contribution))
+		require.Error(t, err)
+	}
+	n, err := c.Contributions()
+	require.NoError(t, err)
+	require.Equal(t, 3, n)
+
+	// Contributions to a stale state and tampered contributions are rejected.
+	stale, err := os.ReadFile(filepath.Join(dir, phase2File(1)))
+	require.NoError(t, err)
+	var staleContribution bytes.Buffer
+	_, err = Contribute(bytes.NewReader(stale), &staleContribution)
+	require.NoError(t, err)
+	_, err = c.Accept(&staleContribution)
+	require.Error(t, err)
+	tampered := contribute(t, c)
+	tampered[len(tampered)/2] ^= 1
+	_, err = c.Accept(bytes.NewReader(tampered))
+	require.Error(t, err)
+	_, err = c.Accept(bytes.NewReader(tampered[:len(tampered)-1]))
+	require.Error(t, err)
+
+	verified, err := c.Verify()
+	require.NoError(t, err)
+	require.Equal(t, hashes, verified)
+
+	var pkBuf, vkBuf, sol bytes.Buffer
+	transcript, err := c.Finalize(&pkBuf, &vkBuf, &sol)
+	require.NoError(t, err)
+	require.Len(t, transcript, 32)
+	require.Contains(t, sol.String(), "contract Verifier")
+	again, err := c.Finalize(&bytes.Buffer{}, &bytes.Buffer{}, &bytes.Buffer{})
+	require.NoError(t, err)
+	require.Equal(t, transcript, again)
+
+	// The keys prove and verify the circuit.
+	pk, vk := groth16.NewProvingKey(ecc.BN254), groth16.NewVerifyingKey(ecc.BN254)
+	_, err = pk.ReadFrom(&pkBuf)
+	require.NoError(t, err)
+	_, err = vk.ReadFrom(&vkBuf)
+	require.NoError(t, err)
+	w, err := frontend.NewWitness(&cubicCircuit{X: 3, Y: 35}, ecc.BN254.ScalarField())
+	require.NoError(t, err)
+	proof, err := groth16.Prove(ccs, pk, w)
+	require.NoError(t, err)
+	publicWitness, err := w.Public()
+	require.NoError(t, err)
+	require.NoError(t, groth16.Verify(proof, vk, publicWitness))
+
+	// Tampering with the transcript is detected.
+	evals, err := os.ReadFile(filepath.Join(dir, evaluationsFile))
+	require.NoError(t, err)
+	evals[len(evals)-1] ^= 1
+	require.NoError(t, os.WriteFile(filepath.Join(dir, evaluationsFile), evals, 0o644))
+	_, err = c.Verify()
+	require.Error(t, err)
+	require.NoError(t, os.Remove(filepath.Join(dir, phase2File(2))))
+	_, err = c.Verify()
+	require.Error(t, err, "expected a gap in the contributions to be detected")
+}
+
+func TestInitRejectsPhase1OfWrongSize(t *testing.T) {
+	srs1 := mpcsetup.InitPhase1(4)
+	raw, err := encode(&srs1)
+	require.NoError(t, err)
+	_, err = Init(t.TempDir(), bytes.NewReader(raw))
+	require.Error(t, err)
+	_, err = Open(t.TempDir())
+	require.Error(t, err)
+}
+
+func TestCeremony_RevocationToken(t *testing.T) {
+	if testing.Short() {
+		t.Skip("the ceremony for the revocation circuit takes minutes")
+	}
+	ccs, err := compileRevocationToken()
+	require.NoError(t, err)
+	dir := t.TempDir()
+	c, err := Init(dir, bytes.NewReader(newPhase1(t, ccs)))
+	require.NoError(t, err)
+	c, err = Open(dir)
+	require.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		_, err = c.Accept(bytes.NewReader(contribute(t, c)))
+		require.NoError(t, err)
+	}
+
+	pkPath, vkPath := filepath.Join(dir, "verifier.g16.pk"), filepath.Join(dir, "verifier.g16.vk")
+	pkFile, err := os.Create(pkPath)
+	require.NoError(t, err)
+	vkFile, err := os.Create(vkPath)
+	require.NoError(t, err)
+	var sol bytes.Buffer
+	_, err = c.Finalize(pkFile, vkFile, &sol)
+	require.NoError(t, err)
+	require.NoError(t, pkFile.Close())
+	require.NoError(t, vkFile.Close())
+
+	prover, err := holder.NewRevocationTokenProver(pkPath, vkPath)
+	require.NoError(t, err)
+	iss := issuer.NewIssuer(issuer.MultiShow)
+	require.NoError(t, iss.IssueCredential(0))
+	cred, err := iss.GetCredentialCopy(0)
+	require.NoError(t, err)
+	proof, err := prover.Prove(cred, time.Now().UTC().Unix(), zkp.Challenge([32]byte{1}, []byte("verifier")))
+	require.NoError(t, err)
+	require.NoError(t, prover.Verify(proof))
+
+	var exported bytes.Buffer
+	require.NoError(t, prover.ExportSolidity(&exported))
+	require.Equal(t, sol.String(), exported.String())
+}
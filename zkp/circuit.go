@@ -10,11 +10,13 @@ import (
 type RevocationTokenProof struct {
 	VrfSecretKey  frontend.Variable // VRF Secret Key
 	VrfPublicKey  eddsa.PublicKey   // VRF Public Key, i.e. single Credential Attribute
-	CredSignature eddsa.Signature   // Signature on VrfPublicKey by IssuerPubKey
+	CredSignature eddsa.Signature   // Signature on VrfPublicKey and ValidUntil by IssuerPubKey
+	ValidUntil    frontend.Variable // Last epoch the credential is valid for, or 0 if it is unbounded
 
 	IssuerPubKey    eddsa.PublicKey   `gnark:",public"` // Issuer Public Key
 	RevocationToken frontend.Variable `gnark:",public"` // Revocation Token, i.e. vrf output
 	Epoch           frontend.Variable `gnark:",public"` // Epoch for Revocation Token
+	Challenge       frontend.Variable `gnark:",public"` // Challenge of the verifier the proof is presented to, see Challenge
 }
 
 func (p *RevocationTokenProof) Define(api frontend.API) error {
@@ -29,11 +31,13 @@ func (p *RevocationTokenProof) Define(api frontend.API) error {
 		return err
 	}
 
-	// 2. Verify signature of issuer on given public key (i.e., credential presentation)
-	err = assertSignaturePk(api, curve, p.CredSignature, p.VrfPublicKey, p.IssuerPubKey)
+	// 2. Verify signature of issuer on given public key (i.e., credential presentation) and that the credential is
+	// valid in the epoch.
+	err = assertSignaturePk(api, curve, p.CredSignature, p.VrfPublicKey, p.ValidUntil, p.IssuerPubKey)
 	if err != nil {
 		return err
 	}
+	assertValidity(api, p.ValidUntil, p.Epoch)
 
 	// 3. Verify the revocation token.
 	err = assertRevocationToken(api, p.Epoch, p.VrfSecretKey, p.RevocationToken)
@@ -41,5 +45,8 @@ func (p *RevocationTokenProof) Define(api frontend.API) error {
 		return err
 	}
 
+	// 4. Bind the proof to the verifier's challenge.
+	bindPublicInput(api, p.Challenge)
+
 	return nil
 }
@@ -13,6 +13,7 @@ import (
 	"github.com/consensys/gnark/frontend/cs/r1cs"
 	"github.com/consensys/gnark/std/algebra/native/twistededwards"
 	eddsaInCicuit "github.com/consensys/gnark/std/signature/eddsa"
+	"github.com/consensys/gnark/test"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"log"
@@ -66,8 +67,10 @@ func TestRevocationTokenProof_Verify(t *testing.T) {
 		VrfPublicKey:    vrfKey.Pk,
 		IssuerPubKey:    icIssuerPublicKey,
 		CredSignature:   icCredSigInCircuit,
+		ValidUntil:      0,
 		RevocationToken: token,
 		Epoch:           epoch,
+		Challenge:       Challenge([32]byte{}, nil),
 	}
 
 	witness, err := frontend.NewWitness(assignment, ecc.BN254.ScalarField())
@@ -78,6 +81,48 @@ func TestRevocationTokenProof_Verify(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestRevocationTokenProof_ValidUntil(t *testing.T) {
+	issuerSecretKey, err := bn254eddsa.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	vrfKey, err := EddsaForCircuitKeyGen()
+	require.NoError(t, err)
+
+	const validUntil = int64(1_700_000_000)
+	msgHash, err := HashEddsaPublicKey(vrfKey.Pk)
+	require.NoError(t, err)
+	msg, err := CredentialMessage(msgHash, validUntil)
+	require.NoError(t, err)
+	cred, err := issuerSecretKey.Sign(msg, mimc.NewMiMC())
+	require.NoError(t, err)
+	icCredSigInCircuit := eddsaInCicuit.Signature{}
+	icCredSigInCircuit.Assign(tedwards.BN254, cred)
+
+	assignment := func(epochUnix, claimedValidUntil int64) *RevocationTokenProof {
+		token, _, err := GenRevocationToken(vrfKey.Sk, epochUnix)
+		require.NoError(t, err)
+		return &RevocationTokenProof{
+			VrfSecretKey:    vrfKey.Sk,
+			VrfPublicKey:    vrfKey.Pk,
+			IssuerPubKey:    eddsaInCicuit.PublicKey{A: twistededwards.Point{X: issuerSecretKey.PublicKey.A.X, Y: issuerSecretKey.PublicKey.A.Y}},
+			CredSignature:   icCredSigInCircuit,
+			ValidUntil:      claimedValidUntil,
+			RevocationToken: token,
+			Epoch:           epochUnix,
+			Challenge:       Challenge([32]byte{}, nil),
+		}
+	}
+
+	var circuit RevocationTokenProof
+	require.NoError(t, test.IsSolved(&circuit, assignment(validUntil-86400, validUntil), ecc.BN254.ScalarField()))
+	require.NoError(t, test.IsSolved(&circuit, assignment(validUntil, validUntil), ecc.BN254.ScalarField()))
+
+	// The credential expired.
+	require.Error(t, test.IsSolved(&circuit, assignment(validUntil+86400, validUntil), ecc.BN254.ScalarField()))
+	// The signature covers the bound, so the holder can neither extend nor drop it.
+	require.Error(t, test.IsSolved(&circuit, assignment(validUntil+86400, validUntil+86400), ecc.BN254.ScalarField()))
+	require.Error(t, test.IsSolved(&circuit, assignment(validUntil+86400, 0), ecc.BN254.ScalarField()))
+}
+
 /*
 func TestRevocationTokenProof_ExportSolidity(t *testing.T) {
 	var circuit RevocationTokenProof
@@ -151,8 +196,10 @@ func TestRevocationTokenProof_FalseCred(t *testing.T) {
 		VrfPublicKey:    vrfKey.Pk,
 		IssuerPubKey:    icIssuerPublicKey,
 		CredSignature:   icCredSigInCircuit,
+		ValidUntil:      0,
 		RevocationToken: token,
 		Epoch:           epoch,
+		Challenge:       Challenge([32]byte{}, nil),
 	}
 
 	witness, err := frontend.NewWitness(assignment, ecc.BN254.ScalarField())
@@ -204,8 +251,10 @@ func TestRevocationTokenProof_FalseSkForToken(t *testing.T) {
 		VrfPublicKey:    vrfKey.Pk,
 		IssuerPubKey:    icIssuerPublicKey,
 		CredSignature:   icCredSigInCircuit,
+		ValidUntil:      0,
 		RevocationToken: token,
 		Epoch:           epoch,
+		Challenge:       Challenge([32]byte{}, nil),
 	}
 
 	witness, err := frontend.NewWitness(assignment, ecc.BN254.ScalarField())
@@ -259,8 +308,10 @@ func TestRevocationTokenProof_FalseEpoch(t *testing.T) {
 		VrfPublicKey:    vrfKey.Pk,
 		IssuerPubKey:    icIssuerPublicKey,
 		CredSignature:   icCredSigInCircuit,
+		ValidUntil:      0,
 		RevocationToken: token,
 		Epoch:           epoch,
+		Challenge:       Challenge([32]byte{}, nil),
 	}
 
 	witness, err := frontend.NewWitness(assignment, ecc.BN254.ScalarField())
@@ -271,6 +322,61 @@ func TestRevocationTokenProof_FalseEpoch(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestRevocationTokenProof_ChallengeBound(t *testing.T) {
+	issuerSecretKey, err := bn254eddsa.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	vrfKey, err := EddsaForCircuitKeyGen()
+	require.NoError(t, err)
+	msgHash, err := HashEddsaPublicKey(vrfKey.Pk)
+	require.NoError(t, err)
+	cred, err := issuerSecretKey.Sign(msgHash, mimc.NewMiMC())
+	require.NoError(t, err)
+	token, epoch, err := GenCurrentRevocationToken(vrfKey.Sk)
+	require.NoError(t, err)
+
+	icCredSigInCircuit := eddsaInCicuit.Signature{}
+	icCredSigInCircuit.Assign(tedwards.BN254, cred)
+
+	var circuit RevocationTokenProof
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &circuit)
+	require.NoError(t, err)
+	pk, vk, err := groth16.Setup(ccs)
+	require.NoError(t, err)
+
+	assignment := &RevocationTokenProof{
+		VrfSecretKey:    vrfKey.Sk,
+		VrfPublicKey:    vrfKey.Pk,
+		IssuerPubKey:    eddsaInCicuit.PublicKey{A: twistededwards.Point{X: issuerSecretKey.PublicKey.A.X, Y: issuerSecretKey.PublicKey.A.Y}},
+		CredSignature:   icCredSigInCircuit,
+		ValidUntil:      0,
+		RevocationToken: token,
+		Epoch:           epoch,
+		Challenge:       Challenge([32]byte{1}, []byte("verifier A")),
+	}
+	fullWitness, err := frontend.NewWitness(assignment, ecc.BN254.ScalarField())
+	require.NoError(t, err)
+	proof, err := groth16.Prove(ccs, pk, fullWitness)
+	require.NoError(t, err)
+
+	publicWitness, err := fullWitness.Public()
+	require.NoError(t, err)
+	require.NoError(t, groth16.Verify(proof, vk, publicWitness))
+
+	// The proof does not verify for another verifier's challenge.
+	assignment.Challenge = Challenge([32]byte{1}, []byte("verifier B"))
+	otherWitness, err := frontend.NewWitness(assignment, ecc.BN254.ScalarField(), frontend.PublicOnly())
+	require.NoError(t, err)
+	require.Error(t, groth16.Verify(proof, vk, otherWitness))
+}
+
+func TestChallenge(t *testing.T) {
+	nonce := [32]byte{1}
+	require.Equal(t, Challenge(nonce, []byte("verifier")), Challenge(nonce, []byte("verifier")))
+	require.NotEqual(t, Challenge(nonce, []byte("verifier")), Challenge([32]byte{2}, []byte("verifier")))
+	require.NotEqual(t, Challenge(nonce, []byte("verifier")), Challenge(nonce, []byte("other")))
+	require.Equal(t, -1, Challenge(nonce, nil).Cmp(ecc.BN254.ScalarField()))
+}
+
 func BenchmarkRevocationTokenProof_ConstraintCount(b *testing.B) {
 	if b.N == 1 {
 		var circuit RevocationTokenProof
@@ -299,8 +405,10 @@ func BenchmarkRevocationTokenProof_Prove(b *testing.B) {
 		VrfPublicKey:    vrfKey.Pk,
 		IssuerPubKey:    icIssuerPublicKey,
 		CredSignature:   icCredSigInCircuit,
+		ValidUntil:      0,
 		RevocationToken: token,
 		Epoch:           epoch,
+		Challenge:       Challenge([32]byte{}, nil),
 	}
 
 	var circuit RevocationTokenProof
@@ -340,8 +448,10 @@ func BenchmarkRevocationTokenProof_Verify(b *testing.B) {
 		VrfPublicKey:    vrfKey.Pk,
 		IssuerPubKey:    icIssuerPublicKey,
 		CredSignature:   icCredSigInCircuit,
+		ValidUntil:      0,
 		RevocationToken: token,
 		Epoch:           epoch,
+		Challenge:       Challenge([32]byte{}, nil),
 	}
 
 	var circuit RevocationTokenProof
@@ -0,0 +1,168 @@
+package zkp
+
+import (
+	"PrivacyPreservingRevocationCode/bloom"
+	"errors"
+	"fmt"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/mimc"
+	"github.com/consensys/gnark/frontend"
+	"math/big"
+)
+
+// CascadeCommitment returns the root committing to a cascade for NonRevocationProof circuits of the given shape:
+//
+//	root = MiMC(n, c_0, ..., c_{L-1}), c_j = MiMC(m_j, k_j, seed_j, merkleRoot_j)
+//
+// where n is the number of layers, c_j is zero for j >= n and merkleRoot_j is the root of the MiMC Merkle tree over
+// the 64-bit words of layer j, padded with zero words to 2^Depth leaves.
+func CascadeCommitment(cascade *bloom.BloomFilterCascade, shape CascadeShape) (*big.Int, error) {
+	trees, err := cascadeTrees(cascade, shape)
+	if err != nil {
+		return nil, err
+	}
+	filters := cascade.GetFilters()
+
+	elements := make([]fr.Element, 1+shape.Layers)
+	elements[0].SetUint64(uint64(len(filters)))
+	for j, f := range filters {
+		elements[1+j] = layerCommitment(f, trees[j].root())
+	}
+	root := hashFr(elements...)
+	return root.BigInt(new(big.Int)), nil
+}
+
+// CascadeOpening returns the LayerCount and Layers assignment of a NonRevocationProof of the given shape that opens
+// the cascade at the hash locations of token. Layers beyond the cascade are padded with empty one bit layers.
+func CascadeOpening(cascade *bloom.BloomFilterCascade, shape CascadeShape, token []byte) (layerCount int, layers []CascadeLayerOpening, err error) {
+	trees, err := cascadeTrees(cascade, shape)
+	if err != nil {
+		return 0, nil, err
+	}
+	filters := cascade.GetFilters()
+
+	layers = NewNonRevocationProof(shape).Layers
+	empty := newMerkleTree(nil, shape.Depth)
+	for j := range layers {
+		var locs []uint
+		tree := empty
+		if j < len(filters) {
+			f := filters[j]
+			// Hash functions beyond the layer's k are opened as well; the circuit computes but ignores them.
+			locs = f.HashLocations(token, uint(shape.K))
+			tree = trees[j]
+			layers[j].BitLen, layers[j].K, layers[j].Seed = f.BitLen(), f.K(), f.Seed()
+		} else {
+			locs = make([]uint, shape.K)
+			layers[j].BitLen, layers[j].K, layers[j].Seed = 1, 0, 0
+		}
+
+		for i, loc := range locs {
+			layers[j].Words[i], layers[j].Paths[i] = tree.open(loc / 64)
+		}
+	}
+	return len(filters), layers, nil
+}
+
+// cascadeTrees checks that the cascade fits the shape and returns the Merkle trees of its layers.
+func cascadeTrees(cascade *bloom.BloomFilterCascade, shape CascadeShape) ([]*merkleTree, error) {
+	if err := shape.Validate(); err != nil {
+		return nil, err
+	}
+	if cascade.Hasher().ID() != bloom.MiMCBN254ID {
+		return nil, fmt.Errorf("cascade hashes with %s, not %s", cascade.Hasher().ID(), bloom.MiMCBN254ID)
+	}
+	if cascade.LayerKind() != bloom.BloomLayers {
+		return nil, fmt.Errorf("cascade has %s layers, not %s", cascade.LayerKind(), bloom.BloomLayers)
+	}
+	filters := cascade.GetFilters()
+	if len(filters) == 0 {
+		return nil, errors.New("cascade has no layers")
+	}
+	if len(filters) > shape.Layers {
+		return nil, fmt.Errorf("cascade has %d layers, shape allows %d", len(filters), shape.Layers)
+	}
+
+	trees := make([]*merkleTree, len(filters))
+	for j, f := range filters {
+		if f.K() > uint(shape.K) {
+			return nil, fmt.Errorf("layer %d: %d hash functions, shape allows %d", j, f.K(), shape.K)
+		}
+		if f.BitLen() > uint(64)<<shape.Depth {
+			return nil, fmt.Errorf("layer %d: %d bits, shape allows %d", j, f.BitLen(), uint(64)<<shape.Depth)
+		}
+		trees[j] = newMerkleTree(f.BitSet().Words(), shape.Depth)
+	}
+	return trees, nil
+}
+
+// layerCommitment returns MiMC(m, k, seed, merkleRoot) of a layer.
+func layerCommitment(f *bloom.BloomFilter, merkleRoot fr.Element) fr.Element {
+	var m, k, seed fr.Element
+	m.SetUint64(uint64(f.BitLen()))
+	k.SetUint64(uint64(f.K()))
+	seed.SetUint64(f.Seed())
+	return hashFr(m, k, seed, merkleRoot)
+}
+
+// merkleTree is a MiMC Merkle tree over 64-bit words, padded with zero words to 2^depth leaves. Only the levels'
+// prefixes covering the words are stored; the remaining nodes are the roots of empty subtrees.
+type merkleTree struct {
+	levels [][]fr.Element // levels holds the stored nodes of each level, leaves first.
+	empty  []fr.Element   // empty holds the root of an empty subtree of each level.
+}
+
+// newMerkleTree builds the Merkle tree of depth over words.
+func newMerkleTree(words []uint64, depth int) *merkleTree {
+	t := &merkleTree{levels: make([][]fr.Element, depth+1), empty: make([]fr.Element, depth+1)}
+	t.levels[0] = make([]fr.Element, len(words))
+	for i, w := range words {
+		t.levels[0][i].SetUint64(w)
+	}
+	for d := 1; d <= depth; d++ {
+		t.empty[d] = hashFr(t.empty[d-1], t.empty[d-1])
+		below := t.levels[d-1]
+		level := make([]fr.Element, (len(below)+1)/2)
+		for i := range level {
+			level[i] = hashFr(t.node(d-1, uint(2*i)), t.node(d-1, uint(2*i+1)))
+		}
+		t.levels[d] = level
+	}
+	return t
+}
+
+// node returns node i of level d.
+func (t *merkleTree) node(d int, i uint) fr.Element {
+	if i < uint(len(t.levels[d])) {
+		return t.levels[d][i]
+	}
+	return t.empty[d]
+}
+
+// root returns the root of the tree.
+func (t *merkleTree) root() fr.Element {
+	return t.node(len(t.levels)-1, 0)
+}
+
+// open returns leaf i and its siblings from the leaf level up.
+func (t *merkleTree) open(i uint) (leaf frontend.Variable, path []frontend.Variable) {
+	leaf = t.node(0, i)
+	path = make([]frontend.Variable, len(t.levels)-1)
+	for d := range path {
+		path[d] = t.node(d, i^1)
+		i >>= 1
+	}
+	return leaf, path
+}
+
+// hashFr returns the MiMC hash of the given field elements, as hashElements does in the circuit.
+func hashFr(elements ...fr.Element) fr.Element {
+	h := mimc.NewMiMC()
+	for _, e := range elements {
+		b := e.Bytes()
+		_, _ = h.Write(b[:]) // canonical field elements are always accepted
+	}
+	var sum fr.Element
+	sum.SetBytes(h.Sum(nil))
+	return sum
+}
@@ -20,7 +20,7 @@ func (p *CredProof) Define(api frontend.API) error {
 		return err
 	}
 
-	return assertSignaturePk(api, curve, p.CredSignature, p.VrfPublicKey, p.IssuerPubKey)
+	return assertSignaturePk(api, curve, p.CredSignature, p.VrfPublicKey, 0, p.IssuerPubKey)
 }
 
 type VrfKeyPairProof struct {
@@ -58,15 +58,24 @@ func assertKeyPair(api frontend.API, curve twistededwards.Curve, secretKey front
 }
 
 // assertSignaturePk verifies a signature against a public key message and an issuer public key using the provided curve.
-// It uses the MiMC hash function and checks the validity of the signature using the eddsa.Verify method.
-func assertSignaturePk(api frontend.API, curve twistededwards.Curve, signature eddsa.Signature, publicKeyMessage, issuerPublicKey eddsa.PublicKey) error {
+// It uses the MiMC hash function and checks the validity of the signature using the eddsa.Verify method. Credentials
+// with a validity bound are signed on the hash of the public key and the bound, see CredentialMessage; a bound of 0
+// denotes an unbounded credential signed on the public key hash alone.
+func assertSignaturePk(api frontend.API, curve twistededwards.Curve, signature eddsa.Signature, publicKeyMessage eddsa.PublicKey, validUntil frontend.Variable, issuerPublicKey eddsa.PublicKey) error {
 	h, err := mimc.NewMiMC(api)
 	if err != nil {
 		return err
 	}
 	h.Write(publicKeyMessage.A.X)
 	h.Write(publicKeyMessage.A.Y)
-	msg := h.Sum()
+	pkHash := h.Sum()
+
+	hb, err := mimc.NewMiMC(api)
+	if err != nil {
+		return err
+	}
+	hb.Write(pkHash, validUntil)
+	msg := api.Select(api.IsZero(validUntil), pkHash, hb.Sum())
 
 	hashsig, err := mimc.NewMiMC(api)
 	if err != nil {
@@ -75,6 +84,14 @@ func assertSignaturePk(api frontend.API, curve twistededwards.Curve, signature e
 	return eddsa.Verify(curve, signature, msg, issuerPublicKey, &hashsig)
 }
 
+// assertValidity ensures that epoch does not exceed the validity bound of a credential, unless the bound is 0. Both
+// are unix times, so the difference of a valid credential fits into 64 bits while that of an expired one wraps around
+// the field.
+func assertValidity(api frontend.API, validUntil, epoch frontend.Variable) {
+	bound := api.Select(api.IsZero(validUntil), epoch, validUntil)
+	api.ToBinary(api.Sub(bound, epoch), 64)
+}
+
 // assertRevocationToken ensures the validity of a revocation token by comparing it with a computed hash using MiMC.
 func assertRevocationToken(api frontend.API, epoch, secretKey, revocationToken frontend.Variable) error {
 	expectedToken, err := mimc.NewMiMC(api)
@@ -86,3 +103,9 @@ func assertRevocationToken(api frontend.API, epoch, secretKey, revocationToken f
 	api.AssertIsEqual(revocationToken, expectedToken.Sum())
 	return nil
 }
+
+// bindPublicInput constrains a public input that no other constraint uses. Groth16 proofs do not depend on such
+// inputs, so without the constraint a proof would verify for any value of v.
+func bindPublicInput(api frontend.API, v frontend.Variable) {
+	api.Mul(v, v)
+}
@@ -0,0 +1,281 @@
+package zkp
+
+import (
+	"errors"
+	"fmt"
+	"github.com/consensys/gnark-crypto/ecc"
+	tedwards "github.com/consensys/gnark-crypto/ecc/twistededwards"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/constraint/solver"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/std/algebra/native/twistededwards"
+	"github.com/consensys/gnark/std/hash/mimc"
+	"github.com/consensys/gnark/std/selector"
+	"github.com/consensys/gnark/std/signature/eddsa"
+	"io"
+	"math/big"
+	mathbits "math/bits"
+)
+
+func init() {
+	solver.RegisterHint(divModHint)
+}
+
+// CascadeShape bounds the cascades a NonRevocationProof can open. The circuit, and therefore the proving and
+// verifying keys, as well as the cascade commitment depend on the shape.
+type CascadeShape struct {
+	Layers int // Layers is the maximum number of cascade layers.
+	K      int // K is the maximum number of hash functions of a layer.
+	Depth  int // Depth is the depth of the Merkle tree over the 64-bit words of a layer, i.e. layers hold at most 64*2^Depth bits.
+}
+
+// DefaultCascadeShape fits cascades of up to 12 layers with at most 8 hash functions and 2^20 bits per layer. The
+// first layer of a cascade needs at most 8 hash functions if at least 0.5% of the credentials are revoked.
+var DefaultCascadeShape = CascadeShape{Layers: 12, K: 8, Depth: 14}
+
+// Validate checks that the shape describes a circuit.
+func (s CascadeShape) Validate() error {
+	if s.Layers < 1 || s.K < 1 || s.Depth < 0 {
+		return fmt.Errorf("invalid cascade shape %+v", s)
+	}
+	if s.Depth > 57 {
+		return errors.New("cascade shape depth exceeds 64-bit bit lengths")
+	}
+	return nil
+}
+
+// CascadeLayerOpening opens one committed cascade layer at the hash locations of the revocation token.
+type CascadeLayerOpening struct {
+	BitLen frontend.Variable     // BitLen is the number of bits m of the layer.
+	K      frontend.Variable     // K is the number of hash functions of the layer.
+	Seed   frontend.Variable     // Seed is the hash seed of the layer.
+	Words  []frontend.Variable   // Words holds, for each of the shape's K hash functions, the word containing its location.
+	Paths  [][]frontend.Variable // Paths holds, for each word, its Merkle siblings from the leaf level up.
+}
+
+// NonRevocationProof proves, for a MultiShow credential of the issuer, that the revocation token of the epoch is not
+// accepted by the cascade committed to by CascadeRoot. Unlike RevocationTokenProof, the token stays private.
+// Like RevocationTokenProof, the proof is bound to the Challenge of the verifier it is presented to.
+// The cascade must hash with bloom.MiMCBN254; see CascadeCommitment for the commitment and CascadeOpening for the
+// assignment of LayerCount and Layers.
+type NonRevocationProof struct {
+	VrfSecretKey  frontend.Variable
+	VrfPublicKey  eddsa.PublicKey       // VRF Public Key, i.e. single Credential Attribute
+	CredSignature eddsa.Signature       // Signature on VrfPublicKey and ValidUntil by IssuerPubKey
+	ValidUntil    frontend.Variable     // Last epoch the credential is valid for, or 0 if it is unbounded
+	LayerCount    frontend.Variable     // Number of layers of the committed cascade
+	Layers        []CascadeLayerOpening // Openings of all layers, padded to the shape
+
+	IssuerPubKey eddsa.PublicKey   `gnark:",public"` // Issuer Public Key
+	CascadeRoot  frontend.Variable `gnark:",public"` // Commitment to the revocation cascade
+	Epoch        frontend.Variable `gnark:",public"` // Epoch of the Revocation Token and cascade
+	Challenge    frontend.Variable `gnark:",public"` // Challenge of the verifier the proof is presented to, see Challenge
+}
+
+// NewNonRevocationProof allocates a NonRevocationProof of the given shape, for compilation or assignment.
+func NewNonRevocationProof(shape CascadeShape) *NonRevocationProof {
+	layers := make([]CascadeLayerOpening, shape.Layers)
+	for j := range layers {
+		layers[j].Words = make([]frontend.Variable, shape.K)
+		layers[j].Paths = make([][]frontend.Variable, shape.K)
+		for i := range layers[j].Paths {
+			layers[j].Paths[i] = make([]frontend.Variable, shape.Depth)
+		}
+	}
+	return &NonRevocationProof{Layers: layers}
+}
+
+// CompileNonRevocation compiles the NonRevocationProof circuit of the given shape.
+func CompileNonRevocation(shape CascadeShape) (constraint.ConstraintSystem, error) {
+	if err := shape.Validate(); err != nil {
+		return nil, err
+	}
+	return frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, NewNonRevocationProof(shape))
+}
+
+// SetupNonRevocation runs a Groth16 setup of the NonRevocationProof circuit of the given shape and writes the raw
+// proving key, the raw verifying key and the Solidity verifier contract of the verifying key. The setup samples its
+// toxic waste locally, so its keys are only suited for testing.
+func SetupNonRevocation(shape CascadeShape, pkOut, vkOut, solOut io.Writer) error {
+	ccs, err := CompileNonRevocation(shape)
+	if err != nil {
+		return err
+	}
+	pk, vk, err := groth16.Setup(ccs)
+	if err != nil {
+		return err
+	}
+	_, err = pk.WriteRawTo(pkOut)
+	if err != nil {
+		return err
+	}
+	_, err = vk.WriteRawTo(vkOut)
+	if err != nil {
+		return err
+	}
+	return vk.ExportSolidity(solOut)
+}
+
+// Define implements frontend.Circuit interface.
+func (p *NonRevocationProof) Define(api frontend.API) error {
+	if len(p.Layers) == 0 || len(p.Layers[0].Words) == 0 {
+		return errors.New("circuit must be allocated with NewNonRevocationProof")
+	}
+	shape := CascadeShape{Layers: len(p.Layers), K: len(p.Layers[0].Words), Depth: len(p.Layers[0].Paths[0])}
+
+	curve, err := twistededwards.NewEdCurve(api, tedwards.BN254)
+	if err != nil {
+		return err
+	}
+	err = assertKeyPair(api, curve, p.VrfSecretKey, p.VrfPublicKey)
+	if err != nil {
+		return err
+	}
+	err = assertSignaturePk(api, curve, p.CredSignature, p.VrfPublicKey, p.ValidUntil, p.IssuerPubKey)
+	if err != nil {
+		return err
+	}
+	assertValidity(api, p.ValidUntil, p.Epoch)
+
+	token, err := hashElements(api, p.Epoch, p.VrfSecretKey)
+	if err != nil {
+		return err
+	}
+
+	active := lessThanMask(api, p.LayerCount, shape.Layers)
+	api.AssertIsEqual(active[0], 1)
+
+	commitments := []frontend.Variable{p.LayerCount}
+	decided, accepted := frontend.Variable(0), frontend.Variable(0)
+	for j, layer := range p.Layers {
+		match, commitment, err := openLayer(api, shape, layer, token)
+		if err != nil {
+			return err
+		}
+		commitments = append(commitments, api.Mul(active[j], commitment))
+
+		// Classify the token like bloom.BloomFilterCascade.Test: the last layer decides by its match, any other
+		// layer decides if the token does not match it.
+		last := active[j]
+		if j+1 < shape.Layers {
+			last = api.Sub(active[j], active[j+1])
+		}
+		inner := api.Sub(active[j], last)
+		var lastAccepts, innerAccepts frontend.Variable = match, 0
+		if j%2 == 1 {
+			lastAccepts, innerAccepts = api.Sub(1, match), 1
+		}
+		decides := api.Mul(api.Sub(1, decided), api.Add(last, api.Mul(inner, api.Sub(1, match))))
+		accepts := api.Add(api.Mul(last, lastAccepts), api.Mul(inner, innerAccepts))
+		accepted = api.Add(accepted, api.Mul(decides, accepts))
+		decided = api.Add(decided, decides)
+	}
+	api.AssertIsEqual(decided, 1)
+	api.AssertIsEqual(accepted, 0)
+
+	root, err := hashElements(api, commitments...)
+	if err != nil {
+		return err
+	}
+	api.AssertIsEqual(root, p.CascadeRoot)
+
+	bindPublicInput(api, p.Challenge)
+	return nil
+}
+
+// openLayer checks the Merkle openings of a layer at the hash locations of token. It returns whether the token
+// matches the layer and the layer commitment MiMC(BitLen, K, Seed, root).
+func openLayer(api frontend.API, shape CascadeShape, layer CascadeLayerOpening, token frontend.Variable) (match, commitment frontend.Variable, err error) {
+	locBits := 6 + shape.Depth
+	api.ToBinary(layer.BitLen, locBits+1)
+	used := lessThanMask(api, layer.K, shape.K)
+
+	digest, err := hashElements(api, token, layer.Seed)
+	if err != nil {
+		return nil, nil, err
+	}
+	h := baseHashes(api, digest)
+
+	match = 1
+	var root frontend.Variable
+	for i := 0; i < shape.K; i++ {
+		// location(h, i) = h[i%2] + i*h[2+((i+i%2)%4)/2] mod 2^64, reduced modulo the bit length
+		raw := api.Add(h[i%2], api.Mul(i, h[2+((i+i%2)%4)/2]))
+		loc := api.FromBinary(api.ToBinary(raw, 65+mathbits.Len(uint(i)))[:64]...)
+
+		qr, err := api.Compiler().NewHint(divModHint, 2, loc, layer.BitLen)
+		if err != nil {
+			return nil, nil, err
+		}
+		api.ToBinary(qr[0], 64)
+		api.AssertIsEqual(loc, api.Add(api.Mul(qr[0], layer.BitLen), qr[1]))
+		api.ToBinary(api.Sub(layer.BitLen, 1, qr[1]), locBits) // qr[1] < BitLen
+		bitIndex := api.ToBinary(qr[1], locBits)
+
+		wordBits := api.ToBinary(layer.Words[i], 64)
+		bit := selector.Mux(api, api.FromBinary(bitIndex[:6]...), wordBits...)
+		match = api.Mul(match, api.Sub(1, api.Mul(used[i], api.Sub(1, bit))))
+
+		node := layer.Words[i]
+		for d, sibling := range layer.Paths[i] {
+			left := api.Select(bitIndex[6+d], sibling, node)
+			right := api.Select(bitIndex[6+d], node, sibling)
+			node, err = hashElements(api, left, right)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+		if i == 0 {
+			root = node
+		} else {
+			api.AssertIsEqual(node, root)
+		}
+	}
+
+	commitment, err = hashElements(api, layer.BitLen, layer.K, layer.Seed, root)
+	return match, commitment, err
+}
+
+// baseHashes splits a digest into the four 64-bit words of its 32 byte big-endian encoding, as bloom does.
+func baseHashes(api frontend.API, digest frontend.Variable) [4]frontend.Variable {
+	bits := api.ToBinary(digest, api.Compiler().FieldBitLen())
+	return [4]frontend.Variable{
+		api.FromBinary(bits[192:]...),
+		api.FromBinary(bits[128:192]...),
+		api.FromBinary(bits[64:128]...),
+		api.FromBinary(bits[:64]...),
+	}
+}
+
+// lessThanMask returns mask[i] = 1 if i < v and 0 otherwise, for i < size. It asserts 0 <= v <= size.
+func lessThanMask(api frontend.API, v frontend.Variable, size int) []frontend.Variable {
+	mask := make([]frontend.Variable, size)
+	var reached frontend.Variable = 0 // reached is 1 once v <= i
+	for i := range mask {
+		reached = api.Add(reached, api.IsZero(api.Sub(v, i)))
+		mask[i] = api.Sub(1, reached)
+	}
+	api.AssertIsEqual(api.Add(reached, api.IsZero(api.Sub(v, size))), 1)
+	return mask
+}
+
+// hashElements returns the MiMC hash of the given field elements.
+func hashElements(api frontend.API, elements ...frontend.Variable) (frontend.Variable, error) {
+	h, err := mimc.NewMiMC(api)
+	if err != nil {
+		return nil, err
+	}
+	h.Write(elements...)
+	return h.Sum(), nil
+}
+
+// divModHint computes the quotient and remainder of inputs[0] divided by inputs[1].
+func divModHint(_ *big.Int, inputs []*big.Int, outputs []*big.Int) error {
+	if inputs[1].Sign() == 0 {
+		return errors.New("division by zero")
+	}
+	outputs[0].DivMod(inputs[0], inputs[1], outputs[1])
+	return nil
+}
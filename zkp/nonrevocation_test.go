@@ -0,0 +1,158 @@
+package zkp
+
+import (
+	"PrivacyPreservingRevocationCode/bloom"
+	"bytes"
+	"crypto/rand"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/mimc"
+	bn254eddsa "github.com/consensys/gnark-crypto/ecc/bn254/twistededwards/eddsa"
+	tedwards "github.com/consensys/gnark-crypto/ecc/twistededwards"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/std/algebra/native/twistededwards"
+	eddsaInCicuit "github.com/consensys/gnark/std/signature/eddsa"
+	"github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"testing"
+	"time"
+)
+
+// testCascadeShape fits the cascades of nonRevocationAssignment.
+var testCascadeShape = CascadeShape{Layers: 16, K: 4, Depth: 3}
+
+// nonRevocationAssignment issues a credential, builds a MiMC cascade for the current epoch in which the credential's
+// token is revoked or valid, and returns the cascade with the assignment of a NonRevocationProof for it.
+func nonRevocationAssignment(t *testing.T, shape CascadeShape, revoked bool) (*bloom.BloomFilterCascade, *NonRevocationProof) {
+	issuerSecretKey, err := bn254eddsa.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	vrfKey, err := EddsaForCircuitKeyGen()
+	require.NoError(t, err)
+
+	msgHash, err := HashEddsaPublicKey(vrfKey.Pk)
+	require.NoError(t, err)
+	cred, err := issuerSecretKey.Sign(msgHash, mimc.NewMiMC())
+	require.NoError(t, err)
+
+	epochUnix := time.Now().UTC().Unix()
+	token, _, err := GenRevocationToken(vrfKey.Sk, epochUnix)
+	require.NoError(t, err)
+	tokenBytes := token.FillBytes(make([]byte, fr.Bytes))
+
+	var valid, revokedTokens [][]byte
+	for i := 0; i < 150; i++ {
+		var e fr.Element
+		_, err := e.SetRandom()
+		require.NoError(t, err)
+		b := e.Bytes()
+		if i < 15 {
+			revokedTokens = append(revokedTokens, b[:])
+		} else {
+			valid = append(valid, b[:])
+		}
+	}
+	if revoked {
+		revokedTokens = append(revokedTokens, tokenBytes)
+	} else {
+		valid = append(valid, tokenBytes)
+	}
+	cascade := bloom.NewCascadeWithHasher(len(valid)+len(revokedTokens), len(revokedTokens), bloom.MiMCBN254)
+	require.NoError(t, cascade.Update(revokedTokens, valid))
+	accepted, _ := cascade.Test(tokenBytes)
+	require.Equal(t, revoked, accepted)
+
+	root, err := CascadeCommitment(cascade, shape)
+	require.NoError(t, err)
+	layerCount, layers, err := CascadeOpening(cascade, shape, tokenBytes)
+	require.NoError(t, err)
+
+	signature := eddsaInCicuit.Signature{}
+	signature.Assign(tedwards.BN254, cred)
+
+	return cascade, &NonRevocationProof{
+		VrfSecretKey:  vrfKey.Sk,
+		VrfPublicKey:  vrfKey.Pk,
+		CredSignature: signature,
+		ValidUntil:    0,
+		LayerCount:    layerCount,
+		Layers:        layers,
+		IssuerPubKey:  eddsaInCicuit.PublicKey{A: twistededwards.Point{X: issuerSecretKey.PublicKey.A.X, Y: issuerSecretKey.PublicKey.A.Y}},
+		CascadeRoot:   root,
+		Epoch:         epochUnix,
+		Challenge:     Challenge([32]byte{1}, []byte("verifier")),
+	}
+}
+
+func TestNonRevocationProof_Valid(t *testing.T) {
+	_, assignment := nonRevocationAssignment(t, testCascadeShape, false)
+	require.NoError(t, test.IsSolved(NewNonRevocationProof(testCascadeShape), assignment, ecc.BN254.ScalarField()))
+
+	// The proof is bound to the committed cascade.
+	assignment.CascadeRoot = new(big.Int).Add(assignment.CascadeRoot.(*big.Int), big.NewInt(1))
+	require.Error(t, test.IsSolved(NewNonRevocationProof(testCascadeShape), assignment, ecc.BN254.ScalarField()))
+}
+
+func TestNonRevocationProof_Revoked(t *testing.T) {
+	_, assignment := nonRevocationAssignment(t, testCascadeShape, true)
+	require.Error(t, test.IsSolved(NewNonRevocationProof(testCascadeShape), assignment, ecc.BN254.ScalarField()))
+}
+
+func TestNonRevocationProof_ForgedOpening(t *testing.T) {
+	_, assignment := nonRevocationAssignment(t, testCascadeShape, true)
+
+	// Hiding the last layers of the cascade changes the committed layer count.
+	assignment.LayerCount = 1
+	require.Error(t, test.IsSolved(NewNonRevocationProof(testCascadeShape), assignment, ecc.BN254.ScalarField()))
+
+	// Clearing the bits of a revoked token's words breaks the Merkle openings.
+	_, assignment = nonRevocationAssignment(t, testCascadeShape, true)
+	for i := range assignment.Layers[0].Words {
+		assignment.Layers[0].Words[i] = 0
+	}
+	require.Error(t, test.IsSolved(NewNonRevocationProof(testCascadeShape), assignment, ecc.BN254.ScalarField()))
+}
+
+func TestCascadeCommitment(t *testing.T) {
+	cascade, _ := nonRevocationAssignment(t, testCascadeShape, false)
+
+	root, err := CascadeCommitment(cascade, testCascadeShape)
+	require.NoError(t, err)
+	again, err := CascadeCommitment(cascade, testCascadeShape)
+	require.NoError(t, err)
+	require.Equal(t, root, again)
+
+	// The commitment depends on the shape.
+	deeper := testCascadeShape
+	deeper.Depth++
+	other, err := CascadeCommitment(cascade, deeper)
+	require.NoError(t, err)
+	require.NotEqual(t, root, other)
+
+	// Cascades must hash with MiMC, consist of Bloom layers and fit the shape.
+	_, err = CascadeCommitment(bloom.NewCascade(100, 10), testCascadeShape)
+	require.Error(t, err)
+	fuse, err := bloom.NewCascadeWithLayers(100, 10, bloom.BinaryFuseLayers, bloom.MiMCBN254)
+	require.NoError(t, err)
+	_, err = CascadeCommitment(fuse, testCascadeShape)
+	require.ErrorContains(t, err, "binary-fuse")
+	_, err = CascadeCommitment(cascade, CascadeShape{Layers: 16, K: 4, Depth: 0})
+	require.Error(t, err)
+	_, err = CascadeCommitment(cascade, CascadeShape{Layers: 1, K: 4, Depth: 3})
+	require.Error(t, err)
+}
+
+func TestNonRevocationProof_ExportSolidity(t *testing.T) {
+	var pk, vk, sol bytes.Buffer
+	require.NoError(t, SetupNonRevocation(CascadeShape{Layers: 2, K: 1, Depth: 0}, &pk, &vk, &sol))
+	require.NotZero(t, pk.Len())
+	require.NotZero(t, vk.Len())
+	require.Contains(t, sol.String(), "function verifyProof")
+}
+
+func TestNonRevocationProof_Compile(t *testing.T) {
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, NewNonRevocationProof(DefaultCascadeShape))
+	require.NoError(t, err)
+	t.Logf("default shape: %d constraints", ccs.GetNbConstraints())
+}
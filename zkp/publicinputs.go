@@ -0,0 +1,98 @@
+package zkp
+
+import (
+	"errors"
+	"fmt"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/native/twistededwards"
+	"github.com/consensys/gnark/std/signature/eddsa"
+	"math/big"
+)
+
+// PublicInputs are the public inputs of the RevocationTokenProof circuit. Encode orders them like the Solidity
+// verifiers: [issuerX, issuerY, token, epoch, challenge].
+type PublicInputs struct {
+	IssuerX         *big.Int // IssuerX is the x-coordinate of the issuer's public key.
+	IssuerY         *big.Int // IssuerY is the y-coordinate of the issuer's public key.
+	RevocationToken *big.Int // RevocationToken is the revocation token of the credential for the epoch.
+	Epoch           int64    // Epoch is the unix time of the epoch, assigned to the circuit as its 8 big-endian bytes.
+	Challenge       *big.Int // Challenge binds the proof to a verifier and its nonce, see Challenge.
+}
+
+// NewPublicInputs reads the public inputs from an assignment of the RevocationTokenProof circuit, with each value
+// reduced into the scalar field like in the witness.
+func NewPublicInputs(assignment *RevocationTokenProof) (*PublicInputs, error) {
+	var values [5]*big.Int
+	for i, v := range []frontend.Variable{
+		assignment.IssuerPubKey.A.X,
+		assignment.IssuerPubKey.A.Y,
+		assignment.RevocationToken,
+		assignment.Epoch,
+		assignment.Challenge,
+	} {
+		var e fr.Element
+		_, err := e.SetInterface(v)
+		if err != nil {
+			return nil, fmt.Errorf("public input %d: %w", i, err)
+		}
+		values[i] = e.BigInt(new(big.Int))
+	}
+	return DecodePublicInputs(values)
+}
+
+// DecodePublicInputs decodes public inputs in the order of Encode. All values must be in the scalar field, as the
+// Solidity verifiers reject others, and the epoch must fit in 8 bytes.
+func DecodePublicInputs(values [5]*big.Int) (*PublicInputs, error) {
+	for _, v := range values {
+		if !inScalarField(v) {
+			return nil, errors.New("public input out of range")
+		}
+	}
+	if !values[3].IsUint64() {
+		return nil, errors.New("epoch out of range")
+	}
+	return &PublicInputs{
+		IssuerX:         new(big.Int).Set(values[0]),
+		IssuerY:         new(big.Int).Set(values[1]),
+		RevocationToken: new(big.Int).Set(values[2]),
+		Epoch:           int64(values[3].Uint64()),
+		Challenge:       new(big.Int).Set(values[4]),
+	}, nil
+}
+
+// Encode returns the public inputs, which must all be set, in the order the Solidity verifiers expect them.
+func (p *PublicInputs) Encode() [5]*big.Int {
+	return [5]*big.Int{
+		new(big.Int).Set(p.IssuerX),
+		new(big.Int).Set(p.IssuerY),
+		new(big.Int).Set(p.RevocationToken),
+		new(big.Int).SetUint64(uint64(p.Epoch)),
+		new(big.Int).Set(p.Challenge),
+	}
+}
+
+// Witness returns the public witness of the RevocationTokenProof circuit for the public inputs, which proofs are
+// verified against.
+func (p *PublicInputs) Witness() (witness.Witness, error) {
+	// gnark would reduce values outside the scalar field instead of rejecting them like the Solidity verifiers.
+	for _, v := range []*big.Int{p.IssuerX, p.IssuerY, p.RevocationToken, p.Challenge} {
+		if !inScalarField(v) {
+			return nil, errors.New("public input out of range")
+		}
+	}
+	assignment := &RevocationTokenProof{
+		IssuerPubKey:    eddsa.PublicKey{A: twistededwards.Point{X: p.IssuerX, Y: p.IssuerY}},
+		RevocationToken: p.RevocationToken,
+		Epoch:           uint64(p.Epoch),
+		Challenge:       p.Challenge,
+	}
+	return frontend.NewWitness(assignment, ecc.BN254.ScalarField(), frontend.PublicOnly())
+}
+
+// inScalarField returns whether v is an element of the BN254 scalar field.
+func inScalarField(v *big.Int) bool {
+	return v != nil && v.Sign() >= 0 && v.Cmp(ecc.BN254.ScalarField()) < 0
+}
@@ -0,0 +1,96 @@
+package zkp
+
+import (
+	"crypto/rand"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/mimc"
+	bn254eddsa "github.com/consensys/gnark-crypto/ecc/bn254/twistededwards/eddsa"
+	tedwards "github.com/consensys/gnark-crypto/ecc/twistededwards"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/native/twistededwards"
+	eddsaInCicuit "github.com/consensys/gnark/std/signature/eddsa"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"testing"
+)
+
+// newTestAssignment returns a full assignment of the RevocationTokenProof circuit for the epoch.
+func newTestAssignment(t *testing.T, epochUnix int64) *RevocationTokenProof {
+	issuerSecretKey, err := bn254eddsa.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	vrfKey, err := EddsaForCircuitKeyGen()
+	require.NoError(t, err)
+	msgHash, err := HashEddsaPublicKey(vrfKey.Pk)
+	require.NoError(t, err)
+	cred, err := issuerSecretKey.Sign(msgHash, mimc.NewMiMC())
+	require.NoError(t, err)
+	token, epoch, err := GenRevocationToken(vrfKey.Sk, epochUnix)
+	require.NoError(t, err)
+
+	sig := eddsaInCicuit.Signature{}
+	sig.Assign(tedwards.BN254, cred)
+	return &RevocationTokenProof{
+		VrfSecretKey:    vrfKey.Sk,
+		VrfPublicKey:    vrfKey.Pk,
+		IssuerPubKey:    eddsaInCicuit.PublicKey{A: twistededwards.Point{X: issuerSecretKey.PublicKey.A.X, Y: issuerSecretKey.PublicKey.A.Y}},
+		CredSignature:   sig,
+		ValidUntil:      0,
+		RevocationToken: token,
+		Epoch:           epoch,
+		Challenge:       Challenge([32]byte{1}, []byte("verifier")),
+	}
+}
+
+func TestPublicInputs(t *testing.T) {
+	// Epochs beyond 2^53 lose precision as float64.
+	for _, epochUnix := range []int64{1_700_000_000, 1<<62 + 1} {
+		assignment := newTestAssignment(t, epochUnix)
+		inputs, err := NewPublicInputs(assignment)
+		require.NoError(t, err)
+		require.Equal(t, epochUnix, inputs.Epoch)
+		require.Equal(t, assignment.Challenge, inputs.Challenge)
+
+		decoded, err := DecodePublicInputs(inputs.Encode())
+		require.NoError(t, err)
+		require.Equal(t, inputs, decoded)
+
+		// The public witness equals the one of the full assignment.
+		fullWitness, err := frontend.NewWitness(assignment, ecc.BN254.ScalarField())
+		require.NoError(t, err)
+		want, err := fullWitness.Public()
+		require.NoError(t, err)
+		got, err := inputs.Witness()
+		require.NoError(t, err)
+		wantBytes, err := want.MarshalBinary()
+		require.NoError(t, err)
+		gotBytes, err := got.MarshalBinary()
+		require.NoError(t, err)
+		require.Equal(t, wantBytes, gotBytes)
+	}
+}
+
+func TestPublicInputs_Invalid(t *testing.T) {
+	inputs, err := NewPublicInputs(newTestAssignment(t, 1_700_000_000))
+	require.NoError(t, err)
+
+	encoded := inputs.Encode()
+	encoded[4] = new(big.Int).Add(inputs.Challenge, ecc.BN254.ScalarField())
+	_, err = DecodePublicInputs(encoded)
+	require.Error(t, err)
+	encoded = inputs.Encode()
+	encoded[3] = new(big.Int).Lsh(big.NewInt(1), 64)
+	_, err = DecodePublicInputs(encoded)
+	require.Error(t, err)
+	encoded = inputs.Encode()
+	encoded[0] = nil
+	_, err = DecodePublicInputs(encoded)
+	require.Error(t, err)
+
+	outOfField := *inputs
+	outOfField.RevocationToken = new(big.Int).Neg(inputs.RevocationToken)
+	_, err = outOfField.Witness()
+	require.Error(t, err)
+
+	_, err = NewPublicInputs(&RevocationTokenProof{})
+	require.Error(t, err)
+}
@@ -0,0 +1,145 @@
+// SPDX-License-Identifier: MIT
+
+pragma solidity ^0.8.0;
+
+import {Verifier} from "./revocationTokenVerifier.sol";
+
+/// @title Groth16 batch verifier of the revocation token circuit.
+/// @notice Extends the exported Groth16 verifier, and thereby its verification key, by verifying many proofs with a
+/// single pairing check. The pairing equations of the proofs are combined with random weights r_i derived from all
+/// proofs and inputs (randomized linear combination), so n proofs take n + 3 pairings instead of 4n, and the public
+/// inputs of all proofs share one multi-scalar multiplication.
+contract BatchVerifier is Verifier {
+
+    /// Verify uncompressed Groth16 proofs in a batch.
+    /// @notice Reverts with PublicInputNotInField if an input is not reduced and with ProofInvalid if any proof is
+    /// invalid, without revealing which one. There is no return value. If the function does not revert, all proofs
+    /// were successfully verified.
+    /// @dev With r_i = keccak256(seed || i) mod R and seed = keccak256(abi.encode(proofs, inputs)), it checks
+    /// prod_i e(r_i A_i, B_i) * e(sum_i r_i C_i, -δ) * e((sum_i r_i) α, -β) * e(sum_i r_i L_i, -γ) = 1,
+    /// where L_i is the public input linear combination of proof i. The Go verifier derives the same weights.
+    /// @param proofs the proofs in the format of verifyProof.
+    /// @param inputs the public inputs of the proofs in the format of verifyProof.
+    function verifyProofs(
+        uint256[8][] calldata proofs,
+        uint256[5][] calldata inputs
+    ) public view {
+        uint256 n = proofs.length;
+        if (n != inputs.length) {
+            revert ProofInvalid();
+        }
+
+        bytes32 seed = keccak256(abi.encode(proofs, inputs));
+        uint256[] memory pairing = new uint256[](6 * (n + 3));
+        uint256 weightSum;           // sum_i r_i
+        uint256[5] memory inputSums; // sum_i r_i * inputs[i][j]
+        uint256 cx;                  // sum_i r_i C_i, starting at the point at infinity
+        uint256 cy;
+        for (uint256 i = 0; i < n; i++) {
+            uint256 r = uint256(keccak256(abi.encodePacked(seed, i))) % R;
+            weightSum = addmod(weightSum, r, R);
+            for (uint256 j = 0; j < 5; j++) {
+                uint256 s = inputs[i][j];
+                if (s >= R) {
+                    revert PublicInputNotInField();
+                }
+                inputSums[j] = addmod(inputSums[j], mulmod(r, s, R), R);
+            }
+
+            // e(r_i A_i, B_i), B_i is already in EIP-197 encoding.
+            uint256 o = 6 * i;
+            (pairing[o], pairing[o + 1]) = g1Mul(proofs[i][0], proofs[i][1], r);
+            pairing[o + 2] = proofs[i][2];
+            pairing[o + 3] = proofs[i][3];
+            pairing[o + 4] = proofs[i][4];
+            pairing[o + 5] = proofs[i][5];
+            (uint256 rcx, uint256 rcy) = g1Mul(proofs[i][6], proofs[i][7], r);
+            (cx, cy) = g1Add(cx, cy, rcx, rcy);
+        }
+
+        uint256 f = 6 * n;
+        pairing[f] = cx;
+        pairing[f + 1] = cy;
+        pairing[f + 2] = DELTA_NEG_X_1;
+        pairing[f + 3] = DELTA_NEG_X_0;
+        pairing[f + 4] = DELTA_NEG_Y_1;
+        pairing[f + 5] = DELTA_NEG_Y_0;
+        (pairing[f + 6], pairing[f + 7]) = g1Mul(ALPHA_X, ALPHA_Y, weightSum);
+        pairing[f + 8] = BETA_NEG_X_1;
+        pairing[f + 9] = BETA_NEG_X_0;
+        pairing[f + 10] = BETA_NEG_Y_1;
+        pairing[f + 11] = BETA_NEG_Y_0;
+        (pairing[f + 12], pairing[f + 13]) = weightedInputMSM(weightSum, inputSums);
+        pairing[f + 14] = GAMMA_NEG_X_1;
+        pairing[f + 15] = GAMMA_NEG_X_0;
+        pairing[f + 16] = GAMMA_NEG_Y_1;
+        pairing[f + 17] = GAMMA_NEG_Y_0;
+
+        bool success;
+        assembly ("memory-safe") {
+            success := staticcall(gas(), PRECOMPILE_VERIFY, add(pairing, 0x20), mul(mload(pairing), 0x20), 0x00, 0x20)
+            success := and(success, mload(0x00))
+        }
+        if (!success) {
+            revert ProofInvalid();
+        }
+    }
+
+    /// Compute sum_i r_i L_i from the summed weights and the weighted sums of the public inputs.
+    /// @param constantWeight the weight of the constant term, i.e. sum_i r_i.
+    /// @param weights the weight of each public input term, i.e. sum_i r_i * inputs[i][j].
+    /// @return x The X coordinate of the resulting G1 point.
+    /// @return y The Y coordinate of the resulting G1 point.
+    function weightedInputMSM(uint256 constantWeight, uint256[5] memory weights)
+    internal view returns (uint256 x, uint256 y) {
+        uint256[2][5] memory points = [
+            [PUB_0_X, PUB_0_Y],
+            [PUB_1_X, PUB_1_Y],
+            [PUB_2_X, PUB_2_Y],
+            [PUB_3_X, PUB_3_Y],
+            [PUB_4_X, PUB_4_Y]
+        ];
+        (x, y) = g1Mul(CONSTANT_X, CONSTANT_Y, constantWeight);
+        for (uint256 j = 0; j < 5; j++) {
+            (uint256 px, uint256 py) = g1Mul(points[j][0], points[j][1], weights[j]);
+            (x, y) = g1Add(x, y, px, py);
+        }
+    }
+
+    /// Multiply a G1 point by a scalar.
+    /// @notice Reverts with ProofInvalid if the point is not on the curve.
+    function g1Mul(uint256 x, uint256 y, uint256 s) internal view returns (uint256 rx, uint256 ry) {
+        bool success;
+        assembly ("memory-safe") {
+            let f := mload(0x40)
+            mstore(f, x)
+            mstore(add(f, 0x20), y)
+            mstore(add(f, 0x40), s)
+            success := staticcall(gas(), PRECOMPILE_MUL, f, 0x60, f, 0x40)
+            rx := mload(f)
+            ry := mload(add(f, 0x20))
+        }
+        if (!success) {
+            revert ProofInvalid();
+        }
+    }
+
+    /// Add two G1 points.
+    /// @notice Reverts with ProofInvalid if a point is not on the curve.
+    function g1Add(uint256 x1, uint256 y1, uint256 x2, uint256 y2) internal view returns (uint256 rx, uint256 ry) {
+        bool success;
+        assembly ("memory-safe") {
+            let f := mload(0x40)
+            mstore(f, x1)
+            mstore(add(f, 0x20), y1)
+            mstore(add(f, 0x40), x2)
+            mstore(add(f, 0x60), y2)
+            success := staticcall(gas(), PRECOMPILE_ADD, f, 0x80, f, 0x40)
+            rx := mload(f)
+            ry := mload(add(f, 0x20))
+        }
+        if (!success) {
+            revert ProofInvalid();
+        }
+    }
+}
@@ -53,38 +53,40 @@ contract Verifier {
     uint256 constant EXP_SQRT_FP = 0xC19139CB84C680A6E14116DA060561765E05AA45A1C72A34F082305B61F3F52; // (P + 1) / 4;
 
     // Groth16 alpha point in G1
-    uint256 constant ALPHA_X = 13133467198328963565476688949353594254553175265824566915220454372432690071628;
-    uint256 constant ALPHA_Y = 4382547225302414491777830753641292005088613182716475309509234686405840995269;
+    uint256 constant ALPHA_X = 20843796873605476169798435422521312546518211917118684482709865447385589027924;
+    uint256 constant ALPHA_Y = 3957713122698047908735004345333198368193247604337852498915859368009077162398;
 
     // Groth16 beta point in G2 in powers of i
-    uint256 constant BETA_NEG_X_0 = 5074974982866762038543680963381417956543972135792234852319569895697446917933;
-    uint256 constant BETA_NEG_X_1 = 13411391015965289847416703659528234227841029722451344201461632660003878220480;
-    uint256 constant BETA_NEG_Y_0 = 14756629397564688683521936294130599475061866561566252259317824848856233354227;
-    uint256 constant BETA_NEG_Y_1 = 14799500551424098929941191761645316857607793048227363342950604264969687093566;
+    uint256 constant BETA_NEG_X_0 = 20236991420193335074260447164829654281001367944719623889522956134953674572964;
+    uint256 constant BETA_NEG_X_1 = 18986286845495540727301270797815577565084117324994269141741155590393956896587;
+    uint256 constant BETA_NEG_Y_0 = 13312534587129910501590537728342034961254173712631175603127759606772396220119;
+    uint256 constant BETA_NEG_Y_1 = 10300397216013083167286969926773441745928708423461210867519713366851324607236;
 
     // Groth16 gamma point in G2 in powers of i
-    uint256 constant GAMMA_NEG_X_0 = 18610765367161251125368529265724470470768379143869670241041759048208868941348;
-    uint256 constant GAMMA_NEG_X_1 = 15957379532748037972438480932808003228511818028657075140855893862330864075362;
-    uint256 constant GAMMA_NEG_Y_0 = 16113215218768670411734317079103768744967970570407867275436812380480579016871;
-    uint256 constant GAMMA_NEG_Y_1 = 7725484890515291059202041788019893522418276440149793554044864123506109847678;
+    uint256 constant GAMMA_NEG_X_0 = 16351866176185970350180869759270511289456436392694510139098867377493670276458;
+    uint256 constant GAMMA_NEG_X_1 = 3961527111635760186084655574146513924748382157354834737980735353493448858563;
+    uint256 constant GAMMA_NEG_Y_0 = 15184246037588280501374322192368773444743688249495720184876059474072127154284;
+    uint256 constant GAMMA_NEG_Y_1 = 14099801584137703770053961048048011415714674472462922599768701540337747114918;
 
     // Groth16 delta point in G2 in powers of i
-    uint256 constant DELTA_NEG_X_0 = 5117267922365077204221974102082220106848868712202435414062681491992195111381;
-    uint256 constant DELTA_NEG_X_1 = 21183242009143500277598858147829217752039235412394482482958236894983715639714;
-    uint256 constant DELTA_NEG_Y_0 = 995532458368599714005954059547559424660722690658180028308778195518846036335;
-    uint256 constant DELTA_NEG_Y_1 = 10667438845858354038474840290069167404325964609157993786733817769085752546100;
+    uint256 constant DELTA_NEG_X_0 = 18402681959814562524949669688299964997886668291931509872439492310412549730958;
+    uint256 constant DELTA_NEG_X_1 = 9908394477468585485570082024666627483911203393138971977641319011451020354817;
+    uint256 constant DELTA_NEG_Y_0 = 16033736489065392728950671955818381972991798535247604712964974019170520710222;
+    uint256 constant DELTA_NEG_Y_1 = 9050197972014644471407177522121851745189636042813892746469231045495769779847;
 
     // Constant and public input points
-    uint256 constant CONSTANT_X = 1292710255612386967157663934452698521583648309197492317781988917109779787027;
-    uint256 constant CONSTANT_Y = 13299487907765022852112453576139524490435419361293099284794720642366234869733;
-    uint256 constant PUB_0_X = 12366244118061480236731199872373247780585741806996063913035916675705203148003;
-    uint256 constant PUB_0_Y = 11070316573465095149308977010530133812160407910521926059920616180744213263350;
-    uint256 constant PUB_1_X = 20510656157650765277801003174872185796179793079137868412491300893765265963579;
-    uint256 constant PUB_1_Y = 6181562082707395381964821096156297092379197510455467203526663107103031923717;
-    uint256 constant PUB_2_X = 13563351669567019934161435416610312652846064122811844175554475925541895522712;
-    uint256 constant PUB_2_Y = 2026359433817776107776173491388906670789565795711338314219306791264426883240;
-    uint256 constant PUB_3_X = 14248614340598158240939703807431469825473315678395405995512300718733827102848;
-    uint256 constant PUB_3_Y = 6580699138925580204138203885940471115827479258206448163913220457097172450125;
+    uint256 constant CONSTANT_X = 4950493730185648499077931480268577033807342547456937314753510353538408794370;
+    uint256 constant CONSTANT_Y = 10461244653304523847447944384511541583959477940433431717356995136651410524127;
+    uint256 constant PUB_0_X = 8286589233343254426296826021211050817298324271585145227398614357026316698354;
+    uint256 constant PUB_0_Y = 7450798629718954229788563580007976786169965086864238781483487589000504525709;
+    uint256 constant PUB_1_X = 21234731592759600627612823239157401683958278080570565061011018982446887298973;
+    uint256 constant PUB_1_Y = 11406493602172347899340597271296392441455657178598081175090691056746101947034;
+    uint256 constant PUB_2_X = 20896200301185484230810374576335364616905150615672759691009357185718930492182;
+    uint256 constant PUB_2_Y = 14434346818836043329923389151765963388875689815548554108887460425506531824160;
+    uint256 constant PUB_3_X = 7373105338403639527595528464095235607789937959984913465993409830733880635012;
+    uint256 constant PUB_3_Y = 15857639180873560437489670143497421849186279820391790803630560923443837092962;
+    uint256 constant PUB_4_X = 4069863805723356430170763093503078852449422696872781914469381034425903355425;
+    uint256 constant PUB_4_Y = 19272833068978731855049124297939719383362084614329741408043224832885407543030;
 
     /// Negation in Fp.
     /// @notice Returns a number x such that a + x = 0 in Fp.
@@ -361,7 +363,7 @@ contract Verifier {
     /// @param input The public inputs. These are elements of the scalar field Fr.
     /// @return x The X coordinate of the resulting G1 point.
     /// @return y The Y coordinate of the resulting G1 point.
-    function publicInputMSM(uint256[4] calldata input)
+    function publicInputMSM(uint256[5] calldata input)
     internal view returns (uint256 x, uint256 y) {
         // Note: The ECMUL precompile does not reject unreduced values, so we check this.
         // Note: Unrolling this loop does not cost much extra in code-size, the bulk of the
@@ -406,6 +408,13 @@ contract Verifier {
             success := and(success, lt(s, R))
             success := and(success, staticcall(gas(), PRECOMPILE_MUL, g, 0x60, g, 0x40))
             success := and(success, staticcall(gas(), PRECOMPILE_ADD, f, 0x80, f, 0x40))
+            mstore(g, PUB_4_X)
+            mstore(add(g, 0x20), PUB_4_Y)
+            s :=  calldataload(add(input, 128))
+            mstore(add(g, 0x40), s)
+            success := and(success, lt(s, R))
+            success := and(success, staticcall(gas(), PRECOMPILE_MUL, g, 0x60, g, 0x40))
+            success := and(success, staticcall(gas(), PRECOMPILE_ADD, f, 0x80, f, 0x40))
 
             x := mload(f)
             y := mload(add(f, 0x20))
@@ -442,7 +451,7 @@ contract Verifier {
     /// Elements must be reduced.
     function verifyCompressedProof(
         uint256[4] calldata compressedProof,
-        uint256[4] calldata input
+        uint256[5] calldata input
     ) public view {
         uint256[24] memory pairings;
 
@@ -509,7 +518,7 @@ contract Verifier {
     /// Elements must be reduced.
     function verifyProof(
         uint256[8] calldata proof,
-        uint256[4] calldata input
+        uint256[5] calldata input
     ) public view {
         (uint256 x, uint256 y) = publicInputMSM(input);
 
@@ -1,15 +1,16 @@
 package zkp
 
 import (
-	"encoding/binary"
+	"PrivacyPreservingRevocationCode/epoch"
+	"errors"
 	"github.com/consensys/gnark-crypto/ecc"
 	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
 	"github.com/consensys/gnark-crypto/ecc/bn254/fr/mimc"
 	bn254ted "github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
 	"github.com/consensys/gnark/std/algebra/native/twistededwards"
 	eddsaInCicuit "github.com/consensys/gnark/std/signature/eddsa"
+	"github.com/ethereum/go-ethereum/crypto"
 	"math/big"
-	"time"
 )
 
 // EddsaKeyPair represents an EdDSA key pair consisting of a secret key
@@ -39,6 +40,17 @@ func EddsaForCircuitKeyGen() (EddsaKeyPair, error) {
 	skBig := new(big.Int)
 	sk.BigInt(skBig)
 
+	return EddsaForCircuitKeyFromSecret(skBig.Bytes())
+}
+
+// EddsaForCircuitKeyFromSecret derives the circuit compliant EdDSA key pair of a secret key as generated by
+// EddsaForCircuitKeyGen. The secret key must be smaller than the BN254 scalar field modulus.
+func EddsaForCircuitKeyFromSecret(secretKey []byte) (EddsaKeyPair, error) {
+	skBig := new(big.Int).SetBytes(secretKey)
+	if skBig.Sign() == 0 || skBig.Cmp(ecc.BN254.ScalarField()) >= 0 {
+		return EddsaKeyPair{}, errors.New("secret key out of range")
+	}
+
 	// Compute public key: pk = sk × G
 	var pk bn254ted.PointAffine
 	base := bn254ted.GetEdwardsCurve().Base
@@ -46,14 +58,19 @@ func EddsaForCircuitKeyGen() (EddsaKeyPair, error) {
 
 	// Convert to in-circuit types
 	icSk := skBig
-	icPk := eddsaInCicuit.PublicKey{
+	icPk := EddsaForCircuitPublicKey(pk)
+
+	return EddsaKeyPair{Sk: icSk.Bytes(), Pk: icPk}, nil
+}
+
+// EddsaForCircuitPublicKey converts a BabyJubJub point into the circuit compliant EdDSA public key format.
+func EddsaForCircuitPublicKey(pk bn254ted.PointAffine) eddsaInCicuit.PublicKey {
+	return eddsaInCicuit.PublicKey{
 		A: twistededwards.Point{
 			X: pk.X,
 			Y: pk.Y,
 		},
 	}
-
-	return EddsaKeyPair{Sk: icSk.Bytes(), Pk: icPk}, nil
 }
 
 // HashEddsaPublicKey hashes an EdDSA public key using the MiMC hashing algorithm.
@@ -78,16 +95,48 @@ func HashEddsaPublicKey(pk eddsaInCicuit.PublicKey) ([]byte, error) {
 	return h.Sum(nil), nil
 }
 
-// GenCurrentRevocationToken generates a revocation token  Hash(epoch || sk) for the current epoch using a VRF secret key.
-// It returns the token as a big.Int, the epoch as a byte slice, and an error if any occurs during execution.
-func GenCurrentRevocationToken(vrfSecretKey []byte) (token *big.Int, epoch []byte, err error) {
-	epochUnix := time.Now().UTC().Unix()
-	epoch = make([]byte, 8)
-	binary.BigEndian.PutUint64(epoch, uint64(epochUnix))
+// CredentialMessage returns the message an issuer signs for a MultiShow credential on the VRF public key hash
+// publicKeyVrfHash that is valid up to and including the unix epoch validUntil: MiMC(publicKeyVrfHash || validUntil),
+// or publicKeyVrfHash itself for an unbounded credential with validUntil 0. RevocationTokenProof and
+// NonRevocationProof verify the credential signature on the same message.
+func CredentialMessage(publicKeyVrfHash []byte, validUntil int64) ([]byte, error) {
+	if validUntil < 0 {
+		return nil, errors.New("validity bound must not be negative")
+	}
+	if validUntil == 0 {
+		return publicKeyVrfHash, nil
+	}
+	h := mimc.NewMiMC()
+	_, err := h.Write(publicKeyVrfHash)
+	if err != nil {
+		return nil, err
+	}
+	_, err = h.Write(epoch.Bytes(validUntil))
+	if err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// GenCurrentRevocationToken generates a revocation token  Hash(epoch || sk) for the current epoch of epoch.Default
+// using a VRF secret key. It returns the token as a big.Int, the epoch as a byte slice, and an error if any occurs
+// during execution.
+func GenCurrentRevocationToken(vrfSecretKey []byte) (token *big.Int, epochBytes []byte, err error) {
+	current, err := epoch.Default.Current()
+	if err != nil {
+		return nil, nil, err
+	}
+	return GenRevocationToken(vrfSecretKey, current)
+}
+
+// GenRevocationToken generates a revocation token Hash(epoch || sk) for the given epoch using a VRF secret key.
+// It returns the token as a big.Int and the epoch in its 8-byte big-endian encoding.
+func GenRevocationToken(vrfSecretKey []byte, epochUnix int64) (token *big.Int, epochBytes []byte, err error) {
+	epochBytes = epoch.Bytes(epochUnix)
 
 	// Compute revocationToken = Hash(epoch || sk)
 	hf := mimc.NewMiMC()
-	_, err = hf.Write(epoch)
+	_, err = hf.Write(epochBytes)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -96,5 +145,13 @@ func GenCurrentRevocationToken(vrfSecretKey []byte) (token *big.Int, epoch []byt
 		return nil, nil, err
 	}
 	revocationToken := hf.Sum(nil)
-	return new(big.Int).SetBytes(revocationToken), epoch, nil
+	return new(big.Int).SetBytes(revocationToken), epochBytes, nil
+}
+
+// Challenge derives the challenge public input of a RevocationTokenProof from a nonce chosen by the verifier and the
+// verifier's identifier: keccak256(nonce || verifierID) reduced modulo the BN254 scalar field. The MultiShowVerifier
+// contract uses its address as identifier, i.e. computes uint256(keccak256(abi.encodePacked(nonce, address(this)))) % R.
+func Challenge(nonce [32]byte, verifierID []byte) *big.Int {
+	digest := crypto.Keccak256(nonce[:], verifierID)
+	return new(big.Int).Mod(new(big.Int).SetBytes(digest), ecc.BN254.ScalarField())
 }